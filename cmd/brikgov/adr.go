@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/BrikByte-Studios/github-governance/internal/adr"
+)
+
+func init() {
+	register(command{
+		name:    "adr",
+		summary: "Lint architecture decision records (lint)",
+		run:     runADR,
+	})
+}
+
+func runADR(args []string) error {
+	if len(args) == 0 || args[0] != "lint" {
+		return fmt.Errorf("usage: brikgov adr lint [--root DIR] [--glob PATTERN] [--out FILE]")
+	}
+	fs := newFlags("adr lint")
+	root := fs.String("root", ".", "repository root")
+	glob := fs.String("glob", adr.DefaultGlob, "ADR files, relative to --root")
+	out := fs.String("out", "", "records and findings JSON")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	files, err := adr.Glob(*root, *glob)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		fmt.Printf("No ADR files match %q; nothing to lint.\n", *glob)
+		return nil
+	}
+	recs, findings, err := adr.Lint(*root, files)
+	if err != nil {
+		return err
+	}
+	if *out != "" {
+		if err := writeJSON(*out, map[string]any{"records": recs, "findings": findings}); err != nil {
+			return err
+		}
+	}
+	if errs := reportFindings(findings); errs > 0 {
+		return failf("%d ADR error(s) in %d file(s)", errs, len(files))
+	}
+	fmt.Printf("✅ %d ADR file(s) valid\n", len(files))
+	return nil
+}
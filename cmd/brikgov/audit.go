@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/BrikByte-Studios/github-governance/internal/audit"
+)
+
+func init() {
+	register(command{
+		name:    "audit",
+		summary: "Compact old .audit records into tar.zst segments and verify the store (compact | check)",
+		run:     runAudit,
+	})
+}
+
+func runAudit(args []string) error {
+	if len(args) == 0 || (args[0] != "compact" && args[0] != "check") {
+		return fmt.Errorf("usage: brikgov audit compact|check [flags]")
+	}
+	sub := args[0]
+	fs := newFlags("audit " + sub)
+	dir := fs.String("dir", audit.DefaultDir, "audit directory")
+	olderThan := fs.Int("older-than-days", 30, "compact: records created more than this many days before --now")
+	now := fs.String("now", "", "compact: reference time (RFC 3339 or YYYY-MM-DD)")
+	out := fs.String("out", "", "check: JSON report (default stdout)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	store := &audit.Store{Dir: *dir}
+
+	if sub == "compact" {
+		at, err := parseNow(*now)
+		if err != nil {
+			return err
+		}
+		cutoff := at.AddDate(0, 0, -*olderThan)
+		seg, err := store.Compact(cutoff)
+		if err != nil {
+			return err
+		}
+		if seg == nil {
+			fmt.Printf("✅ No records before %s to compact\n", cutoff.Format("2006-01-02"))
+			return nil
+		}
+		fmt.Printf("✅ Compacted %d record(s) from %s to %s into %s (%d bytes, sha256 %s)\n", len(seg.Records),
+			seg.From.Format("2006-01-02"), seg.To.Format("2006-01-02"), seg.Name, seg.Size, seg.SHA256)
+		return nil
+	}
+
+	rep, err := store.Check()
+	if err != nil {
+		return err
+	}
+	if err := writeJSON(*out, rep); err != nil {
+		return err
+	}
+	for _, p := range rep.Problems {
+		fmt.Printf("::error title=audit integrity::%s\n", p)
+	}
+	for _, l := range rep.Leftovers {
+		fmt.Printf("::warning title=audit leftover::%s (removed by the next compaction)\n", l)
+	}
+	if len(rep.Problems) > 0 {
+		return failf("%d audit integrity problem(s)", len(rep.Problems))
+	}
+	if *out != "" {
+		fmt.Printf("✅ %d loose and %d compacted record(s) in %d segment(s) verified\n", rep.Loose, rep.Compacted, rep.Segments)
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/BrikByte-Studios/github-governance/internal/audit"
+	"github.com/BrikByte-Studios/github-governance/internal/bypass"
+	"github.com/BrikByte-Studios/github-governance/internal/ghexport"
+)
+
+func init() {
+	register(command{
+		name:    "bypass",
+		summary: "Governance bypasses in the audit log and their justifications (analyze | grant | decide)",
+		run:     runBypass,
+	})
+}
+
+func runBypass(args []string) error {
+	usage := fmt.Errorf("usage: brikgov bypass analyze|grant|decide [flags]")
+	if len(args) == 0 {
+		return usage
+	}
+	switch args[0] {
+	case "analyze":
+		return runBypassAnalyze(args[1:])
+	case "grant":
+		return runBypassGrant(args[1:])
+	case "decide":
+		return runBypassDecide(args[1:])
+	}
+	return usage
+}
+
+func bypassConfig(path string) (*bypass.Config, error) {
+	if path == "" {
+		return bypass.DefaultConfig(), nil
+	}
+	return bypass.LoadConfig(path)
+}
+
+func runBypassAnalyze(args []string) error {
+	fs := newFlags("bypass analyze")
+	logPath := fs.String("log", "", "organization audit log export, JSON or .csv (required)")
+	dir := fs.String("dir", audit.DefaultDir, "audit directory with break-glass and decision records")
+	policyPath := fs.String("policy", "", "effective policy for the bypass section (default: built-in)")
+	since := fs.String("since", "", "ignore events before this time (RFC 3339 or YYYY-MM-DD)")
+	until := fs.String("until", "", "ignore events from this time on")
+	out := fs.String("out", "", "JSON report (default stdout)")
+	md := fs.String("md", "", "write the Markdown report here")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *logPath == "" {
+		return fmt.Errorf("--log is required")
+	}
+	cfg, err := bypassConfig(*policyPath)
+	if err != nil {
+		return err
+	}
+	var from, to time.Time
+	if *since != "" {
+		if from, err = parseNow(*since); err != nil {
+			return err
+		}
+	}
+	if *until != "" {
+		if to, err = parseNow(*until); err != nil {
+			return err
+		}
+	}
+	entries, err := ghexport.LoadAuditLog(*logPath)
+	if err != nil {
+		return err
+	}
+	recs, err := (&audit.Store{Dir: *dir}).List(bypass.Namespace)
+	if err != nil {
+		return err
+	}
+	rep, err := bypass.Analyze(entries, recs, cfg, from, to)
+	if err != nil {
+		return err
+	}
+	if err := writeJSON(*out, rep); err != nil {
+		return err
+	}
+	if *md != "" {
+		if err := os.WriteFile(*md, []byte(bypass.Markdown(rep)), 0o644); err != nil {
+			return err
+		}
+	}
+	for _, f := range rep.Findings {
+		if !f.Justified {
+			fmt.Printf("::error title=%s unjustified bypass::%s: %s by @%s at %s (%s)\n",
+				f.Code, f.Repo, f.Kind, f.Actor, f.Time.Format(time.RFC3339), f.Action)
+		}
+	}
+	if rep.Unjustified > 0 {
+		return failf("%d of %d bypasses have no break-glass grant or decision", rep.Unjustified, rep.Bypasses)
+	}
+	if *out != "" {
+		fmt.Printf("✅ %d bypasses in %d events, all justified → %s\n", rep.Bypasses, rep.Scanned, *out)
+	}
+	return nil
+}
+
+func runBypassGrant(args []string) error {
+	fs := newFlags("bypass grant")
+	repo := fs.String("repo", "", "owner/name or glob (owner/*) (required)")
+	actor := fs.String("actor", "", "account allowed to bypass (required)")
+	reason := fs.String("reason", "", "why protection must be bypassed (required)")
+	incident := fs.String("incident", "", "incident issue or link")
+	approvedBy := fs.String("approved-by", "", "who approved the grant")
+	kinds := fs.String("kinds", "", "comma-separated bypass kinds (default: all)")
+	start := fs.String("start", "", "grant start (default now)")
+	hours := fs.Int("hours", 0, "grant length in hours (default bypass.max_grant_hours)")
+	policyPath := fs.String("policy", "", "effective policy for bypass.max_grant_hours")
+	dir := fs.String("dir", audit.DefaultDir, "audit directory")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	cfg, err := bypassConfig(*policyPath)
+	if err != nil {
+		return err
+	}
+	at, err := parseNow(*start)
+	if err != nil {
+		return err
+	}
+	if *hours == 0 {
+		*hours = cfg.MaxGrantHours
+	}
+	g := &bypass.Grant{
+		Repo: *repo, Actor: strings.TrimPrefix(*actor, "@"), Kinds: splitList(*kinds),
+		Reason: *reason, Incident: *incident, ApprovedBy: strings.TrimPrefix(*approvedBy, "@"),
+		StartsAt: at.UTC(), ExpiresAt: at.UTC().Add(time.Duration(*hours) * time.Hour),
+	}
+	store, err := recordStore(*dir)
+	if err != nil {
+		return err
+	}
+	_, path, err := bypass.RecordGrant(store, g, cfg)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ Break-glass for @%s on %s until %s (%s)\n", g.Actor, g.Repo, g.ExpiresAt.Format("2006-01-02 15:04 MST"), path)
+	return nil
+}
+
+func runBypassDecide(args []string) error {
+	fs := newFlags("bypass decide")
+	repo := fs.String("repo", "", "owner/name (required)")
+	kind := fs.String("kind", "", "bypass kind, e.g. force-push (required)")
+	actor := fs.String("actor", "", "account that bypassed (required)")
+	ref := fs.String("ref", "", "branch or tag ref (refs/heads/main)")
+	sha := fs.String("sha", "", "commit the bypass pushed or merged")
+	at := fs.String("at", "", "bypass time (RFC 3339), required without --sha")
+	reason := fs.String("reason", "", "why the bypass was acceptable (required)")
+	approvedBy := fs.String("approved-by", "", "comma-separated approvers other than the actor (required)")
+	ticket := fs.String("ticket", "", "change or incident ticket")
+	dir := fs.String("dir", audit.DefaultDir, "audit directory")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	d := &bypass.Decision{
+		Repo: *repo, Kind: *kind, Actor: strings.TrimPrefix(*actor, "@"), Ref: *ref, SHA: *sha,
+		Reason: *reason, Ticket: *ticket,
+	}
+	for _, u := range splitList(*approvedBy) {
+		d.ApprovedBy = append(d.ApprovedBy, strings.TrimPrefix(u, "@"))
+	}
+	if *at != "" {
+		t, err := parseNow(*at)
+		if err != nil {
+			return err
+		}
+		d.At = t.UTC()
+	}
+	store, err := recordStore(*dir)
+	if err != nil {
+		return err
+	}
+	_, path, err := bypass.RecordDecision(store, d)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ Decision for %s by @%s on %s recorded (%s)\n", d.Kind, d.Actor, d.Repo, path)
+	return nil
+}
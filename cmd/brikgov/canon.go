@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/BrikByte-Studios/github-governance/internal/canon"
+)
+
+func init() {
+	register(command{
+		name:    "canon",
+		summary: "Normalize JSON files to RFC 8785 canonical form (or its pretty form) and print their digests",
+		run:     runCanon,
+	})
+}
+
+func runCanon(args []string) error {
+	fs := newFlags("canon")
+	pretty := fs.Bool("pretty", false, "pretty form: canonical, indented by two spaces, with a trailing newline")
+	write := fs.Bool("w", false, "rewrite the files in place")
+	check := fs.Bool("check", false, "fail if a file is not already in the selected form")
+	digest := fs.Bool("digest", false, "print the SHA-256 of each file's canonical form (sha256sum format)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	files := fs.Args()
+	if len(files) == 0 {
+		if *write {
+			return fmt.Errorf("-w needs files")
+		}
+		files = []string{"-"}
+	}
+
+	var unformatted []string
+	for _, f := range files {
+		var raw []byte
+		var err error
+		if f == "-" {
+			raw, err = io.ReadAll(os.Stdin)
+		} else {
+			raw, err = os.ReadFile(f)
+		}
+		if err != nil {
+			return err
+		}
+		out, err := canon.Transform(raw)
+		if err != nil {
+			return fmt.Errorf("%s: %w", f, err)
+		}
+		if *digest {
+			d, _ := canon.Digest(out)
+			fmt.Printf("%s  %s\n", d, f)
+			continue
+		}
+		if *pretty {
+			out = append(canon.Indent(out), '\n')
+		}
+		switch {
+		case *check:
+			if !bytes.Equal(raw, out) {
+				unformatted = append(unformatted, f)
+				fmt.Printf("::error file=%s,title=canonical JSON::%s is not in %s form (run brikgov canon%s -w)\n", f, f, form(*pretty), flagIf(*pretty))
+			}
+		case *write:
+			if !bytes.Equal(raw, out) {
+				if err := os.WriteFile(f, out, 0o644); err != nil {
+					return err
+				}
+			}
+		default:
+			if _, err := os.Stdout.Write(out); err != nil {
+				return err
+			}
+		}
+	}
+	if len(unformatted) > 0 {
+		return failf("%d of %d file(s) are not in %s form", len(unformatted), len(files), form(*pretty))
+	}
+	if *check {
+		fmt.Printf("✅ %d file(s) in %s form\n", len(files), form(*pretty))
+	}
+	return nil
+}
+
+func form(pretty bool) string {
+	if pretty {
+		return "pretty canonical"
+	}
+	return "canonical"
+}
+
+func flagIf(pretty bool) string {
+	if pretty {
+		return " --pretty"
+	}
+	return ""
+}
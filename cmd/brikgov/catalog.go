@@ -0,0 +1,130 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/BrikByte-Studios/github-governance/internal/catalog"
+	"github.com/BrikByte-Studios/github-governance/internal/codeowners"
+	"github.com/BrikByte-Studios/github-governance/internal/pipeline"
+)
+
+func init() {
+	register(command{
+		name:    "catalog",
+		summary: "Validate and query the service catalog (validate | query)",
+		run:     runCatalog,
+	})
+}
+
+func runCatalog(args []string) error {
+	if len(args) == 0 || (args[0] != "validate" && args[0] != "query") {
+		return fmt.Errorf("usage: brikgov catalog validate|query [flags]")
+	}
+	sub := args[0]
+	fs := newFlags("catalog " + sub)
+	root := fs.String("root", ".", "repository root")
+	dir := fs.String("dir", "", "service catalog directory (default: "+catalog.DefaultDir+" under --root)")
+	schemaPath := fs.String("schema", "", "service schema (default: "+catalog.DefaultSchemaPath+" under --root)")
+	out := fs.String("out", "", "JSON output (default stdout)")
+	// validate
+	ownersPath := fs.String("codeowners", "", "validate: CODEOWNERS file (default: .github/CODEOWNERS, CODEOWNERS or docs/CODEOWNERS under --root)")
+	matrixPath := fs.String("matrix", "", "validate: runtime matrix (default: "+pipeline.DefaultMatrixPath+" under --root)")
+	// query
+	service := fs.String("service", "", "query: one service")
+	owner := fs.String("owner", "", "query: owning team (@org/team or team)")
+	runtime := fs.String("runtime", "", "query: runtime stack")
+	maxTier := fs.Int("max-tier", -1, "query: tiers 0..N only")
+	dependsOn := fs.String("depends-on", "", "query: direct dependents of a service, datastore or external dependency")
+	paths := fs.String("paths", "", "query: comma-separated changed paths, grouped by owning service")
+	blast := fs.String("blast-radius", "", "query: services depending on this one, directly or not")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if *dir == "" {
+		*dir = filepath.Join(*root, catalog.DefaultDir)
+	}
+	if *schemaPath == "" {
+		*schemaPath = filepath.Join(*root, catalog.DefaultSchemaPath)
+	}
+	schema, err := catalog.LoadSchema(*schemaPath)
+	if err != nil {
+		return err
+	}
+	cat, err := catalog.Load(*dir, schema)
+	var loadErr *catalog.LoadError
+	if sub == "validate" && errors.As(err, &loadErr) {
+		reportFindings(loadErr.Findings)
+		return failf("service catalog is invalid")
+	}
+	if err != nil {
+		return err
+	}
+
+	if sub == "query" {
+		switch {
+		case *service != "":
+			s, ok := cat.Service(*service)
+			if !ok {
+				return fmt.Errorf("no service %q in %s", *service, *dir)
+			}
+			return writeJSON(*out, s)
+		case *paths != "":
+			return writeJSON(*out, cat.ForPaths(splitList(*paths)))
+		case *blast != "":
+			return writeJSON(*out, map[string][]string{
+				"critical": cat.Dependents(*blast, true),
+				"all":      cat.Dependents(*blast, false),
+			})
+		}
+		return writeJSON(*out, cat.Find(catalog.Query{Owner: *owner, Runtime: *runtime, MaxTier: *maxTier, DependsOn: *dependsOn}))
+	}
+
+	env := catalog.Env{Root: *root}
+	if *ownersPath == "" {
+		for _, c := range codeowners.Candidates {
+			if p := filepath.Join(*root, c); fileExists(p) {
+				*ownersPath = p
+				break
+			}
+		}
+	}
+	if *ownersPath != "" {
+		if env.CODEOWNERS, err = codeowners.Load(*ownersPath); err != nil {
+			return err
+		}
+	}
+	if *matrixPath == "" {
+		*matrixPath = filepath.Join(*root, pipeline.DefaultMatrixPath)
+	}
+	if fileExists(*matrixPath) {
+		m, err := pipeline.LoadMatrix(*matrixPath)
+		if err != nil {
+			return err
+		}
+		env.Stacks = m.StackNames()
+	}
+	findings := catalog.Check(cat, env)
+	if err := writeJSON(*out, findings); err != nil {
+		return err
+	}
+	errs := 0
+	for _, f := range findings {
+		if f.Suppressed != "" {
+			fmt.Printf("::notice file=%s,title=%s catalog %s::%s (suppressed: %s)\n", f.File, f.Code, f.Service, f.Message, f.Suppressed)
+			continue
+		}
+		if f.Severity == catalog.SeverityError {
+			errs++
+		}
+		fmt.Printf("::%s file=%s,title=%s catalog %s::%s\n", f.Severity, f.File, f.Code, f.Service, f.Message)
+	}
+	if *out != "" {
+		fmt.Printf("✅ %d services, %d findings → %s\n", len(cat.Services), len(findings), *out)
+	}
+	if errs > 0 {
+		return failf("%d service catalog errors", errs)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/BrikByte-Studios/github-governance/internal/codes"
+)
+
+func init() {
+	register(command{
+		name:    "codes",
+		summary: "Stable error codes of the governance checks (list | explain CODE)",
+		run:     runCodes,
+	})
+}
+
+func runCodes(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: brikgov codes list [--json] | explain CODE")
+	}
+	switch args[0] {
+	case "list":
+		fs := newFlags("codes list")
+		asJSON := fs.Bool("json", false, "print the catalog as JSON")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *asJSON {
+			return writeJSON("", codes.All())
+		}
+		for _, e := range codes.All() {
+			fmt.Printf("%-8s  %-7s  %-36s  %s\n", e.Code, e.Severity, e.Owner, e.Title)
+		}
+		return nil
+	case "explain":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: brikgov codes explain CODE")
+		}
+		e, ok := codes.Lookup(strings.ToUpper(args[1]))
+		if !ok {
+			return fmt.Errorf("unknown code %q (see brikgov codes list)", args[1])
+		}
+		fmt.Printf("%s: %s\n\n", e.Code, e.Title)
+		fmt.Printf("Reported by: %s\nOwner:       %s\nSeverity:    %s\n\n", e.Check, e.Owner, e.Severity)
+		fmt.Printf("%s\n\nFix: %s\n", e.Explanation, e.Fix)
+		return nil
+	}
+	return fmt.Errorf("usage: brikgov codes list [--json] | explain CODE")
+}
+
+// reportFindings annotates coded findings and returns the number of
+// unsuppressed errors.
+func reportFindings(fs []codes.Finding) int {
+	for _, f := range fs {
+		fmt.Println(f.Annotation())
+	}
+	return codes.Errors(fs)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/BrikByte-Studios/github-governance/internal/audit"
+	"github.com/BrikByte-Studios/github-governance/internal/compliance"
+)
+
+func init() {
+	register(command{
+		name:    "compliance",
+		summary: "Control coverage report and auditor evidence pack (coverage | pack)",
+		run:     runCompliance,
+	})
+}
+
+func runCompliance(args []string) error {
+	if len(args) == 0 || (args[0] != "coverage" && args[0] != "pack") {
+		return fmt.Errorf("usage: brikgov compliance coverage|pack [flags]")
+	}
+	sub := args[0]
+	fs := newFlags("compliance " + sub)
+	catalogs := fs.String("catalogs", ".governance/compliance", "control catalog file or directory")
+	auditDir := fs.String("audit-dir", audit.DefaultDir, "audit directory")
+	root := fs.String("root", ".", "repository root for file evidence")
+	from := fs.String("from", "", "range start, YYYY-MM-DD (required)")
+	to := fs.String("to", "", "range end (inclusive), YYYY-MM-DD (required)")
+	out := fs.String("out", "", "coverage: JSON report path (default stdout); pack: zip path (required)")
+	now := fs.String("now", "", "pack timestamp (RFC 3339 or YYYY-MM-DD); defaults to the day after --to")
+	requireFull := fs.Bool("require-full", false, "exit 1 unless every control is covered")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if *from == "" || *to == "" {
+		return fmt.Errorf("--from and --to are required")
+	}
+	rng, err := compliance.ParseRange(*from, *to)
+	if err != nil {
+		return err
+	}
+	cats, err := compliance.LoadCatalogs(*catalogs)
+	if err != nil {
+		return err
+	}
+	recs, err := (&audit.Store{Dir: *auditDir}).List("")
+	if err != nil {
+		return err
+	}
+	cov, err := compliance.Evaluate(cats, recs, *root, rng)
+	if err != nil {
+		return err
+	}
+
+	switch sub {
+	case "coverage":
+		if err := writeJSON(*out, cov); err != nil {
+			return err
+		}
+		if *out != "" {
+			fmt.Print(cov.Markdown())
+		}
+	case "pack":
+		if *out == "" {
+			return fmt.Errorf("--out is required for pack")
+		}
+		gen := rng.To
+		if *now != "" {
+			if gen, err = parseNow(*now); err != nil {
+				return err
+			}
+		}
+		if err := os.MkdirAll(filepath.Dir(*out), 0o755); err != nil {
+			return err
+		}
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		m, err := compliance.WritePack(f, cov, cats, *root, gen)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(*out)
+			return err
+		}
+		fmt.Printf("✅ Evidence pack written: %s (%d entries, %d audit record(s), %d artifact(s))\n",
+			*out, len(m.Entries)+2, m.Records, m.Artifacts)
+	}
+
+	if *requireFull {
+		gaps := 0
+		for _, c := range cov.Catalogs {
+			gaps += c.Partial + c.Missing
+		}
+		if gaps > 0 {
+			return failf("%d control(s) not fully covered", gaps)
+		}
+	}
+	return nil
+}
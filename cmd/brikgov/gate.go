@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/BrikByte-Studios/github-governance/internal/gate"
+)
+
+func init() {
+	register(command{
+		name:    "gate",
+		summary: "Evaluate the policy gate rules against CI inputs and waivers",
+		run:     runGate,
+	})
+}
+
+func runGate(args []string) error {
+	fs := newFlags("gate")
+	policyPath := fs.String("policy", "", "effective policy with rules (from `brikgov policy merge`) (required)")
+	inputsPath := fs.String("inputs", "", "gate inputs JSON (coverage, tests, security, meta, ...) (required)")
+	waiversPath := fs.String("waivers", "", "waivers file (JSON or YAML)")
+	root := fs.String("root", ".", "local clone for rules that inspect the repository")
+	now := fs.String("now", "", "evaluation time for waiver TTLs (RFC 3339 or YYYY-MM-DD)")
+	out := fs.String("out", "", "decision JSON (default stdout)")
+	workers := fs.Int("workers", 0, "rules evaluated at once (default: number of CPUs)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *policyPath == "" || *inputsPath == "" {
+		return fmt.Errorf("--policy and --inputs are required")
+	}
+
+	pol, err := gate.LoadPolicy(*policyPath)
+	if err != nil {
+		return err
+	}
+	inputs, err := gate.LoadInputs(*inputsPath)
+	if err != nil {
+		return err
+	}
+	var waivers []gate.Waiver
+	if *waiversPath != "" {
+		if waivers, err = gate.LoadWaivers(*waiversPath); err != nil {
+			return err
+		}
+	}
+	at, err := parseNow(*now)
+	if err != nil {
+		return err
+	}
+
+	// A cancelled job still writes a decision: unfinished rules are errors.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	d := gate.EvaluateContext(ctx, pol, gate.Context{Inputs: inputs, Root: *root, Now: at}, waivers, gate.Options{Workers: *workers})
+	return reportDecision(d, *out)
+}
+
+// reportDecision writes the decision, annotates failing rules and fails on
+// a failed gate.
+func reportDecision(d *gate.Decision, out string) error {
+	if err := writeJSON(out, d); err != nil {
+		return err
+	}
+	for _, w := range d.WaiversIgnored {
+		fmt.Printf("::notice title=waiver ignored::%s: %s\n", w.Rule, w.Why)
+	}
+	for _, r := range d.Rules {
+		if r.Result != gate.ResultFail && r.Result != gate.ResultError {
+			continue
+		}
+		switch {
+		case r.Waived:
+			fmt.Printf("::warning title=%s %s (waived)::%s\n", r.Code, r.ID, r.Message)
+		case r.Severity == gate.SeverityWarn:
+			fmt.Printf("::warning title=%s %s::%s\n", r.Code, r.ID, r.Message)
+		default:
+			fmt.Printf("::error title=%s %s::%s\n", r.Code, r.ID, r.Message)
+		}
+	}
+	if d.Status == gate.StatusFailed {
+		return failf("policy gate failed (score %d)", d.Score)
+	}
+	if out != "" {
+		fmt.Printf("✅ Policy gate %s (score %d)\n", d.Status, d.Score)
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/BrikByte-Studios/github-governance/internal/audit"
+	"github.com/BrikByte-Studios/github-governance/internal/ghexport"
+	"github.com/BrikByte-Studios/github-governance/internal/hotfix"
+	"github.com/BrikByte-Studios/github-governance/internal/issueform"
+	"github.com/BrikByte-Studios/github-governance/internal/redact"
+	"github.com/BrikByte-Studios/github-governance/internal/reviews"
+)
+
+func init() {
+	register(command{
+		name:    "hotfix",
+		summary: "Hotfix fast path and post-merge review obligations (check | open | clear | status | release-check)",
+		run:     runHotfix,
+	})
+}
+
+// hotfixDecision is the decision file written by `hotfix check`.
+type hotfixDecision struct {
+	Reviews reviews.Result  `json:"reviews"`
+	Hotfix  hotfix.Decision `json:"hotfix"`
+}
+
+func runHotfix(args []string) error {
+	usage := fmt.Errorf("usage: brikgov hotfix check|open|clear|status|release-check [flags]")
+	if len(args) == 0 {
+		return usage
+	}
+	switch args[0] {
+	case "check":
+		return runHotfixCheck(args[1:])
+	case "open":
+		return runHotfixOpen(args[1:])
+	case "clear":
+		return runHotfixClear(args[1:])
+	case "status", "release-check":
+		return runHotfixStatus(args[0], args[1:])
+	}
+	return usage
+}
+
+func runHotfixCheck(args []string) error {
+	fs := newFlags("hotfix check")
+	policyPath := fs.String("policy", "", "effective policy (from `brikgov policy merge`) (required)")
+	reviewsPath := fs.String("reviews", "", "reviews evidence JSON (required)")
+	issuesPath := fs.String("issues", "", "gh issue export containing the linked incident")
+	incidentNo := fs.Int("incident", 0, "linked incident number (default: \"Incident: #<n>\" in the PR body)")
+	incidentForm := fs.String("incident-form", ".github/ISSUE_TEMPLATE/incident.yml", "incident issue form")
+	decision := fs.String("decision", "", "decision JSON (default stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *policyPath == "" || *reviewsPath == "" {
+		return fmt.Errorf("--policy and --reviews are required")
+	}
+	cfg, err := hotfix.LoadConfig(*policyPath)
+	if err != nil {
+		return err
+	}
+	rp, err := reviews.LoadPolicy(*policyPath)
+	if err != nil {
+		return err
+	}
+	ev, err := reviews.LoadEvidence(*reviewsPath)
+	if err != nil {
+		return err
+	}
+
+	var (
+		inc    *hotfix.Incident
+		incErr error
+	)
+	n := *incidentNo
+	if n == 0 {
+		n = hotfix.LinkedIncident(ev.Body)
+	}
+	if n > 0 {
+		if *issuesPath == "" {
+			incErr = fmt.Errorf("incident #%d linked but no --issues export given", n)
+		} else {
+			form, err := issueform.LoadTemplate(*incidentForm)
+			if err != nil {
+				return err
+			}
+			issues, err := ghexport.LoadIssues(*issuesPath)
+			if err != nil {
+				return err
+			}
+			inc, incErr = hotfix.FindIncident(issues, form, n)
+		}
+	}
+
+	res, d := hotfix.Check(cfg, rp, *ev, inc, incErr)
+	if err := writeJSON(*decision, hotfixDecision{Reviews: res, Hotfix: d}); err != nil {
+		return err
+	}
+	for _, note := range d.Notes {
+		fmt.Fprintf(os.Stderr, "::notice title=hotfix::%s\n", note)
+	}
+	if res.Result != reviews.ResultPass {
+		return failf("reviews (%s): %s", d.Mode, strings.Join(res.Reasons, "; "))
+	}
+	fmt.Fprintf(os.Stderr, "✅ Reviews passed (%s, %s)\n", d.Mode, res.Requirement.Source)
+	return nil
+}
+
+// recordStore is an audit store with the default redaction detectors, for
+// records the CLI writes itself.
+func recordStore(dir string) (*audit.Store, error) {
+	r, err := redact.New(redact.Config{})
+	if err != nil {
+		return nil, err
+	}
+	return &audit.Store{Dir: dir, Sanitizer: r}, nil
+}
+
+func runHotfixOpen(args []string) error {
+	fs := newFlags("hotfix open")
+	decisionPath := fs.String("decision", "", "decision JSON from `hotfix check` (required)")
+	author := fs.String("author", "", "PR author (their review does not clear the obligation)")
+	mergedAt := fs.String("merged-at", "", "merge time (RFC 3339 or YYYY-MM-DD; default now)")
+	dir := fs.String("dir", audit.DefaultDir, "audit directory")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *decisionPath == "" {
+		return fmt.Errorf("--decision is required")
+	}
+	raw, err := os.ReadFile(*decisionPath)
+	if err != nil {
+		return err
+	}
+	var d hotfixDecision
+	if err := json.Unmarshal(raw, &d); err != nil {
+		return fmt.Errorf("%s: %w", *decisionPath, err)
+	}
+	if d.Hotfix.Mode != hotfix.ModeFastPath {
+		fmt.Printf("✅ PR #%d merged via %s reviews; no post-merge obligation\n", d.Hotfix.PR, d.Hotfix.Mode)
+		return nil
+	}
+	at, err := parseNow(*mergedAt)
+	if err != nil {
+		return err
+	}
+	o, err := hotfix.NewObligation(d.Hotfix, strings.TrimPrefix(*author, "@"), at)
+	if err != nil {
+		return err
+	}
+	store, err := recordStore(*dir)
+	if err != nil {
+		return err
+	}
+	_, path, err := hotfix.Open(store, o)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ Post-merge review for PR #%d due %s (%s)\n", o.PR, o.DueAt.Format("2006-01-02 15:04 MST"), path)
+	return nil
+}
+
+func runHotfixClear(args []string) error {
+	fs := newFlags("hotfix clear")
+	pr := fs.Int("pr", 0, "hotfix PR number (required)")
+	reviewers := fs.String("reviewers", "", "comma-separated post-merge reviewers (required)")
+	completedAt := fs.String("completed-at", "", "review completion time (default now)")
+	notes := fs.String("notes", "", "review notes or link")
+	dir := fs.String("dir", audit.DefaultDir, "audit directory")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *pr == 0 || *reviewers == "" {
+		return fmt.Errorf("--pr and --reviewers are required")
+	}
+	at, err := parseNow(*completedAt)
+	if err != nil {
+		return err
+	}
+	r := &hotfix.Review{PR: *pr, CompletedAt: at.UTC(), Notes: *notes}
+	for _, u := range strings.Split(*reviewers, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			r.Reviewers = append(r.Reviewers, strings.TrimPrefix(u, "@"))
+		}
+	}
+	store, err := recordStore(*dir)
+	if err != nil {
+		return err
+	}
+	_, path, err := hotfix.Clear(store, r)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ Post-merge review of PR #%d recorded (%s)\n", *pr, path)
+	return nil
+}
+
+func runHotfixStatus(sub string, args []string) error {
+	fs := newFlags("hotfix " + sub)
+	dir := fs.String("dir", audit.DefaultDir, "audit directory")
+	now := fs.String("now", "", "evaluation time (RFC 3339 or YYYY-MM-DD)")
+	out := fs.String("out", "", "status: JSON report (default stdout)")
+	notify := fs.String("notify", "", "status: write an escalation Markdown for overdue reviews here")
+	failOverdue := fs.Bool("fail-on-overdue", false, "status: exit 1 when any review is overdue")
+	tag := fs.String("tag", "", "release-check: tag being created (for messages)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	at, err := parseNow(*now)
+	if err != nil {
+		return err
+	}
+	recs, err := (&audit.Store{Dir: *dir}).List(hotfix.Namespace)
+	if err != nil {
+		return err
+	}
+	statuses, err := hotfix.Evaluate(recs, at)
+	if err != nil {
+		return err
+	}
+
+	if sub == "release-check" {
+		blockers := hotfix.ReleaseBlockers(statuses)
+		for _, b := range blockers {
+			fmt.Printf("::error title=hotfix review pending::PR #%d post-merge review is %s (due %s, %d/%d reviews)\n",
+				b.PR, b.Status, b.DueAt.Format("2006-01-02 15:04 MST"), len(b.Reviewers), b.RequiredApprovals)
+		}
+		if len(blockers) > 0 {
+			return failf("%d hotfix post-merge review(s) must be cleared before tagging %s", len(blockers), orNext(*tag))
+		}
+		fmt.Printf("✅ No pending hotfix reviews block %s\n", orNext(*tag))
+		return nil
+	}
+
+	if err := writeJSON(*out, statuses); err != nil {
+		return err
+	}
+	overdue := 0
+	for _, s := range statuses {
+		if s.Status == hotfix.StatusOverdue {
+			overdue++
+			fmt.Fprintf(os.Stderr, "::warning title=hotfix review overdue::PR #%d is %dh past its post-merge review deadline; escalating to %s\n",
+				s.PR, s.OverdueHours, strings.Join(s.EscalateTo, " "))
+		}
+	}
+	if *notify != "" && overdue > 0 {
+		if err := os.WriteFile(*notify, []byte(hotfix.Escalation(statuses)), 0o644); err != nil {
+			return err
+		}
+	}
+	if *failOverdue && overdue > 0 {
+		return failf("%d hotfix post-merge review(s) overdue", overdue)
+	}
+	return nil
+}
+
+func orNext(tag string) string {
+	if tag == "" {
+		return "the next release"
+	}
+	return tag
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/BrikByte-Studios/github-governance/internal/hotfix"
+)
+
+// TestHotfixStatusStdoutIsJSON: overdue warnings go to stderr, so the
+// default stdout report stays valid JSON.
+func TestHotfixStatusStdoutIsJSON(t *testing.T) {
+	dir := t.TempDir()
+	store, err := recordStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	merged := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	o := &hotfix.Obligation{PR: 77, Branch: "hotfix/a", MergedAt: merged, DueAt: merged.Add(48 * time.Hour),
+		RequiredApprovals: 1, EscalateTo: []string{"@BrikByte-Studios/platform-leads"}}
+	if _, _, err := hotfix.Open(store, o); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := captureStdout(t, func() error {
+		return runHotfixStatus("status", []string{"--dir", dir, "--now", "2026-03-10"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var statuses []hotfix.ObligationStatus
+	if err := json.Unmarshal(out, &statuses); err != nil {
+		t.Fatalf("stdout is not the JSON report: %v\n%s", err, out)
+	}
+	if len(statuses) != 1 || statuses[0].Status != hotfix.StatusOverdue {
+		t.Errorf("statuses = %+v", statuses)
+	}
+}
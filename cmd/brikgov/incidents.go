@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/BrikByte-Studios/github-governance/internal/ghexport"
+	"github.com/BrikByte-Studios/github-governance/internal/incidents"
+	"github.com/BrikByte-Studios/github-governance/internal/issueform"
+)
+
+func init() {
+	register(command{
+		name:    "incidents",
+		summary: "Track incident follow-up actions, draft or create task issues and flag repeat incidents",
+		run:     runIncidents,
+	})
+}
+
+func runIncidents(args []string) error {
+	fs := newFlags("incidents")
+	issuesPath := fs.String("issues", "", "gh issue export (JSON) containing incident and task issues (required)")
+	postmortems := fs.String("postmortems", "", "directory of postmortem Markdown files")
+	incidentForm := fs.String("incident-form", ".github/ISSUE_TEMPLATE/incident.yml", "incident issue form")
+	taskForm := fs.String("task-form", ".github/ISSUE_TEMPLATE/task.yml", "task issue form used for drafts")
+	teamsPath := fs.String("teams", "", "YAML mapping of team → member handles")
+	out := fs.String("out", "", "write the JSON report here (default: stdout)")
+	tasksOut := fs.String("tasks-out", "", "write task issue drafts for untracked actions here")
+	createTasks := fs.Bool("create-tasks", false, "open each task draft as an issue with gh issue create")
+	repo := fs.String("repo", "", "repository for --create-tasks (OWNER/REPO; default: gh's current repository)")
+	now := fs.String("now", "", "evaluation time (RFC 3339 or YYYY-MM-DD)")
+	failOverdue := fs.Bool("fail-on-overdue", false, "exit 1 when any action is overdue or a repeat incident is found")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *issuesPath == "" {
+		return fmt.Errorf("--issues is required")
+	}
+
+	at, err := parseNow(*now)
+	if err != nil {
+		return err
+	}
+	form, err := issueform.LoadTemplate(*incidentForm)
+	if err != nil {
+		return err
+	}
+	issues, err := ghexport.LoadIssues(*issuesPath)
+	if err != nil {
+		return err
+	}
+	in := incidents.Input{Form: form, Issues: issues, Now: at}
+	if *postmortems != "" {
+		pms, skipped, err := incidents.LoadPostmortems(*postmortems)
+		if err != nil {
+			return err
+		}
+		for _, s := range skipped {
+			fmt.Fprintf(os.Stderr, "::warning file=%s::postmortem has no 'Incident: #<n>' line; skipped\n", s)
+		}
+		in.Postmortems = pms
+	}
+	if *teamsPath != "" {
+		if in.Teams, err = incidents.LoadTeams(*teamsPath); err != nil {
+			return err
+		}
+	}
+
+	rep := incidents.Track(in)
+	if err := writeJSON(*out, rep); err != nil {
+		return err
+	}
+	if *tasksOut != "" || *createTasks {
+		tf, err := issueform.LoadTemplate(*taskForm)
+		if err != nil {
+			return err
+		}
+		drafts := incidents.DraftTasks(rep, tf)
+		if *tasksOut != "" {
+			if err := writeJSON(*tasksOut, drafts); err != nil {
+				return err
+			}
+		}
+		if *createTasks {
+			for _, d := range drafts {
+				url, err := createTask(*repo, d)
+				if err != nil {
+					return fmt.Errorf("incident #%d %q: %w", d.Incident, d.Action, err)
+				}
+				fmt.Fprintf(os.Stderr, "::notice::created %s for incident #%d: %s\n", url, d.Incident, d.Action)
+			}
+		}
+	}
+
+	if *out != "" {
+		fmt.Printf("Incidents: %d • open: %d • overdue: %d • closed: %d • repeats: %d\n",
+			len(rep.Incidents), rep.Totals.Open, rep.Totals.Overdue, rep.Totals.Closed, len(rep.Repeats))
+		for _, t := range rep.Teams {
+			fmt.Printf("  %-16s open=%d overdue=%d closed=%d\n", t.Team, t.Counts.Open, t.Counts.Overdue, t.Counts.Closed)
+		}
+	}
+	for _, r := range rep.Repeats {
+		fmt.Fprintf(os.Stderr, "::warning::repeat incident #%d (failure mode %q) follows %v with %d incomplete action(s)\n",
+			r.Incident, r.FailureMode, r.Previous, r.OpenActions)
+	}
+	if *failOverdue && (rep.Totals.Overdue > 0 || len(rep.Repeats) > 0) {
+		return failf("%d overdue action(s), %d repeat incident(s)", rep.Totals.Overdue, len(rep.Repeats))
+	}
+	return nil
+}
+
+// createTask opens a task draft as an issue and returns its URL. The next
+// run links the action to it by title (incidents.Track), so drafts are not
+// created twice.
+func createTask(repo string, d incidents.TaskDraft) (string, error) {
+	args := []string{"issue", "create", "--title", d.Title, "--body-file", "-"}
+	if repo != "" {
+		args = append(args, "--repo", repo)
+	}
+	for _, l := range d.Labels {
+		args = append(args, "--label", l)
+	}
+	for _, a := range d.Assignees {
+		args = append(args, "--assignee", a)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("gh", args...)
+	cmd.Stdin = strings.NewReader(d.Body)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("gh issue create: %s", strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
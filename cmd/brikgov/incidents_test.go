@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/BrikByte-Studios/github-governance/internal/ghexport"
+	"github.com/BrikByte-Studios/github-governance/internal/incidents"
+)
+
+// TestIncidentsStdoutIsJSON: without --out the report is the only thing on
+// stdout, so "brikgov incidents ... > report.json" stays valid JSON even
+// when there are annotations to print.
+func TestIncidentsStdoutIsJSON(t *testing.T) {
+	dir := t.TempDir()
+	incident := func(n int, created string) ghexport.Issue {
+		ts, _ := time.Parse(time.RFC3339, created)
+		return ghexport.Issue{
+			Number: n, Title: "[INCIDENT] gateway timeouts", State: "OPEN", CreatedAt: ts,
+			Labels: []ghexport.Label{{Name: "type:incident"}},
+			Body: "### Failure Mode (Inversion Thinking)\n\nGateway always answers within 2s\n\n" +
+				"### Follow-up Actions / Action Items\n\n- [ ] Add timeout @alice\n",
+		}
+	}
+	raw, err := json.Marshal([]ghexport.Issue{incident(1, "2026-01-01T00:00:00Z"), incident(2, "2026-02-01T00:00:00Z")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	issues := filepath.Join(dir, "issues.json")
+	pms := filepath.Join(dir, "postmortems")
+	if err := os.WriteFile(issues, raw, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(pms, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(pms, "unlinked.md"), []byte("# Postmortem\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := captureStdout(t, func() error {
+		return runIncidents([]string{"--issues", issues, "--postmortems", pms, "--now", "2026-03-05",
+			"--incident-form", filepath.Join("..", "..", ".github", "ISSUE_TEMPLATE", "incident.yml")})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rep incidents.Report
+	if err := json.Unmarshal(out, &rep); err != nil {
+		t.Fatalf("stdout is not the JSON report: %v\n%s", err, out)
+	}
+	if len(rep.Incidents) != 2 || len(rep.Repeats) != 1 {
+		t.Errorf("report = %+v", rep)
+	}
+}
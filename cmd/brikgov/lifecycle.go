@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BrikByte-Studios/github-governance/internal/lifecycle"
+)
+
+func init() {
+	register(command{
+		name:    "lifecycle",
+		summary: "Repository lifecycle stages, archival plan and owner notifications",
+		run:     runLifecycle,
+	})
+}
+
+// lifecycleReport is the JSON report of `brikgov lifecycle`.
+type lifecycleReport struct {
+	Repos         []lifecycle.Status       `json:"repos"`
+	Plan          []lifecycle.PlanItem     `json:"plan"`
+	Notifications []lifecycle.Notification `json:"notifications"`
+}
+
+func runLifecycle(args []string) error {
+	fs := newFlags("lifecycle")
+	snapshot := fs.String("snapshot", "", "directory of <owner>/<name>/ repository snapshots (required)")
+	policyPath := fs.String("policy", "", "effective policy for the lifecycle section (default: built-in)")
+	now := fs.String("now", "", "evaluation time (RFC 3339 or YYYY-MM-DD)")
+	out := fs.String("out", "", "JSON report (default stdout)")
+	report := fs.String("report", "", "write the Markdown plan here")
+	notify := fs.String("notify", "", "write one Markdown notification per owner into this directory")
+	failBlocked := fs.Bool("fail-on-blocked", false, "exit 1 when a deprecated repository fails its requirements")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *snapshot == "" {
+		return fmt.Errorf("--snapshot is required")
+	}
+	cfg := lifecycle.DefaultConfig()
+	if *policyPath != "" {
+		var err error
+		if cfg, err = lifecycle.LoadConfig(*policyPath); err != nil {
+			return err
+		}
+	}
+	at, err := parseNow(*now)
+	if err != nil {
+		return err
+	}
+	repos, err := lifecycle.LoadSnapshot(*snapshot)
+	if err != nil {
+		return err
+	}
+	statuses, err := lifecycle.Evaluate(repos, cfg, at)
+	if err != nil {
+		return err
+	}
+	plan := lifecycle.BuildPlan(statuses, cfg, at)
+	rep := lifecycleReport{Repos: statuses, Plan: plan, Notifications: lifecycle.Notifications(plan)}
+	if err := writeJSON(*out, rep); err != nil {
+		return err
+	}
+	if *report != "" {
+		if err := os.WriteFile(*report, []byte(lifecycle.PlanMarkdown(statuses, plan)), 0o644); err != nil {
+			return err
+		}
+	}
+	if *notify != "" {
+		if err := os.MkdirAll(*notify, 0o755); err != nil {
+			return err
+		}
+		for _, n := range rep.Notifications {
+			name := strings.NewReplacer("@", "", "/", "-", "(", "", ")", "").Replace(n.Owner) + ".md"
+			if err := os.WriteFile(filepath.Join(*notify, name), []byte(lifecycle.NotificationMarkdown(n)), 0o644); err != nil {
+				return err
+			}
+		}
+	}
+
+	blocked := 0
+	for _, it := range plan {
+		switch it.Action {
+		case lifecycle.ActionBlocked:
+			blocked++
+			fmt.Printf("::error title=lifecycle::%s cannot be archived on %s: %s\n", it.Repo, it.ArchiveOn.Format("2006-01-02"), strings.Join(it.Reasons, "; "))
+		case lifecycle.ActionArchive:
+			fmt.Printf("::notice title=lifecycle::%s is ready to archive (%s)\n", it.Repo, it.Command)
+		case lifecycle.ActionProposeDeprecation, lifecycle.ActionFix:
+			fmt.Printf("::warning title=lifecycle::%s: %s: %s\n", it.Repo, it.Action, strings.Join(it.Reasons, "; "))
+		}
+	}
+	if *out != "" {
+		fmt.Printf("✅ %d repositories, %d plan items → %s\n", len(statuses), len(plan), *out)
+	}
+	if *failBlocked && blocked > 0 {
+		return failf("%d deprecated repositories fail their lifecycle requirements", blocked)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+// Command brikgov is the BrikByte Studios governance CLI.
+//
+// Each subcommand lives in its own file and registers itself from init(), so
+// adding a tool never touches the dispatcher.
+//
+// Usage:
+//
+//	brikgov <command> [flags]
+//	brikgov help
+//
+// Exit codes follow the Node governance scripts: 0 = ok, 1 = a governance
+// check failed, 2 = usage or input error.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/BrikByte-Studios/github-governance/internal/canon"
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+var commands = map[string]command{}
+
+func register(c command) {
+	if _, dup := commands[c.name]; dup {
+		panic("brikgov: duplicate command " + c.name)
+	}
+	commands[c.name] = c
+}
+
+// failure signals a governance check that ran correctly but did not pass.
+type failure struct{ msg string }
+
+func (f failure) Error() string { return f.msg }
+
+func failf(format string, args ...any) error { return failure{fmt.Sprintf(format, args...)} }
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "help" || os.Args[1] == "-h" || os.Args[1] == "--help" {
+		usage()
+		if len(os.Args) < 2 {
+			os.Exit(2)
+		}
+		return
+	}
+	c, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "brikgov: unknown command %q\n\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+	if err := c.run(os.Args[2:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		var f failure
+		if errors.As(err, &f) {
+			fmt.Fprintf(os.Stderr, "❌ %s: %s\n", c.name, f.msg)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "ERROR: %s: %v\n", c.name, err)
+		os.Exit(2)
+	}
+}
+
+func usage() {
+	names := make([]string, 0, len(commands))
+	for n := range commands {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	fmt.Fprintln(os.Stderr, "Usage: brikgov <command> [flags]")
+	fmt.Fprintln(os.Stderr, "\nCommands:")
+	for _, n := range names {
+		fmt.Fprintf(os.Stderr, "  %-20s %s\n", n, commands[n].summary)
+	}
+}
+
+// newFlags returns a FlagSet that reports errors instead of exiting, so
+// main() owns the exit code.
+func newFlags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("brikgov "+name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	return fs
+}
+
+// parseNow parses a --now override (RFC 3339 or YYYY-MM-DD); empty means the
+// current time. Tests and CI replays pin it for reproducible reports.
+func parseNow(s string) (time.Time, error) {
+	if s == "" {
+		return time.Now().UTC(), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.UTC(), nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("--now: expected RFC 3339 or YYYY-MM-DD, got %q", s)
+	}
+	return t, nil
+}
+
+// writeJSON writes v as pretty canonical JSON (docs/governance/canonical-json.md),
+// creating parent directories. An empty path or "-" writes to stdout.
+func writeJSON(path string, v any) error {
+	raw, err := canon.MarshalPretty(v)
+	if err != nil {
+		return err
+	}
+	raw = append(raw, '\n')
+	if path == "" || path == "-" {
+		_, err = os.Stdout.Write(raw)
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, raw, 0o644)
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BrikByte-Studios/github-governance/internal/gate"
+	"github.com/BrikByte-Studios/github-governance/internal/gitrepo"
+	"github.com/BrikByte-Studios/github-governance/internal/pipeline"
+)
+
+func init() {
+	register(command{
+		name:    "pipeline",
+		summary: "Build plan from brikpipe.build.yml and the runtime matrix (plan | generate | check | run)",
+		run:     runPipeline,
+	})
+}
+
+const pipelineUsage = "usage: brikgov pipeline plan|generate|check|run [flags]"
+
+// pipelineFlags are shared by every pipeline subcommand.
+type pipelineFlags struct {
+	root, config, matrix, repo, now *string
+}
+
+func newPipelineFlags(fs *flag.FlagSet) pipelineFlags {
+	return pipelineFlags{
+		root:   fs.String("root", ".", "repository root"),
+		config: fs.String("config", pipeline.DefaultConfigPath, "build config, relative to --root"),
+		matrix: fs.String("matrix", pipeline.DefaultMatrixPath, "runtime matrix (ADR-0001), relative to --root"),
+		repo:   fs.String("repo", os.Getenv("GITHUB_REPOSITORY"), "owner/name, for runtime matrix exceptions"),
+		now:    fs.String("now", "", "evaluation time for exception expiry (RFC 3339 or YYYY-MM-DD)"),
+	}
+}
+
+func (f pipelineFlags) path(rel string) string {
+	if filepath.IsAbs(rel) {
+		return rel
+	}
+	return filepath.Join(*f.root, rel)
+}
+
+// plan loads the inputs and resolves the build plan, printing its warnings
+// as annotations.
+func (f pipelineFlags) plan() (*pipeline.Plan, error) {
+	p, _, err := f.load()
+	return p, err
+}
+
+// load is plan that also returns the matrix.
+func (f pipelineFlags) load() (*pipeline.Plan, *pipeline.Matrix, error) {
+	m, err := pipeline.LoadMatrix(f.path(*f.matrix))
+	if err != nil {
+		return nil, nil, err
+	}
+	c, err := pipeline.LoadConfig(f.path(*f.config))
+	if err != nil {
+		return nil, nil, err
+	}
+	at, err := parseNow(*f.now)
+	if err != nil {
+		return nil, nil, err
+	}
+	p, err := pipeline.Resolve(c, m, pipeline.Options{Source: *f.config, Repo: *f.repo, Now: at})
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", *f.config, err)
+	}
+	for _, w := range p.Warnings {
+		fmt.Fprintf(os.Stderr, "::warning title=runtime matrix::%s\n", w)
+	}
+	return p, m, nil
+}
+
+func runPipeline(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf(pipelineUsage)
+	}
+	switch args[0] {
+	case "plan":
+		return runPipelinePlan(args[1:])
+	case "generate", "check":
+		return runPipelineGenerate(args[0], args[1:])
+	case "run":
+		return runPipelineRun(args[1:])
+	}
+	return fmt.Errorf(pipelineUsage)
+}
+
+func runPipelinePlan(args []string) error {
+	fs := newFlags("pipeline plan")
+	pf := newPipelineFlags(fs)
+	out := fs.String("out", "", "plan JSON (default stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	p, err := pf.plan()
+	if err != nil {
+		return err
+	}
+	return writeJSON(*out, p)
+}
+
+func runPipelineGenerate(sub string, args []string) error {
+	fs := newFlags("pipeline " + sub)
+	pf := newPipelineFlags(fs)
+	targetName := fs.String("target", "github", "CI system: "+strings.Join(pipeline.TargetNames(), "|"))
+	out := fs.String("out", "", "generated file, relative to --root (default: the target's path)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	t, ok := pipeline.Targets[*targetName]
+	if !ok {
+		return fmt.Errorf("unknown --target %q (%s)", *targetName, strings.Join(pipeline.TargetNames(), ", "))
+	}
+	if *out == "" {
+		*out = t.Path
+	}
+	p, err := pf.plan()
+	if err != nil {
+		return err
+	}
+	want, err := t.Render(p)
+	if err != nil {
+		return err
+	}
+	target := pf.path(*out)
+	regen := "brikgov pipeline generate"
+	if *targetName != "github" {
+		regen += " --target " + *targetName
+	}
+
+	if sub == "check" {
+		have, err := os.ReadFile(target)
+		if os.IsNotExist(err) {
+			return failf("%s does not exist; run `%s`", *out, regen)
+		}
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(have, want) {
+			fmt.Printf("✅ %s is up to date with %s\n", *out, *pf.config)
+			return nil
+		}
+		fmt.Print(pipeline.Diff("a/"+*out, "b/"+*out, string(have), string(want)))
+		fmt.Printf("::error file=%s::generated %s pipeline is out of date with %s or the runtime matrix\n", *out, *targetName, *pf.config)
+		return failf("%s is out of date; run `%s`", *out, regen)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(target, want, 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Wrote %s (%s %s, %s)\n", *out, p.Stack, p.RuntimeVersion, p.Toolchain)
+	return nil
+}
+
+// runPipelineRun executes the plan's steps locally in a temporary copy of
+// the repository, then gates the reports they produced.
+func runPipelineRun(args []string) error {
+	fs := newFlags("pipeline run")
+	pf := newPipelineFlags(fs)
+	only := fs.String("steps", "install,test,build", "comma-separated step ids to run")
+	base := fs.String("base", "", "base ref for diff-scoped gate rules (meta.base_sha is its merge base with HEAD)")
+	waiversPath := fs.String("waivers", "", "waivers file (JSON or YAML)")
+	keep := fs.Bool("keep", false, "keep the temporary workspace")
+	noGate := fs.Bool("no-gate", false, "skip the policy gate")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	p, m, err := pf.load()
+	if err != nil {
+		return err
+	}
+	root := *pf.root
+
+	local, err := pipeline.CheckRuntime(p, m)
+	if err != nil {
+		return failf("%v", err)
+	}
+	fmt.Printf("Runtime: %s %s (plan %s, %s)\n", p.Stack, local, p.RuntimeVersion, p.Toolchain)
+	if missing := pipeline.MissingTools(p); len(missing) > 0 {
+		return failf("not on PATH: %s", strings.Join(missing, ", "))
+	}
+
+	ws, err := os.MkdirTemp("", "brikpipe-run-")
+	if err != nil {
+		return err
+	}
+	if *keep {
+		fmt.Printf("Workspace: %s\n", ws)
+	} else {
+		defer os.RemoveAll(ws)
+	}
+	n, err := pipeline.CopyWorkspace(root, ws)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Copied %d files to the workspace\n", n)
+
+	logs := filepath.Join(root, pipeline.GateDir, "logs")
+	if err := os.MkdirAll(logs, 0o755); err != nil {
+		return err
+	}
+	selected := map[string]bool{}
+	for _, id := range strings.Split(*only, ",") {
+		selected[strings.TrimSpace(id)] = true
+	}
+	var stepErr error
+	for _, s := range p.Steps {
+		if !selected[s.ID] {
+			continue
+		}
+		fmt.Printf("::group::%s: %s\n", s.Name, s.Run)
+		log, err := os.Create(filepath.Join(logs, s.ID+".log"))
+		if err != nil {
+			return err
+		}
+		stepErr = pipeline.RunStep(p, s, ws, io.MultiWriter(os.Stdout, log))
+		log.Close()
+		fmt.Println("::endgroup::")
+		if stepErr != nil {
+			fmt.Printf("::error title=%s::%s failed: %v (log: %s)\n", s.ID, s.Run, stepErr, filepath.Join(pipeline.GateDir, "logs", s.ID+".log"))
+			stepErr = fmt.Errorf("%s step failed: %v", s.ID, stepErr)
+			break
+		}
+	}
+
+	reports, err := pipeline.CollectReports(p.Reports, ws, root)
+	if err != nil {
+		return err
+	}
+	for _, r := range reports {
+		fmt.Printf("Report: %s\n", r)
+	}
+	inputs, err := pipeline.Evidence(ws, p.Reports)
+	if err != nil {
+		return err
+	}
+	if meta := gitMeta(root, *base); len(meta) > 0 {
+		inputs["meta"] = meta
+	}
+	if err := writeJSON(filepath.Join(root, pipeline.GateDir, "inputs.json"), inputs); err != nil {
+		return err
+	}
+
+	if *noGate || p.Gate == nil {
+		if stepErr != nil {
+			return failf("%v", stepErr)
+		}
+		fmt.Printf("✅ %s steps passed\n", p.Name)
+		return nil
+	}
+	pol, err := gate.LoadPolicy(pf.path(p.Gate.Policy))
+	if err != nil {
+		return err
+	}
+	var waivers []gate.Waiver
+	if *waiversPath != "" {
+		if waivers, err = gate.LoadWaivers(*waiversPath); err != nil {
+			return err
+		}
+	}
+	at, err := parseNow(*pf.now)
+	if err != nil {
+		return err
+	}
+	d := gate.Evaluate(pol, gate.Context{Inputs: inputs, Root: root, Now: at}, waivers)
+	gateErr := reportDecision(d, filepath.Join(root, pipeline.GateDir, "decision.json"))
+	if stepErr != nil {
+		return failf("%v", stepErr)
+	}
+	return gateErr
+}
+
+// gitMeta is the meta section of local gate inputs: HEAD and, with a base
+// ref, its merge base.
+func gitMeta(root, base string) map[string]any {
+	meta := map[string]any{}
+	head, err := gitrepo.Run(context.Background(), root, "rev-parse", "HEAD")
+	if err != nil {
+		return meta
+	}
+	meta["head_sha"] = strings.TrimSpace(head)
+	if branch, err := gitrepo.Run(context.Background(), root, "rev-parse", "--abbrev-ref", "HEAD"); err == nil {
+		meta["branch"] = strings.TrimSpace(branch)
+	}
+	if base != "" {
+		if mb, err := gitrepo.MergeBase(context.Background(), root, base, "HEAD"); err == nil {
+			meta["base_sha"] = mb
+		} else {
+			fmt.Fprintf(os.Stderr, "::warning title=gate inputs::no merge base with %s: %v\n", base, err)
+		}
+	}
+	return meta
+}
@@ -0,0 +1,345 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BrikByte-Studios/github-governance/internal/codes"
+	"github.com/BrikByte-Studios/github-governance/internal/policy"
+)
+
+func init() {
+	register(command{
+		name:    "policy",
+		summary: "Layered policy (org → team → repo) and pinned refs (validate | merge | lock | verify | update)",
+		run:     runPolicy,
+	})
+}
+
+// policyFlags are shared by every policy subcommand.
+type policyFlags struct {
+	root, org, layers, repo, cache, lock *string
+}
+
+func newPolicyFlags(fs *flag.FlagSet) policyFlags {
+	return policyFlags{
+		root:   fs.String("root", ".", "repository root"),
+		org:    fs.String("org", policy.DefaultOrgPath, "org policy, relative to --root"),
+		layers: fs.String("layers", policy.DefaultLayersDir, "directory of named layers (<kind>/<name>.yml), relative to --root"),
+		repo:   fs.String("repo", policy.DefaultRepoPath, "repo overlay, relative to --root; skipped if absent"),
+		cache:  fs.String("cache", policy.DefaultCacheDir, "vendored pinned layers (<tag>/<path>), relative to --root"),
+		lock:   fs.String("lock", policy.DefaultLockPath, "policy lockfile, relative to --root"),
+	}
+}
+
+func (p policyFlags) source() policy.FileSource {
+	return policy.FileSource{Root: *p.root, OrgPath: *p.org, LayersDir: *p.layers, CacheDir: *p.cache}
+}
+
+func (p policyFlags) path(rel string) string {
+	if filepath.IsAbs(rel) {
+		return rel
+	}
+	return filepath.Join(*p.root, rel)
+}
+
+func runPolicy(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: brikgov policy validate|merge|lock|verify|update [flags]")
+	}
+	switch args[0] {
+	case "validate":
+		return runPolicyValidate(args[1:])
+	case "merge", "verify":
+		return runPolicyMerge(args[0], args[1:])
+	case "lock":
+		return runPolicyLock(args[1:])
+	case "update":
+		return runPolicyUpdate(args[1:])
+	}
+	return fmt.Errorf("usage: brikgov policy validate|merge|lock|verify|update [flags]")
+}
+
+func runPolicyMerge(sub string, args []string) error {
+	fs := newFlags("policy " + sub)
+	pf := newPolicyFlags(fs)
+	out := fs.String("out", "", "merge: effective policy JSON (default stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	eff, err := resolvePolicy(pf, pf.source())
+	if err != nil {
+		return err
+	}
+	lock, err := loadLockIfPresent(pf.path(*pf.lock))
+	if err != nil {
+		return err
+	}
+	if err := lock.Verify(eff); err != nil {
+		fmt.Printf("::error file=%s::%s\n", *pf.lock, strings.ReplaceAll(err.Error(), "\n", "%0A"))
+		return failf("%v", err)
+	}
+
+	if sub == "verify" {
+		fmt.Printf("✅ Policy chain verified: %s\n", chainString(eff))
+		return nil
+	}
+	if err := writeJSON(*out, eff.Policy); err != nil {
+		return err
+	}
+	if *out != "" {
+		fmt.Printf("✅ Effective policy written: %s (%s)\n", *out, chainString(eff))
+	}
+	return nil
+}
+
+// runPolicyValidate checks every layer file against the v1 schema (with
+// suppression comments applied), then resolves the chain so relaxations
+// are reported too.
+func runPolicyValidate(args []string) error {
+	fs := newFlags("policy validate")
+	pf := newPolicyFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	files := []string{*pf.org}
+	layers, err := filepath.Glob(filepath.Join(pf.path(*pf.layers), "*", "*.yml"))
+	if err != nil {
+		return err
+	}
+	for _, l := range layers {
+		rel, err := filepath.Rel(*pf.root, l)
+		if err != nil {
+			return err
+		}
+		files = append(files, rel)
+	}
+	if fileExists(pf.path(*pf.repo)) {
+		files = append(files, *pf.repo)
+	}
+
+	var findings []codes.Finding
+	for i, f := range files {
+		raw, err := os.ReadFile(pf.path(f))
+		if err != nil {
+			return err
+		}
+		f = filepath.ToSlash(f)
+		fs, err := policy.Validate(f, raw, i == 0)
+		if err != nil {
+			return failf("%v", err)
+		}
+		sup, problems := codes.ParseSuppressions(f, raw)
+		sup.Apply(fs)
+		findings = append(findings, fs...)
+		findings = append(findings, problems...)
+		findings = append(findings, sup.Unused()...)
+	}
+	codes.Sort(findings)
+	errs := reportFindings(findings)
+
+	eff, err := resolvePolicy(pf, pf.source())
+	if err != nil {
+		return err
+	}
+	if errs > 0 {
+		return failf("%d policy error(s) in %d file(s)", errs, len(files))
+	}
+	fmt.Printf("✅ Policy valid: %d file(s), chain %s\n", len(files), chainString(eff))
+	return nil
+}
+
+func runPolicyLock(args []string) error {
+	fs := newFlags("policy lock")
+	pf := newPolicyFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	eff, err := resolvePolicy(pf, pf.source())
+	if err != nil {
+		return err
+	}
+	lock := policy.BuildLock(eff)
+	if err := writeLock(pf.path(*pf.lock), lock); err != nil {
+		return err
+	}
+	fmt.Printf("✅ %s written (%d pinned layer(s))\n", *pf.lock, len(lock.Layers))
+	return nil
+}
+
+func runPolicyUpdate(args []string) error {
+	fs := newFlags("policy update")
+	pf := newPolicyFlags(fs)
+	to := fs.String("to", "", "tag to pin, vX.Y.Z (required)")
+	upstream := fs.String("upstream", "", "local clone of the governance repo with the tag fetched (required)")
+	apply := fs.Bool("apply", false, "vendor the new layers, bump the pin and rewrite policy.lock (default: show the diff only)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *to == "" || *upstream == "" {
+		return fmt.Errorf("--to and --upstream are required")
+	}
+
+	repoPath := pf.path(*pf.repo)
+	raw, err := os.ReadFile(repoPath)
+	if err != nil {
+		return err
+	}
+	leaf, err := policy.LoadLayer("repo", repoPath)
+	if err != nil {
+		return err
+	}
+	bumps := map[string]string{}
+	pinned := false
+	for i, e := range leaf.Extends {
+		name, tag := policy.SplitRef(e)
+		if tag == "" {
+			continue
+		}
+		pinned = true
+		if tag != *to {
+			bumps[e] = name + "@" + *to
+			leaf.Extends[i] = bumps[e]
+		}
+	}
+	if !pinned {
+		return fmt.Errorf("%s has no pinned extends (e.g. `extends: org@%s`)", *pf.repo, *to)
+	}
+
+	src := pf.source()
+	src.Fetch = policy.GitFetch(*upstream)
+	next, err := resolveLeaf(src, leaf)
+	if err != nil {
+		return err
+	}
+	// The current chain may not be vendored yet (first pin); diff against
+	// an empty policy then.
+	oldChain, oldPolicy := "(not vendored)", map[string]any{}
+	if old, err := resolvePolicy(pf, pf.source()); err == nil {
+		oldChain, oldPolicy = chainString(old), old.Policy
+	}
+
+	changes := policy.Diff(oldPolicy, next.Policy)
+	fmt.Printf("## Policy update: %s ⇒ %s\n\n", oldChain, chainString(next))
+	if len(changes) == 0 {
+		fmt.Println("No effective policy changes.")
+	}
+	for _, c := range changes {
+		fmt.Println(c)
+	}
+	if !*apply {
+		fmt.Println("\nRe-run with --apply to vendor the layers, bump the pin and rewrite policy.lock.")
+		return nil
+	}
+
+	written, err := policy.Vendor(next, *pf.root)
+	if err != nil {
+		return err
+	}
+	text := string(raw)
+	for from, to := range bumps {
+		text = strings.ReplaceAll(text, from, to)
+	}
+	if err := os.WriteFile(repoPath, []byte(text), 0o644); err != nil {
+		return err
+	}
+	lock := policy.BuildLock(next)
+	if err := writeLock(pf.path(*pf.lock), lock); err != nil {
+		return err
+	}
+	if err := pruneCache(pf.path(*pf.cache), lock); err != nil {
+		return err
+	}
+	fmt.Printf("\n✅ Pinned %s; vendored %d layer(s); %s updated\n", *to, len(written), *pf.lock)
+	return nil
+}
+
+// resolvePolicy resolves the repo overlay (or the org layer when there is
+// none), reporting relaxations as annotations.
+func resolvePolicy(pf policyFlags, src policy.FileSource) (*policy.Effective, error) {
+	var (
+		leaf *policy.Layer
+		err  error
+	)
+	if repoPath := pf.path(*pf.repo); fileExists(repoPath) {
+		leaf, err = policy.LoadLayer("repo", repoPath)
+	} else {
+		leaf, err = src.Load(policy.OrgRef)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resolveLeaf(src, leaf)
+}
+
+func resolveLeaf(src policy.Source, leaf *policy.Layer) (*policy.Effective, error) {
+	eff, err := policy.Resolve(src, leaf)
+	var relax *policy.RelaxError
+	if errors.As(err, &relax) {
+		for _, v := range relax.Violations {
+			fmt.Printf("::error title=%s policy %s::%s relaxes %v → %v (%s)\n", v.Code, v.Layer, v.Path, v.Parent, v.Child, v.Reason)
+		}
+		return nil, failf("%d inherited setting(s) relaxed", len(relax.Violations))
+	}
+	if err != nil {
+		return nil, failf("%v", err)
+	}
+	return eff, nil
+}
+
+func loadLockIfPresent(path string) (*policy.Lock, error) {
+	if !fileExists(path) {
+		return nil, nil
+	}
+	return policy.LoadLock(path)
+}
+
+func writeLock(path string, l *policy.Lock) error {
+	raw, err := l.Marshal()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, raw, 0o644)
+}
+
+// pruneCache removes vendored tags that the lock no longer references.
+func pruneCache(dir string, l *policy.Lock) error {
+	keep := map[string]bool{}
+	for _, e := range l.Layers {
+		_, tag := policy.SplitRef(e.Ref)
+		keep[tag] = true
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() && !keep[e.Name()] {
+			if err := os.RemoveAll(filepath.Join(dir, e.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func chainString(eff *policy.Effective) string {
+	refs := make([]string, len(eff.Chain))
+	for i, c := range eff.Chain {
+		refs[i] = c.Layer
+	}
+	return strings.Join(refs, " → ")
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
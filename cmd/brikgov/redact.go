@@ -0,0 +1,165 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/BrikByte-Studios/github-governance/internal/audit"
+	"github.com/BrikByte-Studios/github-governance/internal/ghexport"
+	"github.com/BrikByte-Studios/github-governance/internal/redact"
+)
+
+func init() {
+	register(command{
+		name:    "redact",
+		summary: "Redact or pseudonymize PII and secrets in text (or report them only)",
+		run:     runRedact,
+	})
+	register(command{
+		name:    "audit-store",
+		summary: "Redact an evidence file and store it as a .audit record",
+		run:     runAuditStore,
+	})
+}
+
+// redactorFlags registers the flags shared by redact and audit-store.
+func redactorFlags(fs *flag.FlagSet) func() (*redact.Redactor, error) {
+	cfgPath := fs.String("config", "", "redaction config YAML (custom patterns, allowlist, mode)")
+	mode := fs.String("mode", "", "override mode: redact | pseudonymize")
+	return func() (*redact.Redactor, error) {
+		var cfg redact.Config
+		if *cfgPath != "" {
+			c, err := redact.LoadConfig(*cfgPath)
+			if err != nil {
+				return nil, err
+			}
+			cfg = c
+		}
+		if *mode != "" {
+			cfg.Mode = redact.Mode(*mode)
+		}
+		return redact.New(cfg)
+	}
+}
+
+type issueFindings struct {
+	Number   int              `json:"number"`
+	URL      string           `json:"url,omitempty"`
+	Summary  map[string]int   `json:"summary"`
+	Findings []redact.Finding `json:"findings"`
+}
+
+func runRedact(args []string) error {
+	fs := newFlags("redact")
+	build := redactorFlags(fs)
+	in := fs.String("in", "-", "input file ('-' = stdin)")
+	out := fs.String("out", "-", "output file for redacted text ('-' = stdout)")
+	reportOnly := fs.Bool("report-only", false, "do not rewrite anything; emit findings as JSON")
+	report := fs.String("report", "", "findings JSON destination in report-only mode (default: stdout)")
+	issues := fs.String("issues", "", "gh issue export to scan (implies --report-only)")
+	failOn := fs.Bool("fail-on-findings", false, "exit 1 when anything is found")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	r, err := build()
+	if err != nil {
+		return err
+	}
+
+	if *issues != "" {
+		list, err := ghexport.LoadIssues(*issues)
+		if err != nil {
+			return err
+		}
+		var res []issueFindings
+		total := 0
+		for _, is := range list {
+			f := r.Scan(is.Title + "\n" + is.Body)
+			if len(f) == 0 {
+				continue
+			}
+			total += len(f)
+			res = append(res, issueFindings{Number: is.Number, URL: is.URL, Summary: redact.Summary(f), Findings: f})
+		}
+		if res == nil {
+			res = []issueFindings{}
+		}
+		if err := writeJSON(*report, res); err != nil {
+			return err
+		}
+		if *failOn && total > 0 {
+			return failf("%d finding(s) in %d issue(s)", total, len(res))
+		}
+		return nil
+	}
+
+	text, err := readInput(*in)
+	if err != nil {
+		return err
+	}
+	if *reportOnly {
+		f := r.Scan(string(text))
+		if err := writeJSON(*report, map[string]any{"summary": redact.Summary(f), "findings": f}); err != nil {
+			return err
+		}
+		if *failOn && len(f) > 0 {
+			return failf("%d finding(s)", len(f))
+		}
+		return nil
+	}
+
+	clean, f := r.Redact(string(text))
+	if *out == "-" {
+		_, err = io.WriteString(os.Stdout, clean)
+	} else {
+		err = os.WriteFile(*out, []byte(clean), 0o644)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "redact: %d value(s) replaced %v\n", len(f), redact.Summary(f))
+	if *failOn && len(f) > 0 {
+		return failf("%d finding(s)", len(f))
+	}
+	return nil
+}
+
+func runAuditStore(args []string) error {
+	fs := newFlags("audit-store")
+	build := redactorFlags(fs)
+	dir := fs.String("dir", audit.DefaultDir, "audit directory")
+	ns := fs.String("namespace", "", "record namespace, e.g. incidents/evidence (required)")
+	kind := fs.String("kind", "", "record kind, e.g. logs (required)")
+	subject := fs.String("subject", "", "what the evidence is about, e.g. issue#123")
+	in := fs.String("file", "-", "evidence file ('-' = stdin)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *ns == "" || *kind == "" {
+		return fmt.Errorf("--namespace and --kind are required")
+	}
+	r, err := build()
+	if err != nil {
+		return err
+	}
+	content, err := readInput(*in)
+	if err != nil {
+		return err
+	}
+	store := &audit.Store{Dir: *dir, Sanitizer: r}
+	rec, path, err := store.Put(*ns, *kind, *subject, content)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ Stored %s (%d redaction(s), sha256 %s)\n", path, rec.Redactions, rec.SHA256)
+	return nil
+}
+
+func readInput(path string) ([]byte, error) {
+	if path == "-" || path == "" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(path)
+}
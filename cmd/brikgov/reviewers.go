@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/BrikByte-Studios/github-governance/internal/codeowners"
+	"github.com/BrikByte-Studios/github-governance/internal/ghexport"
+	"github.com/BrikByte-Studios/github-governance/internal/gitrepo"
+	"github.com/BrikByte-Studios/github-governance/internal/people"
+	"github.com/BrikByte-Studios/github-governance/internal/reviews"
+)
+
+func init() {
+	register(command{
+		name:    "reviewers",
+		summary: "Suggest individual reviewers from CODEOWNERS, expertise, review load and availability",
+		run:     runReviewers,
+	})
+}
+
+func runReviewers(args []string) error {
+	fs := newFlags("reviewers")
+	prNo := fs.Int("pr", 0, "pull request number (required)")
+	prsPath := fs.String("prs", "", "gh pr export of open PRs, including --pr (required)")
+	policyPath := fs.String("policy", "", "effective policy (from `brikgov policy merge`) (required)")
+	ownersPath := fs.String("codeowners", "", "CODEOWNERS file (default: .github/CODEOWNERS, CODEOWNERS or docs/CODEOWNERS under --repo)")
+	peoplePath := fs.String("people", "", "people directory (teams, accounts, aliases) (required)")
+	oooPath := fs.String("ooo", "", "out-of-office file")
+	repo := fs.String("repo", ".", "local clone for commit history")
+	sinceDays := fs.Int("since-days", 90, "history window for expertise")
+	maxLoad := fs.Int("max-load", 0, "pending review requests per person before they are only a fallback (0: no cap)")
+	now := fs.String("now", "", "evaluation time for out-of-office (RFC 3339 or YYYY-MM-DD)")
+	out := fs.String("out", "", "recommendation JSON (default stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *prNo == 0 || *prsPath == "" || *policyPath == "" || *peoplePath == "" {
+		return fmt.Errorf("--pr, --prs, --policy and --people are required")
+	}
+
+	prs, err := ghexport.LoadPullRequests(*prsPath)
+	if err != nil {
+		return err
+	}
+	var pr *ghexport.PullRequest
+	for i := range prs {
+		if prs[i].Number == *prNo {
+			pr = &prs[i]
+		}
+	}
+	if pr == nil {
+		return fmt.Errorf("PR #%d not in %s", *prNo, *prsPath)
+	}
+	rp, err := reviews.LoadPolicy(*policyPath)
+	if err != nil {
+		return err
+	}
+	at, err := parseNow(*now)
+	if err != nil {
+		return err
+	}
+	s := reviews.Signals{Open: prs, Now: at, MaxLoad: *maxLoad}
+	if s.People, err = people.Load(*peoplePath); err != nil {
+		return err
+	}
+	if *oooPath != "" {
+		if s.Absences, err = people.LoadAbsences(*oooPath); err != nil {
+			return err
+		}
+	}
+	if *ownersPath == "" {
+		for _, c := range codeowners.Candidates {
+			if p := path.Join(*repo, c); fileExists(p) {
+				*ownersPath = p
+				break
+			}
+		}
+	}
+	if *ownersPath != "" {
+		if s.Owners, err = codeowners.Load(*ownersPath); err != nil {
+			return err
+		}
+	}
+	dirs := map[string]bool{}
+	for _, f := range pr.Files {
+		dirs[path.Dir(f.Path)] = true
+	}
+	if len(dirs) > 0 {
+		logArgs := []string{fmt.Sprintf("--since=%d.days", *sinceDays), "--no-merges", "--"}
+		for d := range dirs {
+			logArgs = append(logArgs, d)
+		}
+		sort.Strings(logArgs[3:])
+		if s.History, err = gitrepo.LogFiles(context.Background(), *repo, logArgs...); err != nil {
+			return err
+		}
+	}
+
+	rec := reviews.Recommend(*pr, rp.For(pr.BaseRefName), s)
+	if err := writeJSON(*out, rec); err != nil {
+		return err
+	}
+	for _, u := range rec.Unavailable {
+		fmt.Printf("::notice title=reviewer unavailable::%s: %s\n", u.Person, u.Reason)
+	}
+	for _, u := range rec.Unmet {
+		fmt.Printf("::warning title=reviewers::PR #%d: %s\n", rec.PR, u)
+	}
+	if len(rec.Reviewers) == 0 {
+		fmt.Printf("✅ PR #%d needs no further reviewers (%s)\n", rec.PR, rec.Requirement.Source)
+		return nil
+	}
+	var logins []string
+	for _, r := range rec.Reviewers {
+		logins = append(logins, r.Person)
+		fmt.Printf("  @%s — %s\n", r.Person, strings.Join(r.Reasons, "; "))
+	}
+	fmt.Printf("✅ Suggested reviewers for PR #%d: gh pr edit %d --add-reviewer %s\n", rec.PR, rec.PR, strings.Join(logins, ","))
+	return nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/BrikByte-Studios/github-governance/internal/codeowners"
+	"github.com/BrikByte-Studios/github-governance/internal/ghexport"
+	"github.com/BrikByte-Studios/github-governance/internal/reviewmetrics"
+	"github.com/BrikByte-Studios/github-governance/internal/reviews"
+)
+
+func init() {
+	register(command{
+		name:    "review-metrics",
+		summary: "Review latency per repo, team and CODEOWNERS path, with SLA breach alerts",
+		run:     runReviewMetrics,
+	})
+}
+
+func runReviewMetrics(args []string) error {
+	fs := newFlags("review-metrics")
+	prsPaths := fs.String("prs", "", "comma-separated gh pr exports (one per repo) (required)")
+	eventsPath := fs.String("events", "", "PR timelines: JSON object of \"owner/repo#n\" → gh timeline events")
+	ownersFlag := fs.String("codeowners", "CODEOWNERS", "comma-separated CODEOWNERS files, optionally owner/repo=path")
+	policyPath := fs.String("policy", "", "effective policy with reviews.sla (required)")
+	now := fs.String("now", "", "evaluation time (RFC 3339 or YYYY-MM-DD)")
+	out := fs.String("out", "", "JSON report (default stdout)")
+	report := fs.String("report", "", "write the team Markdown report here")
+	notify := fs.String("notify", "", "write SLA breach notifications (Markdown) here")
+	failBreach := fs.Bool("fail-on-breach", false, "exit 1 when an open PR is waiting past the SLA")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *prsPaths == "" || *policyPath == "" {
+		return fmt.Errorf("--prs and --policy are required")
+	}
+
+	at, err := parseNow(*now)
+	if err != nil {
+		return err
+	}
+	in := reviewmetrics.Input{Now: at, Owners: map[string]*codeowners.File{}}
+	for _, p := range splitList(*prsPaths) {
+		prs, err := ghexport.LoadPullRequests(p)
+		if err != nil {
+			return err
+		}
+		in.PRs = append(in.PRs, prs...)
+	}
+	if *eventsPath != "" {
+		if in.Timelines, err = ghexport.LoadTimelines(*eventsPath); err != nil {
+			return err
+		}
+	}
+	for _, spec := range splitList(*ownersFlag) {
+		repo, p, ok := strings.Cut(spec, "=")
+		if !ok {
+			repo, p = "", spec
+		}
+		f, err := codeowners.Load(p)
+		if err != nil {
+			return err
+		}
+		in.Owners[repo] = f
+	}
+	if in.Reviews, err = reviews.LoadPolicy(*policyPath); err != nil {
+		return err
+	}
+	if in.Reviews.SLA == nil {
+		fmt.Println("::notice title=review-metrics::no reviews.sla in the policy; measuring without breach detection")
+	}
+
+	rep := reviewmetrics.Analyze(in)
+	if err := writeJSON(*out, rep); err != nil {
+		return err
+	}
+	if *report != "" {
+		if err := os.WriteFile(*report, []byte(reviewmetrics.TeamReport(rep)), 0o644); err != nil {
+			return err
+		}
+	}
+	ns := reviewmetrics.Notifications(rep)
+	if *notify != "" && len(ns) > 0 {
+		if err := os.WriteFile(*notify, []byte(reviewmetrics.NotificationMarkdown(ns)), 0o644); err != nil {
+			return err
+		}
+	}
+	waiting := 0
+	for _, m := range rep.PRs {
+		for _, b := range m.Breaches {
+			if b.Waiting {
+				waiting++
+				fmt.Printf("::warning title=review SLA::%s#%d waiting %gh for %s (target %gh); owners %s\n",
+					m.Repo, m.Number, b.Hours, strings.ReplaceAll(b.Kind, "_", " "), b.TargetHours, strings.Join(m.Owners, " "))
+				break
+			}
+		}
+	}
+	if *out != "" {
+		t := rep.Totals
+		fmt.Printf("PRs: %d • first review p50/p90: %gh/%gh • approval p50/p90: %gh/%gh • breaches: %d • waiting: %d\n",
+			t.PRs, t.FirstReview.P50, t.FirstReview.P90, t.Approval.P50, t.Approval.P90, t.Breaches, t.Waiting)
+	}
+	if *failBreach && waiting > 0 {
+		return failf("%d open PR(s) waiting past the review SLA", waiting)
+	}
+	return nil
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, x := range strings.Split(s, ",") {
+		if x = strings.TrimSpace(x); x != "" {
+			out = append(out, x)
+		}
+	}
+	return out
+}
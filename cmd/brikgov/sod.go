@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/BrikByte-Studios/github-governance/internal/ghexport"
+	"github.com/BrikByte-Studios/github-governance/internal/gitrepo"
+	"github.com/BrikByte-Studios/github-governance/internal/people"
+	"github.com/BrikByte-Studios/github-governance/internal/reviews"
+	"github.com/BrikByte-Studios/github-governance/internal/sod"
+)
+
+func init() {
+	register(command{
+		name:    "sod",
+		summary: "Separation-of-duties checks over a release's commit range",
+		run:     runSoD,
+	})
+}
+
+func runSoD(args []string) error {
+	fs := newFlags("sod")
+	repo := fs.String("repo", ".", "local clone with tags (fetch-depth: 0)")
+	to := fs.String("to", "", "release tag (required)")
+	from := fs.String("from", "", "previous release tag (default: nearest tag before --to)")
+	prsPath := fs.String("prs", "", "gh pr export covering the range (required)")
+	peoplePath := fs.String("people", "", "people directory (accounts, aliases, teams)")
+	policyPath := fs.String("policy", "", "effective policy for required_roles (from `brikgov policy merge`)")
+	publisher := fs.String("publisher", "", "account publishing the release (e.g. github.actor)")
+	decision := fs.String("decision", "", "release decision JSON to record separation_of_duties in (default stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *to == "" || *prsPath == "" {
+		return fmt.Errorf("--to and --prs are required")
+	}
+
+	tag, err := gitrepo.ReadTag(context.Background(), *repo, *to)
+	if err != nil {
+		return err
+	}
+	if *from == "" {
+		prev, err := gitrepo.Run(context.Background(), *repo, "describe", "--tags", "--abbrev=0", tag.Commit+"^")
+		if err != nil {
+			return fmt.Errorf("no tag before %s; pass --from", *to)
+		}
+		*from = strings.TrimSpace(prev)
+	}
+	commits, err := gitrepo.Log(context.Background(), *repo, *from+".."+*to)
+	if err != nil {
+		return err
+	}
+	prs, err := ghexport.LoadPullRequests(*prsPath)
+	if err != nil {
+		return err
+	}
+	in := sod.Input{Tag: tag, From: *from, Commits: commits, PRs: prs, Publisher: *publisher}
+	if *peoplePath != "" {
+		if in.People, err = people.Load(*peoplePath); err != nil {
+			return err
+		}
+	}
+	if *policyPath != "" {
+		if in.Reviews, err = reviews.LoadPolicy(*policyPath); err != nil {
+			return err
+		}
+	}
+
+	rep := sod.Evaluate(in)
+	if err := recordDecision(*decision, "separation_of_duties", rep); err != nil {
+		return err
+	}
+	for _, u := range rep.Unreviewed {
+		fmt.Printf("::notice title=unreviewed commit::%.7s %s (%s)\n", u.SHA, u.Subject, u.Reason)
+	}
+	for _, v := range rep.Violations {
+		fmt.Printf("::error title=%s %s::%s\n", v.Code, v.Rule, v.Message)
+	}
+	if rep.Result != sod.ResultPass {
+		return failf("%d separation-of-duties violation(s) in %s", len(rep.Violations), rep.Range)
+	}
+	fmt.Printf("✅ Separation of duties holds for %s (%d commits, %d PRs)\n", rep.Range, rep.Commits, len(rep.PullRequests))
+	return nil
+}
+
+// recordDecision sets key in the decision JSON at path, keeping the other
+// sections already written by earlier release steps. An empty path prints
+// the section alone.
+func recordDecision(path, key string, v any) error {
+	if path == "" || path == "-" {
+		return writeJSON(path, v)
+	}
+	doc := map[string]json.RawMessage{}
+	raw, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	case !os.IsNotExist(err):
+		return err
+	}
+	section, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	doc[key] = section
+	return writeJSON(path, doc)
+}
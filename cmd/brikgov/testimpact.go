@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/BrikByte-Studios/github-governance/internal/gitrepo"
+	"github.com/BrikByte-Studios/github-governance/internal/impact"
+)
+
+func init() {
+	register(command{
+		name:    "test-impact",
+		summary: "Select the Go and JS/TS tests a change affects",
+		run:     runTestImpact,
+	})
+}
+
+func runTestImpact(args []string) error {
+	fs := newFlags("test-impact")
+	root := fs.String("root", ".", "repository root")
+	policyPath := fs.String("policy", "", "policy file for tests.impact (default: built-in safety valve)")
+	base := fs.String("base", "", "base ref; the diff starts at its merge base with --head")
+	head := fs.String("head", "HEAD", "head ref")
+	changedPath := fs.String("changed", "", "file listing changed paths, one per line (instead of --base)")
+	branch := fs.String("branch", "", "pushed or head branch (default $GITHUB_HEAD_REF, $GITHUB_REF_NAME or the checkout)")
+	target := fs.String("target", os.Getenv("GITHUB_BASE_REF"), "pull request target branch")
+	print := fs.String("print", "", "print the run list of one graph instead of JSON: go or js")
+	module := fs.String("module", ".", "with --print, the module directory to print for")
+	out := fs.String("out", "", "write the selection JSON here (default stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if (*base == "") == (*changedPath == "") {
+		return fmt.Errorf("one of --base or --changed is required")
+	}
+	if *print != "" && *print != "go" && *print != "js" {
+		return fmt.Errorf("--print must be go or js, not %q", *print)
+	}
+
+	cfg := impact.DefaultConfig()
+	if *policyPath != "" {
+		var err error
+		if cfg, err = impact.LoadConfig(*policyPath); err != nil {
+			return err
+		}
+	}
+	c := impact.Change{Branch: *branch, Target: *target}
+	if c.Branch == "" {
+		c.Branch = currentBranch(*root)
+	}
+	if *changedPath != "" {
+		raw, err := os.ReadFile(*changedPath)
+		if err != nil {
+			return err
+		}
+		for _, l := range strings.Split(string(raw), "\n") {
+			if l = strings.TrimSpace(l); l != "" {
+				c.Files = append(c.Files, l)
+			}
+		}
+	} else {
+		mb, err := gitrepo.MergeBase(context.Background(), *root, *base, *head)
+		if err != nil {
+			return err
+		}
+		if c.Files, err = gitrepo.Changed(context.Background(), *root, mb, *head); err != nil {
+			return err
+		}
+	}
+
+	sel, err := impact.Analyze(*root, c, cfg)
+	if err != nil {
+		return err
+	}
+	for _, r := range sel.Reasons {
+		fmt.Fprintf(os.Stderr, "::notice title=test impact::full run: %s\n", r)
+	}
+	if *print != "" {
+		fmt.Println(strings.Join(runList(sel, *print, path.Clean(filepath.ToSlash(*module))), " "))
+		return nil
+	}
+	if err := writeJSON(*out, sel); err != nil {
+		return err
+	}
+	if *out != "" {
+		n := 0
+		for _, m := range sel.Go {
+			n += len(m.Run)
+		}
+		fmt.Fprintf(os.Stderr, "✅ %d changed files: %d Go packages, %d of %d JS/TS test files (full=%t) → %s\n",
+			len(sel.Changed), n, len(sel.JS.Run), sel.JS.Tests, sel.Full, *out)
+	}
+	return nil
+}
+
+// runList is what a test command in module takes: Go packages of that
+// module, or the JS/TS test files below it relative to it. An empty JS
+// list on a full run means every test.
+func runList(sel *impact.Selection, graph, module string) []string {
+	var out []string
+	if graph == "go" {
+		for _, m := range sel.Go {
+			if m.Dir == module {
+				out = m.Run
+			}
+		}
+		return out
+	}
+	for _, f := range sel.JS.Run {
+		if module == "." {
+			out = append(out, f)
+		} else if rel, ok := strings.CutPrefix(f, module+"/"); ok {
+			out = append(out, rel)
+		}
+	}
+	return out
+}
+
+// currentBranch is the CI branch or the checkout's.
+func currentBranch(root string) string {
+	for _, env := range []string{"GITHUB_HEAD_REF", "GITHUB_REF_NAME"} {
+		if b := os.Getenv(env); b != "" {
+			return b
+		}
+	}
+	b, err := gitrepo.Run(context.Background(), root, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil || strings.TrimSpace(b) == "HEAD" {
+		return ""
+	}
+	return strings.TrimSpace(b)
+}
@@ -0,0 +1,8 @@
+module github.com/BrikByte-Studios/github-governance
+
+go 1.22
+
+require (
+	github.com/klauspost/compress v1.18.0
+	gopkg.in/yaml.v3 v3.0.1
+)
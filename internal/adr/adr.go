@@ -0,0 +1,254 @@
+// Package adr lints Architecture Decision Records: the YAML front matter
+// of docs/adr/NNN-*.md against docs/adr/adr.schema.json, plus the
+// invariants the schema cannot express (unique ids and sequence numbers, a
+// filename prefix matching seq). It is the Go counterpart of
+// scripts/adr/adr-lint.js and reports stable codes (ADR-001…) with line
+// numbers; findings can be suppressed with an HTML comment such as
+//
+//	<!-- brikgov:ignore ADR-006 -- renumbered; filename kept for existing links -->
+package adr
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/BrikByte-Studios/github-governance/internal/codes"
+)
+
+// DefaultGlob matches the ADR files, relative to the repository root.
+// Glob leaves out IndexFile, which matches the pattern but is not a decision.
+const DefaultGlob = "docs/adr/[0-9][0-9][0-9]-*.md"
+
+// IndexFile is the index scripts/adr/adr-index-generate.js writes.
+const IndexFile = "000-index.md"
+
+// Statuses are the lifecycle states an ADR may be in.
+var Statuses = []string{"Proposed", "Accepted", "Superseded", "Rejected", "Deprecated"}
+
+var required = []string{"id", "seq", "title", "status", "date", "authors", "area"}
+
+var (
+	idRe     = regexp.MustCompile(`^ADR-\d{4}$`)
+	dateRe   = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	prefixRe = regexp.MustCompile(`^(\d{3})-`)
+)
+
+// Record is the front matter of one ADR that parsed.
+type Record struct {
+	File   string `json:"file"`
+	ID     string `json:"id"`
+	Seq    int    `json:"seq"`
+	Title  string `json:"title"`
+	Status string `json:"status"`
+	Date   string `json:"date"`
+
+	idLine, seqLine int
+}
+
+// Lint checks the ADR files (paths relative to root) and returns the
+// records that parsed plus every finding, suppressed ones included, sorted
+// by file and line.
+func Lint(root string, files []string) ([]Record, []codes.Finding, error) {
+	files = append([]string(nil), files...)
+	sort.Strings(files)
+	var (
+		recs []Record
+		all  []codes.Finding
+		sups []*codes.Suppressions
+	)
+	for _, f := range files {
+		raw, err := os.ReadFile(filepath.Join(root, f))
+		if err != nil {
+			return nil, nil, err
+		}
+		f = filepath.ToSlash(f)
+		rec, fs := lintFile(f, raw)
+		if rec != nil {
+			recs = append(recs, *rec)
+		}
+		all = append(all, fs...)
+		s, problems := codes.ParseSuppressions(f, raw)
+		all = append(all, problems...)
+		sups = append(sups, s)
+	}
+
+	seqs, ids := map[int]string{}, map[string]string{}
+	for _, r := range recs {
+		if r.Seq > 0 {
+			if first, ok := seqs[r.Seq]; ok {
+				all = append(all, codes.New("ADR-004", r.File, r.seqLine, "seq %d is also used by %s", r.Seq, first))
+			} else {
+				seqs[r.Seq] = r.File
+			}
+		}
+		if r.ID != "" {
+			if first, ok := ids[r.ID]; ok {
+				all = append(all, codes.New("ADR-005", r.File, r.idLine, "id %s is also used by %s", r.ID, first))
+			} else {
+				ids[r.ID] = r.File
+			}
+		}
+	}
+
+	for _, s := range sups {
+		s.Apply(all)
+		all = append(all, s.Unused()...)
+	}
+	codes.Sort(all)
+	return recs, all, nil
+}
+
+// lintFile checks one file on its own. The record is nil when the front
+// matter is missing or does not parse.
+func lintFile(file string, raw []byte) (*Record, []codes.Finding) {
+	lines := strings.Split(strings.ReplaceAll(string(raw), "\r\n", "\n"), "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return nil, []codes.Finding{codes.New("ADR-001", file, 0, "no YAML front matter (the file must start with ---)")}
+	}
+	end := -1
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "---" {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return nil, []codes.Finding{codes.New("ADR-002", file, 1, "front matter is not closed by a --- line")}
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(strings.Join(lines[1:end], "\n")), &doc); err != nil {
+		return nil, []codes.Finding{codes.New("ADR-002", file, 1, "front matter: %v", err)}
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, []codes.Finding{codes.New("ADR-002", file, 1, "front matter must be a YAML mapping")}
+	}
+
+	var fs []codes.Finding
+	bad := func(line int, format string, args ...any) {
+		// Front matter starts on the file's second line.
+		fs = append(fs, codes.New("ADR-007", file, line+1, format, args...))
+	}
+	m := doc.Content[0]
+	fields := map[string]*yaml.Node{}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		fields[m.Content[i].Value] = m.Content[i+1]
+	}
+	for _, name := range required {
+		if n, ok := fields[name]; !ok || n.Tag == "!!null" {
+			fs = append(fs, codes.New("ADR-003", file, 1, "required field %q is missing", name))
+		}
+	}
+
+	rec := &Record{File: file}
+	if n, ok := fields["id"]; ok && n.Tag != "!!null" {
+		rec.idLine = n.Line + 1
+		if n.Kind != yaml.ScalarNode || !idRe.MatchString(n.Value) {
+			bad(n.Line, "id must look like ADR-0003, got %q", n.Value)
+		} else {
+			rec.ID = n.Value
+		}
+	}
+	if n, ok := fields["seq"]; ok && n.Tag != "!!null" {
+		rec.seqLine = n.Line + 1
+		seq, err := strconv.Atoi(n.Value)
+		switch {
+		case n.Tag != "!!int" || err != nil:
+			bad(n.Line, "seq must be an integer, got %q", n.Value)
+		case seq < 1:
+			bad(n.Line, "seq must be ≥ 1, got %d", seq)
+		default:
+			rec.Seq = seq
+			if p := prefixRe.FindStringSubmatch(filepath.Base(file)); p != nil {
+				if want, _ := strconv.Atoi(p[1]); want != seq {
+					fs = append(fs, codes.New("ADR-006", file, rec.seqLine, "seq %d does not match the filename prefix %s", seq, p[1]))
+				}
+			}
+		}
+	}
+	if n, ok := fields["title"]; ok && n.Tag != "!!null" {
+		if n.Kind != yaml.ScalarNode || strings.TrimSpace(n.Value) == "" {
+			bad(n.Line, "title must be a non-empty string")
+		}
+		rec.Title = n.Value
+	}
+	if n, ok := fields["status"]; ok && n.Tag != "!!null" {
+		if !contains(Statuses, n.Value) {
+			bad(n.Line, "status must be one of %s, got %q", strings.Join(Statuses, ", "), n.Value)
+		}
+		rec.Status = n.Value
+	}
+	for _, name := range []string{"date", "review_after"} {
+		if n, ok := fields[name]; ok && n.Tag != "!!null" {
+			if !validDate(n.Value) {
+				bad(n.Line, "%s must be a date (YYYY-MM-DD), got %q", name, n.Value)
+			}
+			if name == "date" {
+				rec.Date = n.Value
+			}
+		}
+	}
+	for _, name := range []string{"authors", "area"} {
+		if n, ok := fields[name]; ok && n.Tag != "!!null" {
+			if n.Kind != yaml.SequenceNode || len(n.Content) == 0 {
+				bad(n.Line, "%s must be a non-empty list", name)
+			}
+		}
+	}
+	if n, ok := fields["supersedes"]; ok && n.Kind == yaml.SequenceNode {
+		for _, e := range n.Content {
+			if !idRe.MatchString(e.Value) {
+				bad(e.Line, "supersedes entries must be ADR ids, got %q", e.Value)
+			}
+		}
+	}
+	if n, ok := fields["superseded_by"]; ok && n.Tag != "!!null" && !idRe.MatchString(n.Value) {
+		bad(n.Line, "superseded_by must be an ADR id or null, got %q", n.Value)
+	}
+	return rec, fs
+}
+
+func validDate(s string) bool {
+	if !dateRe.MatchString(s) {
+		return false
+	}
+	_, err := time.Parse("2006-01-02", s)
+	return err == nil
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Glob expands pattern under root and returns paths relative to root,
+// without the generated IndexFile.
+func Glob(root, pattern string) ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(root, filepath.FromSlash(pattern)))
+	if err != nil {
+		return nil, fmt.Errorf("glob %q: %w", pattern, err)
+	}
+	out := make([]string, 0, len(matches))
+	for _, m := range matches {
+		if filepath.Base(m) == IndexFile {
+			continue
+		}
+		rel, err := filepath.Rel(root, m)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, filepath.ToSlash(rel))
+	}
+	return out, nil
+}
@@ -0,0 +1,76 @@
+package adr
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/BrikByte-Studios/github-governance/internal/codes"
+)
+
+const fixtures = "../../tests/fixtures/adr"
+
+func render(fs []codes.Finding) []string {
+	out := make([]string, len(fs))
+	for i, f := range fs {
+		out[i] = fmt.Sprintf("%s %s:%d %s", f.Code, f.File, f.Line, f.Message)
+		if f.Suppressed != "" {
+			out[i] += " (suppressed: " + f.Suppressed + ")"
+		}
+	}
+	return out
+}
+
+func TestLint(t *testing.T) {
+	files, err := Glob(fixtures, "[0-9][0-9][0-9]-*.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	recs, fs, err := Lint(fixtures, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"ADR-005 002-second.md:2 id ADR-0002 is also used by 002-first.md",
+		"ADR-004 002-second.md:3 seq 2 is also used by 002-first.md",
+		`ADR-003 003-broken.md:1 required field "area" is missing`,
+		`ADR-007 003-broken.md:2 id must look like ADR-0003, got "ADR-3"`,
+		"ADR-006 003-broken.md:3 seq 4 does not match the filename prefix 003",
+		`ADR-007 003-broken.md:5 status must be one of Proposed, Accepted, Superseded, Rejected, Deprecated, got "Done"`,
+		`ADR-007 003-broken.md:6 date must be a date (YYYY-MM-DD), got "2025-13-01"`,
+		"ADR-007 003-broken.md:7 authors must be a non-empty list",
+		"ADR-001 004-notes.md:0 no YAML front matter (the file must start with ---) (suppressed: meeting notes, kept next to the decisions they led to)",
+		"SUP-003 004-notes.md:2 brikgov:ignore ADR-004 suppresses nothing; remove it",
+		`SUP-001 004-notes.md:3 brikgov:ignore ADR-006 has no reason (add "-- <why>")`,
+		"ADR-002 005-open.md:1 front matter is not closed by a --- line",
+	}
+	if got := render(fs); !reflect.DeepEqual(got, want) {
+		t.Errorf("findings:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if n := codes.Errors(fs); n != 10 {
+		t.Errorf("errors = %d, want 10", n)
+	}
+	if len(recs) != 4 || recs[0].ID != "ADR-0001" || recs[0].Seq != 1 || recs[0].Date != "2025-11-17" {
+		t.Errorf("records = %+v", recs)
+	}
+}
+
+func TestRepositoryADRs(t *testing.T) {
+	files, err := Glob("../..", DefaultGlob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		if strings.HasSuffix(f, "/"+IndexFile) {
+			t.Errorf("Glob returned the generated index %s", f)
+		}
+	}
+	_, fs, err := Lint("../..", files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := codes.Errors(fs); n != 0 {
+		t.Errorf("docs/adr has %d error(s):\n%s", n, strings.Join(render(fs), "\n"))
+	}
+}
@@ -0,0 +1,364 @@
+// Package audit writes and reads governance evidence records in the .audit
+// directory that release and gate workflows upload as the audit bundle.
+//
+// Layout:
+//
+//	.audit/<namespace>/<created>-<kind>-<digest8>.json
+//	.audit/_segments/index.json                       compacted records
+//	.audit/_segments/<seq>-<from>-<to>.tar.zst
+//	.audit/.lock
+//
+// Every record is sanitized before it touches disk: the Store refuses to
+// write without a Sanitizer, so logs and diagnostics attached as evidence go
+// through the redaction detectors first.
+//
+// Several jobs may write to one directory at once. Writers hold a shared
+// lock on .lock and compaction an exclusive one; a record file appears
+// under its final name only once it is complete and synced, and never
+// replaces another. Readers take no lock, so a downloaded bundle can be
+// read in place.
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BrikByte-Studios/github-governance/internal/canon"
+)
+
+// DefaultDir matches the audit_dir default of the reusable workflows.
+const DefaultDir = ".audit"
+
+// Sanitizer removes sensitive values from evidence content and returns how
+// many were replaced. *redact.Redactor implements it.
+type Sanitizer interface {
+	RedactBytes([]byte) ([]byte, int)
+}
+
+// Record is one stored piece of evidence.
+type Record struct {
+	ID          string          `json:"id"`
+	Namespace   string          `json:"namespace"`
+	Kind        string          `json:"kind"`
+	Subject     string          `json:"subject,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	ContentType string          `json:"content_type"`
+	Content     json.RawMessage `json:"content"`
+	// SHA256 is the digest of the RFC 8785 canonical form of Content
+	// (after redaction). Records written before canonical JSON carry the
+	// digest of the compact form; Verify accepts either.
+	SHA256     string `json:"sha256"`
+	Redactions int    `json:"redactions"`
+}
+
+// Store writes records below Dir.
+type Store struct {
+	Dir       string
+	Sanitizer Sanitizer
+	// Now is overridable for tests.
+	Now func() time.Time
+}
+
+// ErrNoSanitizer is returned by Put when the store has no sanitizer.
+var ErrNoSanitizer = errors.New("audit: refusing to store evidence without a sanitizer")
+
+var nsRe = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*(?:/[a-z0-9][a-z0-9._-]*)*$`)
+
+// Put sanitizes content and stores it as a record. JSON content is embedded
+// as a document; anything else is stored as a JSON string.
+func (s *Store) Put(namespace, kind, subject string, content []byte) (*Record, string, error) {
+	if s.Sanitizer == nil {
+		return nil, "", ErrNoSanitizer
+	}
+	if !nsRe.MatchString(namespace) {
+		return nil, "", fmt.Errorf("audit: invalid namespace %q", namespace)
+	}
+	if !nsRe.MatchString(kind) || strings.Contains(kind, "/") {
+		return nil, "", fmt.Errorf("audit: invalid kind %q", kind)
+	}
+
+	clean, n := s.Sanitizer.RedactBytes(content)
+	rec := &Record{
+		Namespace:  namespace,
+		Kind:       kind,
+		Subject:    subject,
+		CreatedAt:  s.now().UTC().Truncate(time.Second),
+		Redactions: n,
+	}
+	if c, err := canon.Transform(clean); err == nil {
+		rec.ContentType = "application/json"
+		rec.Content = c
+	} else {
+		rec.ContentType = "text/plain"
+		rec.Content, _ = canon.Marshal(string(clean))
+	}
+	rec.SHA256 = digest(rec.Content)
+	rec.ID = rec.CreatedAt.Format("20060102T150405Z") + "-" + kind + "-" + rec.SHA256[:8]
+
+	dir := filepath.Join(s.Dir, filepath.FromSlash(namespace))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, "", err
+	}
+	unlock, err := s.lock(false)
+	if err != nil {
+		return nil, "", err
+	}
+	defer unlock()
+	compacted, err := s.compactedIDs()
+	if err != nil {
+		return nil, "", err
+	}
+	base := rec.ID
+	for n := 2; ; n++ {
+		raw, err := MarshalRecord(*rec)
+		if err != nil {
+			return nil, "", err
+		}
+		path := filepath.Join(dir, rec.ID+".json")
+		if !compacted[rec.ID] {
+			switch err := writeNew(path, raw); {
+			case err == nil:
+				return rec, path, nil
+			case !errors.Is(err, os.ErrExist):
+				return nil, "", err
+			}
+		}
+		// Same second, kind and content: another record (a different
+		// subject, or a retry) has the id.
+		rec.ID = fmt.Sprintf("%s-%d", base, n)
+	}
+}
+
+// writeNew creates path with content, atomically and without replacing an
+// existing file: the content goes to a temporary file that is synced and
+// then linked to path. A crash leaves at most a dot-prefixed temporary
+// file, which readers skip and compaction removes.
+func writeNew(path string, content []byte) error {
+	tmp, err := writeTemp(path, content)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+	if err := os.Link(tmp, path); err != nil {
+		if _, statErr := os.Lstat(path); statErr == nil {
+			return os.ErrExist
+		}
+		return err
+	}
+	return syncDir(filepath.Dir(path))
+}
+
+// replace atomically replaces path with content.
+func replace(path string, content []byte) error {
+	tmp, err := writeTemp(path, content)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	return syncDir(filepath.Dir(path))
+}
+
+// writeTemp writes and syncs content to a temporary file next to path and
+// returns its name.
+func writeTemp(path string, content []byte) (string, error) {
+	f, err := os.CreateTemp(filepath.Dir(path), tempPrefix(path))
+	if err != nil {
+		return "", err
+	}
+	_, err = f.Write(content)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(f.Name(), 0o644)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+func tempPrefix(path string) string {
+	return "." + filepath.Base(path) + ".tmp-*"
+}
+
+// syncDir makes a rename or link in dir durable. Platforms that cannot
+// sync a directory are not an error.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	d.Sync()
+	return nil
+}
+
+// lock takes the store lock, shared for writers and exclusive for
+// compaction.
+func (s *Store) lock(exclusive bool) (func(), error) {
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(s.Dir, lockName), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f, exclusive); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("audit: lock %s: %w", f.Name(), err)
+	}
+	return func() {
+		unlockFile(f)
+		f.Close()
+	}, nil
+}
+
+const lockName = ".lock"
+
+func (s *Store) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+// List returns the records under namespace (recursively; "" lists the whole
+// store), loose and compacted, ordered by creation time, then id.
+func (s *Store) List(namespace string) ([]Record, error) {
+	// Loose records first: compaction removes them only after the index
+	// lists them, so reading the index second cannot miss a record.
+	loose, err := s.loose(namespace)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	var out []Record
+	for _, l := range loose {
+		seen[l.rec.ID] = true
+		out = append(out, l.rec)
+	}
+	compacted, err := s.compacted(namespace)
+	if err != nil {
+		return nil, err
+	}
+	for _, rec := range compacted {
+		if !seen[rec.ID] {
+			seen[rec.ID] = true
+			out = append(out, rec)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.Before(out[j].CreatedAt)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
+
+type looseRecord struct {
+	rec  Record
+	path string
+	raw  []byte
+}
+
+// loose reads the record files under namespace. Files and directories
+// starting with "." or "_" (temporary files, the lock, segments) are not
+// records.
+func (s *Store) loose(namespace string) ([]looseRecord, error) {
+	root := filepath.Join(s.Dir, filepath.FromSlash(namespace))
+	var out []looseRecord
+	err := filepath.WalkDir(root, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) && p == root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if p != root && strings.ContainsAny(d.Name()[:1], "._") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || filepath.Ext(p) != ".json" {
+			return nil
+		}
+		raw, err := os.ReadFile(p)
+		if errors.Is(err, os.ErrNotExist) {
+			// Compacted since the walk listed it.
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var rec Record
+		if err := json.Unmarshal(raw, &rec); err != nil || rec.ID == "" {
+			// Not a record (e.g. a manifest written by another tool).
+			return nil
+		}
+		out = append(out, looseRecord{rec, p, raw})
+		return nil
+	})
+	return out, err
+}
+
+// digest hashes the canonical form (RFC 8785) of content, so re-indenting
+// a record file does not change its digest.
+func digest(content []byte) string {
+	if c, err := canon.Transform(content); err == nil {
+		content = c
+	}
+	return sum(content)
+}
+
+// compactDigest is the digest of records written before canonical JSON:
+// the hash of the compact content as stored.
+func compactDigest(content []byte) string {
+	var compact bytes.Buffer
+	if json.Compact(&compact, content) == nil {
+		content = compact.Bytes()
+	}
+	return sum(content)
+}
+
+// MarshalRecord renders a record file: pretty canonical JSON with a
+// trailing newline. A record with a compact-form digest keeps its content
+// as stored, since canonicalizing it would break the digest.
+func MarshalRecord(rec Record) ([]byte, error) {
+	var raw []byte
+	var err error
+	if digest(rec.Content) == rec.SHA256 {
+		raw, err = canon.MarshalPretty(rec)
+	} else {
+		raw, err = json.MarshalIndent(rec, "", "  ")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append(raw, '\n'), nil
+}
+
+// Verify recomputes the content digest of rec.
+func Verify(rec Record) error {
+	if got := digest(rec.Content); got != rec.SHA256 && compactDigest(rec.Content) != rec.SHA256 {
+		return fmt.Errorf("audit: record %s digest mismatch (stored %s, computed %s)", rec.ID, rec.SHA256, got)
+	}
+	return nil
+}
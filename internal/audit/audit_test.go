@@ -0,0 +1,430 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type upperSanitizer struct{}
+
+func (upperSanitizer) RedactBytes(b []byte) ([]byte, int) {
+	return bytes.ReplaceAll(b, []byte("secret"), []byte("[REDACTED]")), bytes.Count(b, []byte("secret"))
+}
+
+func TestPutRequiresSanitizer(t *testing.T) {
+	s := &Store{Dir: t.TempDir()}
+	if _, _, err := s.Put("incidents/evidence", "logs", "", []byte("x")); !errors.Is(err, ErrNoSanitizer) {
+		t.Fatalf("err = %v, want ErrNoSanitizer", err)
+	}
+}
+
+func TestPutListVerify(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	s := &Store{Dir: t.TempDir(), Sanitizer: upperSanitizer{}, Now: func() time.Time { return now }}
+
+	rec, _, err := s.Put("incidents/evidence", "logs", "issue#12", []byte("token=secret\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Redactions != 1 || rec.ContentType != "text/plain" {
+		t.Errorf("rec = %+v", rec)
+	}
+	var text string
+	_ = json.Unmarshal(rec.Content, &text)
+	if strings.Contains(text, "secret") {
+		t.Errorf("content not sanitized: %q", text)
+	}
+
+	now = now.Add(time.Minute)
+	if _, _, err := s.Put("incidents/evidence", "decision", "", []byte(`{ "status": "passed" }`)); err != nil {
+		t.Fatal(err)
+	}
+
+	recs, err := s.List("incidents")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 2 || recs[0].Kind != "logs" || recs[1].ContentType != "application/json" {
+		t.Fatalf("records = %+v", recs)
+	}
+	for _, r := range recs {
+		if err := Verify(r); err != nil {
+			t.Error(err)
+		}
+	}
+
+	recs[1].Content = json.RawMessage(`{"status":"failed"}`)
+	if err := Verify(recs[1]); err == nil {
+		t.Error("tampered content should not verify")
+	}
+
+	if _, _, err := s.Put("../escape", "logs", "", nil); err == nil {
+		t.Error("invalid namespace accepted")
+	}
+}
+
+func TestCanonicalContent(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	s := &Store{Dir: t.TempDir(), Sanitizer: upperSanitizer{}, Now: func() time.Time { return now }}
+	rec, path, err := s.Put("gate", "decision", "", []byte(`{"status": "passed", "score": 1.0E2, "note": "<a & b>"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"note":"<a & b>","score":100,"status":"passed"}`; string(rec.Content) != want {
+		t.Errorf("content = %s, want %s", rec.Content, want)
+	}
+	if rec.SHA256 != sum(rec.Content) {
+		t.Errorf("digest %s is not the hash of the canonical content", rec.SHA256)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(raw, []byte("{\n  \"content\": {\n    \"note\": \"<a & b>\",")) {
+		t.Errorf("record file is not pretty canonical JSON:\n%s", raw)
+	}
+
+	// A record written before canonical JSON: digest of the compact content
+	// in struct order, HTML-escaped.
+	legacy := Record{ID: "old", Namespace: "gate", Kind: "decision", ContentType: "application/json",
+		Content: json.RawMessage(`{"status":"passed","note":"\u003ca\u003e"}`)}
+	legacy.SHA256 = sum(legacy.Content)
+	if err := Verify(legacy); err != nil {
+		t.Errorf("legacy record: %v", err)
+	}
+	raw, err = MarshalRecord(legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back Record
+	if err := json.Unmarshal(raw, &back); err != nil || Verify(back) != nil {
+		t.Errorf("legacy record does not survive MarshalRecord: %v %v\n%s", err, Verify(back), raw)
+	}
+}
+
+func TestConcurrentWriters(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+	const writers, each = 8, 25
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*each)
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			// Separate stores, as separate jobs would have; the same
+			// second and content for every writer, so ids collide.
+			s := &Store{Dir: dir, Sanitizer: upperSanitizer{}, Now: func() time.Time { return now }}
+			for i := 0; i < each; i++ {
+				content := fmt.Sprintf(`{"n": %d}`, i)
+				if _, _, err := s.Put("gate/decisions", "decision", fmt.Sprintf("writer-%d", w), []byte(content)); err != nil {
+					errs <- err
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+	recs, err := (&Store{Dir: dir}).List("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != writers*each {
+		t.Fatalf("got %d records, want %d", len(recs), writers*each)
+	}
+	ids := map[string]bool{}
+	subjects := map[string]int{}
+	for _, r := range recs {
+		if ids[r.ID] {
+			t.Fatalf("duplicate id %s", r.ID)
+		}
+		ids[r.ID] = true
+		subjects[r.Subject]++
+		if err := Verify(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for w := 0; w < writers; w++ {
+		if n := subjects[fmt.Sprintf("writer-%d", w)]; n != each {
+			t.Errorf("writer-%d has %d records, want %d", w, n, each)
+		}
+	}
+}
+
+func TestCompactWhileWriting(t *testing.T) {
+	dir := t.TempDir()
+	var mu sync.Mutex
+	clock := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	tick := func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		clock = clock.Add(time.Second)
+		return clock
+	}
+	const writers, each = 4, 40
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			s := &Store{Dir: dir, Sanitizer: upperSanitizer{}, Now: tick}
+			for i := 0; i < each; i++ {
+				if _, _, err := s.Put(fmt.Sprintf("ns%d", w), "logs", "", []byte(fmt.Sprintf("line %d secret", i))); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(w)
+	}
+	done := make(chan struct{})
+	compactor := &Store{Dir: dir, Now: tick}
+	segments := 0
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			mu.Lock()
+			cutoff := clock
+			mu.Unlock()
+			seg, err := compactor.Compact(cutoff)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if seg != nil {
+				segments++
+			}
+			// Readers take no lock and must see every record at any time.
+			if _, err := compactor.List(""); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	wg.Wait()
+	<-done
+	if seg, err := compactor.Compact(clock.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	} else if seg != nil {
+		segments++
+	}
+
+	recs, err := compactor.List("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != writers*each {
+		t.Fatalf("got %d records, want %d", len(recs), writers*each)
+	}
+	if ns1, _ := compactor.List("ns1"); len(ns1) != each {
+		t.Errorf("ns1 has %d records, want %d", len(ns1), each)
+	}
+	rep, err := compactor.Check()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.Problems) != 0 || len(rep.Leftovers) != 0 || rep.Loose != 0 || rep.Compacted != writers*each {
+		t.Errorf("check = %+v", rep)
+	}
+	idx, _ := compactor.LoadIndex()
+	if segments == 0 || len(idx.Segments) != segments {
+		t.Errorf("%d segments in index, %d compactions", len(idx.Segments), segments)
+	}
+}
+
+func TestCompactPreservesIntegrity(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	s := &Store{Dir: t.TempDir(), Sanitizer: upperSanitizer{}, Now: func() time.Time { return now }}
+	for i := 0; i < 3; i++ {
+		if _, _, err := s.Put("hotfix", "obligation", fmt.Sprint(i), []byte(fmt.Sprintf(`{"pr": %d}`, i))); err != nil {
+			t.Fatal(err)
+		}
+		now = now.Add(time.Hour)
+	}
+	before, err := s.List("hotfix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	seg, err := s.Compact(now.Add(-90 * time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seg == nil || len(seg.Records) != 2 || seg.Prev != "" {
+		t.Fatalf("segment = %+v", seg)
+	}
+	after, err := s.List("hotfix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(before, after) {
+		t.Errorf("records changed by compaction:\n%+v\n%+v", before, after)
+	}
+	if seg, err := s.Compact(now.Add(-90 * time.Minute)); err != nil || seg != nil {
+		t.Errorf("second compaction = %v, %v; want nothing to do", seg, err)
+	}
+	seg2, err := s.Compact(now)
+	if err != nil || seg2 == nil || seg2.Prev != seg.SHA256 {
+		t.Fatalf("chained segment = %+v, %v", seg2, err)
+	}
+
+	// A compacted id is not reused by a later Put.
+	now = before[0].CreatedAt
+	rec, _, err := s.Put("hotfix", "obligation", "again", []byte(`{"pr": 0}`))
+	if err != nil || rec.ID != before[0].ID+"-2" {
+		t.Errorf("re-put id = %v, %v", rec, err)
+	}
+
+	// Tampering with a segment is detected by readers and by Check.
+	p := filepath.Join(s.Dir, SegmentDir, seg.Name)
+	raw, _ := os.ReadFile(p)
+	raw[len(raw)/2] ^= 0xff
+	if err := os.WriteFile(p, raw, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.List(""); err == nil || !strings.Contains(err.Error(), "digest mismatch") {
+		t.Errorf("List over a tampered segment: %v", err)
+	}
+	rep, err := s.Check()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.Problems) != 1 || !strings.Contains(rep.Problems[0], seg.Name) {
+		t.Errorf("problems = %q", rep.Problems)
+	}
+}
+
+func TestCompactRefusesTampered(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	s := &Store{Dir: t.TempDir(), Sanitizer: upperSanitizer{}, Now: func() time.Time { return now }}
+	_, p, err := s.Put("bypass", "decision", "", []byte(`{"approved": false}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := os.ReadFile(p)
+	os.WriteFile(p, bytes.Replace(raw, []byte("false"), []byte("true"), 1), 0o644)
+	if _, err := s.Compact(now.Add(time.Hour)); err == nil || !strings.Contains(err.Error(), "integrity") {
+		t.Errorf("Compact = %v, want integrity refusal", err)
+	}
+	if recs, _ := s.List(""); len(recs) != 1 {
+		t.Errorf("tampered record removed: %d records", len(recs))
+	}
+}
+
+func TestCrashRecovery(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	s := &Store{Dir: t.TempDir(), Sanitizer: upperSanitizer{}, Now: func() time.Time { return now }}
+	_, p, err := s.Put("gate", "decision", "", []byte(`{"ok": true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := os.ReadFile(p)
+	if _, err := s.Compact(now.Add(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	// Crashes: a writer died before linking its temporary file, a
+	// compaction died after the index but before removing the loose file,
+	// and another before its index update.
+	tmp := filepath.Join(s.Dir, "gate", ".partial.json.tmp-123")
+	os.WriteFile(tmp, []byte(`{"id": "half`), 0o644)
+	os.WriteFile(p, raw, 0o644)
+	orphan := filepath.Join(s.Dir, SegmentDir, "000002-x-y.tar.zst")
+	os.WriteFile(orphan, []byte("garbage"), 0o644)
+
+	recs, err := s.List("")
+	if err != nil || len(recs) != 1 {
+		t.Fatalf("List after crashes = %d records, %v", len(recs), err)
+	}
+	rep, err := s.Check()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{orphan, p, tmp}
+	sort.Strings(want)
+	if len(rep.Problems) != 0 || !reflect.DeepEqual(rep.Leftovers, want) {
+		t.Errorf("check = %+v, want leftovers %v", rep, want)
+	}
+	if _, err := s.Compact(now); err != nil {
+		t.Fatal(err)
+	}
+	if rep, _ := s.Check(); len(rep.Leftovers) != 0 || rep.Compacted != 1 || rep.Loose != 0 {
+		t.Errorf("after recovery = %+v", rep)
+	}
+}
+
+// TestHeldLockStaleBreakers: waiters that all find the same stale held
+// file break it once between them, and then hold the lock one at a time.
+func TestHeldLockStaleBreakers(t *testing.T) {
+	held := filepath.Join(t.TempDir(), ".lock.held")
+	if err := os.WriteFile(held, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * staleLock)
+	if err := os.Chtimes(held, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	const waiters = 8
+	var (
+		mu                 sync.Mutex
+		active, peak, seen int
+		wg, lined          sync.WaitGroup
+	)
+	// Every waiter sees the stale file before any of them breaks it.
+	lined.Add(waiters)
+	staleSeen = func() {
+		mu.Lock()
+		seen++
+		first := seen <= waiters
+		mu.Unlock()
+		if first {
+			lined.Done()
+			lined.Wait()
+		}
+	}
+	t.Cleanup(func() { staleSeen = func() {} })
+	start := make(chan struct{})
+	for i := 0; i < waiters; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			if err := acquireHeld(held); err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			active++
+			peak = max(peak, active)
+			mu.Unlock()
+			time.Sleep(5 * time.Millisecond)
+			mu.Lock()
+			active--
+			mu.Unlock()
+			if err := releaseHeld(held); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+	if peak != 1 {
+		t.Errorf("%d holders at once, want 1", peak)
+	}
+	for _, p := range []string{held, held + ".break"} {
+		if _, err := os.Stat(p); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("%s left behind: %v", p, err)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package audit
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+// The held-file lock backs lockFile where there is no flock
+// (lock_other.go). It only uses portable file operations, so it builds and
+// is tested on every platform.
+
+const (
+	lockRefresh = 10 * time.Second
+	staleLock   = 6 * lockRefresh
+	lockPoll    = 10 * time.Millisecond
+)
+
+// heartbeats maps a held lock file to the channel stopping its refresh.
+var heartbeats sync.Map
+
+// staleSeen runs when a waiter finds the lock stale, before it breaks it;
+// tests use it to line waiters up.
+var staleSeen = func() {}
+
+// acquireHeld creates held exclusively, waiting while another process has
+// it. The holder touches the file every lockRefresh, so one whose mtime is
+// older than staleLock was left behind by a crashed process and is broken;
+// a live holder, however long it writes, never looks stale.
+func acquireHeld(held string) error {
+	for {
+		h, err := os.OpenFile(held, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			if err := h.Close(); err != nil {
+				os.Remove(held)
+				return err
+			}
+			stop := make(chan struct{})
+			heartbeats.Store(held, stop)
+			go heartbeat(held, stop)
+			return nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return err
+		}
+		if stale(held) {
+			staleSeen()
+			if err := breakStale(held); err != nil {
+				return err
+			}
+			continue
+		}
+		time.Sleep(lockPoll)
+	}
+}
+
+// breakStale removes held if it is still stale. Breakers take held.break
+// exclusively and check again under it, so of two waiters that saw the
+// same stale lock only the first removes it; the second finds it gone or
+// replaced by the first one's live lock, which is never stale. A break
+// file is held only for that check, so one older than staleLock was left
+// by a breaker that crashed and is removed.
+func breakStale(held string) error {
+	brk := held + ".break"
+	b, err := os.OpenFile(brk, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if errors.Is(err, os.ErrExist) {
+		if stale(brk) {
+			os.Remove(brk)
+		}
+		time.Sleep(lockPoll)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer os.Remove(brk)
+	if err := b.Close(); err != nil {
+		return err
+	}
+	if stale(held) {
+		if err := os.Remove(held); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+func stale(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && time.Since(info.ModTime()) > staleLock
+}
+
+func heartbeat(held string, stop chan struct{}) {
+	t := time.NewTicker(lockRefresh)
+	defer t.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-t.C:
+			os.Chtimes(held, now, now)
+		}
+	}
+}
+
+// releaseHeld stops the heartbeat and removes held.
+func releaseHeld(held string) error {
+	if stop, ok := heartbeats.LoadAndDelete(held); ok {
+		close(stop.(chan struct{}))
+	}
+	return os.Remove(held)
+}
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package audit
+
+import "os"
+
+// lockFile has no flock to use here. It takes an exclusive lock file next
+// to f instead, shared or not (see acquireHeld).
+func lockFile(f *os.File, exclusive bool) error {
+	return acquireHeld(f.Name() + ".held")
+}
+
+func unlockFile(f *os.File) error {
+	return releaseHeld(f.Name() + ".held")
+}
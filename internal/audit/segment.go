@@ -0,0 +1,453 @@
+package audit
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/BrikByte-Studios/github-governance/internal/canon"
+)
+
+// SegmentDir holds the compacted records, relative to the store. It is not
+// a dot directory, which artifact uploads skip, and no namespace can start
+// with "_".
+const SegmentDir = "_segments"
+
+const indexName = "index.json"
+
+// Index lists the segments of a store in compaction order.
+type Index struct {
+	Version  int       `json:"version"`
+	Segments []Segment `json:"segments"`
+}
+
+// Segment is one tar.zst archive of record files, stored byte for byte
+// as Put wrote them.
+type Segment struct {
+	Name      string    `json:"name"`
+	SHA256    string    `json:"sha256"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+	// Prev is the digest of the previous segment, chaining the segments
+	// so that dropping or reordering one is detected.
+	Prev    string         `json:"prev,omitempty"`
+	Records []SegmentEntry `json:"records"`
+}
+
+// SegmentEntry locates one record in a segment.
+type SegmentEntry struct {
+	ID        string    `json:"id"`
+	Namespace string    `json:"namespace"`
+	Kind      string    `json:"kind"`
+	Subject   string    `json:"subject,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	// SHA256 is the record's content digest, as in the record.
+	SHA256 string `json:"sha256"`
+	// Member is the tar member, <namespace>/<id>.json; FileSHA256 is the
+	// digest of its bytes.
+	Member     string `json:"member"`
+	FileSHA256 string `json:"file_sha256"`
+}
+
+// LoadIndex reads the segment index; a store without one has no segments.
+func (s *Store) LoadIndex() (*Index, error) {
+	raw, err := os.ReadFile(filepath.Join(s.Dir, SegmentDir, indexName))
+	if errors.Is(err, os.ErrNotExist) {
+		return &Index{Version: 1}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var idx Index
+	if err := json.Unmarshal(raw, &idx); err != nil {
+		return nil, fmt.Errorf("audit: %s: %w", indexName, err)
+	}
+	return &idx, nil
+}
+
+func (s *Store) compactedIDs() (map[string]bool, error) {
+	idx, err := s.LoadIndex()
+	if err != nil {
+		return nil, err
+	}
+	ids := map[string]bool{}
+	for _, seg := range idx.Segments {
+		for _, e := range seg.Records {
+			ids[e.ID] = true
+		}
+	}
+	return ids, nil
+}
+
+// compacted reads the records under namespace from the segments holding
+// any. A segment or member whose digest does not match its index entry is
+// an error.
+func (s *Store) compacted(namespace string) ([]Record, error) {
+	idx, err := s.LoadIndex()
+	if err != nil {
+		return nil, err
+	}
+	var out []Record
+	for _, seg := range idx.Segments {
+		want := map[string]SegmentEntry{}
+		for _, e := range seg.Records {
+			if inNamespace(e.Namespace, namespace) {
+				want[e.Member] = e
+			}
+		}
+		if len(want) == 0 {
+			continue
+		}
+		members, err := s.readSegment(seg)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range seg.Records {
+			if _, ok := want[e.Member]; !ok {
+				continue
+			}
+			rec, err := entryRecord(seg, e, members[e.Member])
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, rec)
+		}
+	}
+	return out, nil
+}
+
+func inNamespace(ns, prefix string) bool {
+	return prefix == "" || ns == prefix || strings.HasPrefix(ns, prefix+"/")
+}
+
+func entryRecord(seg Segment, e SegmentEntry, raw []byte) (Record, error) {
+	var rec Record
+	switch {
+	case raw == nil:
+		return rec, fmt.Errorf("audit: segment %s has no member %s", seg.Name, e.Member)
+	case sum(raw) != e.FileSHA256:
+		return rec, fmt.Errorf("audit: segment %s member %s digest mismatch", seg.Name, e.Member)
+	}
+	if err := json.Unmarshal(raw, &rec); err != nil {
+		return rec, fmt.Errorf("audit: segment %s member %s: %w", seg.Name, e.Member, err)
+	}
+	if rec.ID != e.ID || rec.SHA256 != e.SHA256 {
+		return rec, fmt.Errorf("audit: segment %s member %s does not match its index entry", seg.Name, e.Member)
+	}
+	return rec, nil
+}
+
+// readSegment verifies a segment against its index entry and returns its
+// members.
+func (s *Store) readSegment(seg Segment) (map[string][]byte, error) {
+	raw, err := os.ReadFile(filepath.Join(s.Dir, SegmentDir, seg.Name))
+	if err != nil {
+		return nil, fmt.Errorf("audit: segment %s: %w", seg.Name, err)
+	}
+	if got := sum(raw); got != seg.SHA256 {
+		return nil, fmt.Errorf("audit: segment %s digest mismatch (index %s, computed %s)", seg.Name, seg.SHA256, got)
+	}
+	zr, err := zstd.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	members := map[string][]byte{}
+	tr := tar.NewReader(zr)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return members, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("audit: segment %s: %w", seg.Name, err)
+		}
+		b, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("audit: segment %s: %w", seg.Name, err)
+		}
+		members[h.Name] = b
+	}
+}
+
+// Compact moves the loose records created before cutoff into a new
+// segment and returns it, or nil when there is nothing to compact. The
+// record files are archived unchanged, so their digests still verify; a
+// record that does not verify stops the compaction. Compact first cleans
+// up after crashed writers and compactions.
+//
+// The steps are ordered so that a crash at any point loses nothing: the
+// segment is written and synced, then the index is replaced, and only then
+// are the loose files removed.
+func (s *Store) Compact(cutoff time.Time) (*Segment, error) {
+	unlock, err := s.lock(true)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	if _, err := s.recover(); err != nil {
+		return nil, err
+	}
+	loose, err := s.loose("")
+	if err != nil {
+		return nil, err
+	}
+	var batch []looseRecord
+	var bad []string
+	for _, l := range loose {
+		if !l.rec.CreatedAt.Before(cutoff) {
+			continue
+		}
+		if err := Verify(l.rec); err != nil {
+			bad = append(bad, l.path+": "+err.Error())
+		}
+		batch = append(batch, l)
+	}
+	if len(bad) > 0 {
+		return nil, fmt.Errorf("audit: refusing to compact records that fail their integrity check:\n  %s", strings.Join(bad, "\n  "))
+	}
+	if len(batch) == 0 {
+		return nil, nil
+	}
+	sort.Slice(batch, func(i, j int) bool {
+		if !batch[i].rec.CreatedAt.Equal(batch[j].rec.CreatedAt) {
+			return batch[i].rec.CreatedAt.Before(batch[j].rec.CreatedAt)
+		}
+		return batch[i].rec.ID < batch[j].rec.ID
+	})
+
+	idx, err := s.LoadIndex()
+	if err != nil {
+		return nil, err
+	}
+	seg := Segment{
+		CreatedAt: s.now().UTC().Truncate(time.Second),
+		From:      batch[0].rec.CreatedAt,
+		To:        batch[len(batch)-1].rec.CreatedAt,
+	}
+	if n := len(idx.Segments); n > 0 {
+		seg.Prev = idx.Segments[n-1].SHA256
+	}
+	seg.Name = fmt.Sprintf("%06d-%s-%s.tar.zst", len(idx.Segments)+1,
+		seg.From.Format("20060102T150405Z"), seg.To.Format("20060102T150405Z"))
+
+	var buf bytes.Buffer
+	zw, err := zstd.NewWriter(&buf, zstd.WithEncoderLevel(zstd.SpeedBetterCompression))
+	if err != nil {
+		return nil, err
+	}
+	tw := tar.NewWriter(zw)
+	for _, l := range batch {
+		e := SegmentEntry{
+			ID: l.rec.ID, Namespace: l.rec.Namespace, Kind: l.rec.Kind, Subject: l.rec.Subject,
+			CreatedAt: l.rec.CreatedAt, SHA256: l.rec.SHA256,
+			Member: path.Join(l.rec.Namespace, l.rec.ID+".json"), FileSHA256: sum(l.raw),
+		}
+		h := &tar.Header{Name: e.Member, Mode: 0o644, Size: int64(len(l.raw)), ModTime: l.rec.CreatedAt, Format: tar.FormatPAX}
+		if err := tw.WriteHeader(h); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(l.raw); err != nil {
+			return nil, err
+		}
+		seg.Records = append(seg.Records, e)
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	seg.SHA256, seg.Size = sum(buf.Bytes()), int64(buf.Len())
+
+	dir := filepath.Join(s.Dir, SegmentDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	if err := replace(filepath.Join(dir, seg.Name), buf.Bytes()); err != nil {
+		return nil, err
+	}
+	idx.Version = 1
+	idx.Segments = append(idx.Segments, seg)
+	raw, err := canon.MarshalPretty(idx)
+	if err != nil {
+		return nil, err
+	}
+	if err := replace(filepath.Join(dir, indexName), append(raw, '\n')); err != nil {
+		return nil, err
+	}
+	for _, l := range batch {
+		if err := os.Remove(l.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return &seg, nil
+}
+
+// recover removes what crashes leave behind: temporary files, segments the
+// index does not list, and loose records a segment already holds. It
+// returns what it removed. The caller holds the exclusive lock.
+func (s *Store) recover() ([]string, error) {
+	leftovers, err := s.leftovers()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range leftovers {
+		if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return leftovers, nil
+}
+
+// leftovers finds temporary files, unindexed segments and loose records
+// already compacted with the same digest.
+func (s *Store) leftovers() ([]string, error) {
+	idx, err := s.LoadIndex()
+	if err != nil {
+		return nil, err
+	}
+	indexed := map[string]bool{indexName: true}
+	compacted := map[string]string{}
+	for _, seg := range idx.Segments {
+		indexed[seg.Name] = true
+		for _, e := range seg.Records {
+			compacted[e.ID] = e.SHA256
+		}
+	}
+	var out []string
+	err = filepath.WalkDir(s.Dir, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) && p == s.Dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		name := d.Name()
+		switch {
+		case d.IsDir():
+			return nil
+		case strings.HasPrefix(name, ".") && strings.Contains(name, ".tmp-"):
+			out = append(out, p)
+		case filepath.Dir(p) == filepath.Join(s.Dir, SegmentDir) && !indexed[name]:
+			out = append(out, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	loose, err := s.loose("")
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range loose {
+		if sha, ok := compacted[l.rec.ID]; ok && sha == l.rec.SHA256 {
+			out = append(out, l.path)
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// CheckReport is the result of a full store check.
+type CheckReport struct {
+	Loose     int `json:"loose"`
+	Compacted int `json:"compacted"`
+	Segments  int `json:"segments"`
+	// Problems are integrity failures: tampered records or segments, a
+	// broken segment chain, and records compacted twice.
+	Problems []string `json:"problems"`
+	// Leftovers are files crashes left behind; the next Compact removes
+	// them.
+	Leftovers []string `json:"leftovers"`
+}
+
+// Check verifies every segment against the index and its chain, and every
+// record, loose or compacted, against its digest.
+func (s *Store) Check() (*CheckReport, error) {
+	r := &CheckReport{Problems: []string{}, Leftovers: []string{}}
+	idx, err := s.LoadIndex()
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]string{}
+	prev := ""
+	for _, seg := range idx.Segments {
+		r.Segments++
+		if seg.Prev != prev {
+			r.Problems = append(r.Problems, fmt.Sprintf("segment %s: chain broken (prev %s, want %s)", seg.Name, short(seg.Prev), short(prev)))
+		}
+		prev = seg.SHA256
+		members, err := s.readSegment(seg)
+		if err != nil {
+			r.Problems = append(r.Problems, err.Error())
+			continue
+		}
+		for _, e := range seg.Records {
+			rec, err := entryRecord(seg, e, members[e.Member])
+			if err == nil {
+				err = Verify(rec)
+			}
+			if err != nil {
+				r.Problems = append(r.Problems, err.Error())
+				continue
+			}
+			if other, dup := seen[e.ID]; dup {
+				r.Problems = append(r.Problems, fmt.Sprintf("record %s is in segments %s and %s", e.ID, other, seg.Name))
+			}
+			seen[e.ID] = seg.Name
+			r.Compacted++
+		}
+	}
+	loose, err := s.loose("")
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range loose {
+		if err := Verify(l.rec); err != nil {
+			r.Problems = append(r.Problems, l.path+": "+err.Error())
+			continue
+		}
+		if _, dup := seen[l.rec.ID]; !dup {
+			r.Loose++
+		}
+	}
+	if r.Leftovers, err = s.leftovers(); err != nil {
+		return nil, err
+	}
+	if r.Leftovers == nil {
+		r.Leftovers = []string{}
+	}
+	return r, nil
+}
+
+func sum(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+func short(digest string) string {
+	if len(digest) > 12 {
+		return digest[:12]
+	}
+	if digest == "" {
+		return "none"
+	}
+	return digest
+}
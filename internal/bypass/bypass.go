@@ -0,0 +1,301 @@
+// Package bypass finds governance bypasses in the organization audit log:
+// force pushes to protected branches, merges over failing required checks,
+// branch protection and ruleset edits, and deletions or moves of release
+// tags that prevent_tag_move is meant to stop. Each bypass is matched
+// against the .audit trail; one without a break-glass grant or a recorded
+// decision covering it is reported as unjustified, per repo and actor.
+package bypass
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BrikByte-Studios/github-governance/internal/audit"
+	"github.com/BrikByte-Studios/github-governance/internal/ghexport"
+	"github.com/BrikByte-Studios/github-governance/internal/policy"
+)
+
+// Bypass kinds.
+const (
+	KindForcePush      = "force-push"
+	KindFailingChecks  = "failing-checks-merge"
+	KindPolicyOverride = "policy-override"
+	KindProtectionEdit = "protection-edit"
+	KindTagDelete      = "tag-delete"
+	KindTagMove        = "tag-move"
+)
+
+// kindCodes are the stable codes of unjustified bypasses, by kind.
+var kindCodes = map[string]string{
+	KindForcePush:      "BYP-001",
+	KindFailingChecks:  "BYP-002",
+	KindPolicyOverride: "BYP-003",
+	KindProtectionEdit: "BYP-004",
+	KindTagDelete:      "BYP-005",
+	KindTagMove:        "BYP-006",
+}
+
+// Kinds lists the bypass kinds in report order.
+var Kinds = []string{KindForcePush, KindFailingChecks, KindPolicyOverride, KindProtectionEdit, KindTagDelete, KindTagMove}
+
+// Config is the bypass section of the effective policy.
+type Config struct {
+	// ProtectedBranches and ProtectedTags are globs; force pushes and tag
+	// deletions elsewhere are not bypasses.
+	ProtectedBranches []string `json:"protected_branches"`
+	ProtectedTags     []string `json:"protected_tags"`
+	// DecisionWindowHours is how long after a bypass its decision may be
+	// recorded.
+	DecisionWindowHours int `json:"decision_window_hours"`
+	// MaxGrantHours caps a break-glass grant; longer grants cover only
+	// their first MaxGrantHours.
+	MaxGrantHours int `json:"max_grant_hours"`
+}
+
+// DefaultConfig applies without a bypass section.
+func DefaultConfig() *Config {
+	return &Config{
+		ProtectedBranches:   []string{"main", "master", "release/*", "hotfix/*"},
+		ProtectedTags:       []string{"v*"},
+		DecisionWindowHours: 72,
+		MaxGrantHours:       8,
+	}
+}
+
+// LoadConfig reads the bypass section of a policy file.
+func LoadConfig(path string) (*Config, error) {
+	c := DefaultConfig()
+	if err := policy.LoadSection(path, "bypass", c); err != nil {
+		return nil, err
+	}
+	if c.DecisionWindowHours <= 0 || c.MaxGrantHours <= 0 {
+		return nil, fmt.Errorf("%s: bypass.decision_window_hours and bypass.max_grant_hours must be > 0", path)
+	}
+	return c, nil
+}
+
+// Event is a classified bypass.
+type Event struct {
+	Time   time.Time `json:"time"`
+	Kind   string    `json:"kind"`
+	Action string    `json:"action"`
+	Repo   string    `json:"repo"`
+	Actor  string    `json:"actor"`
+	// Ref is the branch or tag, or the protection rule's pattern.
+	Ref    string `json:"ref,omitempty"`
+	SHA    string `json:"sha,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Classify returns the bypass an audit log entry records, or nil.
+func Classify(e ghexport.AuditEntry, cfg *Config) *Event {
+	ev := &Event{Time: e.Time, Action: e.Action, Repo: e.Repo, Actor: strings.TrimPrefix(e.Actor, "@"), Ref: ref(e)}
+	ev.SHA = first(e.String("after"), e.String("sha"), e.String("commit_sha"))
+	switch {
+	case e.Action == "protected_branch.policy_override":
+		// Only admins on protected branches produce this; the reasons say
+		// which requirement they overrode.
+		reasons := strings.ToLower(e.String("reasons") + " " + e.String("overridden_rules"))
+		ev.Detail = strings.TrimSpace(e.String("reasons"))
+		switch {
+		case strings.Contains(reasons, "force"):
+			ev.Kind = KindForcePush
+		case strings.Contains(reasons, "status_check") || strings.Contains(reasons, "status check") || strings.Contains(reasons, "checks"):
+			ev.Kind = KindFailingChecks
+		default:
+			ev.Kind = KindPolicyOverride
+		}
+	case isProtectionEdit(e.Action):
+		ev.Kind = KindProtectionEdit
+		ev.Detail = first(e.String("ruleset_name"), e.String("name"))
+	case e.Action == "pull_request.merge":
+		state := strings.ToLower(first(e.String("checks_state"), e.String("status_checks_state")))
+		if state != "failure" && state != "error" && state != "pending" && e.String("failed_checks") == "" {
+			return nil
+		}
+		if !matchAny(cfg.ProtectedBranches, strings.TrimPrefix(ev.Ref, "refs/heads/")) {
+			return nil
+		}
+		ev.Kind = KindFailingChecks
+		ev.Detail = first(e.String("failed_checks"), state)
+	case e.Action == "git.push" || e.Action == "git.delete" || e.Action == "git.force_push":
+		deleted := e.Action == "git.delete" || e.Bool("deleted") || strings.Trim(e.String("after"), "0") == "" && e.String("after") != ""
+		forced := e.Action == "git.force_push" || e.Bool("forced") || e.Bool("force_push")
+		if tag, ok := strings.CutPrefix(ev.Ref, "refs/tags/"); ok {
+			if !matchAny(cfg.ProtectedTags, tag) {
+				return nil
+			}
+			switch {
+			case deleted:
+				ev.Kind = KindTagDelete
+			case forced || e.String("before") != "" && strings.Trim(e.String("before"), "0") != "":
+				// Pushing over an existing tag moves it.
+				ev.Kind = KindTagMove
+			default:
+				return nil
+			}
+		} else if branch, ok := strings.CutPrefix(ev.Ref, "refs/heads/"); ok && forced && !deleted && matchAny(cfg.ProtectedBranches, branch) {
+			ev.Kind = KindForcePush
+		} else {
+			return nil
+		}
+	default:
+		return nil
+	}
+	return ev
+}
+
+// isProtectionEdit reports whether an action weakens or removes branch,
+// tag or ruleset protection. Creating protection tightens it.
+func isProtectionEdit(action string) bool {
+	group, op, _ := strings.Cut(action, ".")
+	switch group {
+	case "protected_branch":
+		return op != "create" && op != "policy_override" && op != "rejected_ref_update"
+	case "repository_ruleset", "tag_protection":
+		return op == "update" || op == "destroy"
+	}
+	return false
+}
+
+// ref is the fully qualified ref of a push, or the branch of a protection
+// event.
+func ref(e ghexport.AuditEntry) string {
+	if r := e.String("ref"); r != "" {
+		return r
+	}
+	if b := first(e.String("branch"), e.String("base_ref")); b != "" {
+		return "refs/heads/" + b
+	}
+	if t := e.String("tag"); t != "" {
+		return "refs/tags/" + t
+	}
+	return e.String("name")
+}
+
+func first(vals ...string) string {
+	for _, v := range vals {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func matchAny(globs []string, s string) bool {
+	for _, g := range globs {
+		if ok, _ := path.Match(g, s); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Finding is a bypass with the record that justifies it, if any.
+type Finding struct {
+	Event
+	// Code is the stable code of the event's kind (BYP-001…BYP-006).
+	Code      string `json:"code"`
+	Justified bool   `json:"justified"`
+	// Via is "break-glass" or "decision"; By is the record id.
+	Via   string   `json:"via,omitempty"`
+	By    string   `json:"by,omitempty"`
+	Notes []string `json:"notes,omitempty"`
+}
+
+// ActorSummary counts one actor's bypasses in a repo.
+type ActorSummary struct {
+	Actor       string         `json:"actor"`
+	Bypasses    int            `json:"bypasses"`
+	Unjustified int            `json:"unjustified"`
+	Kinds       map[string]int `json:"unjustified_kinds"`
+}
+
+// RepoSummary counts a repo's bypasses.
+type RepoSummary struct {
+	Repo        string         `json:"repo"`
+	Bypasses    int            `json:"bypasses"`
+	Unjustified int            `json:"unjustified"`
+	Actors      []ActorSummary `json:"actors"`
+}
+
+// Report is the analysis of one audit log export.
+type Report struct {
+	From        time.Time     `json:"from"`
+	To          time.Time     `json:"to"`
+	Scanned     int           `json:"scanned"`
+	Bypasses    int           `json:"bypasses"`
+	Unjustified int           `json:"unjustified"`
+	Findings    []Finding     `json:"findings"`
+	Repos       []RepoSummary `json:"repos"`
+}
+
+// Analyze classifies the entries in [from, to) (a zero bound is open) and
+// correlates the bypasses with the bypass records of the audit trail.
+// Records failing their integrity check are an error: a tampered grant
+// must not justify anything.
+func Analyze(entries []ghexport.AuditEntry, records []audit.Record, cfg *Config, from, to time.Time) (*Report, error) {
+	j, err := loadJustifications(records)
+	if err != nil {
+		return nil, err
+	}
+	r := &Report{From: from, To: to, Findings: []Finding{}, Repos: []RepoSummary{}}
+	for _, e := range entries {
+		if !from.IsZero() && e.Time.Before(from) || !to.IsZero() && !e.Time.Before(to) {
+			continue
+		}
+		r.Scanned++
+		ev := Classify(e, cfg)
+		if ev == nil {
+			continue
+		}
+		r.Findings = append(r.Findings, j.justify(*ev, cfg))
+	}
+	sort.SliceStable(r.Findings, func(a, b int) bool { return r.Findings[a].Time.Before(r.Findings[b].Time) })
+
+	repos := map[string]*RepoSummary{}
+	actors := map[[2]string]*ActorSummary{}
+	for _, f := range r.Findings {
+		rs := repos[f.Repo]
+		if rs == nil {
+			rs = &RepoSummary{Repo: f.Repo}
+			repos[f.Repo] = rs
+		}
+		as := actors[[2]string{f.Repo, f.Actor}]
+		if as == nil {
+			as = &ActorSummary{Actor: f.Actor, Kinds: map[string]int{}}
+			actors[[2]string{f.Repo, f.Actor}] = as
+		}
+		r.Bypasses++
+		rs.Bypasses++
+		as.Bypasses++
+		if !f.Justified {
+			r.Unjustified++
+			rs.Unjustified++
+			as.Unjustified++
+			as.Kinds[f.Kind]++
+		}
+	}
+	for key, as := range actors {
+		repos[key[0]].Actors = append(repos[key[0]].Actors, *as)
+	}
+	for _, rs := range repos {
+		sort.Slice(rs.Actors, func(a, b int) bool {
+			if rs.Actors[a].Unjustified != rs.Actors[b].Unjustified {
+				return rs.Actors[a].Unjustified > rs.Actors[b].Unjustified
+			}
+			return rs.Actors[a].Actor < rs.Actors[b].Actor
+		})
+		r.Repos = append(r.Repos, *rs)
+	}
+	sort.Slice(r.Repos, func(a, b int) bool {
+		if r.Repos[a].Unjustified != r.Repos[b].Unjustified {
+			return r.Repos[a].Unjustified > r.Repos[b].Unjustified
+		}
+		return r.Repos[a].Repo < r.Repos[b].Repo
+	})
+	return r, nil
+}
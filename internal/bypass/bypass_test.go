@@ -0,0 +1,229 @@
+package bypass
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BrikByte-Studios/github-governance/internal/audit"
+	"github.com/BrikByte-Studios/github-governance/internal/ghexport"
+)
+
+const fixtures = "../../tests/fixtures/bypass"
+
+type nopSanitizer struct{}
+
+func (nopSanitizer) RedactBytes(b []byte) ([]byte, int) { return b, 0 }
+
+func at(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// trail records the justifications of the fixture log, each created at
+// the given time.
+func trail(t *testing.T) *audit.Store {
+	t.Helper()
+	var now time.Time
+	s := &audit.Store{Dir: t.TempDir(), Sanitizer: nopSanitizer{}, Now: func() time.Time { return now }}
+	cfg := DefaultConfig()
+	put := func(created string, kind string, v any) {
+		t.Helper()
+		now = at(created)
+		raw, _ := json.Marshal(v)
+		if _, _, err := s.Put(Namespace, kind, "", raw); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now = at("2026-09-01T12:00:00Z")
+	if _, _, err := RecordDecision(s, &Decision{
+		Repo: "BrikByte-Studios/payments", Kind: KindForcePush, Actor: "dana", SHA: "2222222",
+		Reason: "revert leaked credentials from history", ApprovedBy: []string{"@eli"}, Ticket: "SEC-19",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// Validation refuses these; written raw, the analysis must still
+	// reject them.
+	put("2026-09-01T12:00:00Z", KindDecision, Decision{
+		Repo: "BrikByte-Studios/payments", Kind: KindFailingChecks, Actor: "eli", SHA: "4444444",
+		Reason: "flaky check", ApprovedBy: []string{"eli"},
+	})
+	put("2026-09-08T09:00:00Z", KindDecision, Decision{
+		Repo: "BrikByte-Studios/identity", Kind: KindTagMove, Actor: "eli", At: at("2026-09-03T15:20:00Z"),
+		Reason: "retagged after a bad build", ApprovedBy: []string{"dana"},
+	})
+
+	now = at("2026-09-02T08:00:00Z")
+	if _, _, err := RecordGrant(s, &Grant{
+		Repo: "BrikByte-Studios/*", Actor: "ops-bot", Kinds: []string{KindProtectionEdit}, Reason: "SEV-1 restore",
+		Incident: "#88", StartsAt: at("2026-09-02T08:00:00Z"), ExpiresAt: at("2026-09-02T12:00:00Z"),
+	}, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := RecordGrant(s, &Grant{
+		Repo: "BrikByte-Studios/identity", Actor: "eli", Reason: "release rollback",
+		StartsAt: at("2026-09-03T16:00:00Z"), ExpiresAt: at("2026-09-03T18:00:00Z"),
+	}, cfg); err != nil {
+		t.Fatal(err)
+	}
+	// Backdated after the fact, a grant is held to the decision deadline.
+	now = at("2026-09-10T08:00:00Z")
+	if _, _, err := RecordGrant(s, &Grant{
+		Repo: "BrikByte-Studios/payments", Actor: "dana", Reason: "ruleset cleanup",
+		StartsAt: at("2026-09-04T07:00:00Z"), ExpiresAt: at("2026-09-04T09:00:00Z"),
+	}, cfg); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func analyze(t *testing.T, log string, s *audit.Store) *Report {
+	t.Helper()
+	entries, err := ghexport.LoadAuditLog(filepath.Join(fixtures, log))
+	if err != nil {
+		t.Fatal(err)
+	}
+	recs, err := s.List(Namespace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := Analyze(entries, recs, DefaultConfig(), time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestAnalyze(t *testing.T) {
+	r := analyze(t, "audit-log.json", trail(t))
+	if r.Scanned != 10 || r.Bypasses != 6 || r.Unjustified != 4 {
+		t.Fatalf("scanned %d, bypasses %d, unjustified %d", r.Scanned, r.Bypasses, r.Unjustified)
+	}
+	type row struct{ kind, repo, actor, via, note string }
+	want := []row{
+		{KindForcePush, "BrikByte-Studios/payments", "dana", KindDecision, ""},
+		{KindFailingChecks, "BrikByte-Studios/payments", "eli", "", "approved only by the actor"},
+		{KindProtectionEdit, "BrikByte-Studios/identity", "ops-bot", KindGrant, ""},
+		{KindTagDelete, "BrikByte-Studios/identity", "eli", "", "covers 2026-09-03T16:00:00Z to 2026-09-03T18:00:00Z only"},
+		{KindTagMove, "BrikByte-Studios/identity", "eli", "", "recorded 114h after the bypass"},
+		{KindProtectionEdit, "BrikByte-Studios/payments", "dana", "", "recorded 144h after the bypass"},
+	}
+	for i, w := range want {
+		f := r.Findings[i]
+		notes := strings.Join(f.Notes, "; ")
+		if f.Kind != w.kind || f.Repo != w.repo || f.Actor != w.actor || f.Via != w.via || f.Justified != (w.via != "") ||
+			!strings.Contains(notes, w.note) || w.note == "" && notes != "" {
+			t.Errorf("finding %d = %s %s %s via %q (%s), want %+v", i, f.Kind, f.Repo, f.Actor, f.Via, notes, w)
+		}
+	}
+
+	var repos []string
+	for _, rs := range r.Repos {
+		repos = append(repos, rs.Repo)
+	}
+	if !reflect.DeepEqual(repos, []string{"BrikByte-Studios/identity", "BrikByte-Studios/payments"}) {
+		t.Errorf("repos = %v", repos)
+	}
+	if eli := r.Repos[0].Actors[0]; eli.Actor != "eli" || eli.Unjustified != 2 || eli.Kinds[KindTagDelete] != 1 || eli.Kinds[KindTagMove] != 1 {
+		t.Errorf("identity actors = %+v", r.Repos[0].Actors)
+	}
+
+	md := Markdown(r)
+	for _, s := range []string{"**4 unjustified**", "| BrikByte-Studios/identity | @eli | 2 | 2 | tag-delete ×1, tag-move ×1 |", "`refs/tags/v1.4.0`"} {
+		if !strings.Contains(md, s) {
+			t.Errorf("markdown lacks %q:\n%s", s, md)
+		}
+	}
+}
+
+func TestCSVExport(t *testing.T) {
+	r := analyze(t, "audit-log.csv", trail(t))
+	var kinds []string
+	for _, f := range r.Findings {
+		kinds = append(kinds, f.Kind+"/"+f.SHA)
+	}
+	want := []string{KindForcePush + "/2222222222222222222222222222222222222222",
+		KindFailingChecks + "/4444444444444444444444444444444444444444", KindTagDelete + "/"}
+	if r.Scanned != 4 || !reflect.DeepEqual(kinds, want) {
+		t.Errorf("scanned %d, findings %v", r.Scanned, kinds)
+	}
+	if !r.Findings[0].Justified || r.Findings[1].Justified {
+		t.Errorf("CSV findings correlate like JSON ones: %+v", r.Findings[:2])
+	}
+}
+
+func TestWindowAndTamper(t *testing.T) {
+	s := trail(t)
+	entries, _ := ghexport.LoadAuditLog(filepath.Join(fixtures, "audit-log.json"))
+	recs, _ := s.List(Namespace)
+	r, err := Analyze(entries, recs, DefaultConfig(), at("2026-09-03T00:00:00Z"), at("2026-09-04T00:00:00Z"))
+	if err != nil || r.Scanned != 3 || r.Bypasses != 2 {
+		t.Fatalf("window: %+v, %v", r, err)
+	}
+
+	// Widening a grant by editing its file breaks its digest.
+	for _, rec := range recs {
+		if rec.Kind != KindGrant {
+			continue
+		}
+		p := filepath.Join(s.Dir, Namespace, rec.ID+".json")
+		raw, _ := os.ReadFile(p)
+		raw = []byte(strings.Replace(string(raw), "protection-edit", "force-push", 1))
+		if err := os.WriteFile(p, raw, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	recs, _ = s.List(Namespace)
+	if _, err := Analyze(entries, recs, DefaultConfig(), time.Time{}, time.Time{}); err == nil || !strings.Contains(err.Error(), "digest mismatch") {
+		t.Errorf("tampered grant: %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cfg := DefaultConfig()
+	start := at("2026-09-01T00:00:00Z")
+	grant := func(g Grant) error {
+		g.Repo, g.Actor, g.StartsAt = "o/r", "a", start
+		if g.ExpiresAt.IsZero() {
+			g.ExpiresAt = start.Add(time.Hour)
+		}
+		return g.Validate(cfg)
+	}
+	decision := func(d Decision) error {
+		d.Repo, d.Actor, d.Kind, d.Reason = "o/r", "a", KindForcePush, "x"
+		return d.Validate()
+	}
+	cases := []struct {
+		want string
+		err  error
+	}{
+		{"needs a reason", grant(Grant{})},
+		{"exceeds bypass.max_grant_hours", grant(Grant{Reason: "x", ExpiresAt: start.Add(9 * time.Hour)})},
+		{"unknown bypass kind", grant(Grant{Reason: "x", Kinds: []string{"push"}})},
+		{"approver other than a", decision(Decision{SHA: "abc", ApprovedBy: []string{"@a"}})},
+		{"commit or the time", decision(Decision{ApprovedBy: []string{"b"}})},
+	}
+	for _, c := range cases {
+		if c.err == nil || !strings.Contains(c.err.Error(), c.want) {
+			t.Errorf("want %q, got %v", c.want, c.err)
+		}
+	}
+	if err := grant(Grant{Reason: "x", Kinds: []string{KindTagDelete}}); err != nil {
+		t.Errorf("valid grant: %v", err)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	cfg, err := LoadConfig("../../.github/policy.yml")
+	if err != nil || !reflect.DeepEqual(cfg, DefaultConfig()) {
+		t.Errorf("org policy bypass = %+v, %v; keep it in sync with DefaultConfig", cfg, err)
+	}
+}
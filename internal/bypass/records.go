@@ -0,0 +1,256 @@
+package bypass
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/BrikByte-Studios/github-governance/internal/audit"
+)
+
+// Audit namespace and record kinds:
+//
+//	bypass/<id>  kind=break-glass  subject=<repo>  (a time-boxed grant)
+//	bypass/<id>  kind=decision     subject=<repo>  (one approved bypass)
+const (
+	Namespace    = "bypass"
+	KindGrant    = "break-glass"
+	KindDecision = "decision"
+)
+
+// Grant is a break-glass grant: an actor may bypass protection in matching
+// repos for a bounded time, during an incident.
+type Grant struct {
+	// Repo is "owner/name" or a glob ("owner/*").
+	Repo  string `json:"repo"`
+	Actor string `json:"actor"`
+	// Kinds limits the grant to some bypass kinds; empty covers all.
+	Kinds      []string  `json:"kinds,omitempty"`
+	Reason     string    `json:"reason"`
+	Incident   string    `json:"incident,omitempty"`
+	ApprovedBy string    `json:"approved_by,omitempty"`
+	StartsAt   time.Time `json:"starts_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// Decision approves one bypass, before or after the fact. It names the
+// event by repo, kind and actor plus the commit or the event time.
+type Decision struct {
+	Repo  string `json:"repo"`
+	Kind  string `json:"kind"`
+	Actor string `json:"actor"`
+	// Ref narrows the decision to a branch or tag (refs/heads/main).
+	Ref string `json:"ref,omitempty"`
+	SHA string `json:"sha,omitempty"`
+	// At is the bypass time; it matches events within decisionSlack.
+	At         time.Time `json:"at"`
+	Reason     string    `json:"reason"`
+	ApprovedBy []string  `json:"approved_by"`
+	Ticket     string    `json:"ticket,omitempty"`
+}
+
+// decisionSlack absorbs the gap between the time an approver writes down
+// and the audit log timestamp.
+const decisionSlack = time.Hour
+
+// Validate checks a grant against the policy before it is recorded.
+func (g *Grant) Validate(cfg *Config) error {
+	switch {
+	case g.Repo == "" || g.Actor == "":
+		return fmt.Errorf("break-glass grant needs a repo and an actor")
+	case strings.TrimSpace(g.Reason) == "":
+		return fmt.Errorf("break-glass grant needs a reason")
+	case !g.ExpiresAt.After(g.StartsAt):
+		return fmt.Errorf("break-glass grant expires before it starts")
+	case g.ExpiresAt.Sub(g.StartsAt) > time.Duration(cfg.MaxGrantHours)*time.Hour:
+		return fmt.Errorf("break-glass grant of %s exceeds bypass.max_grant_hours (%d)", g.ExpiresAt.Sub(g.StartsAt), cfg.MaxGrantHours)
+	}
+	if _, err := path.Match(g.Repo, ""); err != nil {
+		return fmt.Errorf("break-glass grant repo %q: %w", g.Repo, err)
+	}
+	return checkKinds(g.Kinds...)
+}
+
+// Validate checks a decision before it is recorded.
+func (d *Decision) Validate() error {
+	switch {
+	case d.Repo == "" || d.Actor == "":
+		return fmt.Errorf("bypass decision needs a repo and an actor")
+	case strings.TrimSpace(d.Reason) == "":
+		return fmt.Errorf("bypass decision needs a reason")
+	case d.SHA == "" && d.At.IsZero():
+		return fmt.Errorf("bypass decision needs the commit or the time of the bypass")
+	case len(approvers(d.ApprovedBy, d.Actor)) == 0:
+		return fmt.Errorf("bypass decision needs an approver other than %s", d.Actor)
+	}
+	return checkKinds(d.Kind)
+}
+
+func checkKinds(kinds ...string) error {
+	for _, k := range kinds {
+		found := false
+		for _, known := range Kinds {
+			found = found || k == known
+		}
+		if !found {
+			return fmt.Errorf("unknown bypass kind %q (one of %s)", k, strings.Join(Kinds, ", "))
+		}
+	}
+	return nil
+}
+
+// RecordGrant stores a validated grant.
+func RecordGrant(store *audit.Store, g *Grant, cfg *Config) (*audit.Record, string, error) {
+	if err := g.Validate(cfg); err != nil {
+		return nil, "", err
+	}
+	raw, err := json.Marshal(g)
+	if err != nil {
+		return nil, "", err
+	}
+	return store.Put(Namespace, KindGrant, g.Repo, raw)
+}
+
+// RecordDecision stores a validated decision.
+func RecordDecision(store *audit.Store, d *Decision) (*audit.Record, string, error) {
+	if err := d.Validate(); err != nil {
+		return nil, "", err
+	}
+	raw, err := json.Marshal(d)
+	if err != nil {
+		return nil, "", err
+	}
+	return store.Put(Namespace, KindDecision, d.Repo, raw)
+}
+
+type grantRecord struct {
+	Grant
+	id      string
+	created time.Time
+}
+
+type decisionRecord struct {
+	Decision
+	id      string
+	created time.Time
+}
+
+type justifications struct {
+	grants    []grantRecord
+	decisions []decisionRecord
+}
+
+func loadJustifications(records []audit.Record) (*justifications, error) {
+	j := &justifications{}
+	for _, rec := range records {
+		if rec.Namespace != Namespace {
+			continue
+		}
+		if err := audit.Verify(rec); err != nil {
+			return nil, err
+		}
+		switch rec.Kind {
+		case KindGrant:
+			var g Grant
+			if err := json.Unmarshal(rec.Content, &g); err != nil {
+				return nil, fmt.Errorf("%s: %w", rec.ID, err)
+			}
+			j.grants = append(j.grants, grantRecord{g, rec.ID, rec.CreatedAt})
+		case KindDecision:
+			var d Decision
+			if err := json.Unmarshal(rec.Content, &d); err != nil {
+				return nil, fmt.Errorf("%s: %w", rec.ID, err)
+			}
+			j.decisions = append(j.decisions, decisionRecord{d, rec.ID, rec.CreatedAt})
+		}
+	}
+	return j, nil
+}
+
+// justify looks for a grant covering the event, then a decision naming
+// it. On an unjustified bypass the near misses are kept as notes, so
+// reviewers see why a record did not count.
+func (j *justifications) justify(ev Event, cfg *Config) Finding {
+	f := Finding{Event: ev, Code: kindCodes[ev.Kind]}
+	window := time.Duration(cfg.DecisionWindowHours) * time.Hour
+	late := func(id string, created time.Time) bool {
+		if d := created.Sub(ev.Time); d > window {
+			f.Notes = append(f.Notes, fmt.Sprintf("%s was recorded %dh after the bypass, beyond bypass.decision_window_hours (%d)",
+				id, int(d.Hours()), cfg.DecisionWindowHours))
+			return true
+		}
+		return false
+	}
+	for _, g := range j.grants {
+		if !sameActor(g.Actor, ev.Actor) || !matchAny([]string{g.Repo}, ev.Repo) || !coversKind(g.Kinds, ev.Kind) {
+			continue
+		}
+		end := g.ExpiresAt
+		if limit := g.StartsAt.Add(time.Duration(cfg.MaxGrantHours) * time.Hour); end.After(limit) {
+			end = limit
+		}
+		if ev.Time.Before(g.StartsAt) || ev.Time.After(end) {
+			f.Notes = append(f.Notes, fmt.Sprintf("break-glass %s covers %s to %s only", g.id,
+				g.StartsAt.Format(time.RFC3339), end.Format(time.RFC3339)))
+			continue
+		}
+		// A backdated grant is a decision in disguise and gets the same
+		// deadline.
+		if late("break-glass "+g.id, g.created) {
+			continue
+		}
+		f.Justified, f.Via, f.By, f.Notes = true, KindGrant, g.id, nil
+		return f
+	}
+	for _, d := range j.decisions {
+		if d.Repo != ev.Repo || d.Kind != ev.Kind || !sameActor(d.Actor, ev.Actor) || d.Ref != "" && d.Ref != ev.Ref {
+			continue
+		}
+		switch {
+		case d.SHA != "" && !strings.HasPrefix(ev.SHA, d.SHA):
+			continue
+		case d.SHA == "" && (ev.Time.Sub(d.At) > decisionSlack || d.At.Sub(ev.Time) > decisionSlack):
+			continue
+		}
+		if len(approvers(d.ApprovedBy, ev.Actor)) == 0 {
+			f.Notes = append(f.Notes, fmt.Sprintf("decision %s is approved only by the actor", d.id))
+			continue
+		}
+		if late("decision "+d.id, d.created) {
+			continue
+		}
+		f.Justified, f.Via, f.By, f.Notes = true, KindDecision, d.id, nil
+		return f
+	}
+	return f
+}
+
+func coversKind(kinds []string, kind string) bool {
+	if len(kinds) == 0 {
+		return true
+	}
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func sameActor(a, b string) bool {
+	return strings.EqualFold(strings.TrimPrefix(a, "@"), strings.TrimPrefix(b, "@"))
+}
+
+// approvers drops the actor from an approver list: nobody approves their
+// own bypass.
+func approvers(list []string, actor string) []string {
+	var out []string
+	for _, u := range list {
+		if u = strings.TrimPrefix(strings.TrimSpace(u), "@"); u != "" && !sameActor(u, actor) {
+			out = append(out, u)
+		}
+	}
+	return out
+}
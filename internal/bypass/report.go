@@ -0,0 +1,61 @@
+package bypass
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Markdown renders the unjustified bypasses per repo and actor, then each
+// one with the near misses that did not justify it.
+func Markdown(r *Report) string {
+	var b strings.Builder
+	b.WriteString("## Governance bypasses\n\n")
+	fmt.Fprintf(&b, "%d audit log events scanned, %d bypasses, **%d unjustified**.\n\n", r.Scanned, r.Bypasses, r.Unjustified)
+	if r.Bypasses == 0 {
+		return b.String()
+	}
+	b.WriteString("| Repository | Actor | Bypasses | Unjustified | Kinds |\n")
+	b.WriteString("|---|---|---|---|---|\n")
+	for _, rs := range r.Repos {
+		for _, as := range rs.Actors {
+			var kinds []string
+			for _, k := range Kinds {
+				if n := as.Kinds[k]; n > 0 {
+					kinds = append(kinds, fmt.Sprintf("%s ×%d", k, n))
+				}
+			}
+			fmt.Fprintf(&b, "| %s | @%s | %d | %d | %s |\n", rs.Repo, as.Actor, as.Bypasses, as.Unjustified, orDash(strings.Join(kinds, ", ")))
+		}
+	}
+	if r.Unjustified == 0 {
+		return b.String()
+	}
+	b.WriteString("\n### Unjustified\n\n")
+	for _, f := range r.Findings {
+		if f.Justified {
+			continue
+		}
+		fmt.Fprintf(&b, "- %s **%s** in %s by @%s (`%s`", f.Time.Format(time.RFC3339), f.Kind, f.Repo, f.Actor, f.Action)
+		if f.Ref != "" {
+			fmt.Fprintf(&b, " on `%s`", f.Ref)
+		}
+		b.WriteString(")")
+		if f.Detail != "" {
+			fmt.Fprintf(&b, ": %s", f.Detail)
+		}
+		b.WriteString("\n")
+		for _, n := range f.Notes {
+			fmt.Fprintf(&b, "  - %s\n", n)
+		}
+	}
+	b.WriteString("\nRecord a decision with `brikgov bypass decide`, or open a break-glass grant with `brikgov bypass grant` before the next one.\n")
+	return b.String()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "—"
+	}
+	return s
+}
@@ -0,0 +1,247 @@
+// Package canon serializes JSON in the RFC 8785 JSON Canonicalization
+// Scheme (JCS), so that identical governance outputs are byte-identical and
+// can be hashed and signed.
+//
+// The canonical form has no insignificant whitespace, object members sorted
+// by their UTF-16 code units, numbers in the ECMAScript shortest form
+// (1e+30, 0.002, 4.5) and strings escaped only where JSON requires it.
+// Numbers are IEEE 754 doubles, as in JCS: integers beyond 2^53 lose
+// precision.
+//
+// The pretty form is the canonical form indented by two spaces. It keeps
+// the canonical key order and number formatting, so Transform of a pretty
+// document gives back the canonical bytes.
+package canon
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Marshal returns the canonical JSON encoding of v. v is first encoded with
+// encoding/json, so struct tags and Marshaler implementations apply.
+func Marshal(v any) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return Transform(raw)
+}
+
+// MarshalPretty returns the pretty form of v, without a trailing newline.
+func MarshalPretty(v any) ([]byte, error) {
+	c, err := Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return Indent(c), nil
+}
+
+// Transform canonicalizes a JSON document. Duplicate object keys, invalid
+// UTF-8, numbers outside the double range and trailing data are errors.
+func Transform(raw []byte) ([]byte, error) {
+	if !utf8.Valid(raw) {
+		return nil, errors.New("canon: invalid UTF-8")
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var buf bytes.Buffer
+	if err := transform(dec, &buf); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("canon: data after the top-level value")
+	}
+	return buf.Bytes(), nil
+}
+
+// Indent returns the pretty form of a canonical document.
+func Indent(canonical []byte) []byte {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, canonical, "", "  "); err != nil {
+		return canonical
+	}
+	return buf.Bytes()
+}
+
+// Digest returns the hex SHA-256 of the canonical form of a JSON document.
+func Digest(raw []byte) (string, error) {
+	c, err := Transform(raw)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(c)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// transform writes the canonical form of the next value of dec.
+func transform(dec *json.Decoder, buf *bytes.Buffer) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("canon: %w", err)
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		if t == '[' {
+			buf.WriteByte('[')
+			for i := 0; dec.More(); i++ {
+				if i > 0 {
+					buf.WriteByte(',')
+				}
+				if err := transform(dec, buf); err != nil {
+					return err
+				}
+			}
+			_, err := dec.Token()
+			buf.WriteByte(']')
+			return err
+		}
+		return transformObject(dec, buf)
+	case string:
+		writeString(buf, t)
+	case json.Number:
+		s, err := formatNumber(string(t))
+		if err != nil {
+			return err
+		}
+		buf.WriteString(s)
+	case bool:
+		buf.WriteString(strconv.FormatBool(t))
+	case nil:
+		buf.WriteString("null")
+	}
+	return nil
+}
+
+func transformObject(dec *json.Decoder, buf *bytes.Buffer) error {
+	type member struct {
+		key   string
+		value []byte
+	}
+	var members []member
+	seen := map[string]bool{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("canon: %w", err)
+		}
+		key := tok.(string)
+		if seen[key] {
+			return fmt.Errorf("canon: duplicate key %q", key)
+		}
+		seen[key] = true
+		var value bytes.Buffer
+		if err := transform(dec, &value); err != nil {
+			return err
+		}
+		members = append(members, member{key, value.Bytes()})
+	}
+	if _, err := dec.Token(); err != nil {
+		return fmt.Errorf("canon: %w", err)
+	}
+	sort.Slice(members, func(i, j int) bool { return less(members[i].key, members[j].key) })
+	buf.WriteByte('{')
+	for i, m := range members {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		writeString(buf, m.key)
+		buf.WriteByte(':')
+		buf.Write(m.value)
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+// less orders keys by UTF-16 code units, as JCS requires; this differs
+// from byte order for characters above U+FFFF.
+func less(a, b string) bool {
+	ua, ub := utf16.Encode([]rune(a)), utf16.Encode([]rune(b))
+	for i := 0; i < len(ua) && i < len(ub); i++ {
+		if ua[i] != ub[i] {
+			return ua[i] < ub[i]
+		}
+	}
+	return len(ua) < len(ub)
+}
+
+// writeString escapes only '"', '\\' and control characters; the short
+// escapes are used where JSON has them, \u00xx (lowercase) otherwise.
+func writeString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(buf, `\u%04x`, r)
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// formatNumber renders a JSON number the way ECMAScript's Number.prototype
+// .toString does (ECMA-262 Number::toString), which JCS adopts.
+func formatNumber(s string) (string, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+		return "", fmt.Errorf("canon: number %s is not a finite double", s)
+	}
+	if f == 0 {
+		return "0", nil
+	}
+	sign := ""
+	if f < 0 {
+		sign, f = "-", -f
+	}
+	// Shortest round-trip digits d1d2…dk and exponent n with
+	// f = 0.d1d2…dk × 10^n.
+	e := strconv.FormatFloat(f, 'e', -1, 64)
+	mant, exp, _ := strings.Cut(e, "e")
+	digits := strings.Replace(mant, ".", "", 1)
+	x, _ := strconv.Atoi(exp)
+	k, n := len(digits), x+1
+	switch {
+	case k <= n && n <= 21:
+		return sign + digits + strings.Repeat("0", n-k), nil
+	case 0 < n && n <= 21:
+		return sign + digits[:n] + "." + digits[n:], nil
+	case -6 < n && n <= 0:
+		return sign + "0." + strings.Repeat("0", -n) + digits, nil
+	}
+	out := digits[:1]
+	if k > 1 {
+		out += "." + digits[1:]
+	}
+	if n-1 >= 0 {
+		return sign + out + "e+" + strconv.Itoa(n-1), nil
+	}
+	return sign + out + "e" + strconv.Itoa(n-1), nil
+}
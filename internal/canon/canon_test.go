@@ -0,0 +1,138 @@
+package canon
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// The examples of RFC 8785 sections 3.2.2 and 3.2.3.
+func TestRFC8785Examples(t *testing.T) {
+	cases := []struct{ in, want string }{
+		{`{
+  "numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+  "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+  "literals": [null, true, false]
+}`, `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`},
+		{`{
+  "\u20ac": "Euro Sign",
+  "\r": "Carriage Return",
+  "\ufb33": "Hebrew Letter Dalet With Dagesh",
+  "1": "One",
+  "\ud83d\ude00": "Emoji: Grinning Face",
+  "\u0080": "Control",
+  "\u00f6": "Latin Small Letter O With Diaeresis"
+}`, "{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\",\"\u00f6\":\"Latin Small Letter O With Diaeresis\"," +
+			"\"\u20ac\":\"Euro Sign\",\"\U0001f600\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}"},
+	}
+	for _, tc := range cases {
+		got, err := Transform([]byte(tc.in))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tc.want {
+			t.Errorf("Transform:\n got %s\nwant %s", got, tc.want)
+		}
+	}
+}
+
+// Number serialization samples of RFC 8785 appendix B.
+func TestNumbers(t *testing.T) {
+	cases := map[string]string{
+		"0":                         "0",
+		"-0":                        "0",
+		"5e-324":                    "5e-324",
+		"-5e-324":                   "-5e-324",
+		"1.7976931348623157e308":    "1.7976931348623157e+308",
+		"9007199254740992":          "9007199254740992",
+		"-9007199254740992":         "-9007199254740992",
+		"295147905179352830000":     "295147905179352830000",
+		"9.999999999999997e+22":     "9.999999999999997e+22",
+		"1e+23":                     "1e+23",
+		"1e21":                      "1e+21",
+		"999999999999999700000":     "999999999999999700000",
+		"0.000001":                  "0.000001",
+		"1e-7":                      "1e-7",
+		"-1.0":                      "-1",
+		"100":                       "100",
+		"0.1":                       "0.1",
+		"123.456e2":                 "12345.6",
+		"0.0000012345":              "0.0000012345",
+		"1.0000000000000002":        "1.0000000000000002",
+		"4.940656458412465441e-324": "5e-324",
+	}
+	for in, want := range cases {
+		if got, err := formatNumber(in); err != nil || got != want {
+			t.Errorf("formatNumber(%s) = %s, %v; want %s", in, got, err, want)
+		}
+	}
+	if _, err := formatNumber("1e400"); err == nil {
+		t.Error("1e400 accepted")
+	}
+}
+
+func TestTransformErrors(t *testing.T) {
+	for in, want := range map[string]string{
+		`{"a": 1, "a": 2}`: `duplicate key "a"`,
+		`{"a": 1} {}`:      "data after the top-level value",
+		"\"\xff\"":         "invalid UTF-8",
+		`[1, 2`:            "unexpected end",
+		`[1e999]`:          "not a finite double",
+		``:                 "EOF",
+	} {
+		if _, err := Transform([]byte(in)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Transform(%q) = %v, want %q", in, err, want)
+		}
+	}
+}
+
+// TestByteIdentical checks that equal values give identical bytes however
+// they were built: map iteration order, struct field order, number spelling
+// and escaping do not show in the output.
+func TestByteIdentical(t *testing.T) {
+	type rule struct {
+		Result string  `json:"result"`
+		ID     string  `json:"id"`
+		Score  float64 `json:"score"`
+	}
+	a := map[string]any{
+		"status": "passed", "rules": []rule{{"pass", "b<&>", 100}, {"fail", "a", 0.5}},
+		"meta": map[string]any{"z": 1, "y": 2, "x": 3, "w": 4, "v": 5, "u": 6},
+	}
+	first, err := Marshal(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50; i++ {
+		got, err := Marshal(a)
+		if err != nil || !bytes.Equal(got, first) {
+			t.Fatalf("run %d differs:\n%s\n%s", i, got, first)
+		}
+	}
+	want := `{"meta":{"u":6,"v":5,"w":4,"x":3,"y":2,"z":1},"rules":[{"id":"b<&>","result":"pass","score":100},{"id":"a","result":"fail","score":0.5}],"status":"passed"}`
+	if string(first) != want {
+		t.Errorf("Marshal:\n got %s\nwant %s", first, want)
+	}
+
+	hand := `{ "status":"passed","rules":[{"score":1e2,"result":"pass","id":"b\u003c\u0026\u003e"},
+		{"id":"a","score":5E-1,"result":"fail"}],"meta":{"u":6.0,"v":5,"w":4,"x":3,"y":2,"z":1} }`
+	if got, err := Transform([]byte(hand)); err != nil || !bytes.Equal(got, first) {
+		t.Errorf("hand-written equivalent:\n got %s (%v)\nwant %s", got, err, first)
+	}
+
+	pretty, err := MarshalPretty(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(pretty), "{\n  \"meta\": {\n    \"u\": 6,") {
+		t.Errorf("pretty form:\n%s", pretty)
+	}
+	if back, err := Transform(pretty); err != nil || !bytes.Equal(back, first) {
+		t.Errorf("pretty form does not canonicalize back: %s", back)
+	}
+	d1, _ := Digest(pretty)
+	d2, _ := Digest([]byte(hand))
+	if d1 != d2 || len(d1) != 64 {
+		t.Errorf("digests %s and %s", d1, d2)
+	}
+}
@@ -0,0 +1,245 @@
+// Package catalog reads the service catalog: one YAML file per service
+// under .governance/services, naming the owning team, the tier, the
+// runtime stack, the code paths, the dependencies, the runbook and the SLOs.
+//
+//	schemaVersion: 1
+//	service: payments
+//	owner: "@BrikByte-Studios/devops"
+//	tier: 0
+//	runtime: go
+//	paths: ["services/payments/**"]
+//	dependencies:
+//	  - {name: identity, kind: service, critical: true}
+//	runbook: https://github.com/BrikByte-Studios/payments/blob/main/RUNBOOK.md
+//	slos:
+//	  - {id: payments-availability, objective: 99.95, window: 28d}
+//
+// Files are validated against schemas/service.schema.json and then against
+// each other (unique names, known dependencies, no cycles). Check
+// cross-checks a loaded catalog against CODEOWNERS, the runtime matrix and
+// the repository; the query methods serve the gate, incidents and SLO
+// tooling.
+package catalog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/BrikByte-Studios/github-governance/internal/codeowners"
+	"github.com/BrikByte-Studios/github-governance/internal/codes"
+)
+
+// DefaultDir holds the catalog, relative to the repository root.
+const DefaultDir = ".governance/services"
+
+// Dependency kinds.
+const (
+	KindService   = "service"
+	KindDatastore = "datastore"
+	KindExternal  = "external"
+)
+
+// Service is one catalog entry.
+type Service struct {
+	SchemaVersion int          `yaml:"schemaVersion" json:"schemaVersion"`
+	Name          string       `yaml:"service" json:"service"`
+	Description   string       `yaml:"description" json:"description,omitempty"`
+	Owner         string       `yaml:"owner" json:"owner"`
+	Tier          int          `yaml:"tier" json:"tier"`
+	Runtime       string       `yaml:"runtime" json:"runtime"`
+	Paths         []string     `yaml:"paths" json:"paths"`
+	Dependencies  []Dependency `yaml:"dependencies" json:"dependencies,omitempty"`
+	Runbook       string       `yaml:"runbook" json:"runbook,omitempty"`
+	SLOs          []SLO        `yaml:"slos" json:"slos,omitempty"`
+	File          string       `yaml:"-" json:"file"`
+
+	paths []*regexp.Regexp
+}
+
+// Dependency is something a service needs to work. Critical dependencies
+// take the service down with them.
+type Dependency struct {
+	Name     string `yaml:"name" json:"name"`
+	Kind     string `yaml:"kind" json:"kind"`
+	Critical bool   `yaml:"critical" json:"critical,omitempty"`
+}
+
+// SLO references a service level objective.
+type SLO struct {
+	ID          string  `yaml:"id" json:"id"`
+	Description string  `yaml:"description" json:"description,omitempty"`
+	Objective   float64 `yaml:"objective" json:"objective"`
+	// Window is the rolling window, "28d".
+	Window string `yaml:"window" json:"window"`
+	// Ref points at the SLO definition or dashboard.
+	Ref string `yaml:"ref" json:"ref,omitempty"`
+}
+
+// Catalog is the loaded set of services, sorted by name.
+type Catalog struct {
+	Services []Service `json:"services"`
+	byName   map[string]int
+}
+
+// LoadError lists every problem Load found, each with its stable code
+// (CAT-001…CAT-006). Error renders one "file: message" line per problem.
+type LoadError struct {
+	Findings []codes.Finding
+}
+
+func (e *LoadError) Error() string {
+	lines := make([]string, len(e.Findings))
+	for i, f := range e.Findings {
+		lines[i] = f.File + ": " + f.Message
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Load reads every *.yml / *.yaml file in dir and validates each against
+// schema, then the catalog as a whole. All problems are reported at once
+// in a *LoadError.
+func Load(dir string, schema *Schema) (*Catalog, error) {
+	var files []string
+	for _, pat := range []string{"*.yml", "*.yaml"} {
+		m, _ := filepath.Glob(filepath.Join(dir, pat))
+		files = append(files, m...)
+	}
+	sort.Strings(files)
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%s: no service files", dir)
+	}
+	var problems []codes.Finding
+	c := &Catalog{byName: map[string]int{}}
+	for _, f := range files {
+		s, errs := loadService(f, schema)
+		if len(errs) > 0 {
+			problems = append(problems, errs...)
+			continue
+		}
+		c.Services = append(c.Services, *s)
+	}
+	sort.Slice(c.Services, func(i, j int) bool { return c.Services[i].Name < c.Services[j].Name })
+	for i, s := range c.Services {
+		if j, dup := c.byName[s.Name]; dup {
+			problems = append(problems, codes.New("CAT-003", s.File, 0, "service %q already defined in %s", s.Name, c.Services[j].File))
+			continue
+		}
+		c.byName[s.Name] = i
+	}
+	problems = append(problems, c.validate()...)
+	if len(problems) > 0 {
+		return nil, &LoadError{Findings: problems}
+	}
+	return c, nil
+}
+
+func loadService(path string, schema *Schema) (*Service, []codes.Finding) {
+	invalid := func(format string, args ...any) []codes.Finding {
+		return []codes.Finding{codes.New("CAT-001", path, 0, format, args...)}
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, invalid("%v", err)
+	}
+	var doc any
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, invalid("%v", err)
+	}
+	if errs := schema.Validate(doc); len(errs) > 0 {
+		out := make([]codes.Finding, len(errs))
+		for i, e := range errs {
+			out[i] = codes.New("CAT-001", path, 0, "%s", e)
+		}
+		return nil, out
+	}
+	var s Service
+	if err := yaml.Unmarshal(raw, &s); err != nil {
+		return nil, invalid("%v", err)
+	}
+	s.File = filepath.ToSlash(path)
+	if stem := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)); stem != s.Name {
+		return nil, []codes.Finding{codes.New("CAT-002", path, 0, "service %q must be in %s.yml", s.Name, s.Name)}
+	}
+	for _, p := range s.Paths {
+		re, err := codeowners.Compile(p)
+		if err != nil {
+			return nil, invalid("paths: %q: %v", p, err)
+		}
+		s.paths = append(s.paths, re)
+	}
+	return &s, nil
+}
+
+// validate checks what the schema cannot: service dependencies exist, and
+// no service depends on itself, directly or not.
+func (c *Catalog) validate() []codes.Finding {
+	var errs []codes.Finding
+	for _, s := range c.Services {
+		seen := map[string]bool{}
+		for _, d := range s.Dependencies {
+			key := d.Kind + "/" + d.Name
+			if seen[key] {
+				errs = append(errs, codes.New("CAT-004", s.File, 0, "dependency %s listed twice", key))
+			}
+			seen[key] = true
+			if d.Kind != KindService {
+				continue
+			}
+			if _, ok := c.byName[d.Name]; !ok {
+				errs = append(errs, codes.New("CAT-005", s.File, 0, "depends on unknown service %q", d.Name))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	for _, s := range c.Services {
+		// Each cycle is reported once, by its first service.
+		if cycle := c.cycle(s.Name, []string{s.Name}); cycle != nil && !before(cycle, s.Name) {
+			errs = append(errs, codes.New("CAT-006", s.File, 0, "dependency cycle %s", strings.Join(cycle, " → ")))
+		}
+	}
+	return errs
+}
+
+func (c *Catalog) cycle(start string, path []string) []string {
+	for _, d := range c.Services[c.byName[path[len(path)-1]]].Dependencies {
+		if d.Kind != KindService {
+			continue
+		}
+		if d.Name == start {
+			return append(append([]string{}, path...), d.Name)
+		}
+		if contains(path, d.Name) {
+			continue
+		}
+		if cyc := c.cycle(start, append(path, d.Name)); cyc != nil {
+			return cyc
+		}
+	}
+	return nil
+}
+
+func before(names []string, name string) bool {
+	for _, n := range names {
+		if n < name {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
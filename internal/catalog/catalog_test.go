@@ -0,0 +1,276 @@
+package catalog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/BrikByte-Studios/github-governance/internal/codeowners"
+	"github.com/BrikByte-Studios/github-governance/internal/pipeline"
+)
+
+var (
+	fixtures = filepath.Join("..", "..", "tests", "fixtures", "catalog")
+	repoRoot = filepath.Join("..", "..")
+)
+
+func schema(t *testing.T) *Schema {
+	t.Helper()
+	s, err := LoadSchema(filepath.Join(repoRoot, DefaultSchemaPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func load(t *testing.T) *Catalog {
+	t.Helper()
+	c, err := Load(filepath.Join(fixtures, "services"), schema(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestCheck(t *testing.T) {
+	c := load(t)
+	owners, err := codeowners.Load(filepath.Join(fixtures, "CODEOWNERS"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range Check(c, Env{Root: fixtures, CODEOWNERS: owners, Stacks: []string{"dotnet", "go", "java", "node", "python"}}) {
+		line := f.Severity + " " + f.Code + " " + f.Service + ": " + f.Message
+		if f.Suppressed != "" {
+			line += " (suppressed: " + f.Suppressed + ")"
+		}
+		got = append(got, line)
+	}
+	want := []string{
+		"warning CAT-012 admin: admin/** is only covered by the CODEOWNERS catch-all rule",
+		`error CAT-013 admin: runtime "ruby" is not a runtime matrix stack (dotnet, go, java, node, python)`,
+		"error CAT-014 admin: tier 1 service needs a runbook",
+		"error CAT-015 admin: tier 1 service needs at least one SLO",
+		`error CAT-016 checkout: SLO checkout-availability ref "docs/slo/checkout.md" does not exist`,
+		"warning CAT-012 reports: reports/** is only covered by the CODEOWNERS catch-all rule",
+		"error CAT-011 storefront: services/checkout/web/** is owned by @acme/payments in " + filepath.Join(fixtures, "CODEOWNERS") + ":2, not by @acme/web",
+		`error CAT-016 storefront: runbook "http://runbooks.acme.test/storefront" must be an https URL or a repository path`,
+		"error CAT-017 storefront: SLO storefront-availability defined twice",
+		"warning CAT-018 storefront: tier 1 service depends critically on tier 2 service reports",
+		"warning CAT-019 storefront: services/checkout/web/** overlaps service checkout",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findings:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestCheckSuppression(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.yml": "schemaVersion: 1\nservice: a\nowner: \"@o/t\"\ntier: 2\nruntime: go\npaths: [a/**]\n",
+		"b.yml": "schemaVersion: 1\nservice: b\nowner: \"@o/t\"\ntier: 2\nruntime: go\n# brikgov:ignore CAT-019 -- b builds a's assets\npaths: [b/**, a/assets/**]\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c, err := Load(dir, schema(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs := Check(c, Env{Root: dir})
+	if len(fs) != 1 || fs[0].Code != "CAT-019" || fs[0].Suppressed != "b builds a's assets" {
+		t.Fatalf("findings = %+v", fs)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	c := load(t)
+	names := func(ss []Service) []string {
+		out := []string{}
+		for _, s := range ss {
+			out = append(out, s.Name)
+		}
+		return out
+	}
+	cases := []struct {
+		q    Query
+		want []string
+	}{
+		{Query{MaxTier: -1}, []string{"admin", "checkout", "ledger", "reports", "storefront"}},
+		{Query{MaxTier: 0}, []string{"checkout", "ledger"}},
+		{Query{Owner: "acme/web", MaxTier: -1}, []string{"storefront"}},
+		{Query{Owner: "@ACME/Finance", MaxTier: -1}, []string{"ledger"}},
+		{Query{Owner: "payments", MaxTier: -1}, []string{"checkout"}},
+		{Query{Runtime: "python", MaxTier: -1}, []string{"reports"}},
+		{Query{DependsOn: "ledger", MaxTier: -1}, []string{"checkout", "reports"}},
+		{Query{DependsOn: "psp", MaxTier: -1}, []string{"checkout"}},
+	}
+	for _, tc := range cases {
+		if got := names(c.Find(tc.q)); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Find(%+v) = %v, want %v", tc.q, got, tc.want)
+		}
+	}
+
+	if s, ok := c.Service("ledger"); !ok || s.Tier != 0 || s.SLOs[0].Objective != 99.99 {
+		t.Errorf("Service(ledger) = %+v, %v", s, ok)
+	}
+	if _, ok := c.Service("nope"); ok {
+		t.Error("Service(nope) found")
+	}
+	if s := c.ForPath("services/checkout/web/cart.tsx"); s == nil || s.Name != "storefront" {
+		t.Errorf("ForPath(checkout/web) = %v, want storefront (last in name order)", s)
+	}
+	if s := c.ForPath("/services/checkout/main.go"); s == nil || s.Name != "checkout" {
+		t.Errorf("ForPath(checkout) = %v", s)
+	}
+	byService := c.ForPaths([]string{"services/ledger/a.java", "README.md", "reports/q3.py", "services/ledger/b.java"})
+	if want := map[string][]string{"ledger": {"services/ledger/a.java", "services/ledger/b.java"}, "reports": {"reports/q3.py"}}; !reflect.DeepEqual(byService, want) {
+		t.Errorf("ForPaths = %v", byService)
+	}
+
+	graph := []struct {
+		got, want []string
+	}{
+		{c.Dependencies("storefront", false), []string{"checkout", "ledger", "reports"}},
+		{c.Dependencies("storefront", true), []string{"checkout", "ledger", "reports"}},
+		{c.Dependencies("reports", true), []string{}},
+		{c.Dependents("ledger", false), []string{"checkout", "reports", "storefront"}},
+		{c.Dependents("ledger", true), []string{"checkout", "storefront"}},
+		{c.Dependents("ledger-db", true), []string{"checkout", "ledger", "storefront"}},
+		{c.Dependents("psp", false), []string{"checkout", "storefront"}},
+		{c.Dependents("storefront", false), []string{}},
+	}
+	for i, g := range graph {
+		if !reflect.DeepEqual(g.got, g.want) {
+			t.Errorf("graph case %d = %v, want %v", i, g.got, g.want)
+		}
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	cases := []struct {
+		name  string
+		files map[string]string
+		want  []string
+	}{
+		{"schema", map[string]string{"a.yml": `
+schemaVersion: 2
+service: a
+owner: platform
+tier: 5
+runtime: go
+paths: []
+color: blue
+slos:
+  - {id: a-avail, objective: 100, window: 4w}
+`}, []string{
+			"a.yml: color: unknown field",
+			"a.yml: owner: \"platform\" does not match",
+			"a.yml: paths: needs at least 1 item(s)",
+			"a.yml: schemaVersion: must be 1",
+			"a.yml: slos[0].objective: must be < 100",
+			"a.yml: slos[0].window: \"4w\" does not match",
+			"a.yml: tier: must be <= 3",
+		}},
+		{"types", map[string]string{"a.yml": `
+service: a
+owner: "@o/t"
+tier: "0"
+runtime: go
+paths: "a/**"
+`}, []string{
+			`a.yml: document: missing required field "schemaVersion"`,
+			"a.yml: paths: must be an array, got string",
+			"a.yml: tier: must be an integer, got string",
+		}},
+		{"catalog", map[string]string{
+			"a.yml": "schemaVersion: 1\nservice: a\nowner: \"@o/t\"\ntier: 1\nruntime: go\npaths: [a/**]\ndependencies: [{name: b, kind: service}, {name: b, kind: service}]\n",
+			"b.yml": "schemaVersion: 1\nservice: b\nowner: \"@o/t\"\ntier: 1\nruntime: go\npaths: [b/**]\ndependencies: [{name: ghost, kind: service}]\n",
+			"c.yml": "schemaVersion: 1\nservice: d\nowner: \"@o/t\"\ntier: 1\nruntime: go\npaths: [d/**]\n",
+		}, []string{
+			`c.yml: service "d" must be in d.yml`,
+			"a.yml: dependency service/b listed twice",
+			`b.yml: depends on unknown service "ghost"`,
+		}},
+		{"cycle", map[string]string{
+			"a.yml": "schemaVersion: 1\nservice: a\nowner: \"@o/t\"\ntier: 1\nruntime: go\npaths: [a/**]\ndependencies: [{name: b, kind: service}]\n",
+			"b.yml": "schemaVersion: 1\nservice: b\nowner: \"@o/t\"\ntier: 1\nruntime: go\npaths: [b/**]\ndependencies: [{name: c, kind: service}]\n",
+			"c.yml": "schemaVersion: 1\nservice: c\nowner: \"@o/t\"\ntier: 1\nruntime: go\npaths: [c/**]\ndependencies: [{name: a, kind: service}, {name: db, kind: datastore}]\n",
+		}, []string{"a.yml: dependency cycle a → b → c → a"}},
+	}
+	s := schema(t)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tc.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			_, err := Load(dir, s)
+			if err == nil {
+				t.Fatal("no error")
+			}
+			var le *LoadError
+			if !errors.As(err, &le) {
+				t.Fatalf("%T is not a *LoadError", err)
+			}
+			for _, f := range le.Findings {
+				if !strings.HasPrefix(f.Code, "CAT-00") {
+					t.Errorf("%s: code %q", f.Message, f.Code)
+				}
+			}
+			lines := strings.Split(err.Error(), "\n")
+			if len(lines) != len(tc.want) {
+				t.Errorf("got %d problems, want %d:\n%s", len(lines), len(tc.want), err)
+			}
+			for _, w := range tc.want {
+				found := false
+				for _, l := range lines {
+					found = found || strings.Contains(filepath.ToSlash(l), "/"+w)
+				}
+				if !found {
+					t.Errorf("missing %q in:\n%s", w, err)
+				}
+			}
+		})
+	}
+}
+
+func TestSchemaKeywords(t *testing.T) {
+	if _, err := ParseSchema([]byte(`{"type": "object", "properties": {"a": {"type": "string", "format": "email"}}}`)); err == nil ||
+		!strings.Contains(err.Error(), `#/properties/a: unsupported schema keyword "format"`) {
+		t.Errorf("unsupported keyword: %v", err)
+	}
+	if _, err := ParseSchema([]byte(`{"pattern": "("}`)); err == nil {
+		t.Error("bad pattern accepted")
+	}
+}
+
+// TestOrgCatalog loads .governance/services and checks it like
+// `brikgov catalog validate`, including ownership.
+func TestOrgCatalog(t *testing.T) {
+	c, err := Load(filepath.Join(repoRoot, DefaultDir), schema(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := pipeline.LoadMatrix(filepath.Join(repoRoot, pipeline.DefaultMatrixPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	owners, err := codeowners.Load(filepath.Join(repoRoot, "CODEOWNERS"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := Check(c, Env{Root: repoRoot, CODEOWNERS: owners, Stacks: m.StackNames()}); len(f) != 0 {
+		t.Errorf("org catalog findings: %+v", f)
+	}
+	if got := c.Dependents("identity", true); !reflect.DeepEqual(got, []string{"api", "payments", "web"}) {
+		t.Errorf("identity blast radius = %v", got)
+	}
+}
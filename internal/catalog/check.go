@@ -0,0 +1,165 @@
+package catalog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BrikByte-Studios/github-governance/internal/codeowners"
+	"github.com/BrikByte-Studios/github-governance/internal/codes"
+)
+
+// Env is what Check compares the catalog with. Nil or empty fields skip
+// their checks.
+type Env struct {
+	// Root is the repository root runbook and SLO paths are relative to.
+	Root       string
+	CODEOWNERS *codeowners.File
+	// Stacks are the runtime matrix stacks.
+	Stacks []string
+}
+
+// Finding is a cross-check result: an error fails validation, a warning
+// does not. A `brikgov:ignore` comment in the service file suppresses
+// every finding of its code for that service.
+type Finding struct {
+	Service    string `json:"service"`
+	File       string `json:"file"`
+	Code       string `json:"code"`
+	Severity   string `json:"severity"`
+	Message    string `json:"message"`
+	Suppressed string `json:"suppressed,omitempty"`
+}
+
+// Finding severities.
+const (
+	SeverityError   = codes.SeverityError
+	SeverityWarning = codes.SeverityWarning
+)
+
+// CriticalTier is the least critical tier that must have a runbook and an
+// SLO.
+const CriticalTier = 1
+
+// Check cross-checks the catalog with CODEOWNERS, the runtime matrix and
+// the repository. Findings are ordered by service.
+func Check(c *Catalog, env Env) []Finding {
+	out := []Finding{}
+	for _, s := range c.Services {
+		var fs []codes.Finding
+		add := func(code, format string, args ...any) {
+			fs = append(fs, codes.New(code, s.File, 0, format, args...))
+		}
+		if env.CODEOWNERS != nil {
+			for _, p := range s.Paths {
+				probe := probe(p)
+				rule := env.CODEOWNERS.Match(probe)
+				switch {
+				case rule == nil || len(rule.Owners) == 0:
+					add("CAT-010", "%s has no CODEOWNERS owner; add %q with %s", p, "/"+strings.TrimPrefix(p, "/"), s.Owner)
+				case rule.Pattern == "*":
+					// Whoever the catch-all names, the path has no rule of its own.
+					add("CAT-012", "%s is only covered by the CODEOWNERS catch-all rule", p)
+				case !hasOwner(rule.Owners, s.Owner):
+					add("CAT-011", "%s is owned by %s in %s:%d, not by %s", p, strings.Join(rule.Owners, " "), env.CODEOWNERS.Path, rule.Line, s.Owner)
+				}
+			}
+		}
+		if len(env.Stacks) > 0 && !contains(env.Stacks, s.Runtime) {
+			add("CAT-013", "runtime %q is not a runtime matrix stack (%s)", s.Runtime, strings.Join(env.Stacks, ", "))
+		}
+		if s.Tier <= CriticalTier {
+			if s.Runbook == "" {
+				add("CAT-014", "tier %d service needs a runbook", s.Tier)
+			}
+			if len(s.SLOs) == 0 {
+				add("CAT-015", "tier %d service needs at least one SLO", s.Tier)
+			}
+		}
+		if msg := checkRef(env.Root, s.Runbook); msg != "" {
+			add("CAT-016", "runbook %s", msg)
+		}
+		ids := map[string]bool{}
+		for _, slo := range s.SLOs {
+			if ids[slo.ID] {
+				add("CAT-017", "SLO %s defined twice", slo.ID)
+			}
+			ids[slo.ID] = true
+			if msg := checkRef(env.Root, slo.Ref); msg != "" {
+				add("CAT-016", "SLO %s ref %s", slo.ID, msg)
+			}
+		}
+		for _, d := range s.Dependencies {
+			if dep, ok := c.Service(d.Name); ok && d.Kind == KindService && d.Critical && dep.Tier > s.Tier {
+				add("CAT-018", "tier %d service depends critically on tier %d service %s", s.Tier, dep.Tier, dep.Name)
+			}
+		}
+		for _, other := range c.Services {
+			if other.Name == s.Name {
+				continue
+			}
+			for _, p := range s.Paths {
+				if other.owns(probe(p)) {
+					add("CAT-019", "%s overlaps service %s", p, other.Name)
+				}
+			}
+		}
+		fs = suppress(s.File, fs)
+		for _, f := range fs {
+			out = append(out, Finding{Service: s.Name, File: f.File, Code: f.Code, Severity: f.Severity, Message: f.Message, Suppressed: f.Suppressed})
+		}
+	}
+	return out
+}
+
+// suppress applies the suppression comments of a service file to its
+// findings and adds the comments' own problems.
+func suppress(file string, fs []codes.Finding) []codes.Finding {
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return fs
+	}
+	sup, problems := codes.ParseSuppressions(file, raw)
+	sup.Apply(fs)
+	fs = append(fs, problems...)
+	return append(fs, sup.Unused()...)
+}
+
+// probe is a path a glob certainly matches: its static prefix, plus a file
+// below it when the glob continues with a wildcard.
+func probe(glob string) string {
+	var static []string
+	for _, seg := range strings.Split(strings.Trim(glob, "/"), "/") {
+		if strings.ContainsAny(seg, "*?[") {
+			return strings.Join(append(static, "_"), "/")
+		}
+		static = append(static, seg)
+	}
+	return strings.Join(static, "/")
+}
+
+func hasOwner(owners []string, owner string) bool {
+	for _, o := range owners {
+		if strings.EqualFold(o, owner) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRef accepts https URLs and existing repository paths.
+func checkRef(root, ref string) string {
+	switch {
+	case ref == "" || strings.HasPrefix(ref, "https://"):
+		return ""
+	case strings.Contains(ref, "://"):
+		return fmt.Sprintf("%q must be an https URL or a repository path", ref)
+	case root == "":
+		return ""
+	}
+	if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(ref))); err != nil {
+		return fmt.Sprintf("%q does not exist", ref)
+	}
+	return ""
+}
@@ -0,0 +1,150 @@
+package catalog
+
+import (
+	"sort"
+	"strings"
+)
+
+// Service returns the named service.
+func (c *Catalog) Service(name string) (*Service, bool) {
+	i, ok := c.byName[name]
+	if !ok {
+		return nil, false
+	}
+	return &c.Services[i], true
+}
+
+// Query selects services; zero fields match everything.
+type Query struct {
+	// Owner matches the owning team, case-insensitively, with or without
+	// the "@org/" prefix.
+	Owner   string
+	Runtime string
+	// MaxTier keeps tiers 0..MaxTier; negative means any tier.
+	MaxTier int
+	// DependsOn keeps services with a direct dependency of this name.
+	DependsOn string
+}
+
+// Find returns the services matching q, by name.
+func (c *Catalog) Find(q Query) []Service {
+	out := []Service{}
+	for _, s := range c.Services {
+		switch {
+		case q.Owner != "" && !sameTeam(s.Owner, q.Owner):
+		case q.Runtime != "" && s.Runtime != q.Runtime:
+		case q.MaxTier >= 0 && s.Tier > q.MaxTier:
+		case q.DependsOn != "" && !s.dependsOn(q.DependsOn):
+		default:
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func (s *Service) dependsOn(name string) bool {
+	for _, d := range s.Dependencies {
+		if d.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func sameTeam(owner, q string) bool {
+	owner, q = strings.ToLower(owner), strings.ToLower(strings.TrimPrefix(q, "@"))
+	if strings.Contains(q, "/") {
+		return strings.TrimPrefix(owner, "@") == q
+	}
+	return owner[strings.LastIndex(owner, "/")+1:] == q
+}
+
+// ForPath returns the service whose paths match p (repository-relative),
+// or nil. When several do, the last service in name order wins, as the
+// last line of CODEOWNERS does; Check warns about overlapping paths.
+func (c *Catalog) ForPath(p string) *Service {
+	p = strings.TrimPrefix(p, "/")
+	var hit *Service
+	for i := range c.Services {
+		if c.Services[i].owns(p) {
+			hit = &c.Services[i]
+		}
+	}
+	return hit
+}
+
+func (s *Service) owns(p string) bool {
+	for _, re := range s.paths {
+		if re.MatchString(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// ForPaths groups changed paths by owning service; paths outside the
+// catalog are left out.
+func (c *Catalog) ForPaths(paths []string) map[string][]string {
+	out := map[string][]string{}
+	for _, p := range paths {
+		if s := c.ForPath(p); s != nil {
+			out[s.Name] = append(out[s.Name], p)
+		}
+	}
+	return out
+}
+
+// Dependencies returns the services name depends on, directly or not,
+// sorted. With criticalOnly, only chains of critical dependencies count.
+func (c *Catalog) Dependencies(name string, criticalOnly bool) []string {
+	return c.walk(name, func(n string) []string {
+		s, ok := c.Service(n)
+		if !ok {
+			return nil
+		}
+		var next []string
+		for _, d := range s.Dependencies {
+			if d.Kind == KindService && (d.Critical || !criticalOnly) {
+				next = append(next, d.Name)
+			}
+		}
+		return next
+	})
+}
+
+// Dependents returns the services that depend on name, directly or not,
+// sorted: the blast radius of an outage of name. name may also be a
+// datastore or an external dependency.
+func (c *Catalog) Dependents(name string, criticalOnly bool) []string {
+	return c.walk(name, func(n string) []string {
+		var next []string
+		for _, s := range c.Services {
+			for _, d := range s.Dependencies {
+				// Only the outage itself may be a datastore or external.
+				if d.Name == n && (d.Kind == KindService || n == name) && (d.Critical || !criticalOnly) {
+					next = append(next, s.Name)
+				}
+			}
+		}
+		return next
+	})
+}
+
+// walk collects the closure of start under next, without start.
+func (c *Catalog) walk(start string, next func(string) []string) []string {
+	queue := next(start)
+	seen := map[string]bool{start: true}
+	out := []string{}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		if seen[n] {
+			continue
+		}
+		seen[n] = true
+		out = append(out, n)
+		queue = append(queue, next(n)...)
+	}
+	sort.Strings(out)
+	return out
+}
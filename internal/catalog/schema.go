@@ -0,0 +1,289 @@
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultSchemaPath is the service schema, relative to the repository root.
+const DefaultSchemaPath = "schemas/service.schema.json"
+
+// Schema is a JSON Schema restricted to the keywords the service schema
+// uses. Loading a schema with any other keyword fails, so the schema
+// cannot promise a check this validator does not make.
+type Schema struct {
+	Type                 string             `json:"type"`
+	Const                any                `json:"const"`
+	Enum                 []any              `json:"enum"`
+	Pattern              string             `json:"pattern"`
+	MinLength            *int               `json:"minLength"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+	ExclusiveMinimum     *float64           `json:"exclusiveMinimum"`
+	ExclusiveMaximum     *float64           `json:"exclusiveMaximum"`
+	Required             []string           `json:"required"`
+	Properties           map[string]*Schema `json:"properties"`
+	AdditionalProperties *bool              `json:"additionalProperties"`
+	Items                *Schema            `json:"items"`
+	MinItems             *int               `json:"minItems"`
+	UniqueItems          bool               `json:"uniqueItems"`
+
+	re *regexp.Regexp
+}
+
+// annotations are keywords without validation meaning.
+var annotations = map[string]bool{"$schema": true, "$id": true, "title": true, "description": true}
+
+// LoadSchema reads a JSON Schema file.
+func LoadSchema(path string) (*Schema, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s, err := ParseSchema(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// ParseSchema parses a JSON Schema document.
+func ParseSchema(raw []byte) (*Schema, error) {
+	var generic any
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		return nil, err
+	}
+	if err := checkKeywords(generic, "#"); err != nil {
+		return nil, err
+	}
+	var s Schema
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, err
+	}
+	if err := s.compile("#"); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func checkKeywords(v any, at string) error {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return fmt.Errorf("%s: schema must be an object", at)
+	}
+	known := map[string]bool{}
+	t := reflect.TypeOf(Schema{})
+	for i := 0; i < t.NumField(); i++ {
+		if tag := t.Field(i).Tag.Get("json"); tag != "" {
+			known[tag] = true
+		}
+	}
+	for _, k := range sortedKeys(m) {
+		if !known[k] && !annotations[k] {
+			return fmt.Errorf("%s: unsupported schema keyword %q", at, k)
+		}
+	}
+	if props, ok := m["properties"].(map[string]any); ok {
+		for _, k := range sortedKeys(props) {
+			if err := checkKeywords(props[k], at+"/properties/"+k); err != nil {
+				return err
+			}
+		}
+	}
+	if items, ok := m["items"]; ok {
+		return checkKeywords(items, at+"/items")
+	}
+	return nil
+}
+
+func (s *Schema) compile(at string) error {
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("%s: pattern: %w", at, err)
+		}
+		s.re = re
+	}
+	for _, k := range sortedKeys(s.Properties) {
+		if err := s.Properties[k].compile(at + "/properties/" + k); err != nil {
+			return err
+		}
+	}
+	if s.Items != nil {
+		return s.Items.compile(at + "/items")
+	}
+	return nil
+}
+
+// Validate checks a decoded YAML or JSON document and returns one message
+// per violation, prefixed with its location ("slos[0].objective").
+func (s *Schema) Validate(v any) []string {
+	var errs []string
+	s.validate(normalize(v), "", &errs)
+	return errs
+}
+
+func (s *Schema) validate(v any, at string, errs *[]string) {
+	fail := func(format string, args ...any) {
+		loc := at
+		if loc == "" {
+			loc = "document"
+		}
+		*errs = append(*errs, loc+": "+fmt.Sprintf(format, args...))
+	}
+	if s.Type != "" && !hasType(v, s.Type) {
+		fail("must be %s, got %s", article(s.Type), typeOf(v))
+		return
+	}
+	if s.Const != nil && !reflect.DeepEqual(normalize(s.Const), v) {
+		fail("must be %v", s.Const)
+	}
+	if len(s.Enum) > 0 {
+		found := false
+		var names []string
+		for _, e := range s.Enum {
+			found = found || reflect.DeepEqual(normalize(e), v)
+			names = append(names, fmt.Sprint(e))
+		}
+		if !found {
+			fail("must be one of %s", strings.Join(names, ", "))
+		}
+	}
+	switch x := v.(type) {
+	case string:
+		if s.MinLength != nil && len([]rune(x)) < *s.MinLength {
+			fail("must not be shorter than %d", *s.MinLength)
+		}
+		if s.re != nil && !s.re.MatchString(x) {
+			fail("%q does not match %s", x, s.Pattern)
+		}
+	case float64:
+		switch {
+		case s.Minimum != nil && x < *s.Minimum:
+			fail("must be >= %v", *s.Minimum)
+		case s.Maximum != nil && x > *s.Maximum:
+			fail("must be <= %v", *s.Maximum)
+		case s.ExclusiveMinimum != nil && x <= *s.ExclusiveMinimum:
+			fail("must be > %v", *s.ExclusiveMinimum)
+		case s.ExclusiveMaximum != nil && x >= *s.ExclusiveMaximum:
+			fail("must be < %v", *s.ExclusiveMaximum)
+		}
+	case []any:
+		if s.MinItems != nil && len(x) < *s.MinItems {
+			fail("needs at least %d item(s)", *s.MinItems)
+		}
+		for i, item := range x {
+			if s.UniqueItems {
+				for j := 0; j < i; j++ {
+					if reflect.DeepEqual(x[j], item) {
+						fail("item %d repeats item %d", i, j)
+					}
+				}
+			}
+			if s.Items != nil {
+				s.Items.validate(item, fmt.Sprintf("%s[%d]", at, i), errs)
+			}
+		}
+	case map[string]any:
+		for _, k := range s.Required {
+			if _, ok := x[k]; !ok {
+				fail("missing required field %q", k)
+			}
+		}
+		for _, k := range sortedKeys(x) {
+			child := k
+			if at != "" {
+				child = at + "." + k
+			}
+			if p, ok := s.Properties[k]; ok {
+				p.validate(x[k], child, errs)
+			} else if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				*errs = append(*errs, child+": unknown field")
+			}
+		}
+	}
+}
+
+// normalize turns a decoded YAML or JSON value into JSON's data model:
+// numbers are float64 and maps have string keys.
+func normalize(v any) any {
+	switch x := v.(type) {
+	case int:
+		return float64(x)
+	case int64:
+		return float64(x)
+	case uint64:
+		return float64(x)
+	case map[string]any:
+		out := make(map[string]any, len(x))
+		for k, e := range x {
+			out[k] = normalize(e)
+		}
+		return out
+	case map[any]any:
+		out := make(map[string]any, len(x))
+		for k, e := range x {
+			out[fmt.Sprint(k)] = normalize(e)
+		}
+		return out
+	case []any:
+		out := make([]any, len(x))
+		for i, e := range x {
+			out[i] = normalize(e)
+		}
+		return out
+	}
+	return v
+}
+
+func hasType(v any, t string) bool {
+	switch t {
+	case "integer":
+		f, ok := v.(float64)
+		return ok && f == math.Trunc(f)
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	}
+	return typeOf(v) == t
+}
+
+func typeOf(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func article(t string) string {
+	if strings.ContainsRune("aeiou", rune(t[0])) {
+		return "an " + t
+	}
+	return "a " + t
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
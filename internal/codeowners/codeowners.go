@@ -0,0 +1,165 @@
+// Package codeowners parses a CODEOWNERS file and resolves the owners of a
+// path with GitHub's semantics: gitignore-style patterns, and the last
+// matching line wins.
+package codeowners
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Rule is one CODEOWNERS line.
+type Rule struct {
+	Pattern string   `json:"pattern"`
+	Owners  []string `json:"owners"`
+	Line    int      `json:"line"`
+	re      *regexp.Regexp
+}
+
+// File is a parsed CODEOWNERS file.
+type File struct {
+	Path  string
+	Rules []Rule
+}
+
+// Candidates lists where GitHub looks for CODEOWNERS, in order.
+var Candidates = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// Load parses the CODEOWNERS file at path.
+func Load(path string) (*File, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := Parse(string(raw))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	f.Path = path
+	return f, nil
+}
+
+// Parse parses CODEOWNERS content. Lines without owners are kept: they
+// unset ownership for the paths they match.
+func Parse(content string) (*File, error) {
+	f := &File{}
+	sc := bufio.NewScanner(strings.NewReader(content))
+	n := 0
+	for sc.Scan() {
+		n++
+		line := sc.Text()
+		if i := strings.Index(line, "#"); i >= 0 && (i == 0 || line[i-1] != '\\') {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		re, err := Compile(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		f.Rules = append(f.Rules, Rule{Pattern: fields[0], Owners: fields[1:], Line: n, re: re})
+	}
+	return f, sc.Err()
+}
+
+// Match returns the rule that owns p (repository-relative, "/" separated),
+// or nil when no rule matches.
+func (f *File) Match(p string) *Rule {
+	p = strings.TrimPrefix(p, "/")
+	for i := len(f.Rules) - 1; i >= 0; i-- {
+		if f.Rules[i].re.MatchString(p) {
+			return &f.Rules[i]
+		}
+	}
+	return nil
+}
+
+// Owners returns the owners of p.
+func (f *File) Owners(p string) []string {
+	if r := f.Match(p); r != nil {
+		return r.Owners
+	}
+	return nil
+}
+
+// Teams returns the team owners (@org/team) of p.
+func (f *File) Teams(p string) []string {
+	var out []string
+	for _, o := range f.Owners(p) {
+		if IsTeam(o) {
+			out = append(out, o)
+		}
+	}
+	return out
+}
+
+// IsTeam reports whether owner is a team (@org/team) rather than a user or
+// an email.
+func IsTeam(owner string) bool {
+	return strings.HasPrefix(owner, "@") && strings.Contains(owner, "/")
+}
+
+// TeamSlug returns the team part of "@org/team" (lower case); other owners
+// are returned lower case without "@".
+func TeamSlug(owner string) string {
+	owner = strings.ToLower(strings.TrimPrefix(owner, "@"))
+	if i := strings.LastIndex(owner, "/"); i >= 0 {
+		return owner[i+1:]
+	}
+	return owner
+}
+
+// Compile turns a gitignore-style pattern (the syntax CODEOWNERS shares
+// with .gitignore) into a regexp over repository-relative paths. A pattern
+// also matches everything below a directory it matches.
+func Compile(pat string) (*regexp.Regexp, error) {
+	dirOnly := strings.HasSuffix(pat, "/")
+	trimmed := strings.Trim(pat, "/")
+	anchored := strings.HasPrefix(pat, "/") || strings.Contains(trimmed, "/")
+	if trimmed == "" {
+		return nil, fmt.Errorf("empty pattern %q", pat)
+	}
+
+	var b strings.Builder
+	if anchored {
+		b.WriteString("^")
+	} else {
+		b.WriteString("^(?:.*/)?")
+	}
+	segs := strings.Split(trimmed, "/")
+	for i, seg := range segs {
+		last := i == len(segs)-1
+		if seg == "**" {
+			if last {
+				b.WriteString(".*")
+			} else {
+				b.WriteString("(?:.*/)?")
+			}
+			continue
+		}
+		for _, r := range seg {
+			switch r {
+			case '*':
+				b.WriteString("[^/]*")
+			case '?':
+				b.WriteString("[^/]")
+			default:
+				b.WriteString(regexp.QuoteMeta(string(r)))
+			}
+		}
+		if !last {
+			b.WriteString("/")
+		}
+	}
+	if dirOnly {
+		b.WriteString("/.*$")
+	} else {
+		b.WriteString("(?:/.*)?$")
+	}
+	return regexp.Compile(b.String())
+}
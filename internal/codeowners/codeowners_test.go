@@ -0,0 +1,74 @@
+package codeowners
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRepoCodeowners(t *testing.T) {
+	f, err := Load(filepath.Join("..", "..", "CODEOWNERS"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Rules) < 10 {
+		t.Fatalf("parsed %d rules", len(f.Rules))
+	}
+	// GitHub applies the last matching line: the leading catch-all owns
+	// only paths no later rule matches.
+	first := f.Rules[0]
+	if r := f.Match("cmd/brikgov/main.go"); r == nil || r.Line != first.Line || first.Pattern != "*" {
+		t.Fatalf("Match = %+v, want the catch-all on line %d", r, first.Line)
+	}
+	if r := f.Match("services/payments/ledger.go"); r == nil || r.Pattern != "/services/payments/**" {
+		t.Fatalf("Match = %+v, want /services/payments/**", r)
+	}
+	if got := f.Teams("cmd/brikgov/main.go"); len(got) != 2 || got[0] != "@BrikByte-Studios/platform-leads" {
+		t.Fatalf("Teams = %v", got)
+	}
+}
+
+func TestPatterns(t *testing.T) {
+	f, err := Parse(`
+*.js        @org/web
+/build/     @org/build
+docs/       @org/docs      # any docs directory
+**/logs     @org/ops
+/apps/*/cfg @org/cfg
+/vendor/**  @org/vendor
+/vendor/ok  # no owners: unsets ownership
+a?c.txt     user@example.com
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := map[string]string{
+		"src/app.js":          "@org/web",
+		"build/out/app.js":    "@org/build",
+		"build":               "",
+		"x/build/a":           "",
+		"pkg/docs/readme.md":  "@org/docs",
+		"deep/a/logs/today":   "@org/ops",
+		"logs":                "@org/ops",
+		"apps/web/cfg/x.yml":  "@org/cfg",
+		"apps/web/sub/cfg":    "",
+		"vendor/lib/x.go":     "@org/vendor",
+		"vendor/ok/file.go":   "",
+		"abc.txt":             "user@example.com",
+		"dir/abbc.txt":        "",
+		"unowned/file.go":     "",
+		"/src/leading.js":     "@org/web",
+		"pkg/docs_extra/file": "",
+	}
+	for p, want := range cases {
+		if got := strings.Join(f.Owners(p), " "); got != want {
+			t.Errorf("Owners(%q) = %q, want %q", p, got, want)
+		}
+	}
+	if !IsTeam("@org/web") || IsTeam("@alice") || IsTeam("a@b.c") {
+		t.Error("IsTeam")
+	}
+	if TeamSlug("@BrikByte-Studios/DevOps") != "devops" {
+		t.Error("TeamSlug")
+	}
+}
@@ -0,0 +1,392 @@
+package codes
+
+const (
+	teamDevOps        = "@BrikByte-Studios/devops"
+	teamPlatformLeads = "@BrikByte-Studios/platform-leads"
+	teamQA            = "@BrikByte-Studios/qa-automation"
+	teamSecurity      = "@BrikByte-Studios/security"
+)
+
+// catalog is the source of docs/governance/error-codes.md. Append new
+// codes; never renumber or reuse one.
+var catalog = []Entry{
+	// Policy files: brikgov policy validate / merge.
+	{
+		Code: "POL-001", Title: "version missing or invalid", Check: "brikgov policy validate",
+		Owner: teamPlatformLeads, Severity: SeverityError,
+		Explanation: "The org policy must declare `version`, an integer of at least 1, so tools can refuse a format they do not understand.",
+		Fix:         "Add `version: 1` at the top of the policy.",
+	},
+	{
+		Code: "POL-002", Title: "unknown top-level field", Check: "brikgov policy validate",
+		Owner: teamPlatformLeads, Severity: SeverityError,
+		Explanation: "Only the sections listed in docs/governance/policy-inheritance.md are read. An unknown key is usually a typo and would be ignored silently.",
+		Fix:         "Fix the key's spelling, or move the setting under the section that reads it.",
+	},
+	{
+		Code: "POL-010", Title: "unknown release.semver field", Check: "brikgov policy validate",
+		Owner: teamPlatformLeads, Severity: SeverityError,
+		Explanation: "`release.semver` (and its `prerelease` and `guardrails` blocks) accept a fixed set of fields in v1.",
+		Fix:         "Remove the field or fix its spelling.",
+	},
+	{
+		Code: "POL-011", Title: "release.semver field missing", Check: "brikgov policy validate",
+		Owner: teamPlatformLeads, Severity: SeverityError,
+		Explanation: "The org policy must set every `release.semver` field, including the `prerelease.enabled` flag and all three guardrails, so repositories inherit a complete release policy.",
+		Fix:         "Add the missing field; .github/policy.yml has the defaults with comments.",
+	},
+	{
+		Code: "POL-012", Title: "tag_pattern is not strict vX.Y.Z", Check: "brikgov policy validate",
+		Owner: teamPlatformLeads, Severity: SeverityError,
+		Explanation: "In v1 release tags must be exactly `vMAJOR.MINOR.PATCH`; the resolver, the tag workflow and policy pins rely on it.",
+		Fix:         "Set `tag_pattern: \"^v\\\\d+\\\\.\\\\d+\\\\.\\\\d+$\"`.",
+	},
+	{
+		Code: "POL-013", Title: "source_of_truth is not git-tags", Check: "brikgov policy validate",
+		Owner: teamPlatformLeads, Severity: SeverityError,
+		Explanation: "Git tags are the only version source in v1, which avoids drift between version files and tags.",
+		Fix:         "Set `source_of_truth: \"git-tags\"`.",
+	},
+	{
+		Code: "POL-014", Title: "allowed_branches is empty", Check: "brikgov policy validate",
+		Owner: teamPlatformLeads, Severity: SeverityError,
+		Explanation: "With SemVer enabled, releases may only be tagged on the listed branches; an empty list blocks every release.",
+		Fix:         "List at least one branch or pattern, e.g. `main` and `release/*`.",
+	},
+	{
+		Code: "POL-015", Title: "initial_version is not vX.Y.Z", Check: "brikgov policy validate",
+		Owner: teamPlatformLeads, Severity: SeverityError,
+		Explanation: "The resolver bumps from `initial_version` when a repository has no SemVer tag yet.",
+		Fix:         "Use a version such as `v0.1.0`.",
+	},
+	{
+		Code: "POL-016", Title: "value not allowed", Check: "brikgov policy validate",
+		Owner: teamPlatformLeads, Severity: SeverityError,
+		Explanation: "`enforcement_mode` (warn, block), `idempotency` (fail, noop) and `tag_type` (annotated, lightweight) take one of a fixed set of values.",
+		Fix:         "Use one of the values listed in the message.",
+	},
+	{
+		Code: "POL-017", Title: "wrong type", Check: "brikgov policy validate",
+		Owner: teamPlatformLeads, Severity: SeverityError,
+		Explanation: "A `release.semver` field has the wrong YAML type, e.g. a quoted \"true\" where a boolean is expected or an empty `tag_prefix`.",
+		Fix:         "Use the type named in the message; remove the quotes around booleans.",
+	},
+	{
+		Code: "POL-030", Title: "layer relaxes an inherited setting", Check: "brikgov policy merge / verify / validate",
+		Owner: teamPlatformLeads, Severity: SeverityError,
+		Explanation: "Team layers and repo overlays may only tighten what they inherit (docs/governance/policy-inheritance.md). The message names the layer, the setting, the inherited and the new value.",
+		Fix:         "Keep the inherited value or make it stricter; a looser value needs a change to the parent layer, reviewed by its owners.",
+	},
+
+	// Architecture decision records: brikgov adr lint.
+	{
+		Code: "ADR-001", Title: "no front matter", Check: "brikgov adr lint",
+		Owner: teamPlatformLeads, Severity: SeverityError,
+		Explanation: "An ADR starts with YAML front matter between `---` lines; the index and the gate read it.",
+		Fix:         "Start the file from docs/adr/template.md.",
+	},
+	{
+		Code: "ADR-002", Title: "front matter is not valid YAML", Check: "brikgov adr lint",
+		Owner: teamPlatformLeads, Severity: SeverityError,
+		Explanation: "The front matter is not closed by a second `---` line, or does not parse as a YAML mapping.",
+		Fix:         "Close the front matter and fix the YAML error in the message.",
+	},
+	{
+		Code: "ADR-003", Title: "required field missing", Check: "brikgov adr lint",
+		Owner: teamPlatformLeads, Severity: SeverityError,
+		Explanation: "`id`, `seq`, `title`, `status`, `date`, `authors` and `area` are required (docs/adr/adr.schema.json).",
+		Fix:         "Add the field.",
+	},
+	{
+		Code: "ADR-004", Title: "duplicate seq", Check: "brikgov adr lint",
+		Owner: teamPlatformLeads, Severity: SeverityError,
+		Explanation: "Two ADRs share a sequence number, usually after two branches each took the next free one.",
+		Fix:         "Renumber the later ADR to the next free seq and rename its file to match.",
+	},
+	{
+		Code: "ADR-005", Title: "duplicate id", Check: "brikgov adr lint",
+		Owner: teamPlatformLeads, Severity: SeverityError,
+		Explanation: "ADR ids are referenced from PRs, `supersedes` lists and the gate, so each must be unique.",
+		Fix:         "Give the later ADR the id matching its seq (`ADR-00NN`).",
+	},
+	{
+		Code: "ADR-006", Title: "filename prefix does not match seq", Check: "brikgov adr lint",
+		Owner: teamPlatformLeads, Severity: SeverityError,
+		Explanation: "ADR files are named `NNN-title.md` with NNN equal to `seq`, so the directory listing is in decision order.",
+		Fix:         "Rename the file or correct `seq`.",
+	},
+	{
+		Code: "ADR-007", Title: "invalid field value", Check: "brikgov adr lint",
+		Owner: teamPlatformLeads, Severity: SeverityError,
+		Explanation: "A front matter field breaks the ADR schema: an id not `ADR-NNNN`, a seq below 1, an unknown status, a date not `YYYY-MM-DD`, or an empty list.",
+		Fix:         "Correct the value as the message describes.",
+	},
+
+	// Gate rules: brikgov gate. The rule's policy severity applies.
+	{
+		Code: "GATE-001", Title: "tests.green", Check: "brikgov gate",
+		Owner: teamQA, Severity: SeverityPolicy,
+		Explanation: "The test run is not green or reports failed tests.",
+		Fix:         "Fix the failing tests; quarantine a flaky test only with a waiver that has an owner and a TTL.",
+	},
+	{
+		Code: "GATE-002", Title: "coverage.min", Check: "brikgov gate",
+		Owner: teamQA, Severity: SeverityPolicy,
+		Explanation: "Line coverage is below the rule's `threshold`.",
+		Fix:         "Add tests for the changed code.",
+	},
+	{
+		Code: "GATE-003", Title: "security.sca", Check: "brikgov gate",
+		Owner: teamSecurity, Severity: SeverityPolicy,
+		Explanation: "Dependency scanning found vulnerabilities at or above the rule's `max_level`.",
+		Fix:         "Upgrade the affected dependencies; the report URL in the evidence lists them.",
+	},
+	{
+		Code: "GATE-004", Title: "security.sast", Check: "brikgov gate",
+		Owner: teamSecurity, Severity: SeverityPolicy,
+		Explanation: "Static analysis found issues at or above the rule's `max_level`.",
+		Fix:         "Fix the reported code; a false positive is waived with the scanner's own suppression.",
+	},
+	{
+		Code: "GATE-005", Title: "adr.required_for_infra", Check: "brikgov gate",
+		Owner: teamPlatformLeads, Severity: SeverityPolicy,
+		Explanation: "An infrastructure change needs an ADR reference and none was found.",
+		Fix:         "Reference the ADR (`ADR-NNNN`) in the PR, or write one from docs/adr/template.md.",
+	},
+	{
+		Code: "GATE-006", Title: "supplychain.signed", Check: "brikgov gate",
+		Owner: teamSecurity, Severity: SeverityPolicy,
+		Explanation: "Build artifacts are not signed.",
+		Fix:         "Run the signing step of the publish workflow before the gate.",
+	},
+	{
+		Code: "GATE-007", Title: "integrity.sbom", Check: "brikgov gate",
+		Owner: teamSecurity, Severity: SeverityPolicy,
+		Explanation: "No SBOM was produced for the build.",
+		Fix:         "Enable SBOM generation in the build pipeline.",
+	},
+	{
+		Code: "GATE-008", Title: "commits.signed", Check: "brikgov gate",
+		Owner: teamSecurity, Severity: SeverityPolicy,
+		Explanation: "A commit in the range is unsigned or signed by a key that is not in the allowed signers (docs/governance/commit-signatures.md).",
+		Fix:         "Re-sign the commits, or register the key in .governance/signing/allowed-signers.yml.",
+	},
+	{
+		Code: "GATE-009", Title: "repo.hygiene", Check: "brikgov gate",
+		Owner: teamDevOps, Severity: SeverityPolicy,
+		Explanation: "The change adds a forbidden file, an OS artifact, a large file or a binary outside the allowed paths (docs/governance/repo-hygiene.md).",
+		Fix:         "Remove the file from the change, or track it with Git LFS; the evidence lists both commands.",
+	},
+	{
+		Code: "GATE-010", Title: "deps.lockfile", Check: "brikgov gate",
+		Owner: teamSecurity, Severity: SeverityPolicy,
+		Explanation: "A lockfile is missing, out of sync with its manifest, or resolves from a registry that is not allowed (docs/governance/lockfile-verification.md).",
+		Fix:         "Regenerate the lockfile with the package manager and commit it.",
+	},
+	{
+		Code: "GATE-011", Title: "deps.review", Check: "brikgov gate",
+		Owner: teamSecurity, Severity: SeverityPolicy,
+		Explanation: "The change brings in a banned, too new, possibly typosquatted or too many new direct dependencies (docs/governance/dependency-review.md).",
+		Fix:         "Drop or replace the dependency named in the evidence, or get a waiver from security.",
+	},
+	{
+		Code: "GATE-012", Title: "security.review", Check: "brikgov gate",
+		Owner: teamSecurity, Severity: SeverityPolicy,
+		Explanation: "The PR touches a sensitive path, carries `security:review-needed`, or its security answers mention authentication, authorization or secrets, and it lacks a security team approval or a completed security checklist (docs/governance/security-review.md).",
+		Fix:         "Request a review from @BrikByte-Studios/security and tick every item of the PR template's security checklist.",
+	},
+	{
+		Code: "GATE-090", Title: "rule could not be evaluated", Check: "brikgov gate",
+		Owner: teamDevOps, Severity: SeverityPolicy,
+		Explanation: "The rule timed out, panicked, had invalid options or was cancelled (docs/governance/gate-evaluation.md). It counts as failing.",
+		Fix:         "Re-run the job; if it keeps timing out, raise the rule's `timeout_seconds` or fix the evaluator.",
+	},
+	{
+		Code: "GATE-091", Title: "no evaluator for rule", Check: "brikgov gate",
+		Owner: teamDevOps, Severity: SeverityPolicy,
+		Explanation: "The policy configures a rule id that the gate does not implement, usually a typo.",
+		Fix:         "Fix the rule id in the policy's `rules:` section.",
+	},
+	{
+		Code: "GATE-092", Title: "required evidence missing", Check: "brikgov gate",
+		Owner: teamDevOps, Severity: SeverityPolicy,
+		Explanation: "The rule sets `requires_evidence` and the gate inputs do not contain what it reads.",
+		Fix:         "Make sure the step producing the evidence ran and its output reached the gate inputs.",
+	},
+
+	// Service catalog: brikgov catalog validate.
+	{
+		Code: "CAT-001", Title: "service file invalid", Check: "brikgov catalog validate",
+		Owner: teamDevOps, Severity: SeverityError,
+		Explanation: "The service file does not parse or does not match schemas/service.schema.json.",
+		Fix:         "Correct the field named in the message.",
+	},
+	{
+		Code: "CAT-002", Title: "service name does not match file name", Check: "brikgov catalog validate",
+		Owner: teamDevOps, Severity: SeverityError,
+		Explanation: "Each service lives in `<service>.yml`, so it can be found without reading every file.",
+		Fix:         "Rename the file or the service.",
+	},
+	{
+		Code: "CAT-003", Title: "service defined twice", Check: "brikgov catalog validate",
+		Owner: teamDevOps, Severity: SeverityError,
+		Explanation: "Two files declare the same service name.",
+		Fix:         "Merge the files or rename one service.",
+	},
+	{
+		Code: "CAT-004", Title: "dependency listed twice", Check: "brikgov catalog validate",
+		Owner: teamDevOps, Severity: SeverityError,
+		Explanation: "The same dependency appears twice, possibly with different `critical` flags.",
+		Fix:         "Keep one entry.",
+	},
+	{
+		Code: "CAT-005", Title: "unknown service dependency", Check: "brikgov catalog validate",
+		Owner: teamDevOps, Severity: SeverityError,
+		Explanation: "A dependency of kind `service` names no catalog service, so blast-radius queries would miss it.",
+		Fix:         "Add the service to the catalog or change the dependency's kind.",
+	},
+	{
+		Code: "CAT-006", Title: "dependency cycle", Check: "brikgov catalog validate",
+		Owner: teamDevOps, Severity: SeverityError,
+		Explanation: "Services depend on each other in a loop, so none of them can start or recover first.",
+		Fix:         "Break the cycle, for example by making one side asynchronous and non-critical.",
+	},
+	{
+		Code: "CAT-010", Title: "path has no CODEOWNERS owner", Check: "brikgov catalog validate",
+		Owner: teamDevOps, Severity: SeverityError,
+		Explanation: "No CODEOWNERS rule covers a path of the service, so its changes need no review from its team.",
+		Fix:         "Add the CODEOWNERS line suggested in the message.",
+	},
+	{
+		Code: "CAT-011", Title: "path owned by another team", Check: "brikgov catalog validate",
+		Owner: teamDevOps, Severity: SeverityError,
+		Explanation: "The CODEOWNERS rule that owns the path (the last match) does not list the service's owner.",
+		Fix:         "Add the owner to that rule or a more specific rule after it, or correct the service's `owner`.",
+	},
+	{
+		Code: "CAT-012", Title: "path owned only by the catch-all", Check: "brikgov catalog validate",
+		Owner: teamDevOps, Severity: SeverityWarning,
+		Explanation: "Only the `*` rule covers the path, so reviews are routed to the default owners.",
+		Fix:         "Add a specific CODEOWNERS rule for the service's paths.",
+	},
+	{
+		Code: "CAT-013", Title: "runtime not in the runtime matrix", Check: "brikgov catalog validate",
+		Owner: teamDevOps, Severity: SeverityError,
+		Explanation: "The service's `runtime` is not a stack of docs/pipelines/runtime-matrix.yml, so no pipeline builds it.",
+		Fix:         "Use a matrix stack name, or add the stack to the matrix.",
+	},
+	{
+		Code: "CAT-014", Title: "critical service has no runbook", Check: "brikgov catalog validate",
+		Owner: teamDevOps, Severity: SeverityError,
+		Explanation: "Tier 0 and 1 services need a runbook for incident response.",
+		Fix:         "Set `runbook` to an https URL or a repository path.",
+	},
+	{
+		Code: "CAT-015", Title: "critical service has no SLO", Check: "brikgov catalog validate",
+		Owner: teamDevOps, Severity: SeverityError,
+		Explanation: "Tier 0 and 1 services need at least one SLO for reporting and alerting.",
+		Fix:         "Add an entry under `slos`.",
+	},
+	{
+		Code: "CAT-016", Title: "runbook or SLO reference invalid", Check: "brikgov catalog validate",
+		Owner: teamDevOps, Severity: SeverityError,
+		Explanation: "References must be https URLs or paths that exist in the repository.",
+		Fix:         "Fix the URL scheme or the path.",
+	},
+	{
+		Code: "CAT-017", Title: "SLO defined twice", Check: "brikgov catalog validate",
+		Owner: teamDevOps, Severity: SeverityError,
+		Explanation: "SLO ids must be unique within a service.",
+		Fix:         "Rename or remove one SLO.",
+	},
+	{
+		Code: "CAT-018", Title: "critical dependency on a less critical service", Check: "brikgov catalog validate",
+		Owner: teamDevOps, Severity: SeverityWarning,
+		Explanation: "A service depends critically on a service of a higher (less critical) tier, which caps its real availability.",
+		Fix:         "Raise the dependency's tier or make the dependency non-critical.",
+	},
+	{
+		Code: "CAT-019", Title: "paths overlap another service", Check: "brikgov catalog validate",
+		Owner: teamDevOps, Severity: SeverityWarning,
+		Explanation: "A path glob also matches another service, so changed files are attributed to the last service in name order.",
+		Fix:         "Narrow the globs, or suppress the finding where sharing is intended.",
+	},
+
+	// Separation of duties: brikgov sod.
+	{
+		Code: "SOD-001", Title: "self-approved pull request", Check: "brikgov sod",
+		Owner: teamSecurity, Severity: SeverityError,
+		Explanation: "A PR in the release was approved only by one of its authors.",
+		Fix:         "Have an independent reviewer approve a follow-up PR, or record an approved exception.",
+	},
+	{
+		Code: "SOD-002", Title: "releaser shipped unreviewed commits", Check: "brikgov sod",
+		Owner: teamSecurity, Severity: SeverityError,
+		Explanation: "The person publishing the release authored commits in it that no one else reviewed.",
+		Fix:         "Let another maintainer publish the release, or get the commits reviewed first.",
+	},
+	{
+		Code: "SOD-003", Title: "approvers not independent of the author's role", Check: "brikgov sod",
+		Owner: teamSecurity, Severity: SeverityError,
+		Explanation: "A branch requires approvals from a role the author belongs to, and only members of the author's own team approved.",
+		Fix:         "Request an approval from a role member outside the authoring team.",
+	},
+
+	// Bypasses: brikgov bypass analyze.
+	{
+		Code: "BYP-001", Title: "force push to a protected branch", Check: "brikgov bypass analyze",
+		Owner: teamSecurity, Severity: SeverityError,
+		Explanation: "History of a protected branch was rewritten without a break-glass grant or an approved decision (docs/governance/bypass-analysis.md).",
+		Fix:         "Record a decision for the event with `brikgov bypass decide`, or restore the branch.",
+	},
+	{
+		Code: "BYP-002", Title: "merge over failing required checks", Check: "brikgov bypass analyze",
+		Owner: teamSecurity, Severity: SeverityError,
+		Explanation: "A PR was merged while required checks failed, without a grant or decision.",
+		Fix:         "Record a decision, and fix or revert the change.",
+	},
+	{
+		Code: "BYP-003", Title: "policy override", Check: "brikgov bypass analyze",
+		Owner: teamSecurity, Severity: SeverityError,
+		Explanation: "An administrator overrode a branch or ruleset policy without a grant or decision.",
+		Fix:         "Record a decision for the override.",
+	},
+	{
+		Code: "BYP-004", Title: "protection or ruleset edited", Check: "brikgov bypass analyze",
+		Owner: teamSecurity, Severity: SeverityError,
+		Explanation: "Branch protection or a ruleset was changed outside a reviewed governance change.",
+		Fix:         "Restore the protection, or record the decision that allowed the edit.",
+	},
+	{
+		Code: "BYP-005", Title: "protected tag deleted", Check: "brikgov bypass analyze",
+		Owner: teamSecurity, Severity: SeverityError,
+		Explanation: "A release tag was deleted; consumers pinned to it break and provenance is lost.",
+		Fix:         "Recreate the tag at its original commit and record a decision.",
+	},
+	{
+		Code: "BYP-006", Title: "protected tag moved", Check: "brikgov bypass analyze",
+		Owner: teamSecurity, Severity: SeverityError,
+		Explanation: "A release tag now points at a different commit, so the same version names different code.",
+		Fix:         "Move the tag back and release the change under a new version.",
+	},
+
+	// Suppression comments.
+	{
+		Code: "SUP-001", Title: "suppression without a reason", Check: "brikgov policy validate / adr lint / catalog validate",
+		Owner: teamPlatformLeads, Severity: SeverityError,
+		Explanation: "`brikgov:ignore` comments must say why the finding is acceptable, after `--`. Without a reason the comment suppresses nothing.",
+		Fix:         "Append `-- <reason>` to the comment.",
+	},
+	{
+		Code: "SUP-002", Title: "suppression of an unknown code", Check: "brikgov policy validate / adr lint / catalog validate",
+		Owner: teamPlatformLeads, Severity: SeverityError,
+		Explanation: "The comment names no code, or one that is not in this catalog.",
+		Fix:         "Use the code shown in the finding's annotation title.",
+	},
+	{
+		Code: "SUP-003", Title: "unused suppression", Check: "brikgov policy validate / adr lint / catalog validate",
+		Owner: teamPlatformLeads, Severity: SeverityWarning,
+		Explanation: "The comment matches no finding, so it would silently hide a future one.",
+		Fix:         "Remove the comment.",
+	},
+}
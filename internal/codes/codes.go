@@ -0,0 +1,173 @@
+// Package codes is the catalog of stable error codes emitted by the Go
+// governance checks. A code ("POL-012", "ADR-004") names one kind of
+// problem for good: messages may be reworded, codes are never reused.
+// Each entry records the owning team, the default severity, what the
+// problem means and how to fix it; docs/governance/error-codes.md is
+// generated from the catalog (go test ./internal/codes -update).
+//
+// Checks that read YAML or Markdown files honour inline suppressions (see
+// ParseSuppressions), which must name a code and give a reason.
+package codes
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Severities. SeverityPolicy marks codes whose severity the policy sets
+// (gate rules).
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityPolicy  = "policy"
+)
+
+// Entry documents one code.
+type Entry struct {
+	Code     string `json:"code"`
+	Title    string `json:"title"`
+	Check    string `json:"check"`
+	Owner    string `json:"owner"`
+	Severity string `json:"severity"`
+	// Explanation says what the problem is and why it matters; Fix how to
+	// resolve it.
+	Explanation string `json:"explanation"`
+	Fix         string `json:"fix"`
+}
+
+// Pattern is the code format: an upper-case prefix, a dash and three
+// digits.
+var Pattern = regexp.MustCompile(`^[A-Z]{2,4}-\d{3}$`)
+
+var byCode = func() map[string]Entry {
+	m := make(map[string]Entry, len(catalog))
+	for _, e := range catalog {
+		m[e.Code] = e
+	}
+	return m
+}()
+
+// All returns the catalog ordered by code.
+func All() []Entry {
+	out := append([]Entry(nil), catalog...)
+	sort.Slice(out, func(i, j int) bool { return out[i].Code < out[j].Code })
+	return out
+}
+
+// Lookup returns the entry for code.
+func Lookup(code string) (Entry, bool) {
+	e, ok := byCode[code]
+	return e, ok
+}
+
+// Finding is one coded problem in a file.
+type Finding struct {
+	Code     string `json:"code"`
+	Severity string `json:"severity"`
+	File     string `json:"file,omitempty"`
+	// Line is 1-based; 0 when the problem concerns the whole file.
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+	// Suppressed is the reason of the comment that suppressed the finding.
+	Suppressed string `json:"suppressed,omitempty"`
+}
+
+// New builds a finding with the code's catalog severity.
+func New(code, file string, line int, format string, args ...any) Finding {
+	e, ok := byCode[code]
+	if !ok {
+		panic("codes: unknown code " + code)
+	}
+	return Finding{Code: code, Severity: e.Severity, File: file, Line: line, Message: fmt.Sprintf(format, args...)}
+}
+
+// Annotation renders f as a GitHub Actions workflow command; suppressed
+// findings become notices that quote the reason.
+func (f Finding) Annotation() string {
+	var props []string
+	if f.File != "" {
+		props = append(props, "file="+f.File)
+	}
+	if f.Line > 0 {
+		props = append(props, fmt.Sprintf("line=%d", f.Line))
+	}
+	props = append(props, "title="+f.Code)
+	level, msg := "error", f.Message
+	switch {
+	case f.Suppressed != "":
+		level, msg = "notice", fmt.Sprintf("%s (suppressed: %s)", msg, f.Suppressed)
+	case f.Severity == SeverityWarning:
+		level = "warning"
+	}
+	return fmt.Sprintf("::%s %s::%s", level, strings.Join(props, ","), msg)
+}
+
+// Errors counts the unsuppressed error findings.
+func Errors(fs []Finding) int {
+	n := 0
+	for _, f := range fs {
+		if f.Severity == SeverityError && f.Suppressed == "" {
+			n++
+		}
+	}
+	return n
+}
+
+// Sort orders findings by file, line and code.
+func Sort(fs []Finding) {
+	sort.SliceStable(fs, func(i, j int) bool {
+		a, b := fs[i], fs[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Code < b.Code
+	})
+}
+
+// groups title the code prefixes in the generated reference.
+var groups = []struct{ prefix, title string }{
+	{"POL", "Policy files"},
+	{"ADR", "Architecture decision records"},
+	{"GATE", "Policy gate rules"},
+	{"CAT", "Service catalog"},
+	{"SOD", "Separation of duties"},
+	{"BYP", "Bypasses"},
+	{"SUP", "Suppression comments"},
+}
+
+// Markdown renders the catalog as docs/governance/error-codes.md.
+func Markdown() string {
+	var b strings.Builder
+	b.WriteString("# Error codes\n\n")
+	b.WriteString("<!-- Generated from internal/codes/catalog.go by `go test ./internal/codes -update`. Do not edit. -->\n\n")
+	b.WriteString("Every problem reported by the Go governance checks carries a stable code.\n")
+	b.WriteString("See [error-codes-and-suppressions.md](error-codes-and-suppressions.md) for how codes are used and suppressed.\n")
+	all := All()
+	for _, g := range groups {
+		fmt.Fprintf(&b, "\n## %s — %s\n\n", g.prefix, g.title)
+		b.WriteString("| Code | Title | Severity | Owner |\n|------|-------|----------|-------|\n")
+		var es []Entry
+		for _, e := range all {
+			if strings.HasPrefix(e.Code, g.prefix+"-") {
+				es = append(es, e)
+				fmt.Fprintf(&b, "| [%s](#%s) | %s | %s | %s |\n", e.Code, strings.ToLower(e.Code), e.Title, e.Severity, e.Owner)
+			}
+		}
+		for _, e := range es {
+			fmt.Fprintf(&b, "\n### %s\n\n", e.Code)
+			sev := e.Severity
+			if sev == SeverityPolicy {
+				sev = "set by the rule in the policy"
+			}
+			fmt.Fprintf(&b, "**%s.** Reported by `%s`; owned by %s; severity: %s.\n\n", e.Title, e.Check, e.Owner, sev)
+			fmt.Fprintf(&b, "%s\n\n", e.Explanation)
+			fmt.Fprintf(&b, "**Fix:** %s\n", e.Fix)
+		}
+	}
+	return b.String()
+}
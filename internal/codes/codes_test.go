@@ -0,0 +1,180 @@
+package codes
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite docs/governance/error-codes.md")
+
+const (
+	repoRoot = "../.."
+	docPath  = "docs/governance/error-codes.md"
+)
+
+func TestCatalog(t *testing.T) {
+	owner := regexp.MustCompile(`^@BrikByte-Studios/[a-z-]+$`)
+	seen := map[string]bool{}
+	for _, e := range catalog {
+		if !Pattern.MatchString(e.Code) {
+			t.Errorf("%s: not a valid code", e.Code)
+		}
+		if seen[e.Code] {
+			t.Errorf("%s: listed twice", e.Code)
+		}
+		seen[e.Code] = true
+		if !owner.MatchString(e.Owner) {
+			t.Errorf("%s: owner %q is not an org team", e.Code, e.Owner)
+		}
+		switch e.Severity {
+		case SeverityError, SeverityWarning, SeverityPolicy:
+		default:
+			t.Errorf("%s: severity %q", e.Code, e.Severity)
+		}
+		if e.Title == "" || e.Check == "" || e.Explanation == "" || e.Fix == "" {
+			t.Errorf("%s: title, check, explanation and fix are required", e.Code)
+		}
+		grouped := false
+		for _, g := range groups {
+			grouped = grouped || strings.HasPrefix(e.Code, g.prefix+"-")
+		}
+		if !grouped {
+			t.Errorf("%s: prefix has no group", e.Code)
+		}
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	path := filepath.Join(repoRoot, docPath)
+	got := Markdown()
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("%s is out of date; run go test ./internal/codes -update", docPath)
+	}
+}
+
+// TestCodesUsed keeps the catalog and the checks in step: every code
+// literal in the Go sources is catalogued, and every catalogued code is
+// emitted somewhere.
+func TestCodesUsed(t *testing.T) {
+	lit := regexp.MustCompile(`"([A-Z]{2,4}-\d{3})"`)
+	used := map[string]bool{}
+	for _, dir := range []string{"cmd", "internal"} {
+		err := filepath.WalkDir(filepath.Join(repoRoot, dir), func(p string, d os.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.HasSuffix(p, ".go") || strings.HasSuffix(p, "_test.go") || strings.HasSuffix(p, "catalog.go") && filepath.Base(filepath.Dir(p)) == "codes" {
+				return err
+			}
+			raw, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			for _, m := range lit.FindAllStringSubmatch(string(raw), -1) {
+				if _, ok := Lookup(m[1]); !ok {
+					t.Errorf("%s: %s is not in the catalog", p, m[1])
+				}
+				used[m[1]] = true
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	var unused []string
+	for _, e := range catalog {
+		if !used[e.Code] {
+			unused = append(unused, e.Code)
+		}
+	}
+	sort.Strings(unused)
+	if len(unused) > 0 {
+		t.Errorf("catalogued but never emitted: %s", strings.Join(unused, ", "))
+	}
+}
+
+func TestParseSuppressions(t *testing.T) {
+	raw := `release:
+  semver:
+    tag_pattern: "^v\\d+$" # brikgov:ignore POL-012 -- calendar tags until v2
+
+    # brikgov:ignore POL-015, POL-016 -- migrated repo keeps its first tag
+    # a plain comment
+    initial_version: "1.0"
+    tag_type: "signed" # brikgov:ignore POL-016
+    idempotency: "x" # brikgov:ignore NOPE-001 -- typo
+    enforcement_mode: "x" # brikgov:ignore -- no code
+`
+	s, problems := ParseSuppressions("policy.yml", []byte(raw))
+	var got []string
+	for _, sup := range s.List {
+		got = append(got, sup.Code+" "+sup.Reason+" "+strconv.Itoa(sup.Line)+"→"+strconv.Itoa(sup.Target))
+	}
+	want := []string{
+		"POL-012 calendar tags until v2 3→3",
+		"POL-015 migrated repo keeps its first tag 5→7",
+		"POL-016 migrated repo keeps its first tag 5→7",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("suppressions:\n%s", strings.Join(got, "\n"))
+	}
+	got = nil
+	for _, p := range problems {
+		got = append(got, p.Code+" "+strconv.Itoa(p.Line)+" "+p.Message)
+	}
+	want = []string{
+		`SUP-001 8 brikgov:ignore POL-016 has no reason (add "-- <why>")`,
+		`SUP-002 9 brikgov:ignore names unknown code "NOPE-001"`,
+		"SUP-002 10 brikgov:ignore names no code",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("problems:\n%s", strings.Join(got, "\n"))
+	}
+
+	fs := []Finding{
+		New("POL-012", "policy.yml", 3, "tag_pattern"),
+		New("POL-015", "policy.yml", 7, "initial_version"),
+		New("POL-016", "policy.yml", 8, "tag_type"),
+		New("POL-012", "other.yml", 3, "tag_pattern"),
+	}
+	s.Apply(fs)
+	if fs[0].Suppressed == "" || fs[1].Suppressed == "" || fs[2].Suppressed != "" || fs[3].Suppressed != "" {
+		t.Errorf("applied: %+v", fs)
+	}
+	if n := Errors(fs); n != 2 {
+		t.Errorf("errors = %d, want 2", n)
+	}
+	unused := s.Unused()
+	if len(unused) != 1 || unused[0].Code != "SUP-003" || !strings.Contains(unused[0].Message, "POL-016") {
+		t.Errorf("unused: %+v", unused)
+	}
+}
+
+func TestAnnotation(t *testing.T) {
+	cases := map[string]Finding{
+		"::error file=docs/adr/004-x.md,line=3,title=ADR-004::seq 3 is also used by 003-y.md": New("ADR-004", "docs/adr/004-x.md", 3, "seq 3 is also used by %s", "003-y.md"),
+		"::warning file=a.yml,title=SUP-003::stale":                                           New("SUP-003", "a.yml", 0, "stale"),
+		"::notice file=a.yml,line=2,title=POL-012::strict (suppressed: calendar tags)": {
+			Code: "POL-012", Severity: SeverityError, File: "a.yml", Line: 2, Message: "strict", Suppressed: "calendar tags",
+		},
+	}
+	for want, f := range cases {
+		if got := f.Annotation(); got != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	}
+}
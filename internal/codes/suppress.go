@@ -0,0 +1,131 @@
+package codes
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Suppression is an inline comment that silences findings of one code:
+//
+//	tag_pattern: "^v\\d+$"  # brikgov:ignore POL-012 -- calendar tags until v2
+//	# brikgov:ignore CAT-019 -- the storefront shares checkout's web assets
+//	<!-- brikgov:ignore ADR-006 -- renumbered; filename kept for existing links -->
+//
+// A comment after content covers its own line; a comment on a line of its
+// own covers the next line with content. Several codes may be listed,
+// separated by commas. The reason after "--" is required.
+type Suppression struct {
+	Code   string `json:"code"`
+	Reason string `json:"reason"`
+	File   string `json:"file"`
+	// Line is the comment's line; Target the line it covers.
+	Line   int `json:"line"`
+	Target int `json:"target"`
+
+	used bool
+}
+
+// Suppressions are the suppressions of one file.
+type Suppressions struct {
+	File string
+	List []*Suppression
+}
+
+var (
+	suppressRe = regexp.MustCompile(`(#|<!--)\s*brikgov:ignore\b(.*)$`)
+	commentRe  = regexp.MustCompile(`^\s*(#.*|<!--.*-->)?\s*$`)
+)
+
+// ParseSuppressions finds the suppression comments in a YAML or Markdown
+// file. Comments without a reason or with unknown codes are findings
+// (SUP-001, SUP-002) and suppress nothing.
+func ParseSuppressions(file string, raw []byte) (*Suppressions, []Finding) {
+	s := &Suppressions{File: file}
+	var problems []Finding
+	lines := strings.Split(string(raw), "\n")
+	for i, l := range lines {
+		m := suppressRe.FindStringSubmatchIndex(l)
+		if m == nil {
+			continue
+		}
+		line := i + 1
+		rest := strings.TrimSpace(l[m[4]:m[5]])
+		if l[m[2]:m[3]] == "<!--" {
+			rest = strings.TrimSpace(strings.TrimSuffix(rest, "-->"))
+		}
+		list, reason, _ := strings.Cut(rest, "--")
+		reason = strings.TrimSpace(reason)
+		var codes []string
+		for _, c := range strings.Split(list, ",") {
+			if c = strings.TrimSpace(c); c != "" {
+				codes = append(codes, c)
+			}
+		}
+		if len(codes) == 0 {
+			problems = append(problems, New("SUP-002", file, line, "brikgov:ignore names no code"))
+			continue
+		}
+		if reason == "" {
+			problems = append(problems, New("SUP-001", file, line, "brikgov:ignore %s has no reason (add \"-- <why>\")", strings.Join(codes, ", ")))
+			continue
+		}
+		target := line
+		if strings.TrimSpace(l[:m[0]]) == "" {
+			target = 0
+			for j := i + 1; j < len(lines); j++ {
+				if !commentRe.MatchString(lines[j]) {
+					target = j + 1
+					break
+				}
+			}
+		}
+		for _, c := range codes {
+			if _, ok := Lookup(c); !ok {
+				problems = append(problems, New("SUP-002", file, line, "brikgov:ignore names unknown code %q", c))
+				continue
+			}
+			s.List = append(s.List, &Suppression{Code: c, Reason: reason, File: file, Line: line, Target: target})
+		}
+	}
+	return s, problems
+}
+
+// Match returns the reason of a suppression covering code at line, and
+// marks it used. A finding without a line (0) is covered by any
+// suppression of its code in the file.
+func (s *Suppressions) Match(code string, line int) (string, bool) {
+	if s == nil {
+		return "", false
+	}
+	for _, sup := range s.List {
+		if sup.Code == code && (line == 0 || line == sup.Target || line == sup.Line) {
+			sup.used = true
+			return sup.Reason, true
+		}
+	}
+	return "", false
+}
+
+// Apply marks the findings of s.File that a suppression covers.
+func (s *Suppressions) Apply(fs []Finding) {
+	for i := range fs {
+		if fs[i].File != s.File || fs[i].Code == "SUP-003" {
+			continue
+		}
+		if reason, ok := s.Match(fs[i].Code, fs[i].Line); ok {
+			fs[i].Suppressed = reason
+		}
+	}
+}
+
+// Unused reports the suppressions that matched nothing (SUP-003), so stale
+// comments do not hide a future problem.
+func (s *Suppressions) Unused() []Finding {
+	var out []Finding
+	for _, sup := range s.List {
+		if !sup.used {
+			out = append(out, New("SUP-003", sup.File, sup.Line, "brikgov:ignore %s suppresses nothing; remove it", sup.Code))
+		}
+	}
+	return out
+}
@@ -0,0 +1,153 @@
+// Package compliance maps policy rules and governance artifacts to external
+// control catalogs (ISO 27001, POPIA, GDPR, …) and assembles auditor
+// evidence packs from the .audit records of a date range.
+//
+// A catalog is a YAML file:
+//
+//	catalog: iso27001
+//	title: "ISO/IEC 27001:2022 Annex A"
+//	owner: "@BrikByte-Studios/security"
+//	controls:
+//	  - id: "A.8.28"
+//	    title: "Secure coding"
+//	    evidence:
+//	      rules: ["security.sast"]          # gate rule results in decisions
+//	      audit: [{kind: "reviews"}]        # .audit records by namespace/kind
+//	      files: ["CODEOWNERS"]             # governance artifacts in the repo
+//
+// A control is covered when every evidence requirement it declares is met
+// by at least one item in the range.
+package compliance
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Catalog is a set of controls from one framework.
+type Catalog struct {
+	ID       string    `yaml:"catalog" json:"catalog"`
+	Title    string    `yaml:"title" json:"title"`
+	Owner    string    `yaml:"owner" json:"owner,omitempty"`
+	Controls []Control `yaml:"controls" json:"controls"`
+	Path     string    `yaml:"-" json:"path"`
+}
+
+// Control is a single catalog entry and the evidence that satisfies it.
+type Control struct {
+	ID       string   `yaml:"id" json:"id"`
+	Title    string   `yaml:"title" json:"title"`
+	Evidence Evidence `yaml:"evidence" json:"evidence"`
+}
+
+// Evidence lists the requirement kinds a control can declare.
+type Evidence struct {
+	// Rules are gate rule ids (e.g. "security.sast") whose results must
+	// appear in decision records.
+	Rules []string `yaml:"rules" json:"rules,omitempty"`
+	// Audit selects .audit records by namespace prefix and/or kind.
+	Audit []AuditSelector `yaml:"audit" json:"audit,omitempty"`
+	// Files are repository globs for governance artifacts (policy, CODEOWNERS,
+	// ADRs) included in the pack.
+	Files []string `yaml:"files" json:"files,omitempty"`
+}
+
+// AuditSelector matches records whose namespace starts with Namespace and
+// whose kind equals Kind (empty fields match anything).
+type AuditSelector struct {
+	Namespace string `yaml:"namespace" json:"namespace,omitempty"`
+	Kind      string `yaml:"kind" json:"kind,omitempty"`
+}
+
+func (s AuditSelector) String() string {
+	switch {
+	case s.Namespace != "" && s.Kind != "":
+		return s.Namespace + "/*:" + s.Kind
+	case s.Kind != "":
+		return "*:" + s.Kind
+	default:
+		return s.Namespace + "/*"
+	}
+}
+
+// LoadCatalogs reads every *.yml / *.yaml file in dir (or a single file).
+func LoadCatalogs(path string) ([]Catalog, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{path}
+	if info.IsDir() {
+		files = nil
+		for _, pat := range []string{"*.yml", "*.yaml"} {
+			m, _ := filepath.Glob(filepath.Join(path, pat))
+			files = append(files, m...)
+		}
+		sort.Strings(files)
+	}
+
+	var out []Catalog
+	seen := map[string]string{}
+	for _, f := range files {
+		raw, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		var c Catalog
+		if err := yaml.Unmarshal(raw, &c); err != nil {
+			return nil, fmt.Errorf("%s: %w", f, err)
+		}
+		c.Path = filepath.ToSlash(f)
+		if err := c.validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", f, err)
+		}
+		if prev, dup := seen[c.ID]; dup {
+			return nil, fmt.Errorf("%s: catalog %q already defined in %s", f, c.ID, prev)
+		}
+		seen[c.ID] = f
+		out = append(out, c)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("%s: no control catalogs found", path)
+	}
+	return out, nil
+}
+
+func (c *Catalog) validate() error {
+	if c.ID == "" {
+		return fmt.Errorf("missing 'catalog' id")
+	}
+	if len(c.Controls) == 0 {
+		return fmt.Errorf("catalog %q has no controls", c.ID)
+	}
+	ids := map[string]bool{}
+	for i, ctl := range c.Controls {
+		if ctl.ID == "" {
+			return fmt.Errorf("controls[%d]: missing id", i)
+		}
+		if ids[ctl.ID] {
+			return fmt.Errorf("control %s defined twice", ctl.ID)
+		}
+		ids[ctl.ID] = true
+		e := ctl.Evidence
+		if len(e.Rules)+len(e.Audit)+len(e.Files) == 0 {
+			return fmt.Errorf("control %s declares no evidence", ctl.ID)
+		}
+		for _, s := range e.Audit {
+			if s.Namespace == "" && s.Kind == "" {
+				return fmt.Errorf("control %s: audit selector needs namespace or kind", ctl.ID)
+			}
+		}
+		for _, g := range e.Files {
+			if _, err := filepath.Match(g, ""); err != nil || strings.HasPrefix(g, "/") || strings.Contains(g, "..") {
+				return fmt.Errorf("control %s: invalid file glob %q", ctl.ID, g)
+			}
+		}
+	}
+	return nil
+}
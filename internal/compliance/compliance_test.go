@@ -0,0 +1,177 @@
+package compliance
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BrikByte-Studios/github-governance/internal/audit"
+)
+
+type nopSanitizer struct{}
+
+func (nopSanitizer) RedactBytes(b []byte) ([]byte, int) { return b, 0 }
+
+func TestRepoCatalogsLoad(t *testing.T) {
+	cats, err := LoadCatalogs(filepath.Join("..", "..", ".governance", "compliance"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cats) < 3 {
+		t.Errorf("loaded %d catalogs", len(cats))
+	}
+}
+
+func TestCatalogValidation(t *testing.T) {
+	dir := t.TempDir()
+	bad := "catalog: x\ncontrols:\n  - id: C1\n    evidence: {}\n"
+	_ = os.WriteFile(filepath.Join(dir, "x.yml"), []byte(bad), 0o644)
+	if _, err := LoadCatalogs(dir); err == nil || !strings.Contains(err.Error(), "no evidence") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func fixture(t *testing.T) (root string, cats []Catalog, recs []audit.Record) {
+	t.Helper()
+	root = t.TempDir()
+	_ = os.WriteFile(filepath.Join(root, "CODEOWNERS"), []byte("* @org/team\n"), 0o644)
+	cat := `catalog: iso27001
+title: ISO
+controls:
+  - id: A.8.28
+    title: Secure coding
+    evidence:
+      rules: [security.sast, reviews]
+  - id: A.8.4
+    title: Access to source code
+    evidence:
+      files: [CODEOWNERS]
+      audit: [{namespace: release, kind: tag}]
+  - id: A.8.8
+    title: Vulnerabilities
+    evidence:
+      rules: [security.sca]
+`
+	catPath := filepath.Join(root, "iso.yml")
+	_ = os.WriteFile(catPath, []byte(cat), 0o644)
+	var err error
+	if cats, err = LoadCatalogs(catPath); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2026, 2, 10, 9, 0, 0, 0, time.UTC)
+	store := &audit.Store{Dir: filepath.Join(root, ".audit"), Sanitizer: nopSanitizer{}, Now: func() time.Time { return now }}
+	put := func(ns, kind, content string) {
+		if _, _, err := store.Put(ns, kind, "", []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+		now = now.Add(time.Hour)
+	}
+	put("gate", "decision", `{"status":"passed","rules":[{"id":"security.sast","result":"pass","waived":false},{"id":"security.sca","result":"fail","waived":true}]}`)
+	put("gate", "decision", `{"reviews":{"result":"fail"}}`)
+	put("gate", "unrelated", `{"x":1}`)
+	now = time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	put("release", "tag", `{"tag":"v1.0.0"}`) // outside the range
+
+	if recs, err = store.List(""); err != nil {
+		t.Fatal(err)
+	}
+	return root, cats, recs
+}
+
+func TestEvaluateCoverage(t *testing.T) {
+	root, cats, recs := fixture(t)
+	rng, _ := ParseRange("2026-02-01", "2026-02-28")
+	cov, err := Evaluate(cats, recs, root, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctl := map[string]ControlResult{}
+	for _, c := range cov.Catalogs[0].Controls {
+		ctl[c.ID] = c
+	}
+	if c := ctl["A.8.28"]; c.Status != StatusCovered || c.Rules[0].Passed != 1 || c.Rules[1].Failed != 1 {
+		t.Errorf("A.8.28 = %+v", c)
+	}
+	if c := ctl["A.8.4"]; c.Status != StatusPartial || len(c.Audit[0].Records) != 0 {
+		t.Errorf("A.8.4 = %+v", c)
+	}
+	if c := ctl["A.8.8"]; c.Status != StatusCovered || c.Rules[0].Waived != 1 {
+		t.Errorf("A.8.8 = %+v", c)
+	}
+	if len(cov.records) != 2 {
+		t.Errorf("relevant records = %d, want 2", len(cov.records))
+	}
+}
+
+func TestPackIsReproducibleAndHashed(t *testing.T) {
+	root, cats, recs := fixture(t)
+	rng, _ := ParseRange("2026-02-01", "2026-02-28")
+	gen := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	build := func() []byte {
+		cov, err := Evaluate(cats, recs, root, rng)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if _, err := WritePack(&buf, cov, cats, root, gen); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+	a, b := build(), build()
+	if !bytes.Equal(a, b) {
+		t.Fatal("evidence pack is not byte-identical for identical inputs")
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(a), int64(len(a)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	contents := map[string][]byte{}
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		var buf bytes.Buffer
+		_, _ = buf.ReadFrom(rc)
+		rc.Close()
+		contents[f.Name] = buf.Bytes()
+	}
+	var m Manifest
+	if err := json.Unmarshal(contents["manifest.json"], &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Records != 2 || m.Artifacts != 1 {
+		t.Errorf("manifest = %+v", m)
+	}
+	for _, e := range m.Entries {
+		if sha(contents[e.Path]) != e.SHA256 {
+			t.Errorf("hash mismatch for %s", e.Path)
+		}
+	}
+	if _, ok := contents["artifacts/CODEOWNERS"]; !ok {
+		t.Error("artifact missing from pack")
+	}
+	if !strings.Contains(string(contents["SHA256SUMS"]), "  manifest.json\n") {
+		t.Error("SHA256SUMS does not cover manifest.json")
+	}
+}
+
+func TestPackRejectsTamperedRecords(t *testing.T) {
+	root, cats, recs := fixture(t)
+	recs[0].Content = json.RawMessage(`{"rules":[{"id":"security.sast","result":"pass"}],"tampered":true}`)
+	rng, _ := ParseRange("2026-02-01", "2026-02-28")
+	cov, err := Evaluate(cats, recs, root, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err := WritePack(&buf, cov, cats, root, time.Now()); err == nil {
+		t.Error("pack built from a tampered record")
+	}
+}
@@ -0,0 +1,284 @@
+package compliance
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BrikByte-Studios/github-governance/internal/audit"
+)
+
+// Control coverage statuses.
+const (
+	StatusCovered = "covered"
+	StatusPartial = "partial"
+	StatusMissing = "missing"
+)
+
+// Range is a half-open time window [From, To).
+type Range struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// Contains reports whether t falls inside the range.
+func (r Range) Contains(t time.Time) bool { return !t.Before(r.From) && t.Before(r.To) }
+
+// ParseRange parses inclusive YYYY-MM-DD bounds into a half-open range
+// ending at midnight after "to".
+func ParseRange(from, to string) (Range, error) {
+	f, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		return Range{}, fmt.Errorf("--from: %w", err)
+	}
+	t, err := time.Parse("2006-01-02", to)
+	if err != nil {
+		return Range{}, fmt.Errorf("--to: %w", err)
+	}
+	if t.Before(f) {
+		return Range{}, fmt.Errorf("--to (%s) is before --from (%s)", to, from)
+	}
+	return Range{From: f, To: t.AddDate(0, 0, 1)}, nil
+}
+
+// RuleEvidence summarises the decisions that evaluated one gate rule.
+type RuleEvidence struct {
+	Rule    string   `json:"rule"`
+	Passed  int      `json:"passed"`
+	Failed  int      `json:"failed"`
+	Waived  int      `json:"waived"`
+	Records []string `json:"records"`
+}
+
+// SelectorEvidence lists the audit records matched by a selector.
+type SelectorEvidence struct {
+	Selector string   `json:"selector"`
+	Records  []string `json:"records"`
+}
+
+// FileEvidence lists the repository files matched by a glob.
+type FileEvidence struct {
+	Glob  string   `json:"glob"`
+	Paths []string `json:"paths"`
+}
+
+// ControlResult is the coverage of one control.
+type ControlResult struct {
+	ID     string             `json:"id"`
+	Title  string             `json:"title"`
+	Status string             `json:"status"`
+	Rules  []RuleEvidence     `json:"rules,omitempty"`
+	Audit  []SelectorEvidence `json:"audit,omitempty"`
+	Files  []FileEvidence     `json:"files,omitempty"`
+}
+
+// CatalogCoverage aggregates control results per catalog.
+type CatalogCoverage struct {
+	Catalog  string          `json:"catalog"`
+	Title    string          `json:"title"`
+	Owner    string          `json:"owner,omitempty"`
+	Covered  int             `json:"covered"`
+	Partial  int             `json:"partial"`
+	Missing  int             `json:"missing"`
+	Controls []ControlResult `json:"controls"`
+}
+
+// Coverage is the control coverage report for a date range.
+type Coverage struct {
+	Range    Range             `json:"range"`
+	Catalogs []CatalogCoverage `json:"catalogs"`
+	// records holds the audit records referenced by any control, keyed by id,
+	// for the evidence pack.
+	records map[string]audit.Record
+	files   []string
+}
+
+// ruleResult is the shape of a rule entry in a gate decision.
+type ruleResult struct {
+	ID     string `json:"id"`
+	Result string `json:"result"`
+	Waived bool   `json:"waived"`
+}
+
+// ruleResults extracts rule outcomes from a decision record. Two shapes are
+// understood: the gate's {"rules": [{id, result, waived}]} and the
+// per-evaluator {"reviews": {"result": "pass"}} form.
+func ruleResults(rec audit.Record) []ruleResult {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(rec.Content, &doc); err != nil {
+		return nil
+	}
+	var out []ruleResult
+	if raw, ok := doc["rules"]; ok {
+		var rules []ruleResult
+		if json.Unmarshal(raw, &rules) == nil {
+			out = append(out, rules...)
+		}
+	}
+	for key, raw := range doc {
+		var r ruleResult
+		if key == "rules" || json.Unmarshal(raw, &r) != nil || r.Result == "" {
+			continue
+		}
+		r.ID = key
+		out = append(out, r)
+	}
+	return out
+}
+
+// Evaluate computes control coverage from the records inside rng and the
+// files under root.
+func Evaluate(catalogs []Catalog, records []audit.Record, root string, rng Range) (*Coverage, error) {
+	var inRange []audit.Record
+	for _, r := range records {
+		if rng.Contains(r.CreatedAt) {
+			inRange = append(inRange, r)
+		}
+	}
+
+	cov := &Coverage{Range: rng, records: map[string]audit.Record{}}
+	fileSet := map[string]bool{}
+
+	for _, cat := range catalogs {
+		cc := CatalogCoverage{Catalog: cat.ID, Title: cat.Title, Owner: cat.Owner}
+		for _, ctl := range cat.Controls {
+			res := ControlResult{ID: ctl.ID, Title: ctl.Title}
+			met, total := 0, 0
+
+			for _, rule := range ctl.Evidence.Rules {
+				total++
+				ev := RuleEvidence{Rule: rule, Records: []string{}}
+				for _, rec := range inRange {
+					for _, rr := range ruleResults(rec) {
+						if rr.ID != rule {
+							continue
+						}
+						switch {
+						case rr.Result == "pass":
+							ev.Passed++
+						case rr.Waived:
+							ev.Waived++
+						default:
+							ev.Failed++
+						}
+						ev.Records = appendUnique(ev.Records, rec.ID)
+						cov.records[rec.ID] = rec
+					}
+				}
+				if len(ev.Records) > 0 {
+					met++
+				}
+				res.Rules = append(res.Rules, ev)
+			}
+
+			for _, sel := range ctl.Evidence.Audit {
+				total++
+				ev := SelectorEvidence{Selector: sel.String(), Records: []string{}}
+				for _, rec := range inRange {
+					if sel.Namespace != "" && rec.Namespace != sel.Namespace && !strings.HasPrefix(rec.Namespace, sel.Namespace+"/") {
+						continue
+					}
+					if sel.Kind != "" && rec.Kind != sel.Kind {
+						continue
+					}
+					ev.Records = append(ev.Records, rec.ID)
+					cov.records[rec.ID] = rec
+				}
+				if len(ev.Records) > 0 {
+					met++
+				}
+				res.Audit = append(res.Audit, ev)
+			}
+
+			for _, g := range ctl.Evidence.Files {
+				total++
+				matches, err := filepath.Glob(filepath.Join(root, filepath.FromSlash(g)))
+				if err != nil {
+					return nil, fmt.Errorf("%s %s: %w", cat.ID, ctl.ID, err)
+				}
+				ev := FileEvidence{Glob: g, Paths: []string{}}
+				for _, m := range matches {
+					rel, _ := filepath.Rel(root, m)
+					rel = filepath.ToSlash(rel)
+					ev.Paths = append(ev.Paths, rel)
+					fileSet[rel] = true
+				}
+				sort.Strings(ev.Paths)
+				if len(ev.Paths) > 0 {
+					met++
+				}
+				res.Files = append(res.Files, ev)
+			}
+
+			switch {
+			case met == total:
+				res.Status = StatusCovered
+				cc.Covered++
+			case met == 0:
+				res.Status = StatusMissing
+				cc.Missing++
+			default:
+				res.Status = StatusPartial
+				cc.Partial++
+			}
+			cc.Controls = append(cc.Controls, res)
+		}
+		cov.Catalogs = append(cov.Catalogs, cc)
+	}
+
+	for f := range fileSet {
+		cov.files = append(cov.files, f)
+	}
+	sort.Strings(cov.files)
+	return cov, nil
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+// Markdown renders the coverage report for humans.
+func (c *Coverage) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## Compliance Control Coverage\n\n")
+	fmt.Fprintf(&b, "**Range:** %s → %s\n\n", c.Range.From.Format("2006-01-02"), c.Range.To.AddDate(0, 0, -1).Format("2006-01-02"))
+	for _, cc := range c.Catalogs {
+		fmt.Fprintf(&b, "### %s — %s\n\n", cc.Catalog, cc.Title)
+		fmt.Fprintf(&b, "Covered: %d • Partial: %d • Missing: %d\n\n", cc.Covered, cc.Partial, cc.Missing)
+		b.WriteString("| Control | Title | Status | Evidence |\n|---------|-------|--------|----------|\n")
+		for _, ctl := range cc.Controls {
+			var ev []string
+			for _, r := range ctl.Rules {
+				ev = append(ev, fmt.Sprintf("%s: %d pass / %d fail / %d waived", r.Rule, r.Passed, r.Failed, r.Waived))
+			}
+			for _, a := range ctl.Audit {
+				ev = append(ev, fmt.Sprintf("%s: %d record(s)", a.Selector, len(a.Records)))
+			}
+			for _, f := range ctl.Files {
+				ev = append(ev, fmt.Sprintf("%s: %d file(s)", f.Glob, len(f.Paths)))
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", ctl.ID, ctl.Title, statusIcon(ctl.Status), strings.Join(ev, "<br>"))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func statusIcon(s string) string {
+	switch s {
+	case StatusCovered:
+		return "✅ Covered"
+	case StatusPartial:
+		return "⚠️ Partial"
+	default:
+		return "❌ Missing"
+	}
+}
@@ -0,0 +1,148 @@
+package compliance
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BrikByte-Studios/github-governance/internal/audit"
+	"github.com/BrikByte-Studios/github-governance/internal/canon"
+)
+
+// ManifestEntry describes one file in the evidence pack.
+type ManifestEntry struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+	Size   int    `json:"size"`
+}
+
+// Manifest is written to manifest.json at the root of the pack. It lists
+// every other file with its digest; SHA256SUMS additionally covers the
+// manifest itself.
+type Manifest struct {
+	GeneratedAt string          `json:"generated_at"`
+	Range       Range           `json:"range"`
+	Catalogs    []string        `json:"catalogs"`
+	Records     int             `json:"records"`
+	Artifacts   int             `json:"artifacts"`
+	Entries     []ManifestEntry `json:"entries"`
+}
+
+// WritePack assembles the evidence pack zip:
+//
+//	manifest.json       entries + hashes
+//	SHA256SUMS          sha256sum-compatible list (includes manifest.json)
+//	coverage.json       control coverage report
+//	coverage.md         human-readable coverage report
+//	catalogs/<file>     the catalogs used
+//	audit/<ns>/<id>.json  relevant .audit records (digest verified)
+//	artifacts/<path>    governance artifacts matched by file evidence
+//
+// Entries are sorted and timestamped with generatedAt so identical inputs
+// produce an identical archive.
+func WritePack(w io.Writer, cov *Coverage, catalogs []Catalog, root string, generatedAt time.Time) (*Manifest, error) {
+	files := map[string][]byte{}
+
+	ids := make([]string, 0, len(cov.records))
+	for id := range cov.records {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	var bad []string
+	for _, id := range ids {
+		rec := cov.records[id]
+		if err := audit.Verify(rec); err != nil {
+			bad = append(bad, err.Error())
+			continue
+		}
+		raw, err := audit.MarshalRecord(rec)
+		if err != nil {
+			return nil, err
+		}
+		files["audit/"+rec.Namespace+"/"+rec.ID+".json"] = raw
+	}
+	if len(bad) > 0 {
+		return nil, fmt.Errorf("audit records failed integrity check:\n  %s", strings.Join(bad, "\n  "))
+	}
+
+	for _, f := range cov.files {
+		raw, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(f)))
+		if err != nil {
+			return nil, err
+		}
+		files["artifacts/"+f] = raw
+	}
+
+	var catIDs []string
+	for _, c := range catalogs {
+		catIDs = append(catIDs, c.ID)
+		raw, err := os.ReadFile(c.Path)
+		if err != nil {
+			return nil, err
+		}
+		files["catalogs/"+filepath.Base(c.Path)] = raw
+	}
+
+	covJSON, err := canon.MarshalPretty(cov)
+	if err != nil {
+		return nil, err
+	}
+	files["coverage.json"] = append(covJSON, '\n')
+	files["coverage.md"] = []byte(cov.Markdown())
+
+	m := &Manifest{
+		GeneratedAt: generatedAt.UTC().Format(time.RFC3339),
+		Range:       cov.Range,
+		Catalogs:    catIDs,
+		Records:     len(ids),
+		Artifacts:   len(cov.files),
+	}
+	for _, p := range sortedKeys(files) {
+		m.Entries = append(m.Entries, ManifestEntry{Path: p, SHA256: sha(files[p]), Size: len(files[p])})
+	}
+	manJSON, err := canon.MarshalPretty(m)
+	if err != nil {
+		return nil, err
+	}
+	files["manifest.json"] = append(manJSON, '\n')
+
+	var sums strings.Builder
+	for _, p := range sortedKeys(files) {
+		fmt.Fprintf(&sums, "%s  %s\n", sha(files[p]), p)
+	}
+	files["SHA256SUMS"] = []byte(sums.String())
+
+	zw := zip.NewWriter(w)
+	for _, p := range sortedKeys(files) {
+		hdr := &zip.FileHeader{Name: p, Method: zip.Deflate, Modified: generatedAt.UTC()}
+		fw, err := zw.CreateHeader(hdr)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := fw.Write(files[p]); err != nil {
+			return nil, err
+		}
+	}
+	return m, zw.Close()
+}
+
+func sha(b []byte) string {
+	s := sha256.Sum256(b)
+	return hex.EncodeToString(s[:])
+}
+
+func sortedKeys(m map[string][]byte) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
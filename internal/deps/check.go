@@ -0,0 +1,386 @@
+package deps
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Finding kinds.
+const (
+	// KindMissing: a manifest dependency has no locked package.
+	KindMissing = "missing"
+	// KindUnsatisfied: the locked version is outside the declared range.
+	KindUnsatisfied = "unsatisfied"
+	// KindStale: the lockfile was generated from a different manifest.
+	KindStale              = "stale"
+	KindIntegrityMissing   = "integrity_missing"
+	KindIntegrityMalformed = "integrity_malformed"
+	// KindIntegrityWeak: only a SHA-1 integrity hash.
+	KindIntegrityWeak = "integrity_weak"
+	KindRegistry      = "registry"
+	// KindConflict: one package version locked with different contents.
+	KindConflict  = "conflict"
+	KindDuplicate = "duplicate"
+	KindMalformed = "malformed"
+)
+
+// DefaultRegistries are the approved download locations when the policy
+// sets none.
+var DefaultRegistries = []string{
+	"https://registry.npmjs.org/",
+	"https://files.pythonhosted.org/",
+	"https://pypi.org/",
+}
+
+// Config is the deps.lockfile rule's policy options.
+type Config struct {
+	// Registries are URL prefixes packages may be resolved from.
+	Registries []string `json:"registries"`
+	// AllowDuplicates accepts several versions of one package (npm nests
+	// them by design); they are still listed in the report.
+	AllowDuplicates *bool `json:"allow_duplicates"`
+}
+
+// Finding is one problem in a lockfile.
+type Finding struct {
+	File    string `json:"file"`
+	Package string `json:"package,omitempty"`
+	Version string `json:"version,omitempty"`
+	Kind    string `json:"kind"`
+	Detail  string `json:"detail"`
+}
+
+// Summary describes one checked lockfile.
+type Summary struct {
+	Path      string `json:"path"`
+	Manifest  string `json:"manifest,omitempty"`
+	Ecosystem string `json:"ecosystem"`
+	Packages  int    `json:"packages"`
+	Direct    int    `json:"direct"`
+}
+
+// Report is the result of checking lockfiles.
+type Report struct {
+	Lockfiles []Summary      `json:"lockfiles"`
+	Findings  []Finding      `json:"findings"`
+	Counts    map[string]int `json:"counts"`
+	// Duplicates lists packages locked at several versions when
+	// duplicates are allowed.
+	Duplicates []string `json:"duplicates,omitempty"`
+	// Unverifiable lists manifest ranges that are not version constraints
+	// (git, file and URL specs).
+	Unverifiable []string `json:"unverifiable,omitempty"`
+}
+
+// Add checks one lockfile against its manifest (nil if there is none) and
+// records the result.
+func (r *Report) Add(lf *Lockfile, man *Manifest, cfg Config) {
+	if r.Counts == nil {
+		r.Counts = map[string]int{}
+	}
+	if r.Findings == nil {
+		r.Findings = []Finding{}
+	}
+	MarkDirect(lf, man)
+	s := Summary{Path: lf.Path, Ecosystem: lf.Ecosystem, Packages: len(lf.Packages)}
+	for _, p := range lf.Packages {
+		if p.Direct {
+			s.Direct++
+		}
+	}
+	if man != nil {
+		s.Manifest = man.Path
+	}
+	r.Lockfiles = append(r.Lockfiles, s)
+
+	c := &checker{lf: lf, cfg: cfg, report: r}
+	c.add(lf.Problems...)
+	if man != nil {
+		c.manifest(man)
+	}
+	if lf.Ecosystem == NPM {
+		c.npmTree()
+	}
+	c.integrity()
+	c.registries()
+	c.duplicates()
+}
+
+// Summary counts the findings as "integrity_missing: 1, registry: 2".
+func (r *Report) Summary() string {
+	var parts []string
+	for k, n := range r.Counts {
+		parts = append(parts, fmt.Sprintf("%s: %d", k, n))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ", ")
+}
+
+// MarkDirect flags the packages the manifest declares. npm lockfiles
+// already know their direct packages.
+func MarkDirect(lf *Lockfile, man *Manifest) {
+	if man == nil || lf.Ecosystem == NPM {
+		return
+	}
+	for i := range lf.Packages {
+		p := &lf.Packages[i]
+		if _, ok := man.Requires[p.Name]; ok && !man.Indirect[p.Name] {
+			p.Direct = true
+			p.Dev = p.Dev || man.Dev[p.Name]
+		}
+	}
+}
+
+type checker struct {
+	lf     *Lockfile
+	cfg    Config
+	report *Report
+}
+
+func (c *checker) add(fs ...Finding) {
+	for _, f := range fs {
+		if f.File == "" {
+			f.File = c.lf.Path
+		}
+		c.report.Findings = append(c.report.Findings, f)
+		c.report.Counts[f.Kind]++
+	}
+}
+
+func (c *checker) find(p Package, kind, format string, args ...any) {
+	c.add(Finding{Package: p.Name, Version: p.Version, Kind: kind, Detail: fmt.Sprintf(format, args...)})
+}
+
+// manifest checks that every declared dependency is locked at a version
+// inside its range.
+func (c *checker) manifest(man *Manifest) {
+	byName := map[string][]Package{}
+	for _, p := range c.lf.Packages {
+		key := p.Name
+		if c.lf.Ecosystem == NPM {
+			if strings.Count(p.Path, "node_modules/") != 1 {
+				continue
+			}
+			key = alias(p.Path)
+		}
+		byName[key] = append(byName[key], p)
+	}
+	names := make([]string, 0, len(man.Requires))
+	for n := range man.Requires {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		rng := man.Requires[name]
+		if c.lf.Root != nil && c.lf.Root[name] != rng {
+			c.add(Finding{File: c.lf.Path, Package: name, Kind: KindStale,
+				Detail: fmt.Sprintf("%s declares %q but the lockfile was generated for %q", man.Path, rng, c.lf.Root[name])})
+		}
+		locked := byName[name]
+		if len(locked) == 0 {
+			c.add(Finding{Package: name, Kind: KindMissing, Detail: fmt.Sprintf("%s %s is not in the lockfile", name, rng)})
+			continue
+		}
+		if c.lf.Ecosystem == Go {
+			// go.mod pins a minimum version; go.sum must cover exactly it.
+			if !hasVersion(locked, rng) {
+				c.add(Finding{Package: name, Version: rng, Kind: KindMissing, Detail: fmt.Sprintf("go.sum has no hash for %s %s", name, rng)})
+			}
+			continue
+		}
+		c.satisfied(locked[0], name, rng, man.Path)
+	}
+	if c.lf.Root != nil {
+		for name, rng := range c.lf.Root {
+			if _, ok := man.Requires[name]; !ok {
+				c.add(Finding{Package: name, Kind: KindStale,
+					Detail: fmt.Sprintf("lockfile records %s %s, which %s no longer declares", name, rng, man.Path)})
+			}
+		}
+	}
+}
+
+func hasVersion(ps []Package, v string) bool {
+	for _, p := range ps {
+		if p.Version == v {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *checker) satisfied(p Package, name, rng, where string) {
+	spec := rng
+	if target, ok := strings.CutPrefix(rng, "npm:"); ok {
+		// npm alias: "npm:string-width@^4.2.0".
+		if i := strings.LastIndex(target, "@"); i > 0 {
+			spec = target[i+1:]
+		}
+	}
+	ok, err := Satisfies(spec, p.Version)
+	switch {
+	case err != nil:
+		c.report.Unverifiable = append(c.report.Unverifiable, fmt.Sprintf("%s: %s %s", where, name, rng))
+	case !ok:
+		c.find(p, KindUnsatisfied, "%s requires %s %s but %s is locked", where, name, rng, p.Version)
+	}
+}
+
+// npmTree checks every package's own dependencies against the package
+// Node would load for them.
+func (c *checker) npmTree() {
+	byPath := map[string]*Package{}
+	for i := range c.lf.Packages {
+		byPath[c.lf.Packages[i].Path] = &c.lf.Packages[i]
+	}
+	for _, p := range c.lf.Packages {
+		names := make([]string, 0, len(p.Requires))
+		for n := range p.Requires {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			dep := resolveNPM(byPath, p.Path, n)
+			if dep == nil {
+				// Optional and platform-specific dependencies may be absent.
+				continue
+			}
+			c.satisfied(*dep, n, p.Requires[n], p.Key())
+		}
+	}
+}
+
+// integrity checks every downloaded package has a well-formed hash.
+func (c *checker) integrity() {
+	for _, p := range c.lf.Packages {
+		if p.Local {
+			continue
+		}
+		if len(p.Integrity) == 0 {
+			c.find(p, KindIntegrityMissing, "%s has no integrity hash", p.Key())
+			continue
+		}
+		strong := false
+		for _, h := range p.Integrity {
+			algo, err := checkHash(c.lf.Ecosystem, h)
+			if err != nil {
+				c.find(p, KindIntegrityMalformed, "%s: %v", p.Key(), err)
+				strong = true // reported already
+				break
+			}
+			strong = strong || algo != "sha1"
+		}
+		if !strong {
+			c.find(p, KindIntegrityWeak, "%s only has a SHA-1 integrity hash", p.Key())
+		}
+	}
+}
+
+var digestSizes = map[string]int{"sha1": 20, "sha256": 32, "sha384": 48, "sha512": 64}
+
+// checkHash validates one integrity value and returns its algorithm.
+func checkHash(ecosystem, h string) (string, error) {
+	switch ecosystem {
+	case Go:
+		h = strings.TrimPrefix(h, "go.mod ")
+		b64, ok := strings.CutPrefix(h, "h1:")
+		if !ok {
+			return "", fmt.Errorf("go.sum hash %q is not h1:", h)
+		}
+		if b, err := base64.StdEncoding.DecodeString(b64); err != nil || len(b) != 32 {
+			return "", fmt.Errorf("go.sum hash %q is not a base64 SHA-256", h)
+		}
+		return "sha256", nil
+	case PyPI:
+		algo, digest, ok := strings.Cut(h, ":")
+		b, err := hex.DecodeString(digest)
+		if !ok || err != nil || len(b) != digestSizes[algo] {
+			return "", fmt.Errorf("file hash %q is not <algo>:<hex digest>", h)
+		}
+		return algo, nil
+	}
+	algo, digest, ok := strings.Cut(h, "-")
+	b, err := base64.StdEncoding.DecodeString(digest)
+	if !ok || err != nil || digestSizes[algo] == 0 || len(b) != digestSizes[algo] {
+		return "", fmt.Errorf("integrity %q is not a valid SRI hash", h)
+	}
+	return algo, nil
+}
+
+// registries checks resolved URLs against the approved prefixes. Go
+// modules are fetched by module path through GOPROXY, so go.sum has no
+// URLs to check.
+func (c *checker) registries() {
+	approved := c.cfg.Registries
+	if approved == nil {
+		approved = DefaultRegistries
+	}
+	for _, p := range c.lf.Packages {
+		if p.Local || p.Resolved == "" {
+			continue
+		}
+		ok := false
+		for _, r := range approved {
+			if strings.HasPrefix(p.Resolved, r) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			host := p.Resolved
+			if u, err := url.Parse(p.Resolved); err == nil && u.Host != "" {
+				host = u.Scheme + "://" + u.Host
+			}
+			c.find(p, KindRegistry, "%s is resolved from %s, outside the approved registries", p.Key(), host)
+		}
+	}
+}
+
+// duplicates reports packages locked at several versions and versions
+// locked with different contents.
+func (c *checker) duplicates() {
+	versions := map[string]map[string]Package{}
+	var names []string
+	for _, p := range c.lf.Packages {
+		if versions[p.Name] == nil {
+			versions[p.Name] = map[string]Package{}
+			names = append(names, p.Name)
+		}
+		if prev, ok := versions[p.Name][p.Version]; ok {
+			if !p.Local && !prev.Local && len(p.Integrity) > 0 && len(prev.Integrity) > 0 && p.Integrity[0] != prev.Integrity[0] {
+				c.find(p, KindConflict, "%s is locked with different integrity at %s and %s", p.Key(), prev.Path, p.Path)
+			}
+			continue
+		}
+		versions[p.Name][p.Version] = p
+	}
+	allow := c.cfg.AllowDuplicates == nil || *c.cfg.AllowDuplicates
+	for _, n := range names {
+		if len(versions[n]) < 2 {
+			continue
+		}
+		var vs []string
+		for v := range versions[n] {
+			vs = append(vs, v)
+		}
+		sort.Strings(vs)
+		// Go keeps hashes of every version in the module graph; only the
+		// selected one is built, so several versions are normal there.
+		if c.lf.Ecosystem == Go {
+			continue
+		}
+		if c.lf.Ecosystem == PyPI {
+			c.add(Finding{Package: n, Kind: KindConflict, Detail: fmt.Sprintf("%s is locked at several versions: %s", n, strings.Join(vs, ", "))})
+			continue
+		}
+		if allow {
+			c.report.Duplicates = append(c.report.Duplicates, n+"@"+strings.Join(vs, ","))
+			continue
+		}
+		c.add(Finding{Package: n, Kind: KindDuplicate, Detail: fmt.Sprintf("%s is installed at several versions: %s", n, strings.Join(vs, ", "))})
+	}
+}
@@ -0,0 +1,394 @@
+package deps
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSatisfies(t *testing.T) {
+	cases := []struct {
+		rng, v string
+		want   bool
+	}{
+		{"^8.17.1", "8.17.1", true},
+		{"^8.17.1", "8.20.0", true},
+		{"^8.17.1", "9.0.0", false},
+		{"^8.17.1", "8.17.0", false},
+		{"^0.2.3", "0.2.9", true},
+		{"^0.2.3", "0.3.0", false},
+		{"^0.0.3", "0.0.4", false},
+		{"~1.2.3", "1.2.9", true},
+		{"~1.2.3", "1.3.0", false},
+		{"~1", "1.9.0", true},
+		{"1.x", "1.4.2", true},
+		{"1.x", "2.0.0", false},
+		{"*", "3.1.4", true},
+		{">=1.2.0 <2", "1.9.9", true},
+		{">=1.2.0 <2", "2.0.0", false},
+		{"1.2.0 - 1.4", "1.4.7", true},
+		{"1.2.0 - 1.4", "1.5.0", false},
+		{"^1.0.0 || ^2.0.0", "2.3.0", true},
+		{"^1.0.0", "1.1.0-rc.1", false},
+		{"^1.1.0-rc.0", "1.1.0-rc.1", true},
+		{">=2.31,<3", "2.32.3", true},
+		{"~=1.4", "1.9", true},
+		{"~=1.4", "2.0", false},
+		{"~=1.4.2", "1.5.0", false},
+		{"==1.2.*", "1.2.7", true},
+		{"==1.2", "1.2.7", false},
+		{"!=1.3.0,>=1", "1.3.0", false},
+		{"^3.11", "3.12.1", true},
+		{">1.2", "1.2.5", false},
+		{"<=1.2", "1.2.5", true},
+		{"^2.0", "2.0.0rc1", false},
+	}
+	for _, c := range cases {
+		got, err := Satisfies(c.rng, c.v)
+		if err != nil || got != c.want {
+			t.Errorf("Satisfies(%q, %q) = %v, %v; want %v", c.rng, c.v, got, err, c.want)
+		}
+	}
+	if _, err := Satisfies("github:org/repo#main", "1.0.0"); err != ErrUnverifiable {
+		t.Errorf("git spec: %v", err)
+	}
+}
+
+func TestRepoLockfiles(t *testing.T) {
+	r := &Report{}
+	for _, p := range []string{"package-lock.json", "go.sum"} {
+		lf, err := Load(filepath.Join("..", "..", p))
+		if err != nil {
+			t.Fatal(err)
+		}
+		man, err := LoadManifest(lf.Path)
+		if err != nil || man == nil {
+			t.Fatalf("%s manifest: %v", p, err)
+		}
+		r.Add(lf, man, Config{})
+	}
+	if len(r.Findings) != 0 {
+		t.Fatalf("repo lockfiles: %+v", r.Findings)
+	}
+	// go.mod requires gopkg.in/yaml.v3 and github.com/klauspost/compress
+	// (zstd for .audit compaction).
+	if r.Lockfiles[0].Direct != 6 || r.Lockfiles[1].Direct != 2 || len(r.Duplicates) == 0 {
+		t.Fatalf("summary: %+v, duplicates %v", r.Lockfiles, r.Duplicates)
+	}
+	if len(r.Unverifiable) != 0 {
+		t.Errorf("npm: aliases should be checked: %v", r.Unverifiable)
+	}
+}
+
+const manifest = `{"dependencies": {"left-pad": "^1.3.0", "chalk": "^5.0.0"}, "devDependencies": {"tap": "~16.3.0"}}`
+
+const lock = `{
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"dependencies": {"left-pad": "^1.3.0", "chalk": "^4.0.0"}, "devDependencies": {"tap": "~16.3.0"}},
+    "node_modules/left-pad": {"version": "1.3.0", "resolved": "https://registry.npmjs.org/left-pad/-/left-pad-1.3.0.tgz",
+      "integrity": "sha512-XI5MPzVNApjAyhQzphX8BkmKsKUxD4LdyK24iZeQEXGkMOCRSJtWQpLYcbOGkZnoJK/+oQSvwZhSv9Hy3DgTYQ==",
+      "dependencies": {"chalk": "^5.0.0"}},
+    "node_modules/chalk": {"version": "4.1.2", "resolved": "https://npm.evil.example/chalk/-/chalk-4.1.2.tgz",
+      "integrity": "sha1-abc"},
+    "node_modules/tap": {"version": "16.3.10", "resolved": "https://registry.npmjs.org/tap/-/tap-16.3.10.tgz", "dev": true},
+    "node_modules/tap/node_modules/chalk": {"version": "4.1.2", "resolved": "https://registry.npmjs.org/chalk/-/chalk-4.1.2.tgz",
+      "integrity": "sha1-rRQGDYGNj3N3MfXSaDcgfgA/MwU="},
+    "node_modules/local": {"version": "0.0.1", "resolved": "file:../local", "link": true}
+  }
+}`
+
+func TestNPMLockfile(t *testing.T) {
+	lf, err := Parse("package-lock.json", []byte(lock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	man, err := ParseManifest("package-lock.json", "package.json", []byte(manifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &Report{}
+	r.Add(lf, man, Config{AllowDuplicates: new(bool)})
+
+	got := map[string]int{}
+	for _, f := range r.Findings {
+		got[f.Kind+" "+f.Package]++
+	}
+	for _, want := range []string{
+		KindStale + " chalk",              // root records ^4.0.0
+		KindUnsatisfied + " chalk",        // 4.1.2 vs ^5.0.0, for the manifest and for left-pad
+		KindIntegrityMissing + " tap",     //
+		KindIntegrityMalformed + " chalk", // top-level sha1-abc
+		KindIntegrityWeak + " chalk",      // nested sha1 only
+		KindRegistry + " chalk",           //
+		KindConflict + " chalk",           // 4.1.2 with two hashes
+	} {
+		if got[want] == 0 {
+			t.Errorf("missing finding %q in %v", want, got)
+		}
+	}
+	if got[KindUnsatisfied+" chalk"] != 2 {
+		t.Errorf("unsatisfied chalk = %d, want manifest and left-pad", got[KindUnsatisfied+" chalk"])
+	}
+	for k := range got {
+		if strings.HasSuffix(k, " local") || strings.HasSuffix(k, " left-pad") {
+			t.Errorf("unexpected finding %q", k)
+		}
+	}
+}
+
+func TestGoSum(t *testing.T) {
+	sum := "golang.org/x/mod v0.17.0 h1:zY54UmvipHiNd+pm+m0x9KhZ9hl1/7QNMyxXbc6ICqA=\n" +
+		"golang.org/x/mod v0.17.0/go.mod h1:hTbmBsO62+eylJbnUtE2MGJUyE7QWk4xUqPFrRgJ+7c=\n" +
+		"golang.org/x/mod v0.17.0/go.mod h1:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=\n" +
+		"golang.org/x/sys v0.20.0/go.mod h1:not-base64\n" +
+		"broken line\n"
+	lf, err := Parse("go.sum", []byte(sum))
+	if err != nil {
+		t.Fatal(err)
+	}
+	man, err := ParseManifest("go.sum", "go.mod", []byte("module x\n\nrequire (\n\tgolang.org/x/mod v0.17.0\n\tgolang.org/x/sys v0.21.0 // indirect\n)\nrequire golang.org/x/text v0.15.0\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &Report{}
+	r.Add(lf, man, Config{})
+	for _, k := range []string{KindConflict, KindMalformed, KindIntegrityMalformed} {
+		if r.Counts[k] != 1 {
+			t.Errorf("%s: %d (%s)", k, r.Counts[k], r.Summary())
+		}
+	}
+	if r.Counts[KindMissing] != 2 { // x/sys v0.21.0 and x/text
+		t.Errorf("missing: %d (%+v)", r.Counts[KindMissing], r.Findings)
+	}
+	if r.Lockfiles[0].Direct != 1 {
+		t.Errorf("direct: %+v", r.Lockfiles[0])
+	}
+}
+
+const pyproject = `
+[tool.poetry]
+name = "svc"
+
+[tool.poetry.dependencies]
+python = "^3.11"
+requests = { version = "^2.31", extras = ["socks"] }
+"ruamel.yaml" = ">=0.18,<0.19"
+internal-lib = { path = "../lib", develop = true }
+
+[tool.poetry.group.dev.dependencies]
+pytest = "~8.1"
+`
+
+const poetryLock = `# This file is automatically @generated by Poetry and should not be changed by hand.
+
+[[package]]
+name = "requests"
+version = "2.32.3"
+description = "Python HTTP for Humans."
+optional = false
+python-versions = ">=3.8"
+files = [
+    {file = "requests-2.32.3-py3-none-any.whl", hash = "sha256:70761cfe03c773ceb22aa2f671b4757976145175cdfca038c02654d061d6dcc6"},
+]
+
+[package.dependencies]
+urllib3 = ">=1.21.1,<3"
+
+[[package]]
+name = "ruamel-yaml"
+version = "0.17.40"
+description = """Multi-line
+description"""
+files = [
+    {file = "ruamel.yaml-0.17.40.tar.gz", hash = "sha256:6024b986f06765d482b5b07e086cc4b4cd05dd22ddcbc758fa23d54873cf313d"},
+]
+
+[[package]]
+name = "pytest"
+version = "8.1.1"
+files = []
+
+[[package]]
+name = "urllib3"
+version = "2.2.1"
+files = [
+    {file = "urllib3-2.2.1.tar.gz", hash = "sha256:deadbeef"},
+]
+
+[package.source]
+type = "legacy"
+url = "https://pypi.mirror.example/simple"
+reference = "mirror"
+
+[[package]]
+name = "internal-lib"
+version = "0.1.0"
+files = []
+
+[package.source]
+type = "directory"
+url = "../lib"
+
+[metadata]
+lock-version = "2.0"
+python-versions = "^3.11"
+content-hash = "abc"
+`
+
+func TestPoetry(t *testing.T) {
+	lf, err := Parse("poetry.lock", []byte(poetryLock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	man, err := ParseManifest("poetry.lock", "pyproject.toml", []byte(pyproject))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if man.Requires["ruamel-yaml"] != ">=0.18,<0.19" || man.Requires["internal-lib"] != "path:../lib" || !man.Dev["pytest"] {
+		t.Fatalf("pyproject: %+v", man)
+	}
+	r := &Report{}
+	r.Add(lf, man, Config{})
+	want := map[string]string{
+		"ruamel-yaml": KindUnsatisfied,
+		"pytest":      KindIntegrityMissing,
+		"urllib3":     KindIntegrityMalformed,
+	}
+	got := map[string][]string{}
+	for _, f := range r.Findings {
+		got[f.Package] = append(got[f.Package], f.Kind)
+	}
+	for p, k := range want {
+		if !strings.Contains(strings.Join(got[p], " "), k) {
+			t.Errorf("%s: %v, want %s", p, got[p], k)
+		}
+	}
+	if !strings.Contains(strings.Join(got["urllib3"], " "), KindRegistry) {
+		t.Errorf("urllib3 mirror not flagged: %v", got["urllib3"])
+	}
+	if len(got["internal-lib"]) != 0 || len(got["requests"]) != 0 {
+		t.Errorf("unexpected findings: %v", got)
+	}
+	if r.Lockfiles[0].Direct != 4 {
+		t.Errorf("direct: %+v", r.Lockfiles[0])
+	}
+}
+
+func TestFind(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "go.sum"), nil, 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "poetry.lock"), nil, 0o644)
+	if got := Find(dir); len(got) != 2 || filepath.Base(got[0]) != "go.sum" {
+		t.Fatalf("Find = %v", got)
+	}
+	if _, err := Load(filepath.Join(dir, "yarn.lock")); err == nil {
+		t.Fatal("yarn.lock should be rejected")
+	}
+}
+
+const baseLock = `{
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"dependencies": {"express": "^4.18.0", "lodash": "^4.17.0"}},
+    "node_modules/express": {"version": "4.18.2", "dependencies": {"debug": "2.6.9"}},
+    "node_modules/debug": {"version": "2.6.9"},
+    "node_modules/lodash": {"version": "4.17.20"}
+  }
+}`
+
+const headLock = `{
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"dependencies": {"express": "^4.18.0", "lodash": "^4.17.0", "axois": "^1.0.0", "left-pad": "^1.3.0"}},
+    "node_modules/express": {"version": "4.18.2", "dependencies": {"debug": "2.6.9"}},
+    "node_modules/debug": {"version": "2.6.9"},
+    "node_modules/lodash": {"version": "4.17.21"},
+    "node_modules/axois": {"version": "1.0.1", "dependencies": {"follow-redirects": "^1.15.0", "debug": "*"}},
+    "node_modules/follow-redirects": {"version": "1.15.6"},
+    "node_modules/left-pad": {"version": "1.3.0"}
+  }
+}`
+
+func TestDiffAndReview(t *testing.T) {
+	base, err := Parse("package-lock.json", []byte(baseLock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := Parse("package-lock.json", []byte(headLock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := Diff(base, head)
+	var got []string
+	for _, c := range changes {
+		got = append(got, c.Kind+" "+c.Name+" "+strings.Join(c.To, ","))
+	}
+	want := "added axois 1.0.1|added follow-redirects 1.15.6|added left-pad 1.3.0|upgraded lodash 4.17.21"
+	if strings.Join(got, "|") != want {
+		t.Fatalf("Diff = %q", got)
+	}
+	if tr := changes[0].Transitive; len(tr) != 1 || tr[0] != "follow-redirects@1.15.6" {
+		t.Errorf("axois transitive = %v (debug is shared with express)", tr)
+	}
+
+	two := 1
+	cfg := ReviewConfig{
+		MaxNewDirect:      &two,
+		Banned:            []string{"npm:lodash@<4.17.21", "npm:left-pad"},
+		MinAgeDays:        14,
+		TyposquatDistance: 1,
+	}
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	meta := Metadata{NPM: {
+		"axois":            {"1.0.1": now.Add(-48 * time.Hour)},
+		"follow-redirects": {"1.15.6": now.AddDate(-1, 0, 0)},
+		"lodash":           {"4.17.21": now.AddDate(-4, 0, 0)},
+	}}
+	popular := Popular{NPM: {"axios", "express", "lodash", "ms"}}
+	r := Review(changes, cfg, meta, popular, now)
+	kinds := map[string]string{}
+	for _, f := range r.Findings {
+		kinds[f.Kind] += f.Package + " "
+	}
+	if kinds[KindTooManyDirect] != " " || kinds[KindBanned] != "left-pad " ||
+		kinds[KindTooNew] != "axois " || kinds[KindTyposquat] != "axois " {
+		t.Fatalf("findings: %+v", r.Findings)
+	}
+	if len(r.Unverified) != 1 || r.Unverified[0] != "npm:left-pad@1.3.0" {
+		t.Errorf("unverified: %v", r.Unverified)
+	}
+	if s := r.Summary; s.Added != 3 || s.NewDirect != 2 || s.NewTransitive != 1 || s.Upgraded != 1 {
+		t.Errorf("summary: %+v", s)
+	}
+
+	cfg.RequireMetadata = true
+	unknown := 0
+	for _, f := range Review(changes, cfg, meta, popular, now).Findings {
+		if f.Kind == KindAgeUnknown {
+			unknown++
+		}
+	}
+	if unknown != 1 {
+		t.Errorf("require_metadata: %d age_unknown findings", unknown)
+	}
+	if d := Diff(head, nil); len(d) != 6 || d[0].Kind != Removed {
+		t.Errorf("deleted lockfile: %+v", d)
+	}
+}
+
+func TestDistance(t *testing.T) {
+	for _, c := range []struct {
+		a, b string
+		want int
+	}{
+		{"axois", "axios", 1}, {"lodahs", "lodash", 1}, {"requets", "requests", 1},
+		{"expresss", "express", 1}, {"react", "preact", 1}, {"chalk", "chalk", 0}, {"colors", "kleur", 5},
+	} {
+		if got := distance(c.a, c.b); got != c.want {
+			t.Errorf("distance(%q, %q) = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package deps
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parseGoSum reads go.sum. Each module version becomes one package whose
+// integrity holds its module hash and its go.mod hash ("go.mod h1:...").
+// Two lines for the same module, version and kind with different hashes are
+// a conflict.
+func parseGoSum(path string, raw []byte) (*Lockfile, error) {
+	lf := &Lockfile{Packages: []Package{}}
+	index := map[string]int{}
+	seen := map[string]string{}
+	for n, line := range strings.Split(string(raw), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		f := strings.Fields(line)
+		if len(f) != 3 {
+			lf.Problems = append(lf.Problems, Finding{
+				File: path, Kind: KindMalformed, Detail: fmt.Sprintf("line %d: want \"<module> <version> <hash>\"", n+1),
+			})
+			continue
+		}
+		mod, ver, hash := f[0], f[1], f[2]
+		kind := ""
+		if v, ok := strings.CutSuffix(ver, "/go.mod"); ok {
+			ver, kind = v, "go.mod "
+		}
+		key := mod + " " + ver + " " + kind
+		if prev, dup := seen[key]; dup {
+			if prev != hash {
+				lf.Problems = append(lf.Problems, Finding{
+					File: path, Package: mod, Version: ver, Kind: KindConflict,
+					Detail: fmt.Sprintf("line %d: %shash %s differs from %s", n+1, kind, hash, prev),
+				})
+			}
+			continue
+		}
+		seen[key] = hash
+		i, ok := index[mod+"@"+ver]
+		if !ok {
+			i = len(lf.Packages)
+			index[mod+"@"+ver] = i
+			lf.Packages = append(lf.Packages, Package{Name: mod, Version: ver})
+		}
+		lf.Packages[i].Integrity = append(lf.Packages[i].Integrity, kind+hash)
+	}
+	return lf, nil
+}
+
+// parseGoMod reads the require directives of go.mod. Requirements marked
+// "// indirect" are not direct; replace directives are ignored.
+func parseGoMod(_ string, raw []byte) (*Manifest, error) {
+	m := &Manifest{Requires: map[string]string{}, Indirect: map[string]bool{}}
+	block := false
+	for _, line := range strings.Split(string(raw), "\n") {
+		code, comment, _ := strings.Cut(line, "//")
+		f := strings.Fields(code)
+		switch {
+		case block && len(f) == 1 && f[0] == ")":
+			block = false
+			continue
+		case len(f) >= 2 && f[0] == "require" && f[1] == "(":
+			block = true
+			continue
+		case len(f) == 3 && f[0] == "require":
+			f = f[1:]
+		case block && len(f) == 2:
+		default:
+			continue
+		}
+		m.Requires[f[0]] = f[1]
+		if strings.TrimSpace(comment) == "indirect" {
+			m.Indirect[f[0]] = true
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,165 @@
+// Package deps reads dependency manifests and lockfiles (npm
+// package-lock.json v2/v3, go.mod/go.sum, pyproject.toml/poetry.lock) into
+// one model and checks them offline: the lockfile must satisfy the
+// manifest, every package must carry a well-formed integrity hash and come
+// from an approved registry, and a package must not be locked to
+// conflicting contents.
+package deps
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Ecosystems.
+const (
+	NPM  = "npm"
+	Go   = "go"
+	PyPI = "pypi"
+)
+
+// Package is one locked package.
+type Package struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// Path is where the lockfile records the package (npm install path,
+	// e.g. "node_modules/a/node_modules/b"); "" for flat lockfiles.
+	Path      string   `json:"path,omitempty"`
+	Resolved  string   `json:"resolved,omitempty"`
+	Integrity []string `json:"integrity,omitempty"`
+	Direct    bool     `json:"direct"`
+	Dev       bool     `json:"dev,omitempty"`
+	// Local packages (workspace links, file: and directory sources) are
+	// not downloaded, so they have no registry or integrity.
+	Local bool `json:"local,omitempty"`
+	// Requires maps dependency names to the ranges this package declares.
+	Requires map[string]string `json:"requires,omitempty"`
+}
+
+// Key identifies a package version ("name@version").
+func (p Package) Key() string { return p.Name + "@" + p.Version }
+
+// Lockfile is a parsed lockfile.
+type Lockfile struct {
+	Path      string    `json:"path"`
+	Ecosystem string    `json:"ecosystem"`
+	Packages  []Package `json:"packages"`
+	// Root is the manifest's dependencies as the lockfile recorded them
+	// (npm's "" package); nil when the format does not record them.
+	Root map[string]string `json:"-"`
+	// Problems are entries that could not be parsed.
+	Problems []Finding `json:"-"`
+}
+
+// Manifest is the dependency declaration a lockfile resolves.
+type Manifest struct {
+	Path      string            `json:"path"`
+	Ecosystem string            `json:"ecosystem"`
+	Requires  map[string]string `json:"requires"`
+	Dev       map[string]bool   `json:"dev,omitempty"`
+	// Indirect requirements (go.mod "// indirect") are recorded for the
+	// build list, not imported.
+	Indirect map[string]bool `json:"indirect,omitempty"`
+}
+
+// Lockfile names and the manifest each one resolves.
+var lockfiles = map[string]struct {
+	ecosystem, manifest string
+	parse               func(path string, raw []byte) (*Lockfile, error)
+	parseManifest       func(path string, raw []byte) (*Manifest, error)
+}{
+	"package-lock.json": {NPM, "package.json", parsePackageLock, parsePackageJSON},
+	"go.sum":            {Go, "go.mod", parseGoSum, parseGoMod},
+	"poetry.lock":       {PyPI, "pyproject.toml", parsePoetryLock, parsePyproject},
+}
+
+// Names returns the lockfile names Load understands, sorted.
+func Names() []string {
+	var out []string
+	for n := range lockfiles {
+		out = append(out, n)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Load parses the lockfile at path; the format follows the file name.
+func Load(path string) (*Lockfile, error) {
+	if _, ok := lockfiles[filepath.Base(path)]; !ok {
+		return nil, fmt.Errorf("%s: unknown lockfile (want one of %s)", path, strings.Join(Names(), ", "))
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(path, raw)
+}
+
+// Parse parses lockfile content; path names the format.
+func Parse(path string, raw []byte) (*Lockfile, error) {
+	kind, ok := lockfiles[filepath.Base(path)]
+	if !ok {
+		return nil, fmt.Errorf("%s: unknown lockfile", path)
+	}
+	lf, err := kind.parse(path, raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	lf.Path, lf.Ecosystem = path, kind.ecosystem
+	sort.SliceStable(lf.Packages, func(i, j int) bool {
+		a, b := lf.Packages[i], lf.Packages[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Path < b.Path
+	})
+	return lf, nil
+}
+
+// ManifestFor returns the path of the manifest a lockfile resolves.
+func ManifestFor(lockPath string) string {
+	return filepath.Join(filepath.Dir(lockPath), lockfiles[filepath.Base(lockPath)].manifest)
+}
+
+// LoadManifest reads the manifest next to a lockfile. It returns nil, nil
+// when there is none.
+func LoadManifest(lockPath string) (*Manifest, error) {
+	_, ok := lockfiles[filepath.Base(lockPath)]
+	if !ok {
+		return nil, fmt.Errorf("%s: unknown lockfile", lockPath)
+	}
+	p := ManifestFor(lockPath)
+	raw, err := os.ReadFile(p)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ParseManifest(lockPath, p, raw)
+}
+
+// ParseManifest parses the manifest content for the lockfile at lockPath.
+func ParseManifest(lockPath, path string, raw []byte) (*Manifest, error) {
+	kind := lockfiles[filepath.Base(lockPath)]
+	m, err := kind.parseManifest(path, raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	m.Path, m.Ecosystem = path, kind.ecosystem
+	return m, nil
+}
+
+// Find returns the lockfiles directly in dir, sorted.
+func Find(dir string) []string {
+	var out []string
+	for _, n := range Names() {
+		if _, err := os.Stat(filepath.Join(dir, n)); err == nil {
+			out = append(out, filepath.Join(dir, n))
+		}
+	}
+	return out
+}
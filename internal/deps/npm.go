@@ -0,0 +1,113 @@
+package deps
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type npmPackage struct {
+	Name                 string            `json:"name"`
+	Version              string            `json:"version"`
+	Resolved             string            `json:"resolved"`
+	Integrity            string            `json:"integrity"`
+	Link                 bool              `json:"link"`
+	Dev                  bool              `json:"dev"`
+	Dependencies         map[string]string `json:"dependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+}
+
+// parsePackageLock reads lockfileVersion 2 and 3 (the "packages" map).
+func parsePackageLock(_ string, raw []byte) (*Lockfile, error) {
+	var doc struct {
+		LockfileVersion int                   `json:"lockfileVersion"`
+		Packages        map[string]npmPackage `json:"packages"`
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	if doc.LockfileVersion < 2 || doc.Packages == nil {
+		return nil, fmt.Errorf("lockfileVersion %d has no \"packages\" map; regenerate with npm 7 or later", doc.LockfileVersion)
+	}
+	lf := &Lockfile{Packages: []Package{}}
+	root := doc.Packages[""]
+	lf.Root = merge(root.Dependencies, root.DevDependencies, root.OptionalDependencies)
+	for path, p := range doc.Packages {
+		if path == "" || !strings.Contains(path, "node_modules/") {
+			continue // the root and workspace sources
+		}
+		name := p.Name
+		if name == "" {
+			name = path[strings.LastIndex(path, "node_modules/")+len("node_modules/"):]
+		}
+		pkg := Package{
+			Name: name, Version: p.Version, Path: path, Resolved: p.Resolved,
+			Dev: p.Dev, Local: p.Link || strings.HasPrefix(p.Resolved, "file:"),
+			Requires: merge(p.Dependencies, p.OptionalDependencies),
+			Direct:   strings.Count(path, "node_modules/") == 1 && lf.Root[alias(path)] != "",
+		}
+		if p.Integrity != "" {
+			pkg.Integrity = strings.Fields(p.Integrity)
+		}
+		lf.Packages = append(lf.Packages, pkg)
+	}
+	return lf, nil
+}
+
+// alias is the name a package is installed under (its node_modules
+// folder), which differs from its package name for npm: aliases.
+func alias(path string) string {
+	return path[strings.LastIndex(path, "node_modules/")+len("node_modules/"):]
+}
+
+func parsePackageJSON(_ string, raw []byte) (*Manifest, error) {
+	var doc struct {
+		Dependencies         map[string]string `json:"dependencies"`
+		DevDependencies      map[string]string `json:"devDependencies"`
+		OptionalDependencies map[string]string `json:"optionalDependencies"`
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	m := &Manifest{Requires: merge(doc.Dependencies, doc.OptionalDependencies, doc.DevDependencies), Dev: map[string]bool{}}
+	for n := range doc.DevDependencies {
+		m.Dev[n] = true
+	}
+	return m, nil
+}
+
+// resolveNPM finds the package a dependency of the package at from
+// resolves to, following Node's lookup: from/node_modules/name, then each
+// ancestor's node_modules, then the top level.
+func resolveNPM(byPath map[string]*Package, from, name string) *Package {
+	dir := from
+	for {
+		cand := "node_modules/" + name
+		if dir != "" {
+			cand = dir + "/node_modules/" + name
+		}
+		if p := byPath[cand]; p != nil {
+			return p
+		}
+		if dir == "" {
+			return nil
+		}
+		i := strings.LastIndex(dir, "/node_modules/")
+		if i < 0 {
+			dir = ""
+		} else {
+			dir = dir[:i]
+		}
+	}
+}
+
+func merge(maps ...map[string]string) map[string]string {
+	out := map[string]string{}
+	for _, m := range maps {
+		for k, v := range m {
+			out[k] = v
+		}
+	}
+	return out
+}
@@ -0,0 +1,160 @@
+package deps
+
+import (
+	"regexp"
+	"strings"
+)
+
+// parsePoetryLock reads poetry.lock (lock-version 1.x and 2.x): one
+// [[package]] per locked distribution, file hashes inline or, in older
+// files, under [metadata.files].
+func parsePoetryLock(_ string, raw []byte) (*Lockfile, error) {
+	doc, err := parseTOML(string(raw))
+	if err != nil {
+		return nil, err
+	}
+	var legacyFiles map[string]any
+	if meta, ok := doc["metadata"].(map[string]any); ok {
+		legacyFiles, _ = meta["files"].(map[string]any)
+	}
+	lf := &Lockfile{Packages: []Package{}}
+	pkgs, _ := doc["package"].([]any)
+	for _, raw := range pkgs {
+		t, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		p := Package{Name: normalizePyPI(str(t["name"])), Version: str(t["version"]), Requires: map[string]string{}}
+		cat := str(t["category"])
+		p.Dev = cat == "dev"
+		files, _ := t["files"].([]any)
+		if files == nil && legacyFiles != nil {
+			files, _ = legacyFiles[str(t["name"])].([]any)
+		}
+		for _, f := range files {
+			if fm, ok := f.(map[string]any); ok {
+				p.Integrity = append(p.Integrity, str(fm["hash"]))
+			}
+		}
+		if src, ok := t["source"].(map[string]any); ok {
+			switch str(src["type"]) {
+			case "directory", "file":
+				p.Local = true
+				p.Resolved = str(src["url"])
+			default:
+				p.Resolved = str(src["url"])
+			}
+		}
+		if ds, ok := t["dependencies"].(map[string]any); ok {
+			for name, spec := range ds {
+				p.Requires[normalizePyPI(name)] = constraint(spec)
+			}
+		}
+		lf.Packages = append(lf.Packages, p)
+	}
+	return lf, nil
+}
+
+// parsePyproject reads Poetry dependency tables ([tool.poetry.dependencies],
+// dev-dependencies and groups) and PEP 621 [project] dependencies.
+func parsePyproject(_ string, raw []byte) (*Manifest, error) {
+	doc, err := parseTOML(string(raw))
+	if err != nil {
+		return nil, err
+	}
+	m := &Manifest{Requires: map[string]string{}, Dev: map[string]bool{}}
+	add := func(tbl any, dev bool) {
+		deps, _ := tbl.(map[string]any)
+		for name, spec := range deps {
+			if name == "python" {
+				continue
+			}
+			n := normalizePyPI(name)
+			m.Requires[n] = constraint(spec)
+			if dev {
+				m.Dev[n] = true
+			}
+		}
+	}
+	poetry := lookup(doc, "tool", "poetry")
+	add(lookup(poetry, "dependencies"), false)
+	add(lookup(poetry, "dev-dependencies"), true)
+	if groups, ok := lookup(poetry, "group").(map[string]any); ok {
+		for _, g := range groups {
+			add(lookup(g, "dependencies"), true)
+		}
+	}
+	if list, ok := lookup(doc, "project", "dependencies").([]any); ok {
+		for _, req := range list {
+			if name, spec, ok := parsePEP508(str(req)); ok {
+				m.Requires[name] = spec
+			}
+		}
+	}
+	return m, nil
+}
+
+var pep508 = regexp.MustCompile(`^\s*([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[[^\]]*\])?\s*\(?([^;()]*)\)?`)
+
+// parsePEP508 splits "requests[socks] >=2.31,<3 ; python_version>'3.8'"
+// into its normalized name and version specifier ("*" when there is none).
+func parsePEP508(s string) (string, string, bool) {
+	m := pep508.FindStringSubmatch(s)
+	if m == nil {
+		return "", "", false
+	}
+	spec := strings.TrimSpace(m[2])
+	if spec == "" {
+		spec = "*"
+	}
+	return normalizePyPI(m[1]), spec, true
+}
+
+// constraint returns the version constraint of a Poetry dependency spec: a
+// string, or a table with "version" (git, path and url specs have none).
+func constraint(spec any) string {
+	switch s := spec.(type) {
+	case string:
+		return s
+	case map[string]any:
+		if v := str(s["version"]); v != "" {
+			return v
+		}
+		for _, k := range []string{"git", "path", "url"} {
+			if v := str(s[k]); v != "" {
+				return k + ":" + v
+			}
+		}
+	case []any:
+		// Multiple constraints by marker: any of them.
+		var alts []string
+		for _, x := range s {
+			alts = append(alts, constraint(x))
+		}
+		return strings.Join(alts, " || ")
+	}
+	return "*"
+}
+
+var pypiSep = regexp.MustCompile(`[-_.]+`)
+
+// normalizePyPI applies PEP 503 name normalization.
+func normalizePyPI(name string) string {
+	return pypiSep.ReplaceAllString(strings.ToLower(name), "-")
+}
+
+func lookup(v any, keys ...string) any {
+	for _, k := range keys {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = m[k]
+	}
+	return v
+}
+
+func str(v any) string {
+	s, _ := v.(string)
+	return s
+}
@@ -0,0 +1,495 @@
+package deps
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Change kinds.
+const (
+	Added      = "added"
+	Removed    = "removed"
+	Upgraded   = "upgraded"
+	Downgraded = "downgraded"
+	// Changed: the highest version is the same but the set of locked
+	// versions differs (a second copy was nested or dropped).
+	Changed = "changed"
+)
+
+// Change is one package whose locked versions differ between two
+// lockfiles.
+type Change struct {
+	File      string   `json:"file"`
+	Ecosystem string   `json:"ecosystem"`
+	Name      string   `json:"name"`
+	Kind      string   `json:"kind"`
+	From      []string `json:"from,omitempty"`
+	To        []string `json:"to,omitempty"`
+	Direct    bool     `json:"direct"`
+	Dev       bool     `json:"dev,omitempty"`
+	// Transitive lists the packages added by this PR that are only reachable
+	// through this one ("name@version"); empty for Go, whose go.sum has no
+	// dependency graph.
+	Transitive []string `json:"transitive,omitempty"`
+}
+
+// Introduced returns the versions the change brings in.
+func (c Change) Introduced() []string {
+	var out []string
+	for _, v := range c.To {
+		if !contains(c.From, v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// Diff compares the packages of two lockfiles for the same path. A nil
+// base means the lockfile is new, a nil head that it was deleted.
+func Diff(base, head *Lockfile) []Change {
+	file, eco := "", ""
+	for _, lf := range []*Lockfile{head, base} {
+		if lf != nil {
+			file, eco = lf.Path, lf.Ecosystem
+			break
+		}
+	}
+	before, after := versions(base), versions(head)
+	names := map[string]bool{}
+	for n := range before {
+		names[n] = true
+	}
+	for n := range after {
+		names[n] = true
+	}
+	var out []Change
+	for _, n := range sortedKeys(names) {
+		from, to := before[n].list(), after[n].list()
+		if strings.Join(from, ",") == strings.Join(to, ",") {
+			continue
+		}
+		c := Change{File: file, Ecosystem: eco, Name: n, From: from, To: to}
+		info := after[n]
+		switch {
+		case len(from) == 0:
+			c.Kind = Added
+		case len(to) == 0:
+			c.Kind, info = Removed, before[n]
+		default:
+			switch sign(compareVersions(to[len(to)-1], from[len(from)-1])) {
+			case 1:
+				c.Kind = Upgraded
+			case -1:
+				c.Kind = Downgraded
+			default:
+				c.Kind = Changed
+			}
+		}
+		c.Direct, c.Dev = info.direct, info.dev
+		out = append(out, c)
+	}
+	attributeTransitive(out, head)
+	return out
+}
+
+type versionSet struct {
+	set         map[string]bool
+	direct, dev bool
+}
+
+func (s versionSet) list() []string {
+	out := sortedKeys(s.set)
+	sort.SliceStable(out, func(i, j int) bool { return compareVersions(out[i], out[j]) < 0 })
+	return out
+}
+
+func versions(lf *Lockfile) map[string]versionSet {
+	out := map[string]versionSet{}
+	if lf == nil {
+		return out
+	}
+	for _, p := range lf.Packages {
+		s, ok := out[p.Name]
+		if !ok {
+			s = versionSet{set: map[string]bool{}, dev: true}
+		}
+		s.set[p.Version] = true
+		s.direct = s.direct || p.Direct
+		s.dev = s.dev && p.Dev
+		out[p.Name] = s
+	}
+	return out
+}
+
+// compareVersions orders parsable versions by precedence and the rest as
+// strings.
+func compareVersions(a, b string) int {
+	va, errA := ParseVersion(a)
+	vb, errB := ParseVersion(b)
+	if errA != nil || errB != nil {
+		return strings.Compare(a, b)
+	}
+	return va.Compare(vb)
+}
+
+// attributeTransitive fills Transitive on direct changes: the added
+// packages reachable from the changed package in head and from no
+// unchanged direct package.
+func attributeTransitive(changes []Change, head *Lockfile) {
+	if head == nil || head.Ecosystem == Go {
+		return
+	}
+	added := map[string]bool{}
+	changed := map[string]bool{}
+	for _, c := range changes {
+		if c.Kind == Added {
+			added[c.Name] = true
+		}
+		changed[c.Name] = true
+	}
+	g := graphOf(head)
+	// Packages kept alive by unchanged direct dependencies are not this
+	// PR's doing.
+	shared := map[string]bool{}
+	for _, p := range head.Packages {
+		if p.Direct && !changed[p.Name] {
+			g.walk(p, shared)
+		}
+	}
+	for i := range changes {
+		c := &changes[i]
+		if !c.Direct || c.Kind == Removed {
+			continue
+		}
+		reach := map[string]bool{}
+		for _, p := range head.Packages {
+			if p.Name == c.Name && p.Direct {
+				g.walk(p, reach)
+			}
+		}
+		for key := range reach {
+			name := key[:strings.LastIndex(key, "@")]
+			if name != c.Name && added[name] && !shared[key] {
+				c.Transitive = append(c.Transitive, key)
+			}
+		}
+		sort.Strings(c.Transitive)
+	}
+}
+
+// graph resolves a package's dependencies within one lockfile.
+type graph struct {
+	lf     *Lockfile
+	byPath map[string]*Package
+	byName map[string]*Package
+}
+
+func graphOf(lf *Lockfile) *graph {
+	g := &graph{lf: lf, byPath: map[string]*Package{}, byName: map[string]*Package{}}
+	for i := range lf.Packages {
+		p := &lf.Packages[i]
+		g.byPath[p.Path] = p
+		if _, ok := g.byName[p.Name]; !ok {
+			g.byName[p.Name] = p
+		}
+	}
+	return g
+}
+
+func (g *graph) deps(p Package) []*Package {
+	var out []*Package
+	for _, n := range sortedKeys(p.Requires) {
+		var dep *Package
+		if g.lf.Ecosystem == NPM {
+			dep = resolveNPM(g.byPath, p.Path, n)
+		} else {
+			dep = g.byName[n]
+		}
+		if dep != nil {
+			out = append(out, dep)
+		}
+	}
+	return out
+}
+
+// walk adds every package reachable from p (including p) to seen, keyed by
+// "name@version".
+func (g *graph) walk(p Package, seen map[string]bool) {
+	if seen[p.Key()] {
+		return
+	}
+	seen[p.Key()] = true
+	for _, d := range g.deps(p) {
+		g.walk(*d, seen)
+	}
+}
+
+// Review finding kinds.
+const (
+	KindTooManyDirect = "too_many_direct"
+	KindBanned        = "banned"
+	KindTooNew        = "too_new"
+	KindAgeUnknown    = "age_unknown"
+	KindTyposquat     = "typosquat"
+)
+
+// ReviewConfig is the deps.review rule's policy.
+type ReviewConfig struct {
+	// MaxNewDirect caps the direct dependencies one PR may add; nil means
+	// no cap.
+	MaxNewDirect *int `json:"max_new_direct"`
+	// Banned entries are "[ecosystem:]name[@range]"; the name may be a glob
+	// ("@evil/*").
+	Banned []string `json:"banned"`
+	// MinAgeDays is how long a version must have been published before it
+	// may be introduced; 0 turns the check off.
+	MinAgeDays float64 `json:"min_age_days"`
+	// RequireMetadata fails versions the metadata cache does not know
+	// instead of listing them as unverified.
+	RequireMetadata bool `json:"require_metadata"`
+	// TyposquatDistance is the largest edit distance at which a new package
+	// name counts as a look-alike of a popular one; 0 turns the check off.
+	TyposquatDistance int `json:"typosquat_distance"`
+}
+
+// Metadata is the local package metadata cache: ecosystem → package →
+// version → publish time (the npm registry "time" field, PyPI
+// upload_time, the Go proxy .info Time).
+type Metadata map[string]map[string]map[string]time.Time
+
+// Popular is the typosquat allowlist: ecosystem → well-known names.
+type Popular map[string][]string
+
+// LoadMetadata reads a metadata cache (JSON or YAML). A missing file is an
+// empty cache.
+func LoadMetadata(p string) (Metadata, error) {
+	var m Metadata
+	return m, loadData(p, &m)
+}
+
+// LoadPopular reads a typosquat allowlist (JSON or YAML). A missing file is
+// an empty list.
+func LoadPopular(p string) (Popular, error) {
+	var m Popular
+	return m, loadData(p, &m)
+}
+
+func loadData(p string, dst any) error {
+	raw, err := os.ReadFile(p)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if filepath.Ext(p) == ".json" {
+		err = json.Unmarshal(raw, dst)
+	} else {
+		err = yaml.Unmarshal(raw, dst)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", p, err)
+	}
+	return nil
+}
+
+// ReviewSummary counts the changes of a review.
+type ReviewSummary struct {
+	Added         int `json:"added"`
+	Removed       int `json:"removed"`
+	Upgraded      int `json:"upgraded"`
+	Downgraded    int `json:"downgraded"`
+	NewDirect     int `json:"new_direct"`
+	NewTransitive int `json:"new_transitive"`
+}
+
+// ReviewReport is the result of reviewing lockfile changes.
+type ReviewReport struct {
+	Base     string        `json:"base"`
+	Head     string        `json:"head"`
+	Summary  ReviewSummary `json:"summary"`
+	Changes  []Change      `json:"changes"`
+	Findings []Finding     `json:"findings"`
+	// Unverified lists introduced versions the metadata cache has no
+	// publish time for.
+	Unverified []string `json:"unverified,omitempty"`
+}
+
+// Review applies the policy to lockfile changes.
+func Review(changes []Change, cfg ReviewConfig, meta Metadata, popular Popular, now time.Time) *ReviewReport {
+	r := &ReviewReport{Changes: changes, Findings: []Finding{}}
+	if r.Changes == nil {
+		r.Changes = []Change{}
+	}
+	var direct []string
+	for _, c := range changes {
+		switch c.Kind {
+		case Added:
+			r.Summary.Added++
+			if c.Direct {
+				r.Summary.NewDirect++
+				direct = append(direct, c.Name)
+			} else {
+				r.Summary.NewTransitive++
+			}
+		case Removed:
+			r.Summary.Removed++
+		case Upgraded:
+			r.Summary.Upgraded++
+		case Downgraded:
+			r.Summary.Downgraded++
+		}
+		for _, v := range c.Introduced() {
+			r.banned(c, v, cfg.Banned)
+			if cfg.MinAgeDays > 0 {
+				r.age(c, v, cfg, meta, now)
+			}
+		}
+		if c.Kind == Added && cfg.TyposquatDistance > 0 {
+			r.typosquat(c, popular[c.Ecosystem], cfg.TyposquatDistance)
+		}
+	}
+	if cfg.MaxNewDirect != nil && len(direct) > *cfg.MaxNewDirect {
+		r.Findings = append(r.Findings, Finding{Kind: KindTooManyDirect,
+			Detail: fmt.Sprintf("%d new direct dependencies (%s), at most %d per PR", len(direct), strings.Join(direct, ", "), *cfg.MaxNewDirect)})
+	}
+	return r
+}
+
+func (r *ReviewReport) find(c Change, v, kind, format string, args ...any) {
+	r.Findings = append(r.Findings, Finding{File: c.File, Package: c.Name, Version: v, Kind: kind, Detail: fmt.Sprintf(format, args...)})
+}
+
+func (r *ReviewReport) banned(c Change, v string, banned []string) {
+	for _, b := range banned {
+		eco, rest, ok := strings.Cut(b, ":")
+		if !ok || strings.ContainsAny(eco, "@/") {
+			eco, rest = "", b
+		}
+		name, rng := rest, ""
+		if i := strings.LastIndex(rest, "@"); i > 0 {
+			name, rng = rest[:i], rest[i+1:]
+		}
+		if eco != "" && eco != c.Ecosystem {
+			continue
+		}
+		if ok, _ := path.Match(name, c.Name); !ok {
+			continue
+		}
+		if rng != "" {
+			if ok, err := Satisfies(rng, v); err != nil || !ok {
+				continue
+			}
+		}
+		r.find(c, v, KindBanned, "%s@%s is banned by policy (%s)", c.Name, v, b)
+		return
+	}
+}
+
+func (r *ReviewReport) age(c Change, v string, cfg ReviewConfig, meta Metadata, now time.Time) {
+	published, ok := meta[c.Ecosystem][c.Name][v]
+	if !ok {
+		if cfg.RequireMetadata {
+			r.find(c, v, KindAgeUnknown, "%s@%s is not in the metadata cache", c.Name, v)
+		} else {
+			r.Unverified = append(r.Unverified, c.Ecosystem+":"+c.Name+"@"+v)
+		}
+		return
+	}
+	if days := now.Sub(published).Hours() / 24; days < cfg.MinAgeDays {
+		r.find(c, v, KindTooNew, "%s@%s was published %s (%.1f days ago), minimum age is %g days",
+			c.Name, v, published.UTC().Format(time.RFC3339), days, cfg.MinAgeDays)
+	}
+}
+
+func (r *ReviewReport) typosquat(c Change, popular []string, maxDist int) {
+	for _, p := range popular {
+		if p == c.Name {
+			return
+		}
+	}
+	for _, p := range popular {
+		// Short names are a few edits from each other anyway.
+		if len(p) < 4 {
+			continue
+		}
+		d := distance(c.Name, p)
+		if squash(c.Name) == squash(p) {
+			d = 0
+		}
+		if d <= maxDist {
+			r.find(c, firstOr(c.To, ""), KindTyposquat, "%s is %d edit(s) from the popular package %s", c.Name, d, p)
+			return
+		}
+	}
+}
+
+// squash drops case and separators, so "left_pad" and "Left-Pad" match.
+func squash(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || r == '.' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(s))
+}
+
+// distance is the optimal string alignment distance: insertions,
+// deletions, substitutions and adjacent transpositions.
+func distance(a, b string) int {
+	x, y := []rune(a), []rune(b)
+	d := make([][]int, len(x)+1)
+	for i := range d {
+		d[i] = make([]int, len(y)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(x); i++ {
+		for j := 1; j <= len(y); j++ {
+			cost := 1
+			if x[i-1] == y[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && x[i-1] == y[j-2] && x[i-2] == y[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(x)][len(y)]
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+func firstOr(list []string, def string) string {
+	if len(list) > 0 {
+		return list[0]
+	}
+	return def
+}
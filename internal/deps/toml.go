@@ -0,0 +1,328 @@
+package deps
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseTOML reads the TOML subset that poetry.lock and pyproject.toml use:
+// [tables], [[arrays of tables]], dotted and quoted keys, strings (basic,
+// literal and multi-line), numbers, booleans, arrays and inline tables.
+// Dates are kept as strings. The result uses map[string]any and []any like
+// encoding/json.
+func parseTOML(src string) (map[string]any, error) {
+	p := &tomlParser{src: src, line: 1}
+	root := map[string]any{}
+	cur := root
+	for {
+		p.skipSpace(true)
+		if p.eof() {
+			return root, nil
+		}
+		switch {
+		case strings.HasPrefix(p.rest(), "[["):
+			p.pos += 2
+			keys, err := p.keys("]]")
+			if err != nil {
+				return nil, err
+			}
+			parent, err := p.table(root, keys[:len(keys)-1])
+			if err != nil {
+				return nil, err
+			}
+			last := keys[len(keys)-1]
+			arr, _ := parent[last].([]any)
+			cur = map[string]any{}
+			parent[last] = append(arr, cur)
+		case p.peek() == '[':
+			p.pos++
+			keys, err := p.keys("]")
+			if err != nil {
+				return nil, err
+			}
+			if cur, err = p.table(root, keys); err != nil {
+				return nil, err
+			}
+		default:
+			if err := p.keyValue(cur); err != nil {
+				return nil, err
+			}
+		}
+		p.skipSpace(false)
+		if !p.eof() && p.peek() != '\n' {
+			return nil, p.errorf("unexpected %q after value", p.peek())
+		}
+	}
+}
+
+type tomlParser struct {
+	src  string
+	pos  int
+	line int
+}
+
+func (p *tomlParser) eof() bool    { return p.pos >= len(p.src) }
+func (p *tomlParser) peek() byte   { return p.src[p.pos] }
+func (p *tomlParser) rest() string { return p.src[p.pos:] }
+func (p *tomlParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("toml line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+// skipSpace skips blanks and comments, and newlines when nl is set.
+func (p *tomlParser) skipSpace(nl bool) {
+	for !p.eof() {
+		switch c := p.peek(); {
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+		case c == '\n' && nl:
+			p.line++
+			p.pos++
+		case c == '#':
+			for !p.eof() && p.peek() != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// table walks (creating as needed) to the table at keys. Arrays of tables
+// resolve to their last element.
+func (p *tomlParser) table(root map[string]any, keys []string) (map[string]any, error) {
+	cur := root
+	for _, k := range keys {
+		switch v := cur[k].(type) {
+		case nil:
+			next := map[string]any{}
+			cur[k] = next
+			cur = next
+		case map[string]any:
+			cur = v
+		case []any:
+			last, ok := v[len(v)-1].(map[string]any)
+			if !ok {
+				return nil, p.errorf("%s is not a table", k)
+			}
+			cur = last
+		default:
+			return nil, p.errorf("%s is not a table", k)
+		}
+	}
+	return cur, nil
+}
+
+// keys reads a dotted key up to end.
+func (p *tomlParser) keys(end string) ([]string, error) {
+	var keys []string
+	for {
+		p.skipSpace(false)
+		k, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+		p.skipSpace(false)
+		if strings.HasPrefix(p.rest(), end) {
+			p.pos += len(end)
+			return keys, nil
+		}
+		if p.eof() || p.peek() != '.' {
+			return nil, p.errorf("expected . or %s in key", end)
+		}
+		p.pos++
+	}
+}
+
+func (p *tomlParser) key() (string, error) {
+	if p.eof() {
+		return "", p.errorf("expected key")
+	}
+	if c := p.peek(); c == '"' || c == '\'' {
+		return p.str()
+	}
+	start := p.pos
+	for !p.eof() {
+		c := p.peek()
+		if !(c == '-' || c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z') {
+			break
+		}
+		p.pos++
+	}
+	if p.pos == start {
+		return "", p.errorf("expected key, got %q", p.peek())
+	}
+	return p.src[start:p.pos], nil
+}
+
+func (p *tomlParser) keyValue(dst map[string]any) error {
+	keys, err := p.keys("=")
+	if err != nil {
+		return err
+	}
+	t, err := p.table(dst, keys[:len(keys)-1])
+	if err != nil {
+		return err
+	}
+	p.skipSpace(false)
+	v, err := p.value()
+	if err != nil {
+		return err
+	}
+	t[keys[len(keys)-1]] = v
+	return nil
+}
+
+func (p *tomlParser) value() (any, error) {
+	if p.eof() {
+		return nil, p.errorf("expected value")
+	}
+	switch c := p.peek(); {
+	case c == '"' || c == '\'':
+		return p.str()
+	case c == '[':
+		p.pos++
+		arr := []any{}
+		for {
+			p.skipSpace(true)
+			if p.eof() {
+				return nil, p.errorf("unterminated array")
+			}
+			if p.peek() == ']' {
+				p.pos++
+				return arr, nil
+			}
+			v, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+			p.skipSpace(true)
+			if !p.eof() && p.peek() == ',' {
+				p.pos++
+			}
+		}
+	case c == '{':
+		p.pos++
+		tbl := map[string]any{}
+		for {
+			p.skipSpace(false)
+			if p.eof() {
+				return nil, p.errorf("unterminated inline table")
+			}
+			if p.peek() == '}' {
+				p.pos++
+				return tbl, nil
+			}
+			if err := p.keyValue(tbl); err != nil {
+				return nil, err
+			}
+			p.skipSpace(false)
+			if !p.eof() && p.peek() == ',' {
+				p.pos++
+			}
+		}
+	}
+	start := p.pos
+	for !p.eof() && !strings.ContainsRune(",]}\n#", rune(p.peek())) {
+		p.pos++
+	}
+	raw := strings.TrimSpace(p.src[start:p.pos])
+	switch raw {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	if i, err := strconv.ParseInt(strings.ReplaceAll(raw, "_", ""), 0, 64); err == nil {
+		return float64(i), nil
+	}
+	if f, err := strconv.ParseFloat(strings.ReplaceAll(raw, "_", ""), 64); err == nil {
+		return f, nil
+	}
+	if raw == "" {
+		return nil, p.errorf("expected value")
+	}
+	return raw, nil // dates and times
+}
+
+func (p *tomlParser) str() (string, error) {
+	q := p.peek()
+	multi := strings.HasPrefix(p.rest(), strings.Repeat(string(q), 3))
+	if multi {
+		p.pos += 3
+		if !p.eof() && p.peek() == '\n' {
+			p.line++
+			p.pos++
+		}
+	} else {
+		p.pos++
+	}
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.peek()
+		switch {
+		case multi && strings.HasPrefix(p.rest(), strings.Repeat(string(q), 3)):
+			p.pos += 3
+			return b.String(), nil
+		case !multi && c == q:
+			p.pos++
+			return b.String(), nil
+		case !multi && c == '\n':
+			return "", p.errorf("newline in string")
+		case c == '\\' && q == '"':
+			p.pos++
+			if p.eof() {
+				return "", p.errorf("unterminated escape")
+			}
+			e := p.peek()
+			p.pos++
+			switch e {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			case '"', '\\':
+				b.WriteByte(e)
+			case 'u', 'U':
+				n := 4
+				if e == 'U' {
+					n = 8
+				}
+				if p.pos+n > len(p.src) {
+					return "", p.errorf("short unicode escape")
+				}
+				r, err := strconv.ParseUint(p.src[p.pos:p.pos+n], 16, 32)
+				if err != nil {
+					return "", p.errorf("bad unicode escape")
+				}
+				b.WriteRune(rune(r))
+				p.pos += n
+			case '\n':
+				// Line-ending backslash in a multi-line string trims the
+				// newline and leading whitespace.
+				p.line++
+				for !p.eof() && strings.ContainsRune(" \t\r\n", rune(p.peek())) {
+					if p.peek() == '\n' {
+						p.line++
+					}
+					p.pos++
+				}
+			default:
+				return "", p.errorf("bad escape \\%c", e)
+			}
+		default:
+			if c == '\n' {
+				p.line++
+			}
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+}
@@ -0,0 +1,338 @@
+package deps
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a dotted release number with an optional pre-release tag. It
+// covers SemVer (1.2.3-rc.1+build) and the common PEP 440 forms (1.2,
+// 2.0.0rc1, 1.0.post1); Go module versions are SemVer with a "v" prefix.
+type Version struct {
+	Release []int
+	// Pre is the pre-release tag ("rc.1", "rc1", "dev0"); pre-releases sort
+	// before the release. Post-releases ("post1") sort after it.
+	Pre  string
+	Post int
+}
+
+// ParseVersion parses a version; a leading "v" or "=" is ignored.
+func ParseVersion(s string) (Version, error) {
+	raw := s
+	s = strings.TrimLeft(strings.TrimSpace(s), "v=")
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s = s[:i]
+	}
+	var v Version
+	if i := strings.Index(s, ".post"); i >= 0 {
+		n, err := strconv.Atoi(s[i+5:])
+		if err != nil {
+			return v, fmt.Errorf("invalid version %q", raw)
+		}
+		v.Post, s = n+1, s[:i]
+	}
+	end := 0
+	for end < len(s) && (s[end] >= '0' && s[end] <= '9' || s[end] == '.') {
+		end++
+	}
+	nums, rest := strings.TrimSuffix(s[:end], "."), s[end:]
+	if nums == "" {
+		return v, fmt.Errorf("invalid version %q", raw)
+	}
+	for _, part := range strings.Split(nums, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return v, fmt.Errorf("invalid version %q", raw)
+		}
+		v.Release = append(v.Release, n)
+	}
+	v.Pre = strings.TrimLeft(rest, "-.")
+	return v, nil
+}
+
+func (v Version) at(i int) int {
+	if i < len(v.Release) {
+		return v.Release[i]
+	}
+	return 0
+}
+
+// Compare returns -1, 0 or 1.
+func (v Version) Compare(o Version) int {
+	n := len(v.Release)
+	if len(o.Release) > n {
+		n = len(o.Release)
+	}
+	for i := 0; i < n; i++ {
+		if a, b := v.at(i), o.at(i); a != b {
+			return sign(a - b)
+		}
+	}
+	switch {
+	case v.Pre == o.Pre:
+	case v.Pre == "":
+		return 1
+	case o.Pre == "":
+		return -1
+	default:
+		return comparePre(v.Pre, o.Pre)
+	}
+	return sign(v.Post - o.Post)
+}
+
+// comparePre orders pre-release tags SemVer-style: dot-separated
+// identifiers, numeric ones numerically.
+func comparePre(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				return sign(an - bn)
+			}
+		case as[i] != bs[i]:
+			if as[i] < bs[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return sign(len(as) - len(bs))
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+func (v Version) String() string {
+	parts := make([]string, len(v.Release))
+	for i, n := range v.Release {
+		parts[i] = strconv.Itoa(n)
+	}
+	s := strings.Join(parts, ".")
+	if v.Pre != "" {
+		s += "-" + v.Pre
+	}
+	return s
+}
+
+// ErrUnverifiable marks ranges that are not version constraints (git URLs,
+// file: and link: specs, dist-tags); the lockfile cannot be checked
+// against them offline.
+var ErrUnverifiable = fmt.Errorf("not a version range")
+
+type comparator struct {
+	op string
+	v  Version
+}
+
+func (c comparator) ok(v Version) bool {
+	d := v.Compare(c.v)
+	switch c.op {
+	case ">":
+		return d > 0
+	case ">=":
+		return d >= 0
+	case "<":
+		return d < 0
+	case "<=":
+		return d <= 0
+	case "!=":
+		return d != 0
+	}
+	return d == 0
+}
+
+// Satisfies reports whether version meets the range. It accepts npm ranges
+// (^1.2.3, ~1.2, 1.x, >=1 <2, a - b, ||) and Poetry/PEP 440 constraints
+// (^1.2, ~=1.2, >=1,<2, ==1.2.*, !=1.3).
+func Satisfies(rng, version string) (bool, error) {
+	v, err := ParseVersion(version)
+	if err != nil {
+		return false, err
+	}
+	rng = strings.TrimSpace(rng)
+	if strings.ContainsAny(rng, ":/#") || rng == "latest" || rng == "next" {
+		return false, ErrUnverifiable
+	}
+	for _, alt := range strings.Split(strings.ReplaceAll(rng, "||", "|"), "|") {
+		set, err := parseSet(alt)
+		if err != nil {
+			return false, err
+		}
+		if setOK(set, v) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// setOK: every comparator matches, and a pre-release only matches when a
+// comparator names a pre-release of the same release (npm semantics).
+func setOK(set []comparator, v Version) bool {
+	preAllowed := v.Pre == ""
+	for _, c := range set {
+		if !c.ok(v) {
+			return false
+		}
+		if c.v.Pre != "" && (Version{Release: c.v.Release}).Compare(Version{Release: v.Release}) == 0 {
+			preAllowed = true
+		}
+	}
+	return preAllowed
+}
+
+func parseSet(s string) ([]comparator, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "*" || s == "x" || s == "X" {
+		return nil, nil
+	}
+	if a, b, ok := strings.Cut(s, " - "); ok {
+		lo, err := partial(a)
+		if err != nil {
+			return nil, err
+		}
+		hi, err := partial(b)
+		if err != nil {
+			return nil, err
+		}
+		set := []comparator{{">=", lo.v}}
+		if hi.n < 3 {
+			return append(set, comparator{"<", hi.bump(hi.n - 1)}), nil
+		}
+		return append(set, comparator{"<=", hi.v}), nil
+	}
+	// Join operators to their versions (">= 1.2" → ">=1.2") and split on
+	// commas and spaces.
+	for _, op := range []string{">=", "<=", "~=", "==", "!=", ">", "<", "^", "~", "="} {
+		s = strings.ReplaceAll(s, op+" ", op)
+	}
+	var set []comparator
+	for _, tok := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' }) {
+		cs, err := parseComparator(tok)
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, cs...)
+	}
+	return set, nil
+}
+
+// partialVersion is a version with n given components ("1.2" has n=2);
+// wildcard components count as missing.
+type partialVersion struct {
+	v    Version
+	n    int
+	wild bool
+}
+
+func partial(s string) (partialVersion, error) {
+	s = strings.TrimLeft(strings.TrimSpace(s), "v=")
+	parts := strings.SplitN(s, ".", 3)
+	n, wild := 0, false
+	for _, p := range parts {
+		if p == "*" || p == "x" || p == "X" {
+			wild = true
+			break
+		}
+		n++
+	}
+	if n == 0 {
+		return partialVersion{wild: true}, nil
+	}
+	v, err := ParseVersion(strings.Join(parts[:n], "."))
+	if err != nil {
+		return partialVersion{}, fmt.Errorf("%w: %q", ErrUnverifiable, s)
+	}
+	if n == 3 || len(v.Release) > 3 {
+		n = len(v.Release)
+	}
+	return partialVersion{v: v, n: n, wild: wild}, nil
+}
+
+// bump increments component i and drops the rest (1.2.3 bump(0) → 2.0.0).
+func (p partialVersion) bump(i int) Version {
+	out := Version{Release: make([]int, i+1)}
+	for j := 0; j <= i; j++ {
+		out.Release[j] = p.v.at(j)
+	}
+	out.Release[i]++
+	return out
+}
+
+func parseComparator(tok string) ([]comparator, error) {
+	op := ""
+	for _, o := range []string{">=", "<=", "~=", "==", "!=", ">", "<", "^", "~", "="} {
+		if strings.HasPrefix(tok, o) {
+			op, tok = o, tok[len(o):]
+			break
+		}
+	}
+	p, err := partial(tok)
+	if err != nil {
+		return nil, err
+	}
+	lower := comparator{">=", p.v}
+	switch op {
+	case "^":
+		// Up to the next change of the first non-zero component.
+		i := 0
+		for i < p.n-1 && p.v.at(i) == 0 {
+			i++
+		}
+		if p.n == 0 {
+			return nil, nil
+		}
+		return []comparator{lower, {"<", p.bump(i)}}, nil
+	case "~":
+		if p.n == 0 {
+			return nil, nil
+		}
+		i := 1
+		if p.n == 1 {
+			i = 0
+		}
+		return []comparator{lower, {"<", p.bump(i)}}, nil
+	case "~=":
+		if p.n < 2 {
+			return nil, fmt.Errorf("~= needs two components: %q", tok)
+		}
+		return []comparator{lower, {"<", p.bump(p.n - 2)}}, nil
+	case ">", "<=":
+		// npm: a partial version covers the whole x-range, so >1.2 means
+		// >=1.3.0 and <=1.2 means <1.3.0.
+		if p.n == 0 {
+			return nil, nil
+		}
+		if p.n < 3 {
+			if op == ">" {
+				return []comparator{{">=", p.bump(p.n - 1)}}, nil
+			}
+			return []comparator{{"<", p.bump(p.n - 1)}}, nil
+		}
+		return []comparator{{op, p.v}}, nil
+	case ">=", "<", "!=":
+		if p.n == 0 {
+			return nil, nil
+		}
+		return []comparator{{op, p.v}}, nil
+	}
+	// Exact or x-range ("1.2", "1.2.x", "==1.2.*"). PEP 440 "==1.2" is
+	// exact; npm "1.2" is 1.2.x.
+	if p.n == 0 {
+		return nil, nil
+	}
+	if p.wild || op != "==" && p.n < 3 {
+		return []comparator{lower, {"<", p.bump(p.n - 1)}}, nil
+	}
+	return []comparator{{"=", p.v}}, nil
+}
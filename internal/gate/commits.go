@@ -0,0 +1,54 @@
+package gate
+
+import (
+	"path/filepath"
+
+	"github.com/BrikByte-Studios/github-governance/internal/signing"
+)
+
+func init() {
+	Register("commits.signed", "GATE-008", RuleFunc(commitsSigned))
+}
+
+// commitsSignedOptions are the rule's policy options.
+type commitsSignedOptions struct {
+	AllowedSigners string `json:"allowed_signers"`
+	ExemptWebFlow  *bool  `json:"exempt_web_flow"`
+}
+
+// commitsSigned verifies the signature of every commit in the PR or
+// release range against the allowed-signers file.
+//
+// Inputs: commits.range ("v1.3.0..v1.4.0"), else meta.base_sha..meta.head_sha;
+// commits.repo (default: the gate root).
+func commitsSigned(ctx Context, cfg RuleConfig) Outcome {
+	var opt commitsSignedOptions
+	if err := cfg.Decode(&opt); err != nil {
+		return Errorf("commits.signed options: %v", err)
+	}
+	rng := ctx.Inputs.String("commits.range")
+	if rng == "" {
+		base, head := ctx.Inputs.String("meta.base_sha"), ctx.Inputs.String("meta.head_sha")
+		if base == "" || head == "" {
+			return Missing("No commit range (inputs.commits.range or meta.base_sha/head_sha)")
+		}
+		rng = base + ".." + head
+	}
+	repo := or(ctx.Inputs.String("commits.repo"), or(ctx.Root, "."))
+	file := or(opt.AllowedSigners, signing.DefaultPath)
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(or(ctx.Root, "."), file)
+	}
+	signers, err := signing.Load(file)
+	if err != nil {
+		return Errorf("allowed signers: %v", err)
+	}
+	rep, err := signing.Verify(ctx.Ctx, repo, rng, signers, signing.Options{ExemptWebFlow: opt.ExemptWebFlow == nil || *opt.ExemptWebFlow})
+	if err != nil {
+		return Fail(nil, "verify %s: %v", rng, err)
+	}
+	if problems := rep.Problems(); len(problems) > 0 {
+		return Fail(rep, "%d of %d commit(s) in %s are not verifiably signed (%s)", len(problems), len(rep.Commits), rng, rep.Summary())
+	}
+	return Pass(rep, "All %d commit(s) in %s are signed by allowed signers", len(rep.Commits), rng)
+}
@@ -0,0 +1,71 @@
+package gate
+
+import (
+	"path/filepath"
+
+	"github.com/BrikByte-Studios/github-governance/internal/deps"
+)
+
+func init() {
+	Register("deps.lockfile", "GATE-010", RuleFunc(depsLockfile))
+}
+
+type depsLockfileOptions struct {
+	deps.Config
+	// Lockfiles to check besides every supported lockfile at the root,
+	// relative to the gate root (services/api/poetry.lock).
+	Lockfiles []string `json:"lockfiles"`
+}
+
+// depsLockfile checks the lockfiles at the gate root against their
+// manifests, integrity hashes, approved registries and duplicate versions,
+// without network access.
+func depsLockfile(ctx Context, cfg RuleConfig) Outcome {
+	var opt depsLockfileOptions
+	if err := cfg.Decode(&opt); err != nil {
+		return Errorf("deps.lockfile options: %v", err)
+	}
+	root := or(ctx.Root, ".")
+	paths := deps.Find(root)
+	for _, p := range opt.Lockfiles {
+		paths = appendNew(paths, filepath.Join(root, p))
+	}
+	if len(paths) == 0 {
+		return Missing("No lockfiles (%v) at %s", deps.Names(), root)
+	}
+	rep := &deps.Report{}
+	for _, p := range paths {
+		lf, err := deps.Load(p)
+		if err != nil {
+			return Fail(nil, "deps.lockfile: %v", err)
+		}
+		man, err := deps.LoadManifest(p)
+		if err != nil {
+			return Fail(nil, "deps.lockfile: %v", err)
+		}
+		rep.Add(lf, man, opt.Config)
+	}
+	if len(rep.Findings) > 0 {
+		return Fail(rep, "%d lockfile finding(s) (%s)", len(rep.Findings), rep.Summary())
+	}
+	return Pass(rep, "%d lockfile(s) consistent with their manifests and registries", len(rep.Lockfiles))
+}
+
+// appendNew appends the entries of add that list does not hold yet. The
+// lockfiles option only adds to the root lockfiles, so no layer can drop
+// one from the check.
+func appendNew(list []string, add ...string) []string {
+	for _, a := range add {
+		found := false
+		for _, l := range list {
+			if filepath.Clean(l) == filepath.Clean(a) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, a)
+		}
+	}
+	return list
+}
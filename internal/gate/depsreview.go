@@ -0,0 +1,102 @@
+package gate
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/BrikByte-Studios/github-governance/internal/deps"
+	"github.com/BrikByte-Studios/github-governance/internal/gitrepo"
+)
+
+func init() {
+	Register("deps.review", "GATE-011", RuleFunc(depsReview))
+}
+
+type depsReviewOptions struct {
+	deps.ReviewConfig
+	// Lockfiles to diff besides every supported lockfile at the root at
+	// base or head, relative to the repo root.
+	Lockfiles []string `json:"lockfiles"`
+	// MetadataCache holds publish times for min_age_days.
+	MetadataCache string `json:"metadata_cache"`
+	// Popular is the typosquat allowlist.
+	Popular string `json:"popular"`
+}
+
+// depsReview diffs the lockfiles between the merge base and head of the
+// clone at the gate root and applies the dependency policy to the packages
+// the PR adds or upgrades.
+//
+// Inputs: meta.base_sha, meta.head_sha (default HEAD).
+func depsReview(ctx Context, cfg RuleConfig) Outcome {
+	var opt depsReviewOptions
+	if err := cfg.Decode(&opt); err != nil {
+		return Errorf("deps.review options: %v", err)
+	}
+	root := or(ctx.Root, ".")
+	base, head := ctx.Inputs.String("meta.base_sha"), or(ctx.Inputs.String("meta.head_sha"), "HEAD")
+	if base == "" {
+		return Missing("deps.review needs meta.base_sha")
+	}
+	mb, err := gitrepo.MergeBase(ctx.Ctx, root, base, head)
+	if err != nil {
+		return Fail(nil, "deps.review: %v", err)
+	}
+	meta, err := deps.LoadMetadata(filepath.Join(root, or(opt.MetadataCache, ".governance/deps/metadata.yml")))
+	if err != nil {
+		return Fail(nil, "deps.review: %v", err)
+	}
+	popular, err := deps.LoadPopular(filepath.Join(root, or(opt.Popular, ".governance/deps/popular.yml")))
+	if err != nil {
+		return Fail(nil, "deps.review: %v", err)
+	}
+	paths := appendNew(deps.Names(), opt.Lockfiles...)
+	var changes []deps.Change
+	for _, p := range paths {
+		before, err := lockfileAt(ctx.Ctx, root, mb, p)
+		if err != nil {
+			return Fail(nil, "deps.review: %v", err)
+		}
+		after, err := lockfileAt(ctx.Ctx, root, head, p)
+		if err != nil {
+			return Fail(nil, "deps.review: %v", err)
+		}
+		if before == nil && after == nil {
+			continue
+		}
+		changes = append(changes, deps.Diff(before, after)...)
+	}
+	rep := deps.Review(changes, opt.ReviewConfig, meta, popular, ctx.Now)
+	rep.Base, rep.Head = mb, head
+	s := rep.Summary
+	if len(rep.Findings) > 0 {
+		return Fail(rep, "%d dependency finding(s) for %d added (%d direct) and %d upgraded package(s)", len(rep.Findings), s.Added, s.NewDirect, s.Upgraded)
+	}
+	return Pass(rep, "%d added (%d direct), %d upgraded, %d removed package(s) within policy", s.Added, s.NewDirect, s.Upgraded, s.Removed)
+}
+
+// lockfileAt parses a lockfile and marks its direct packages from the
+// manifest at rev; nil when rev has no such lockfile.
+func lockfileAt(ctx context.Context, root, rev, path string) (*deps.Lockfile, error) {
+	raw, ok, err := gitrepo.Show(ctx, root, rev, path)
+	if err != nil || !ok {
+		return nil, err
+	}
+	lf, err := deps.Parse(path, raw)
+	if err != nil {
+		return nil, err
+	}
+	manPath := deps.ManifestFor(path)
+	raw, ok, err = gitrepo.Show(ctx, root, rev, manPath)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		man, err := deps.ParseManifest(path, manPath, raw)
+		if err != nil {
+			return nil, err
+		}
+		deps.MarkDirect(lf, man)
+	}
+	return lf, nil
+}
@@ -0,0 +1,539 @@
+// Package gate is the Go policy gate engine. It evaluates the rules
+// configured under `rules:` in the effective policy against CI inputs and
+// waivers and produces a decision of this shape:
+//
+//	{"status": "passed|passed_with_warnings|failed", "score": 100,
+//	 "rules": [{"id", "severity", "result", "waived", "message",
+//	            "evidence", "missing_evidence"}],
+//	 "missing_evidence": ["coverage.min"], "waivers_used": [...]}
+//
+// Rules register themselves with Register; each rule file documents the
+// inputs it reads and the options it accepts. Rules run concurrently, each
+// under its own timeout.
+package gate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/BrikByte-Studios/github-governance/internal/policy"
+)
+
+// Decision statuses.
+const (
+	StatusPassed             = "passed"
+	StatusPassedWithWarnings = "passed_with_warnings"
+	StatusFailed             = "failed"
+)
+
+// Rule results.
+const (
+	ResultPass    = "pass"
+	ResultFail    = "fail"
+	ResultSkipped = "skipped"
+	// ResultError: the rule could not be evaluated (timeout, panic,
+	// cancellation, bad options).
+	ResultError = "error"
+)
+
+// Severities.
+const (
+	SeverityBlock = "block"
+	SeverityWarn  = "warn"
+)
+
+// RuleConfig is one entry of the policy's rules map. Rule-specific options
+// are kept and can be read with Decode.
+type RuleConfig struct {
+	Severity         string   `json:"severity"`
+	RequiresEvidence bool     `json:"requires_evidence"`
+	Threshold        *float64 `json:"threshold,omitempty"`
+	MaxLevel         string   `json:"max_level,omitempty"`
+	// TimeoutSeconds bounds the rule's evaluation (default DefaultTimeout).
+	TimeoutSeconds *float64 `json:"timeout_seconds,omitempty"`
+
+	raw map[string]any
+}
+
+// DefaultTimeout bounds rules without timeout_seconds.
+const DefaultTimeout = 2 * time.Minute
+
+// timeout returns the rule's evaluation deadline.
+func (c RuleConfig) timeout() (time.Duration, error) {
+	if c.TimeoutSeconds == nil {
+		return DefaultTimeout, nil
+	}
+	if *c.TimeoutSeconds <= 0 {
+		return 0, fmt.Errorf("timeout_seconds must be positive, got %g", *c.TimeoutSeconds)
+	}
+	return time.Duration(*c.TimeoutSeconds * float64(time.Second)), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (c *RuleConfig) UnmarshalJSON(b []byte) error {
+	type plain RuleConfig
+	*c = RuleConfig{}
+	if err := json.Unmarshal(b, (*plain)(c)); err != nil {
+		return err
+	}
+	return json.Unmarshal(b, &c.raw)
+}
+
+// Decode decodes the rule's options into dst (a struct with json tags).
+func (c RuleConfig) Decode(dst any) error {
+	raw, err := json.Marshal(c.raw)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, dst)
+}
+
+// Policy is the part of the effective policy the gate reads.
+type Policy struct {
+	PolicyVersion string                `json:"policy_version,omitempty"`
+	Rules         map[string]RuleConfig `json:"rules"`
+}
+
+// LoadPolicy reads the rules from an effective policy (JSON or YAML).
+func LoadPolicy(p string) (*Policy, error) {
+	pol := &Policy{}
+	if err := policy.LoadSection(p, "rules", &pol.Rules); err != nil {
+		return nil, err
+	}
+	var version any
+	if err := policy.LoadSection(p, "policy_version", &version); err != nil {
+		return nil, err
+	}
+	if version != nil {
+		pol.PolicyVersion = fmt.Sprint(version)
+	}
+	return pol, nil
+}
+
+// Inputs is the gate input document (coverage, tests, security, meta, ...).
+type Inputs map[string]any
+
+// LoadInputs reads an inputs JSON file.
+func LoadInputs(p string) (Inputs, error) {
+	raw, err := os.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	var in Inputs
+	if err := json.Unmarshal(raw, &in); err != nil {
+		return nil, fmt.Errorf("%s: %w", p, err)
+	}
+	return in, nil
+}
+
+// Get returns the value at a dotted path ("security.sca.count").
+func (in Inputs) Get(p string) (any, bool) {
+	var cur any = map[string]any(in)
+	for _, k := range strings.Split(p, ".") {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = m[k]; !ok || cur == nil {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// Number returns a numeric input.
+func (in Inputs) Number(p string) (float64, bool) {
+	v, ok := in.Get(p)
+	f, isNum := v.(float64)
+	return f, ok && isNum
+}
+
+// String returns a string input.
+func (in Inputs) String(p string) string {
+	v, _ := in.Get(p)
+	s, _ := v.(string)
+	return s
+}
+
+// Bool returns a boolean input and whether it was present.
+func (in Inputs) Bool(p string) (value, ok bool) {
+	v, present := in.Get(p)
+	b, isBool := v.(bool)
+	return b, present && isBool
+}
+
+// Context is what a rule evaluates against.
+type Context struct {
+	Inputs Inputs
+	// Root is the local clone the gate runs in; rules that inspect the
+	// repository resolve paths against it.
+	Root string
+	Now  time.Time
+	// Ctx is done when the rule's timeout expires or the gate is cancelled;
+	// rules that run commands or read many files should stop when it is.
+	// Evaluate sets it for each rule.
+	Ctx context.Context
+}
+
+// Outcome is a rule's verdict before severity and waivers are applied.
+type Outcome struct {
+	Result          string
+	Message         string
+	Evidence        any
+	MissingEvidence bool
+}
+
+// Pass builds a passing outcome.
+func Pass(evidence any, format string, args ...any) Outcome {
+	return Outcome{Result: ResultPass, Message: fmt.Sprintf(format, args...), Evidence: evidence}
+}
+
+// Fail builds a failing outcome.
+func Fail(evidence any, format string, args ...any) Outcome {
+	return Outcome{Result: ResultFail, Message: fmt.Sprintf(format, args...), Evidence: evidence}
+}
+
+// Missing reports that the rule's evidence is absent.
+func Missing(format string, args ...any) Outcome {
+	return Outcome{Result: ResultFail, Message: fmt.Sprintf(format, args...), MissingEvidence: true}
+}
+
+// Errorf reports that the rule could not be evaluated (bad options, an
+// unreadable config file); it resolves to ResultError (GATE-090), not a
+// policy failure.
+func Errorf(format string, args ...any) Outcome {
+	return Outcome{Result: ResultError, Message: fmt.Sprintf(format, args...)}
+}
+
+// Rule evaluates one rule id.
+type Rule interface {
+	Evaluate(ctx Context, cfg RuleConfig) Outcome
+}
+
+// RuleFunc adapts a function to Rule.
+type RuleFunc func(ctx Context, cfg RuleConfig) Outcome
+
+// Evaluate implements Rule.
+func (f RuleFunc) Evaluate(ctx Context, cfg RuleConfig) Outcome { return f(ctx, cfg) }
+
+type registered struct {
+	code string
+	rule Rule
+}
+
+var registry = map[string]registered{}
+
+// Register adds a rule evaluator under its stable code (see
+// internal/codes). It panics on duplicates.
+func Register(id, code string, r Rule) {
+	if _, dup := registry[id]; dup {
+		panic("gate: duplicate rule " + id)
+	}
+	registry[id] = registered{code: code, rule: r}
+}
+
+// Code returns the stable code of a registered rule.
+func Code(id string) string {
+	return registry[id].code
+}
+
+// Registered returns the registered rule ids, sorted.
+func Registered() []string {
+	ids := make([]string, 0, len(registry))
+	for id := range registry {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Waiver exempts a failing rule until its TTL.
+type Waiver struct {
+	Rule     string `json:"rule" yaml:"rule"`
+	Scope    string `json:"scope" yaml:"scope"`
+	Reason   string `json:"reason" yaml:"reason"`
+	TTL      string `json:"ttl" yaml:"ttl"`
+	Approver string `json:"approver" yaml:"approver"`
+	Evidence string `json:"evidence,omitempty" yaml:"evidence,omitempty"`
+}
+
+// LoadWaivers reads a waivers file ({"waivers": [...]} or a bare list,
+// JSON or YAML).
+func LoadWaivers(p string) ([]Waiver, error) {
+	raw, err := os.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	var wrapped struct {
+		Waivers []Waiver `yaml:"waivers"`
+	}
+	if err := yaml.Unmarshal(raw, &wrapped); err == nil && wrapped.Waivers != nil {
+		return wrapped.Waivers, nil
+	}
+	var list []Waiver
+	if err := yaml.Unmarshal(raw, &list); err != nil {
+		return nil, fmt.Errorf("%s: %w", p, err)
+	}
+	return list, nil
+}
+
+// expires returns the end of the waiver's validity (a date TTL covers the
+// whole day).
+func (w Waiver) expires() (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, w.TTL); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", w.TTL)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("ttl %q is not YYYY-MM-DD or RFC 3339", w.TTL)
+	}
+	return t.Add(24*time.Hour - time.Nanosecond), nil
+}
+
+// check reports why a waiver cannot be used at now, or "".
+func (w Waiver) check(now time.Time) string {
+	if w.Reason == "" || w.Approver == "" {
+		return "reason and approver are required"
+	}
+	exp, err := w.expires()
+	if err != nil {
+		return err.Error()
+	}
+	if now.After(exp) {
+		return "expired " + w.TTL
+	}
+	return ""
+}
+
+func (w Waiver) matches(id string) bool {
+	if w.Rule == id {
+		return true
+	}
+	ok, _ := path.Match(w.Rule, id)
+	return ok
+}
+
+// RuleResult is one entry of decision.rules.
+type RuleResult struct {
+	ID string `json:"id"`
+	// Code is the rule's stable code, or GATE-090 (error), GATE-091 (no
+	// evaluator) or GATE-092 (required evidence missing).
+	Code            string `json:"code"`
+	Severity        string `json:"severity"`
+	Result          string `json:"result"`
+	Waived          bool   `json:"waived"`
+	Message         string `json:"message"`
+	Evidence        any    `json:"evidence"`
+	MissingEvidence bool   `json:"missing_evidence"`
+}
+
+// IgnoredWaiver is a waiver that was not applied.
+type IgnoredWaiver struct {
+	Waiver
+	Why string `json:"why"`
+}
+
+// Decision is the gate output.
+type Decision struct {
+	Status          string          `json:"status"`
+	Score           int             `json:"score"`
+	PolicyVersion   string          `json:"policy_version,omitempty"`
+	EvaluatedAt     string          `json:"evaluated_at"`
+	Rules           []RuleResult    `json:"rules"`
+	MissingEvidence []string        `json:"missing_evidence"`
+	WaiversUsed     []Waiver        `json:"waivers_used"`
+	WaiversIgnored  []IgnoredWaiver `json:"waivers_ignored,omitempty"`
+}
+
+// Options controls how EvaluateContext schedules rules.
+type Options struct {
+	// Workers bounds how many rules run at once (default GOMAXPROCS).
+	Workers int
+}
+
+// Evaluate runs every configured rule and folds the results into a
+// decision:
+//
+//   - a failing block rule fails the gate unless an active waiver covers it;
+//   - failing warn rules and waived failures give passed_with_warnings;
+//   - missing evidence fails a rule that requires evidence and skips one
+//     that does not;
+//   - a rule that errors (times out, panics, is cancelled) counts as
+//     failing, so its severity decides whether it blocks;
+//   - score is the percentage of evaluated (non-skipped) rules that pass.
+//
+// Rules are listed in id order whatever order they finish in.
+func Evaluate(pol *Policy, ctx Context, waivers []Waiver) *Decision {
+	return EvaluateContext(context.Background(), pol, ctx, waivers, Options{})
+}
+
+// EvaluateContext is Evaluate on a bounded worker pool. Cancelling parent
+// resolves the rules still running or queued to errors.
+func EvaluateContext(parent context.Context, pol *Policy, ctx Context, waivers []Waiver, opt Options) *Decision {
+	d := &Decision{
+		PolicyVersion: pol.PolicyVersion, EvaluatedAt: ctx.Now.UTC().Format(time.RFC3339),
+		Rules: []RuleResult{}, MissingEvidence: []string{}, WaiversUsed: []Waiver{},
+	}
+	active := []Waiver{}
+	for _, w := range waivers {
+		if why := w.check(ctx.Now); why != "" {
+			d.WaiversIgnored = append(d.WaiversIgnored, IgnoredWaiver{Waiver: w, Why: why})
+			continue
+		}
+		active = append(active, w)
+	}
+
+	ids := make([]string, 0, len(pol.Rules))
+	for id := range pol.Rules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	d.Rules = make([]RuleResult, len(ids))
+	workers := opt.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(ids); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				d.Rules[i] = evaluateRule(parent, ids[i], pol.Rules[ids[i]], ctx)
+			}
+		}()
+	}
+	for i := range ids {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	d.fold(active)
+	return d
+}
+
+func evaluateRule(parent context.Context, id string, cfg RuleConfig, ctx Context) RuleResult {
+	sev := cfg.Severity
+	if sev != SeverityWarn {
+		sev = SeverityBlock
+	}
+	r, ok := registry[id]
+	rr := RuleResult{ID: id, Code: r.code, Severity: sev}
+	if !ok {
+		rr.Code, rr.Result, rr.Message = "GATE-091", ResultFail, fmt.Sprintf("no evaluator for rule %s", id)
+		return rr
+	}
+	timeout, err := cfg.timeout()
+	if err != nil {
+		rr.Code, rr.Result, rr.Message = "GATE-090", ResultError, fmt.Sprintf("%s options: %v", id, err)
+		return rr
+	}
+	o := run(parent, r.rule, ctx, cfg, timeout)
+	rr.Result, rr.Message, rr.Evidence, rr.MissingEvidence = o.Result, o.Message, o.Evidence, o.MissingEvidence
+	if o.MissingEvidence && !cfg.RequiresEvidence {
+		rr.Result, rr.MissingEvidence = ResultSkipped, false
+		rr.Message += " (evidence not required)"
+	}
+	switch {
+	case rr.Result == ResultError:
+		rr.Code = "GATE-090"
+	case rr.MissingEvidence:
+		rr.Code = "GATE-092"
+	}
+	return rr
+}
+
+// run evaluates r under timeout. A rule still running at its deadline, or
+// when parent is cancelled, resolves to an error; if it ignores ctx.Ctx it
+// is left to finish in the background and its outcome is dropped. A panic
+// is an error too.
+func run(parent context.Context, r Rule, ctx Context, cfg RuleConfig, timeout time.Duration) Outcome {
+	if err := parent.Err(); err != nil {
+		return Outcome{Result: ResultError, Message: fmt.Sprintf("not evaluated: %v", err)}
+	}
+	c, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+	ctx.Ctx = c
+	done := make(chan Outcome, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- Outcome{Result: ResultError, Message: fmt.Sprintf("evaluator panicked: %v", p)}
+			}
+		}()
+		done <- r.Evaluate(ctx, cfg)
+	}()
+	var o Outcome
+	select {
+	case o = <-done:
+	case <-c.Done():
+	}
+	// An outcome that raced the deadline is dropped as well, so the result
+	// does not depend on scheduling.
+	if c.Err() != nil {
+		if err := parent.Err(); err != nil {
+			return Outcome{Result: ResultError, Message: fmt.Sprintf("cancelled: %v", err)}
+		}
+		return Outcome{Result: ResultError, Message: fmt.Sprintf("timed out after %s", timeout)}
+	}
+	return o
+}
+
+// fold applies waivers and computes status, score and missing evidence.
+func (d *Decision) fold(waivers []Waiver) {
+	used := map[int]bool{}
+	failed, warned, evaluated, passed := false, false, 0, 0
+	for i := range d.Rules {
+		r := &d.Rules[i]
+		if r.MissingEvidence {
+			d.MissingEvidence = append(d.MissingEvidence, r.ID)
+		}
+		if r.Result == ResultSkipped {
+			continue
+		}
+		evaluated++
+		if r.Result == ResultPass {
+			passed++
+			continue
+		}
+		for j, w := range waivers {
+			if w.matches(r.ID) {
+				r.Waived = true
+				if !used[j] {
+					used[j] = true
+					d.WaiversUsed = append(d.WaiversUsed, w)
+				}
+				break
+			}
+		}
+		switch {
+		case r.Waived || r.Severity == SeverityWarn:
+			warned = true
+		default:
+			failed = true
+		}
+	}
+	d.Score = 100
+	if evaluated > 0 {
+		d.Score = passed * 100 / evaluated
+	}
+	switch {
+	case failed:
+		d.Status = StatusFailed
+	case warned:
+		d.Status = StatusPassedWithWarnings
+	default:
+		d.Status = StatusPassed
+	}
+}
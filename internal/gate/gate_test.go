@@ -0,0 +1,551 @@
+package gate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/BrikByte-Studios/github-governance/internal/canon"
+	"github.com/BrikByte-Studios/github-governance/internal/codes"
+	"github.com/BrikByte-Studios/github-governance/internal/deps"
+	"github.com/BrikByte-Studios/github-governance/internal/policy"
+)
+
+var (
+	fixtures = filepath.Join("..", "..", "tests", "fixtures", "policy-gate")
+	now      = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+)
+
+func load(t *testing.T, inputs string) (*Policy, Context) {
+	t.Helper()
+	pol, err := LoadPolicy(filepath.Join(fixtures, "policy.strict.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	in, err := LoadInputs(filepath.Join(fixtures, inputs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pol, Context{Inputs: in, Now: now}
+}
+
+func rule(t *testing.T, d *Decision, id string) RuleResult {
+	t.Helper()
+	for _, r := range d.Rules {
+		if r.ID == id {
+			return r
+		}
+	}
+	t.Fatalf("rule %s not in decision", id)
+	return RuleResult{}
+}
+
+func TestFixtures(t *testing.T) {
+	pol, ctx := load(t, "inputs.good.json")
+	d := Evaluate(pol, ctx, nil)
+	if d.Status != StatusPassed || d.Score < 80 || d.PolicyVersion != "1.0.0" {
+		t.Fatalf("good: %s score %d version %q", d.Status, d.Score, d.PolicyVersion)
+	}
+	if len(d.Rules) != 7 || d.Rules[0].ID != "adr.required_for_infra" {
+		t.Fatalf("rules not sorted by id: %+v", d.Rules)
+	}
+
+	pol, ctx = load(t, "inputs.bad-coverage.json")
+	d = Evaluate(pol, ctx, nil)
+	if r := rule(t, d, "coverage.min"); r.Result != ResultFail || d.Status != StatusFailed {
+		t.Fatalf("bad coverage: %s / %+v", d.Status, r)
+	}
+
+	pol, ctx = load(t, "inputs.bad-sca.json")
+	if d = Evaluate(pol, ctx, nil); d.Status != StatusFailed || rule(t, d, "security.sca").Result != ResultFail {
+		t.Fatalf("bad sca: %s", d.Status)
+	}
+	waivers, err := LoadWaivers(filepath.Join(fixtures, "waivers.sca.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d = Evaluate(pol, ctx, waivers)
+	sca := rule(t, d, "security.sca")
+	if d.Status != StatusPassedWithWarnings || !sca.Waived || sca.Result != ResultFail || len(d.WaiversUsed) != 1 {
+		t.Fatalf("waived sca: %s / %+v", d.Status, sca)
+	}
+}
+
+// TestDecisionBytes: identical inputs give a byte-identical canonical
+// decision, whatever order the workers finish in.
+func TestDecisionBytes(t *testing.T) {
+	pol, ctx := load(t, "inputs.bad-sca.json")
+	waivers, err := LoadWaivers(filepath.Join(fixtures, "waivers.sca.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var first []byte
+	for i := 0; i < 20; i++ {
+		d := EvaluateContext(context.Background(), pol, ctx, waivers, Options{Workers: 1 + i%8})
+		raw, err := canon.Marshal(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if first == nil {
+			first = raw
+		} else if !bytes.Equal(raw, first) {
+			t.Fatalf("run %d differs:\n%s\n%s", i, raw, first)
+		}
+	}
+}
+
+func TestBaselineRules(t *testing.T) {
+	th := 80.0
+	pol := &Policy{Rules: map[string]RuleConfig{"coverage.min": {Severity: "block", RequiresEvidence: true, Threshold: &th}}}
+	d := Evaluate(pol, Context{Inputs: Inputs{"coverage": map[string]any{"line": 86.3}}, Now: now}, nil)
+	if r := rule(t, d, "coverage.min"); r.Result != ResultPass || !regexp.MustCompile(`86\.3% .* 80%`).MatchString(r.Message) {
+		t.Fatalf("coverage: %+v", r)
+	}
+
+	d = Evaluate(pol, Context{Inputs: Inputs{}, Now: now}, nil)
+	if r := rule(t, d, "coverage.min"); r.Result != ResultFail || !r.MissingEvidence || d.MissingEvidence[0] != "coverage.min" {
+		t.Fatalf("missing evidence: %+v", d)
+	}
+	pol.Rules["coverage.min"] = RuleConfig{Severity: "block", Threshold: &th}
+	if d = Evaluate(pol, Context{Inputs: Inputs{}, Now: now}, nil); d.Rules[0].Result != ResultSkipped || d.Status != StatusPassed {
+		t.Fatalf("optional evidence: %+v", d.Rules[0])
+	}
+
+	pol = &Policy{Rules: map[string]RuleConfig{"security.sast": {Severity: "warn", MaxLevel: "no-high", RequiresEvidence: true}}}
+	in := Inputs{"security": map[string]any{"sast": map[string]any{"count": map[string]any{"high": 2.0}}}}
+	if d = Evaluate(pol, Context{Inputs: in, Now: now}, nil); d.Status != StatusPassedWithWarnings || d.Score != 0 {
+		t.Fatalf("warn sast: %s %d", d.Status, d.Score)
+	}
+
+	pol = &Policy{Rules: map[string]RuleConfig{"no.such.rule": {Severity: "warn"}}}
+	if d = Evaluate(pol, Context{Inputs: Inputs{}, Now: now}, nil); d.Rules[0].Result != ResultFail {
+		t.Fatalf("unknown rule: %+v", d.Rules[0])
+	}
+}
+
+func TestWaiverValidity(t *testing.T) {
+	pol := &Policy{Rules: map[string]RuleConfig{"tests.green": {Severity: "block", RequiresEvidence: true}}}
+	ctx := Context{Inputs: Inputs{"tests": map[string]any{"status": "red", "failed": 2.0}}, Now: now}
+	cases := []struct {
+		w      Waiver
+		status string
+	}{
+		{Waiver{Rule: "tests.*", Reason: "flaky suite", Approver: "@lead", TTL: "2026-03-01"}, StatusPassedWithWarnings},
+		{Waiver{Rule: "tests.green", Reason: "flaky suite", Approver: "@lead", TTL: "2026-02-28"}, StatusFailed},
+		{Waiver{Rule: "tests.green", Approver: "@lead", TTL: "2099-12-31"}, StatusFailed},
+		{Waiver{Rule: "coverage.min", Reason: "x", Approver: "@lead", TTL: "2099-12-31"}, StatusFailed},
+	}
+	for _, c := range cases {
+		if d := Evaluate(pol, ctx, []Waiver{c.w}); d.Status != c.status {
+			t.Errorf("%+v: status %s, want %s", c.w, d.Status, c.status)
+		}
+	}
+
+	p := filepath.Join(t.TempDir(), "waivers.yml")
+	_ = os.WriteFile(p, []byte("- rule: tests.green\n  reason: r\n  approver: a\n  ttl: 2099-12-31\n"), 0o644)
+	if ws, err := LoadWaivers(p); err != nil || len(ws) != 1 || ws[0].TTL != "2099-12-31" {
+		t.Fatalf("YAML list: %v %+v", err, ws)
+	}
+}
+
+func TestCommitsSignedInputs(t *testing.T) {
+	pol := &Policy{Rules: map[string]RuleConfig{"commits.signed": {Severity: "warn", RequiresEvidence: true}}}
+	d := Evaluate(pol, Context{Inputs: Inputs{}, Now: now}, nil)
+	if r := d.Rules[0]; !r.MissingEvidence {
+		t.Fatalf("no range: %+v", r)
+	}
+	in := Inputs{"meta": map[string]any{"base_sha": "a", "head_sha": "b"}}
+	d = Evaluate(pol, Context{Inputs: in, Root: t.TempDir(), Now: now}, nil)
+	if r := d.Rules[0]; r.Result != ResultError || r.Code != "GATE-090" || r.MissingEvidence || d.Status != StatusPassedWithWarnings {
+		t.Fatalf("no signers file: %+v", r)
+	}
+	pol.Rules["commits.signed"] = RuleConfig{Severity: "block", raw: map[string]any{"allowed_signers": 42}}
+	d = Evaluate(pol, Context{Inputs: in, Root: t.TempDir(), Now: now}, nil)
+	if r := d.Rules[0]; r.Result != ResultError || r.Code != "GATE-090" || d.Status != StatusFailed {
+		t.Fatalf("bad options: %+v", r)
+	}
+}
+
+func TestRepoHygieneOptions(t *testing.T) {
+	for opts, want := range map[string][2]string{
+		`{"severity": "block", "requires_evidence": true, "scope": "diff"}`: {ResultFail, "needs meta.base_sha"},
+		`{"severity": "block", "scope": "everything"}`:                      {ResultError, "unknown scope"},
+	} {
+		pol := &Policy{}
+		if err := json.Unmarshal([]byte(`{"repo.hygiene": `+opts+`}`), &pol.Rules); err != nil {
+			t.Fatal(err)
+		}
+		d := Evaluate(pol, Context{Inputs: Inputs{}, Now: now}, nil)
+		if r := d.Rules[0]; r.Result != want[0] || d.Status != StatusFailed || !strings.Contains(r.Message, want[1]) {
+			t.Errorf("%s: %+v", opts, r)
+		}
+	}
+}
+
+func TestDepsLockfile(t *testing.T) {
+	pol := &Policy{}
+	if err := json.Unmarshal([]byte(`{"deps.lockfile": {"severity": "block", "requires_evidence": true}}`), &pol.Rules); err != nil {
+		t.Fatal(err)
+	}
+	if d := Evaluate(pol, Context{Inputs: Inputs{}, Root: "../..", Now: now}, nil); d.Status != StatusPassed {
+		t.Fatalf("repo lockfiles: %+v", d.Rules[0])
+	}
+	d := Evaluate(pol, Context{Inputs: Inputs{}, Root: t.TempDir(), Now: now}, nil)
+	if d.Status != StatusFailed || len(d.MissingEvidence) != 1 {
+		t.Fatalf("no lockfiles: %+v", d)
+	}
+	// Listing lockfiles adds to the root ones; it never drops one.
+	root := deps.Find("../..")
+	pol.Rules["deps.lockfile"] = RuleConfig{Severity: "block", raw: map[string]any{"lockfiles": []any{filepath.Base(root[0])}}}
+	d = Evaluate(pol, Context{Inputs: Inputs{}, Root: "../..", Now: now}, nil)
+	if rep, ok := d.Rules[0].Evidence.(*deps.Report); !ok || len(rep.Lockfiles) != len(root) {
+		t.Fatalf("listed lockfiles: %+v", d.Rules[0])
+	}
+}
+
+func TestDepsReview(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_SYSTEM=/dev/null",
+			"GIT_AUTHOR_NAME=a", "GIT_AUTHOR_EMAIL=a@x", "GIT_COMMITTER_NAME=a", "GIT_COMMITTER_EMAIL=a@x")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	write := func(p, data string) {
+		if err := os.WriteFile(filepath.Join(dir, p), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("init", "-q", "-b", "main")
+	write("go.mod", "module x\n\nrequire golang.org/x/mod v0.17.0\n")
+	write("go.sum", "golang.org/x/mod v0.17.0 h1:zY54UmvipHiNd+pm+m0x9KhZ9hl1/7QNMyxXbc6ICqA=\n")
+	git("add", "-A")
+	git("commit", "-q", "-m", "base")
+	base := git("rev-parse", "HEAD")
+	write("go.mod", "module x\n\nrequire (\n\tgolang.org/x/mod v0.18.0\n\tgithub.com/sirupsen/logrus v1.9.3\n)\n")
+	write("go.sum", "golang.org/x/mod v0.17.0 h1:zY54UmvipHiNd+pm+m0x9KhZ9hl1/7QNMyxXbc6ICqA=\n"+
+		"golang.org/x/mod v0.18.0 h1:5+9lSbEzPSdWkH32vYPBwEpX8KwDbM52Ud9xBUvNlb0=\n"+
+		"github.com/sirupsen/logrus v1.9.3 h1:dueUQJ1C2q9oE3F7wvmSGAaVtTmUizReu6fjN8uqzbQ=\n")
+	write("package.json", `{"dependencies": {}}`)
+	write("package-lock.json", `{"lockfileVersion": 3, "packages": {"": {}}}`)
+	git("add", "-A")
+	git("commit", "-q", "-m", "deps")
+
+	pol := &Policy{}
+	if err := json.Unmarshal([]byte(`{"deps.review": {"severity": "block", "max_new_direct": 0, "banned": ["go:github.com/sirupsen/*"]}}`), &pol.Rules); err != nil {
+		t.Fatal(err)
+	}
+	in := Inputs{"meta": map[string]any{"base_sha": base}}
+	d := Evaluate(pol, Context{Inputs: in, Root: dir, Now: now}, nil)
+	r := d.Rules[0]
+	if d.Status != StatusFailed || !strings.Contains(r.Message, "2 dependency finding(s) for 1 added (1 direct) and 1 upgraded") {
+		t.Fatalf("deps.review: %+v", r)
+	}
+	if d := Evaluate(pol, Context{Inputs: Inputs{}, Root: dir, Now: now}, nil); d.Rules[0].Result != ResultSkipped {
+		t.Errorf("no base: %+v", d.Rules[0])
+	}
+}
+
+// registerTest registers a test rule for the duration of t.
+func registerTest(t *testing.T, id, code string, r Rule) {
+	t.Helper()
+	Register(id, code, r)
+	t.Cleanup(func() { delete(registry, id) })
+}
+
+// TestParallelEvaluation checks that the pool runs Workers rules at once
+// and no more: every rule waits at a barrier until four are running, so a
+// pool that runs fewer never releases it and the rules time out.
+func TestParallelEvaluation(t *testing.T) {
+	const workers = 4
+	var running, peak atomic.Int32
+	barrier := make(chan struct{})
+	var once sync.Once
+	rule := RuleFunc(func(ctx Context, _ RuleConfig) Outcome {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+		}
+		if n == workers {
+			once.Do(func() { close(barrier) })
+		}
+		select {
+		case <-barrier:
+			return Pass(nil, "barrier released")
+		case <-ctx.Ctx.Done():
+			return Fail(nil, "barrier not released: %v", ctx.Ctx.Err())
+		}
+	})
+	timeout := 30.0
+	pol := &Policy{Rules: map[string]RuleConfig{}}
+	for i := 0; i < 12; i++ {
+		id := fmt.Sprintf("test.barrier.%02d", i)
+		registerTest(t, id, "TEST-001", rule)
+		pol.Rules[id] = RuleConfig{Severity: "block", TimeoutSeconds: &timeout}
+	}
+	d := EvaluateContext(context.Background(), pol, Context{Inputs: Inputs{}, Now: now}, nil, Options{Workers: workers})
+	if p := peak.Load(); p != workers {
+		t.Errorf("peak concurrency %d, want %d", p, workers)
+	}
+	for i, r := range d.Rules {
+		if want := fmt.Sprintf("test.barrier.%02d", i); r.ID != want || r.Result != ResultPass {
+			t.Fatalf("rule %d = %+v, want %s passing", i, r, want)
+		}
+	}
+	if d.Status != StatusPassed {
+		t.Errorf("status %s", d.Status)
+	}
+}
+
+func TestRuleTimeouts(t *testing.T) {
+	// test.hang waits for its context or, with inputs.ignore_ctx, for
+	// release.
+	release := make(chan struct{})
+	defer close(release)
+	registerTest(t, "test.hang", "TEST-002", RuleFunc(func(ctx Context, _ RuleConfig) Outcome {
+		if ignore, _ := ctx.Inputs.Bool("ignore_ctx"); ignore {
+			<-release
+			return Pass(nil, "released")
+		}
+		<-ctx.Ctx.Done()
+		return Fail(nil, "%v", ctx.Ctx.Err())
+	}))
+	registerTest(t, "test.panic", "TEST-003", RuleFunc(func(Context, RuleConfig) Outcome { panic("boom") }))
+	fast, slow := 0.05, 5.0
+	cases := []struct {
+		name    string
+		cfg     RuleConfig
+		id      string
+		in      Inputs
+		status  string
+		message string
+	}{
+		{"block timeout", RuleConfig{Severity: "block", TimeoutSeconds: &fast}, "test.hang", Inputs{}, StatusFailed, "timed out after 50ms"},
+		{"warn timeout", RuleConfig{Severity: "warn", TimeoutSeconds: &fast}, "test.hang", Inputs{}, StatusPassedWithWarnings, "timed out after 50ms"},
+		{"ignores ctx", RuleConfig{Severity: "block", TimeoutSeconds: &fast}, "test.hang", Inputs{"ignore_ctx": true}, StatusFailed, "timed out after 50ms"},
+		{"panic", RuleConfig{Severity: "block", TimeoutSeconds: &slow}, "test.panic", Inputs{}, StatusFailed, "evaluator panicked: boom"},
+		{"bad timeout", RuleConfig{Severity: "block", TimeoutSeconds: new(float64)}, "test.hang", Inputs{}, StatusFailed, "timeout_seconds must be positive"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pol := &Policy{Rules: map[string]RuleConfig{tc.id: tc.cfg}}
+			d := Evaluate(pol, Context{Inputs: tc.in, Now: now}, nil)
+			r := d.Rules[0]
+			if r.Result != ResultError || r.Code != "GATE-090" || d.Status != tc.status || d.Score != 0 || !strings.Contains(r.Message, tc.message) {
+				t.Errorf("%s / %+v", d.Status, r)
+			}
+		})
+	}
+
+	// An erroring rule can be waived like a failing one.
+	pol := &Policy{Rules: map[string]RuleConfig{"test.hang": {Severity: "block", TimeoutSeconds: &fast}}}
+	w := Waiver{Rule: "test.hang", Reason: "registry outage", Approver: "@lead", TTL: "2099-12-31"}
+	if d := Evaluate(pol, Context{Inputs: Inputs{}, Now: now}, []Waiver{w}); d.Status != StatusPassedWithWarnings || !d.Rules[0].Waived {
+		t.Errorf("waived timeout: %s / %+v", d.Status, d.Rules[0])
+	}
+}
+
+func TestRuleCodes(t *testing.T) {
+	for _, id := range Registered() {
+		e, ok := codes.Lookup(Code(id))
+		if !ok || e.Title != id {
+			t.Errorf("%s: code %q is not catalogued for it", id, Code(id))
+		}
+	}
+
+	pol := &Policy{Rules: map[string]RuleConfig{
+		"coverage.min": {Severity: "block", RequiresEvidence: true},
+		"tests.green":  {Severity: "block"},
+		"nope.typo":    {Severity: "block"},
+	}}
+	d := Evaluate(pol, Context{Inputs: Inputs{"tests": map[string]any{"status": "passed"}}, Now: now}, nil)
+	want := map[string]string{"coverage.min": "GATE-092", "nope.typo": "GATE-091", "tests.green": "GATE-001"}
+	for _, r := range d.Rules {
+		if r.Code != want[r.ID] {
+			t.Errorf("%s: code %s, want %s (%s)", r.ID, r.Code, want[r.ID], r.Message)
+		}
+	}
+}
+
+func TestEvaluateCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	registerTest(t, "test.hang", "TEST-002", RuleFunc(func(c Context, _ RuleConfig) Outcome {
+		close(started)
+		<-c.Ctx.Done()
+		return Fail(nil, "%v", c.Ctx.Err())
+	}))
+	registerTest(t, "test.queued", "TEST-004", RuleFunc(func(Context, RuleConfig) Outcome {
+		return Pass(nil, "ran")
+	}))
+	go func() {
+		<-started
+		cancel()
+	}()
+	pol := &Policy{Rules: map[string]RuleConfig{
+		"test.hang":   {Severity: "block"},
+		"test.queued": {Severity: "warn"},
+	}}
+	d := EvaluateContext(ctx, pol, Context{Inputs: Inputs{}, Now: now}, nil, Options{Workers: 1})
+	if r := d.Rules[0]; r.ID != "test.hang" || r.Result != ResultError || r.Message != "cancelled: context canceled" {
+		t.Errorf("hung rule: %+v", r)
+	}
+	// The only worker was busy until the cancellation.
+	if r := d.Rules[1]; r.ID != "test.queued" || r.Result != ResultError || !strings.Contains(r.Message, "not evaluated") {
+		t.Errorf("queued rule: %+v", r)
+	}
+	if d.Status != StatusFailed {
+		t.Errorf("status %s", d.Status)
+	}
+}
+
+func TestSecurityReview(t *testing.T) {
+	org, err := LoadPolicy("../../.github/policy.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pol := &Policy{Rules: map[string]RuleConfig{"security.review": org.Rules["security.review"]}}
+	raw, err := os.ReadFile("../../PULL_REQUEST_TEMPLATE.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := string(raw)
+	checked := strings.ReplaceAll(template, "- [ ] ", "- [x] ")
+	answered := strings.Replace(template, "- Authentication?", "- Authentication? Yes, adds OAuth login", 1)
+	declined := strings.Replace(strings.Replace(template, "- Authentication?", "- Authentication? No", 1), "- Secrets or configuration?", "- Secrets or configuration? N/A", 1)
+	dropped := strings.Replace(checked, "- [x] Untrusted input is validated and output is encoded.\n", "", 1)
+	security := []any{map[string]any{"user": "sam", "teams": []any{"BrikByte-Studios/security"}}}
+	foreign := []any{map[string]any{"user": "eve", "teams": []any{"other-org/security", "security"}}}
+
+	cases := []struct {
+		name      string
+		pr        map[string]any
+		approvals []any
+		result    string
+		message   string
+	}{
+		{"not sensitive", map[string]any{"body": template, "files": []any{"README.md", "docs/guide.md"}}, nil,
+			ResultPass, "no security review needed"},
+		{"declined answers", map[string]any{"body": declined, "files": []any{"README.md"}}, nil,
+			ResultPass, "no security review needed"},
+		{"sensitive path", map[string]any{"body": template, "files": []any{"services/identity/login.go"}}, nil,
+			ResultFail, "(path services/identity/login.go matches identity/): no approval from the @BrikByte-Studios/security team; 5 of 5 checklist item(s) unchecked"},
+		{"workflow and dockerfile", map[string]any{"body": checked, "files": []any{".github/workflows/ci.yml", "build/Dockerfile"}}, nil,
+			ResultFail, "matches /.github/workflows/ and 1 more): no approval from the @BrikByte-Studios/security team"},
+		{"label", map[string]any{"body": template, "labels": []any{map[string]any{"name": "security:review-needed"}}}, security,
+			ResultFail, "(label security:review-needed): 5 of 5 checklist item(s) unchecked"},
+		{"answer", map[string]any{"body": answered, "files": []any{}}, nil,
+			ResultFail, `(answer mentions authentication: "- Authentication? Yes, adds OAuth login")`},
+		{"dropped item", map[string]any{"body": dropped, "labels": []any{"security:review-needed"}}, security,
+			ResultFail, "1 of 5 checklist item(s) unchecked"},
+		{"reviewed", map[string]any{"body": checked, "files": []any{"payments/refund.go"}}, security,
+			ResultPass, "approved by sam, checklist 5/5"},
+		{"another org's team", map[string]any{"body": checked, "files": []any{"payments/refund.go"}}, foreign,
+			ResultFail, "no approval from the @BrikByte-Studios/security team"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			in := Inputs{"pr": tc.pr, "reviews": map[string]any{"author": "alex", "approvals": tc.approvals}}
+			d := Evaluate(pol, Context{Inputs: in, Root: "../..", Now: now}, nil)
+			if r := d.Rules[0]; r.Result != tc.result || r.Code != "GATE-012" || !strings.Contains(r.Message, tc.message) {
+				t.Errorf("%s %s: %s", r.Result, r.Code, r.Message)
+			}
+		})
+	}
+
+	// The author's own approval does not count, even from the security team.
+	in := Inputs{
+		"pr":      map[string]any{"body": checked, "labels": []any{"security:review-needed"}},
+		"reviews": map[string]any{"author": "sam", "approvals": security},
+	}
+	if d := Evaluate(pol, Context{Inputs: in, Root: "../..", Now: now}, nil); d.Status != StatusFailed {
+		t.Errorf("self-approval: %+v", d.Rules[0])
+	}
+	// Without reviews.author, the PR's author is used.
+	for _, author := range []any{"sam", map[string]any{"login": "sam"}} {
+		in = Inputs{
+			"pr":      map[string]any{"body": checked, "labels": []any{"security:review-needed"}, "author": author},
+			"reviews": map[string]any{"approvals": security},
+		}
+		if d := Evaluate(pol, Context{Inputs: in, Root: "../..", Now: now}, nil); d.Status != StatusFailed {
+			t.Errorf("self-approval via pr.author %v: %+v", author, d.Rules[0])
+		}
+	}
+	if d := Evaluate(pol, Context{Inputs: Inputs{}, Root: "../..", Now: now}, nil); d.Rules[0].Result != ResultSkipped {
+		t.Errorf("no inputs: %+v", d.Rules[0])
+	}
+	// Without a team the rule cannot be evaluated.
+	noTeam := RuleConfig{Severity: "block", raw: map[string]any{"sensitive_paths": []any{"identity/"}}}
+	d := Evaluate(&Policy{Rules: map[string]RuleConfig{"security.review": noTeam}}, Context{Inputs: in, Root: "../..", Now: now}, nil)
+	if r := d.Rules[0]; r.Result != ResultError || !strings.Contains(r.Message, "team is required") {
+		t.Errorf("no team: %+v", r)
+	}
+}
+
+// TestSecurityReviewEffectivePolicy runs the rule the way CI does: the
+// shipped layers are resolved, written out and loaded by the gate.
+func TestSecurityReviewEffectivePolicy(t *testing.T) {
+	eff, err := policy.Resolve(policy.FileSource{Root: filepath.Join("..", "..")}, parseLayer(t, "extends: team/identity\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := filepath.Join(t.TempDir(), "effective-policy.json")
+	raw, err := json.Marshal(eff.Policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, raw, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	resolved, err := LoadPolicy(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pol := &Policy{Rules: map[string]RuleConfig{"security.review": resolved.Rules["security.review"]}}
+
+	tmpl, err := os.ReadFile("../../PULL_REQUEST_TEMPLATE.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checked := strings.ReplaceAll(string(tmpl), "- [ ] ", "- [x] ")
+	pr := map[string]any{"body": checked, "files": []any{"services/identity/login.go"}}
+	for _, tc := range []struct {
+		teams  []any
+		result string
+	}{
+		{[]any{"BrikByte-Studios/security"}, ResultPass},
+		{[]any{"security"}, ResultFail},
+	} {
+		approvals := []any{map[string]any{"user": "sam", "teams": tc.teams}}
+		in := Inputs{"pr": pr, "reviews": map[string]any{"author": "alex", "approvals": approvals}}
+		if r := Evaluate(pol, Context{Inputs: in, Root: "../..", Now: now}, nil).Rules[0]; r.Result != tc.result {
+			t.Errorf("teams %v: %s %s", tc.teams, r.Result, r.Message)
+		}
+	}
+}
+
+func parseLayer(t *testing.T, doc string) *policy.Layer {
+	t.Helper()
+	l, err := policy.ParseLayer("repo", []byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
@@ -0,0 +1,71 @@
+package gate
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/BrikByte-Studios/github-governance/internal/gitrepo"
+	"github.com/BrikByte-Studios/github-governance/internal/hygiene"
+)
+
+func init() {
+	Register("repo.hygiene", "GATE-009", RuleFunc(repoHygiene))
+}
+
+type repoHygieneOptions struct {
+	hygiene.Config
+	// Scope is "diff" (files added or modified by the PR), "tree" (every
+	// file at head) or "" (diff when the inputs have a base, else tree).
+	Scope string `json:"scope"`
+}
+
+// HygieneEvidence is the repo.hygiene rule's evidence.
+type HygieneEvidence struct {
+	*hygiene.Report
+	GitignorePatch string `json:"gitignore_patch,omitempty"`
+}
+
+// repoHygiene scans the PR diff or the whole tree of the clone at the gate
+// root for forbidden files, OS artifacts, oversized files and stray
+// binaries.
+//
+// Inputs: meta.base_sha (diff scope), meta.head_sha (default HEAD).
+func repoHygiene(ctx Context, cfg RuleConfig) Outcome {
+	var opt repoHygieneOptions
+	if err := cfg.Decode(&opt); err != nil {
+		return Errorf("repo.hygiene options: %v", err)
+	}
+	root := or(ctx.Root, ".")
+	base, head := ctx.Inputs.String("meta.base_sha"), or(ctx.Inputs.String("meta.head_sha"), "HEAD")
+	scope := opt.Scope
+	if scope == "" {
+		scope = "tree"
+		if base != "" {
+			scope = "diff"
+		}
+	}
+	switch scope {
+	case "tree":
+		base = ""
+	case "diff":
+		if base == "" {
+			return Missing("repo.hygiene scope diff needs meta.base_sha")
+		}
+	default:
+		return Errorf("repo.hygiene: unknown scope %q (diff or tree)", scope)
+	}
+	files, err := gitrepo.Files(ctx.Ctx, root, base, head)
+	if err != nil {
+		return Fail(nil, "repo.hygiene: %v", err)
+	}
+	rep, err := hygiene.Scan(scope, files, opt.Config)
+	if err != nil {
+		return Errorf("repo.hygiene options: %v", err)
+	}
+	if len(rep.Findings) == 0 {
+		return Pass(HygieneEvidence{Report: rep}, "%d file(s) scanned (%s), no hygiene findings", rep.Files, scope)
+	}
+	existing, _ := os.ReadFile(filepath.Join(root, ".gitignore"))
+	ev := HygieneEvidence{Report: rep, GitignorePatch: hygiene.GitignorePatch(string(existing), rep.Ignore)}
+	return Fail(ev, "%d of %d file(s) violate repo hygiene (%s)", len(rep.Findings), rep.Files, rep.Summary())
+}
@@ -0,0 +1,124 @@
+package gate
+
+import (
+	"strings"
+)
+
+// Baseline rules. They read the normalised CI inputs (tests, coverage,
+// security, adr, integrity).
+func init() {
+	Register("tests.green", "GATE-001", RuleFunc(testsGreen))
+	Register("coverage.min", "GATE-002", RuleFunc(coverageMin))
+	Register("security.sca", "GATE-003", RuleFunc(func(ctx Context, cfg RuleConfig) Outcome { return securityLevel(ctx, cfg, "sca") }))
+	Register("security.sast", "GATE-004", RuleFunc(func(ctx Context, cfg RuleConfig) Outcome { return securityLevel(ctx, cfg, "sast") }))
+	Register("adr.required_for_infra", "GATE-005", RuleFunc(adrRequired))
+	Register("supplychain.signed", "GATE-006", RuleFunc(func(ctx Context, cfg RuleConfig) Outcome {
+		return integrityFlag(ctx, "integrity.signed_artifacts", "integrity.integrity_report_url", "Artifacts")
+	}))
+	Register("integrity.sbom", "GATE-007", RuleFunc(func(ctx Context, cfg RuleConfig) Outcome {
+		return integrityFlag(ctx, "integrity.sbom_present", "integrity.sbom_url", "SBOM")
+	}))
+}
+
+// testsGreen: tests.status is "green" and tests.failed is 0.
+func testsGreen(ctx Context, _ RuleConfig) Outcome {
+	status := ctx.Inputs.String("tests.status")
+	failed, hasFailed := ctx.Inputs.Number("tests.failed")
+	if status == "" && !hasFailed {
+		return Missing("No test results in inputs.tests")
+	}
+	ev := ctx.Inputs.String("tests.report_url")
+	if (status != "" && status != "green") || failed > 0 {
+		return Fail(ev, "Tests are %s (%g failed)", or(status, "failing"), failed)
+	}
+	return Pass(ev, "Tests are green")
+}
+
+// coverageMin: coverage.line is at least threshold (default 80).
+func coverageMin(ctx Context, cfg RuleConfig) Outcome {
+	line, ok := ctx.Inputs.Number("coverage.line")
+	if !ok {
+		return Missing("No line coverage in inputs.coverage")
+	}
+	min := 80.0
+	if cfg.Threshold != nil {
+		min = *cfg.Threshold
+	}
+	ev := ctx.Inputs.String("coverage.report_url")
+	if line < min {
+		return Fail(ev, "Coverage %g%% is below minimum %g%%", line, min)
+	}
+	return Pass(ev, "Coverage %g%% meets minimum %g%%", line, min)
+}
+
+// levels orders findings severities; max_level "no-<level>" forbids that
+// level and everything above it.
+var levels = []string{"low", "medium", "high", "critical"}
+
+// securityLevel: security.<tool>.count has no findings at or above
+// max_level (default no-critical).
+func securityLevel(ctx Context, cfg RuleConfig, tool string) Outcome {
+	raw, ok := ctx.Inputs.Get("security." + tool + ".count")
+	counts, isMap := raw.(map[string]any)
+	if !ok || !isMap {
+		return Missing("No %s findings in inputs.security.%s.count", strings.ToUpper(tool), tool)
+	}
+	maxLevel := or(cfg.MaxLevel, "no-critical")
+	from := len(levels) - 1
+	for i, l := range levels {
+		if "no-"+l == maxLevel {
+			from = i
+		}
+	}
+	var over []string
+	total := 0.0
+	for _, l := range levels[from:] {
+		if n, _ := counts[l].(float64); n > 0 {
+			total += n
+			over = append(over, l)
+		}
+	}
+	ev := ctx.Inputs.String("security." + tool + ".report_url")
+	if total > 0 {
+		return Fail(ev, "%s: %g finding(s) at %s violate %s", strings.ToUpper(tool), total, strings.Join(over, "/"), maxLevel)
+	}
+	return Pass(ev, "%s findings within %s", strings.ToUpper(tool), maxLevel)
+}
+
+// adrRequired: when adr.required, an ADR is referenced and none is missing.
+func adrRequired(ctx Context, _ RuleConfig) Outcome {
+	required, ok := ctx.Inputs.Bool("adr.required")
+	if !ok {
+		return Missing("No ADR evidence in inputs.adr")
+	}
+	ev := ctx.Inputs.String("adr.details_url")
+	if !required {
+		return Pass(ev, "No ADR required for this change")
+	}
+	refs, _ := ctx.Inputs.Get("adr.referenced")
+	list, _ := refs.([]any)
+	if missing, _ := ctx.Inputs.Bool("adr.missing_required"); missing || len(list) == 0 {
+		return Fail(ev, "Infrastructure change requires an ADR reference")
+	}
+	return Pass(ev, "ADR referenced (%d)", len(list))
+}
+
+// integrityFlag: a boolean integrity input is true.
+func integrityFlag(ctx Context, flag, url, what string) Outcome {
+	v, ok := ctx.Inputs.Bool(flag)
+	if !ok {
+		return Missing("No %s evidence (inputs.%s)", what, flag)
+	}
+	ev := ctx.Inputs.String(url)
+	if !v {
+		return Fail(ev, "%s evidence is false (inputs.%s)", what, flag)
+	}
+	return Pass(ev, "%s evidence present", what)
+}
+
+func or(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,345 @@
+package gate
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BrikByte-Studios/github-governance/internal/codeowners"
+	"github.com/BrikByte-Studios/github-governance/internal/gitrepo"
+	"github.com/BrikByte-Studios/github-governance/internal/reviews"
+)
+
+func init() {
+	Register("security.review", "GATE-012", RuleFunc(securityReview))
+}
+
+type securityReviewOptions struct {
+	// SensitivePaths are CODEOWNERS-style patterns of security-sensitive
+	// files; changing one triggers the review.
+	SensitivePaths []string `json:"sensitive_paths"`
+	// Labels trigger the review (default security:review-needed).
+	Labels []string `json:"labels"`
+	// Keywords trigger the review when the PR's answers in the security
+	// section mention one (case-insensitive, at a word start).
+	Keywords []string `json:"keywords"`
+	// Team must approve a triggered PR, as "@org/slug" (required). It is
+	// compared with the approvals' teams by full name.
+	Team string `json:"team"`
+	// Section and Checklist are the PR template headings the rule reads.
+	Section   string `json:"section"`
+	Checklist string `json:"checklist"`
+	// Template is the PR template, relative to the gate root; default: the
+	// first of the usual locations that exists.
+	Template string `json:"template"`
+}
+
+var prTemplates = []string{
+	"PULL_REQUEST_TEMPLATE.md", ".github/pull_request_template.md",
+	".github/PULL_REQUEST_TEMPLATE.md", "docs/pull_request_template.md",
+}
+
+// SecurityReviewEvidence is the security.review rule's evidence.
+type SecurityReviewEvidence struct {
+	Triggers []string `json:"triggers"`
+	// Approvers are the approving members of the security team.
+	Approvers []string `json:"approvers"`
+	Checklist struct {
+		Checked   int      `json:"checked"`
+		Total     int      `json:"total"`
+		Unchecked []string `json:"unchecked,omitempty"`
+	} `json:"checklist"`
+	Notes []string `json:"notes,omitempty"`
+}
+
+var (
+	checkboxRe = regexp.MustCompile(`^\s*[-*]\s*\[([ xX])\]\s*(.*)$`)
+	headingRe  = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	negativeRe = regexp.MustCompile(`(?i)^(no|none|n/?a|-)?[.!]?$`)
+)
+
+// securityReview requires an approval from the security team and a
+// completed security checklist on PRs that touch sensitive paths, carry a
+// security label, or answer the template's security questions with
+// authentication, authorization or secrets.
+//
+// Inputs: pr.body, pr.labels, pr.files (default: the diff between
+// meta.base_sha and meta.head_sha in the gate root) and reviews (the
+// reviews.json evidence: author, approvals with teams). reviews.body is
+// used when pr.body is absent, pr.author when reviews.author is.
+func securityReview(ctx Context, cfg RuleConfig) Outcome {
+	var opt securityReviewOptions
+	if err := cfg.Decode(&opt); err != nil {
+		return Errorf("security.review options: %v", err)
+	}
+	if opt.Team == "" {
+		return Errorf(`security.review options: team is required ("@org/slug")`)
+	}
+	if len(opt.Labels) == 0 {
+		opt.Labels = []string{"security:review-needed"}
+	}
+	opt.Section = or(opt.Section, "Security Considerations")
+	opt.Checklist = or(opt.Checklist, "Security checklist")
+
+	_, hasPR := ctx.Inputs.Get("pr")
+	raw, hasReviews := ctx.Inputs.Get("reviews")
+	if !hasPR && !hasReviews {
+		return Missing("security.review needs inputs.pr or inputs.reviews")
+	}
+	var rev reviews.Evidence
+	if hasReviews {
+		buf, _ := json.Marshal(raw)
+		if err := json.Unmarshal(buf, &rev); err != nil {
+			return Fail(nil, "security.review: inputs.reviews: %v", err)
+		}
+	}
+	body := strings.ReplaceAll(or(ctx.Inputs.String("pr.body"), rev.Body), "\r\n", "\n")
+	root := or(ctx.Root, ".")
+	ev := SecurityReviewEvidence{Triggers: []string{}, Approvers: []string{}}
+
+	files, note, err := changedFiles(ctx, root)
+	if err != nil {
+		return Fail(nil, "security.review: %v", err)
+	}
+	if note != "" {
+		ev.Notes = append(ev.Notes, note)
+	}
+	for _, pat := range opt.SensitivePaths {
+		re, err := codeowners.Compile(pat)
+		if err != nil {
+			return Errorf("security.review options: sensitive_paths: %q: %v", pat, err)
+		}
+		for _, f := range files {
+			if re.MatchString(f) {
+				ev.Triggers = append(ev.Triggers, fmt.Sprintf("path %s matches %s", f, pat))
+			}
+		}
+	}
+	for _, l := range stringList(ctx.Inputs, "pr.labels", "name") {
+		for _, want := range opt.Labels {
+			if strings.EqualFold(l, want) {
+				ev.Triggers = append(ev.Triggers, "label "+l)
+			}
+		}
+	}
+
+	template, err := loadTemplate(root, opt.Template)
+	if err != nil {
+		return Fail(nil, "security.review: %v", err)
+	}
+	answers, items := splitSection(section(body, opt.Section), opt.Checklist)
+	prompts, templateItems := splitSection(section(template, opt.Section), opt.Checklist)
+	keywords := make([]*regexp.Regexp, len(opt.Keywords))
+	for i, k := range opt.Keywords {
+		keywords[i] = regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(k))
+	}
+	for _, a := range answerLines(answers, prompts) {
+		for i, re := range keywords {
+			if re.MatchString(a) {
+				ev.Triggers = append(ev.Triggers, fmt.Sprintf("answer mentions %s: %q", opt.Keywords[i], a))
+				break
+			}
+		}
+	}
+	if len(ev.Triggers) == 0 {
+		return Pass(ev, "No sensitive paths, security labels or security answers; no security review needed")
+	}
+
+	// gh pr view --json author prints {"login": ...}.
+	author := or(rev.Author, or(ctx.Inputs.String("pr.author.login"), ctx.Inputs.String("pr.author")))
+	for _, a := range rev.Approvals {
+		if author != "" && strings.EqualFold(a.User, author) {
+			continue
+		}
+		for _, t := range a.Teams {
+			if teamName(t) == teamName(opt.Team) && !contains(ev.Approvers, a.User) {
+				ev.Approvers = append(ev.Approvers, a.User)
+			}
+		}
+	}
+	sort.Strings(ev.Approvers)
+
+	// Items of the template's checklist count even when the PR body drops
+	// them, so deleting an item does not complete the checklist.
+	checked := map[string]bool{}
+	for _, it := range items {
+		checked[it.text] = checked[it.text] || it.done
+	}
+	for _, it := range templateItems {
+		if _, ok := checked[it.text]; !ok {
+			checked[it.text] = false
+		}
+	}
+	for text, done := range checked {
+		ev.Checklist.Total++
+		if done {
+			ev.Checklist.Checked++
+		} else {
+			ev.Checklist.Unchecked = append(ev.Checklist.Unchecked, text)
+		}
+	}
+	sort.Strings(ev.Checklist.Unchecked)
+
+	var missing []string
+	if len(ev.Approvers) == 0 {
+		missing = append(missing, "no approval from the "+opt.Team+" team")
+	}
+	switch {
+	case ev.Checklist.Total == 0:
+		missing = append(missing, fmt.Sprintf("no %q checklist in the PR description", opt.Checklist))
+	case len(ev.Checklist.Unchecked) > 0:
+		missing = append(missing, fmt.Sprintf("%d of %d checklist item(s) unchecked", len(ev.Checklist.Unchecked), ev.Checklist.Total))
+	}
+	if len(missing) > 0 {
+		return Fail(ev, "Security review required (%s): %s", ev.Triggers[0]+more(len(ev.Triggers)-1), strings.Join(missing, "; "))
+	}
+	return Pass(ev, "Security review complete: approved by %s, checklist %d/%d", strings.Join(ev.Approvers, ", "), ev.Checklist.Checked, ev.Checklist.Total)
+}
+
+// changedFiles returns pr.files, else the diff of the clone between
+// meta.base_sha and meta.head_sha. Without either, paths cannot trigger
+// the review and the note says so.
+func changedFiles(ctx Context, root string) ([]string, string, error) {
+	if _, ok := ctx.Inputs.Get("pr.files"); ok {
+		return stringList(ctx.Inputs, "pr.files", "path"), "", nil
+	}
+	base := ctx.Inputs.String("meta.base_sha")
+	if base == "" {
+		return nil, "no pr.files or meta.base_sha; sensitive paths not checked", nil
+	}
+	files, err := gitrepo.Changed(ctx.Ctx, root, base, or(ctx.Inputs.String("meta.head_sha"), "HEAD"))
+	return files, "", err
+}
+
+// stringList reads a list input whose entries are strings or objects with
+// a key field (as gh exports labels and files).
+func stringList(in Inputs, p, key string) []string {
+	v, _ := in.Get(p)
+	list, _ := v.([]any)
+	var out []string
+	for _, e := range list {
+		switch e := e.(type) {
+		case string:
+			out = append(out, e)
+		case map[string]any:
+			if s, ok := e[key].(string); ok {
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
+
+func loadTemplate(root, name string) (string, error) {
+	candidates := prTemplates
+	if name != "" {
+		candidates = []string{name}
+	}
+	for _, c := range candidates {
+		raw, err := os.ReadFile(filepath.Join(root, c))
+		if err == nil {
+			return strings.ReplaceAll(string(raw), "\r\n", "\n"), nil
+		}
+		if name != "" || !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+// section returns the lines under the first heading containing title, up
+// to the next heading of the same or a higher level.
+func section(doc, title string) []string {
+	var out []string
+	level := 0
+	for _, l := range strings.Split(doc, "\n") {
+		if m := headingRe.FindStringSubmatch(strings.TrimSpace(l)); m != nil {
+			if level > 0 && len(m[1]) <= level {
+				break
+			}
+			if level == 0 && strings.Contains(strings.ToLower(m[2]), strings.ToLower(title)) {
+				level = len(m[1])
+				continue
+			}
+		}
+		if level > 0 {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+type checkItem struct {
+	text string
+	done bool
+}
+
+// splitSection separates the checklist items under the checklist heading
+// from the other lines of a section.
+func splitSection(lines []string, checklist string) (rest []string, items []checkItem) {
+	in := false
+	for _, l := range lines {
+		if m := headingRe.FindStringSubmatch(strings.TrimSpace(l)); m != nil {
+			in = strings.Contains(strings.ToLower(m[2]), strings.ToLower(checklist))
+			continue
+		}
+		if m := checkboxRe.FindStringSubmatch(l); m != nil {
+			if in {
+				items = append(items, checkItem{text: strings.TrimSpace(m[2]), done: m[1] != " "})
+			}
+			continue
+		}
+		rest = append(rest, l)
+	}
+	return rest, items
+}
+
+// answerLines drops the template's own lines and unanswered or negatively
+// answered prompts ("- Authentication? No"), leaving what the author wrote.
+func answerLines(lines, prompts []string) []string {
+	var out []string
+	for _, l := range lines {
+		t := strings.TrimSpace(l)
+		if t == "" || t == "---" {
+			continue
+		}
+		answer, prompt := t, false
+		for _, p := range prompts {
+			if p = strings.TrimSpace(p); len(p) > 2 && strings.HasPrefix(t, p) {
+				answer, prompt = strings.TrimSpace(t[len(p):]), true
+				break
+			}
+		}
+		if prompt && negativeRe.MatchString(answer) {
+			continue
+		}
+		out = append(out, t)
+	}
+	return out
+}
+
+// teamName normalises "@Org/slug" to "org/slug". The org is kept, so a
+// team of the same name in another org does not count.
+func teamName(t string) string {
+	return strings.TrimPrefix(strings.ToLower(strings.TrimSpace(t)), "@")
+}
+
+func more(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" and %d more", n)
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
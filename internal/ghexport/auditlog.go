@@ -0,0 +1,148 @@
+package ghexport
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AuditEntry is one organization audit log event, from the JSON export
+// (an array or one object per line, as the streaming and REST APIs write
+// it) or the CSV export. The common fields are normalised; Fields keeps
+// the whole event with CSV values as strings.
+type AuditEntry struct {
+	Time   time.Time      `json:"time"`
+	Action string         `json:"action"`
+	Actor  string         `json:"actor"`
+	Repo   string         `json:"repo"`
+	Fields map[string]any `json:"-"`
+}
+
+// String returns a field as text ("" when absent); nested values are
+// rendered as JSON.
+func (e AuditEntry) String(key string) string {
+	switch v := e.Fields[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		raw, _ := json.Marshal(v)
+		return string(raw)
+	}
+}
+
+// Bool reports whether a field is true or "true".
+func (e AuditEntry) Bool(key string) bool {
+	b, _ := strconv.ParseBool(e.String(key))
+	return b
+}
+
+// LoadAuditLog reads an audit log export; a .csv extension selects CSV.
+func LoadAuditLog(path string) ([]AuditEntry, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rows []map[string]any
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		rows, err = auditCSV(raw)
+	} else {
+		rows, err = auditJSON(raw)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	out := make([]AuditEntry, 0, len(rows))
+	for i, row := range rows {
+		e := AuditEntry{Fields: row}
+		e.Action, e.Actor, e.Repo = e.String("action"), e.String("actor"), e.String("repo")
+		if e.Action == "" {
+			return nil, fmt.Errorf("%s: event %d has no action", path, i+1)
+		}
+		if e.Time, err = auditTime(e); err != nil {
+			return nil, fmt.Errorf("%s: event %d (%s): %w", path, i+1, e.Action, err)
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+func auditJSON(raw []byte) ([]map[string]any, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) > 0 && raw[0] == '[' {
+		var rows []map[string]any
+		return rows, json.Unmarshal(raw, &rows)
+	}
+	var rows []map[string]any
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	for {
+		var row map[string]any
+		if err := dec.Decode(&row); err == io.EOF {
+			return rows, nil
+		} else if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+}
+
+// auditCSV maps each record onto its header; "data." column prefixes of
+// flattened exports are dropped.
+func auditCSV(raw []byte) ([]map[string]any, error) {
+	r := csv.NewReader(bytes.NewReader(raw))
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	header := records[0]
+	for i, h := range header {
+		header[i] = strings.TrimPrefix(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")), "data.")
+	}
+	var rows []map[string]any
+	for _, rec := range records[1:] {
+		row := map[string]any{}
+		for i, v := range rec {
+			if i < len(header) && v != "" {
+				row[header[i]] = v
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// auditTime reads @timestamp or created_at, as epoch milliseconds or
+// RFC 3339.
+func auditTime(e AuditEntry) (time.Time, error) {
+	for _, key := range []string{"@timestamp", "created_at", "timestamp"} {
+		s := e.String(key)
+		if s == "" {
+			continue
+		}
+		if ms, err := strconv.ParseFloat(s, 64); err == nil {
+			return time.UnixMilli(int64(ms)).UTC(), nil
+		}
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%s %q is not epoch milliseconds or RFC 3339", key, s)
+		}
+		return t.UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("no @timestamp or created_at")
+}
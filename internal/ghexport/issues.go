@@ -0,0 +1,115 @@
+// Package ghexport loads the JSON exports produced by `gh issue list --json`
+// and `gh pr list --json` (or the equivalent REST payloads) so governance
+// tools can run offline against a snapshot instead of calling the API.
+package ghexport
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Label accepts both the object form ({"name": "type:incident"}) used by the
+// API and gh, and a bare string used in hand-written fixtures.
+type Label struct {
+	Name string `json:"name"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (l *Label) UnmarshalJSON(b []byte) error {
+	if len(b) > 0 && b[0] == '"' {
+		return json.Unmarshal(b, &l.Name)
+	}
+	type plain Label
+	return json.Unmarshal(b, (*plain)(l))
+}
+
+// Actor accepts {"login": "alice"} as well as a bare login string.
+type Actor struct {
+	Login string `json:"login"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (a *Actor) UnmarshalJSON(b []byte) error {
+	if len(b) > 0 && b[0] == '"' {
+		return json.Unmarshal(b, &a.Login)
+	}
+	type plain Actor
+	return json.Unmarshal(b, (*plain)(a))
+}
+
+// Issue is a GitHub issue as exported by gh.
+type Issue struct {
+	Number    int        `json:"number"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	State     string     `json:"state"`
+	URL       string     `json:"url"`
+	Author    Actor      `json:"author"`
+	Assignees []Actor    `json:"assignees"`
+	Labels    []Label    `json:"labels"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt *time.Time `json:"updatedAt"`
+	ClosedAt  *time.Time `json:"closedAt"`
+}
+
+// Closed reports whether the issue is closed (gh uses "CLOSED", the REST API
+// uses "closed").
+func (i Issue) Closed() bool { return strings.EqualFold(i.State, "closed") }
+
+// HasLabel reports whether the issue carries the given label.
+func (i Issue) HasLabel(name string) bool {
+	for _, l := range i.Labels {
+		if strings.EqualFold(l.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// LabelsWithPrefix returns the suffixes of labels starting with prefix,
+// e.g. LabelsWithPrefix("area:") → ["sre"].
+func (i Issue) LabelsWithPrefix(prefix string) []string {
+	var out []string
+	for _, l := range i.Labels {
+		if strings.HasPrefix(strings.ToLower(l.Name), strings.ToLower(prefix)) {
+			out = append(out, l.Name[len(prefix):])
+		}
+	}
+	return out
+}
+
+// LoadIssues reads an export containing either a JSON array of issues or a
+// single issue object.
+func LoadIssues(path string) ([]Issue, error) {
+	var issues []Issue
+	if err := loadList(path, &issues); err != nil {
+		return nil, err
+	}
+	return issues, nil
+}
+
+// loadList decodes a JSON array into dst, accepting a single object as a
+// one-element list.
+func loadList[T any](path string, dst *[]T) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	raw = bytes.TrimSpace(raw)
+	if len(raw) > 0 && raw[0] == '{' {
+		var one T
+		if err := json.Unmarshal(raw, &one); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		*dst = []T{one}
+		return nil
+	}
+	if err := json.Unmarshal(raw, dst); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
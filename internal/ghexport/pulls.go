@@ -0,0 +1,121 @@
+package ghexport
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Review is one pull request review.
+type Review struct {
+	Author      Actor     `json:"author"`
+	State       string    `json:"state"`
+	SubmittedAt time.Time `json:"submittedAt"`
+}
+
+// CommitAuthor is one author of a PR commit (gh lists co-authors too).
+type CommitAuthor struct {
+	Login string `json:"login"`
+	Email string `json:"email"`
+	Name  string `json:"name"`
+}
+
+// PRCommit is a commit on a pull request.
+type PRCommit struct {
+	OID     string         `json:"oid"`
+	Authors []CommitAuthor `json:"authors"`
+}
+
+// Ref wraps the object id gh reports for mergeCommit.
+type Ref struct {
+	OID string `json:"oid"`
+}
+
+// File is a changed file on a pull request.
+type File struct {
+	Path      string `json:"path"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+}
+
+// PullRequest is a pull request as exported by
+//
+//	gh pr list --state all --json number,title,body,url,state,author,baseRefName,headRefName,
+//	  createdAt,isDraft,mergedAt,mergedBy,mergeCommit,commits,reviews,reviewRequests,labels,files
+type PullRequest struct {
+	Number         int        `json:"number"`
+	Title          string     `json:"title"`
+	Body           string     `json:"body"`
+	URL            string     `json:"url"`
+	State          string     `json:"state"`
+	IsDraft        bool       `json:"isDraft"`
+	Author         Actor      `json:"author"`
+	BaseRefName    string     `json:"baseRefName"`
+	HeadRefName    string     `json:"headRefName"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	MergedAt       *time.Time `json:"mergedAt"`
+	MergedBy       Actor      `json:"mergedBy"`
+	MergeCommit    *Ref       `json:"mergeCommit"`
+	Commits        []PRCommit `json:"commits"`
+	Reviews        []Review   `json:"reviews"`
+	ReviewRequests []Actor    `json:"reviewRequests"`
+	Labels         []Label    `json:"labels"`
+	Files          []File     `json:"files"`
+}
+
+// Open reports whether the PR is still open.
+func (p PullRequest) Open() bool { return strings.EqualFold(p.State, "open") }
+
+// Repo returns "owner/repo" from the PR URL, or "" when the URL is absent.
+func (p PullRequest) Repo() string {
+	rest, ok := strings.CutPrefix(p.URL, "https://github.com/")
+	if !ok {
+		return ""
+	}
+	parts := strings.Split(rest, "/")
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[0] + "/" + parts[1]
+}
+
+// HasLabel reports whether the PR carries the given label.
+func (p PullRequest) HasLabel(name string) bool {
+	for _, l := range p.Labels {
+		if strings.EqualFold(l.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Approvers returns the logins whose latest decisive review (APPROVED,
+// CHANGES_REQUESTED or DISMISSED) is an approval, sorted.
+func (p PullRequest) Approvers() []string {
+	reviews := append([]Review{}, p.Reviews...)
+	sort.SliceStable(reviews, func(i, j int) bool { return reviews[i].SubmittedAt.Before(reviews[j].SubmittedAt) })
+	latest := map[string]string{}
+	for _, r := range reviews {
+		switch strings.ToUpper(r.State) {
+		case "APPROVED", "CHANGES_REQUESTED", "DISMISSED":
+			latest[r.Author.Login] = strings.ToUpper(r.State)
+		}
+	}
+	var out []string
+	for login, state := range latest {
+		if state == "APPROVED" && login != "" {
+			out = append(out, login)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// LoadPullRequests reads a gh pr export (array or single object).
+func LoadPullRequests(path string) ([]PullRequest, error) {
+	var prs []PullRequest
+	if err := loadList(path, &prs); err != nil {
+		return nil, err
+	}
+	return prs, nil
+}
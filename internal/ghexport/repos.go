@@ -0,0 +1,43 @@
+package ghexport
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Repository is a repository as exported by
+//
+//	gh repo view --json nameWithOwner,url,pushedAt,isArchived,latestRelease,repositoryTopics
+type Repository struct {
+	NameWithOwner string    `json:"nameWithOwner"`
+	URL           string    `json:"url"`
+	PushedAt      time.Time `json:"pushedAt"`
+	IsArchived    bool      `json:"isArchived"`
+	LatestRelease *Release  `json:"latestRelease"`
+	// Topics accept gh's [{"name": …}] and bare strings, like labels.
+	Topics []Label `json:"repositoryTopics"`
+}
+
+// Release is the latest release of a repository.
+type Release struct {
+	TagName     string    `json:"tagName"`
+	PublishedAt time.Time `json:"publishedAt"`
+}
+
+// LoadRepository reads a single repository export.
+func LoadRepository(path string) (*Repository, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var r Repository
+	if err := json.Unmarshal(raw, &r); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if r.NameWithOwner == "" {
+		return nil, fmt.Errorf("%s: no nameWithOwner", path)
+	}
+	return &r, nil
+}
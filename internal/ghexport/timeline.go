@@ -0,0 +1,71 @@
+package ghexport
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event is one entry of a PR's issue timeline as returned by
+// `gh api repos/{owner}/{repo}/issues/{n}/timeline --paginate`. Only the
+// review-related events are interpreted (ready_for_review,
+// review_requested, review_request_removed, reviewed, convert_to_draft).
+type Event struct {
+	Event             string    `json:"event"`
+	CreatedAt         time.Time `json:"created_at"`
+	SubmittedAt       time.Time `json:"submitted_at"`
+	State             string    `json:"state"`
+	Actor             Actor     `json:"actor"`
+	User              Actor     `json:"user"`
+	RequestedReviewer Actor     `json:"requested_reviewer"`
+	RequestedTeam     struct {
+		Slug string `json:"slug"`
+	} `json:"requested_team"`
+}
+
+// At returns when the event happened (reviews carry submitted_at instead of
+// created_at).
+func (e Event) At() time.Time {
+	if e.CreatedAt.IsZero() {
+		return e.SubmittedAt
+	}
+	return e.CreatedAt
+}
+
+// Timelines maps a PR to its events. Keys are "owner/repo#n" or "n".
+type Timelines map[string][]Event
+
+// LoadTimelines reads a JSON object of PR key → timeline array.
+func LoadTimelines(path string) (Timelines, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var t Timelines
+	if err := json.Unmarshal(raw, &t); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for k := range t {
+		n := k
+		if i := strings.LastIndex(k, "#"); i >= 0 {
+			n = k[i+1:]
+		}
+		if _, err := strconv.Atoi(n); err != nil {
+			return nil, fmt.Errorf("%s: key %q is not \"owner/repo#n\" or \"n\"", path, k)
+		}
+	}
+	return t, nil
+}
+
+// For returns the events of a PR, preferring the repo-qualified key.
+func (t Timelines) For(repo string, number int) []Event {
+	if repo != "" {
+		if ev, ok := t[fmt.Sprintf("%s#%d", repo, number)]; ok {
+			return ev
+		}
+	}
+	return t[strconv.Itoa(number)]
+}
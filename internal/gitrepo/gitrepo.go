@@ -0,0 +1,247 @@
+// Package gitrepo reads commits and tags from a local clone with the git
+// CLI. Nothing is fetched: callers run against the checkout the workflow
+// already has (fetch-depth: 0 for ranges).
+//
+// Every call takes a context; git is killed when it is done, so a gate
+// rule that times out does not leave git running.
+package gitrepo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Commit is one commit as reported by git log.
+type Commit struct {
+	SHA            string    `json:"sha"`
+	Parents        []string  `json:"parents,omitempty"`
+	AuthorName     string    `json:"author_name"`
+	AuthorEmail    string    `json:"author_email"`
+	AuthoredAt     time.Time `json:"authored_at"`
+	CommitterName  string    `json:"committer_name"`
+	CommitterEmail string    `json:"committer_email"`
+	CommittedAt    time.Time `json:"committed_at"`
+	Subject        string    `json:"subject"`
+	// Files is set by LogFiles.
+	Files []string `json:"files,omitempty"`
+}
+
+// Run executes git in dir and returns stdout.
+func Run(ctx context.Context, dir string, args ...string) (string, error) {
+	return RunEnv(ctx, dir, nil, args...)
+}
+
+// RunEnv is Run with extra environment variables ("GNUPGHOME=...").
+func RunEnv(ctx context.Context, dir string, env []string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), ctx.Err())
+		}
+		return "", fmt.Errorf("git %s: %s", strings.Join(args, " "), strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+const (
+	fieldSep  = "\x1f"
+	recordSep = "\x1e"
+	fields    = "%H%x1f%P%x1f%an%x1f%ae%x1f%aI%x1f%cn%x1f%ce%x1f%cI%x1f%s"
+	logFormat = fields + "%x1e"
+	// fileFormat leads with the separator so --name-only output follows
+	// each record.
+	fileFormat = "%x1e" + fields + "%x1f"
+)
+
+// Log returns the commits selected by revs (e.g. "v1.3.0..v1.4.0", or
+// "--since=90.days", "--", "path") newest first.
+func Log(ctx context.Context, dir string, revs ...string) ([]Commit, error) {
+	out, err := Run(ctx, dir, append([]string{"log", "--format=" + logFormat}, revs...)...)
+	if err != nil {
+		return nil, err
+	}
+	var commits []Commit
+	for _, rec := range strings.Split(out, recordSep) {
+		rec = strings.TrimLeft(rec, "\n")
+		if rec == "" {
+			continue
+		}
+		f := strings.Split(rec, fieldSep)
+		if len(f) != 9 {
+			return nil, fmt.Errorf("git log: unexpected record %q", rec)
+		}
+		commits = append(commits, parse(f))
+	}
+	return commits, nil
+}
+
+// LogFiles is Log with the paths each commit touched (merge commits list
+// none). Pass "--" and paths in revs to limit the history to those paths.
+func LogFiles(ctx context.Context, dir string, revs ...string) ([]Commit, error) {
+	out, err := Run(ctx, dir, append([]string{"log", "--name-only", "--format=" + fileFormat}, revs...)...)
+	if err != nil {
+		return nil, err
+	}
+	var commits []Commit
+	for _, rec := range strings.Split(out, recordSep) {
+		if strings.TrimSpace(rec) == "" {
+			continue
+		}
+		f := strings.SplitN(rec, fieldSep, 10)
+		if len(f) != 10 {
+			return nil, fmt.Errorf("git log: unexpected record %q", rec)
+		}
+		c := parse(f[:9])
+		for _, line := range strings.Split(f[9], "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				c.Files = append(c.Files, line)
+			}
+		}
+		commits = append(commits, c)
+	}
+	return commits, nil
+}
+
+func parse(f []string) Commit {
+	c := Commit{
+		SHA: f[0], AuthorName: f[2], AuthorEmail: f[3],
+		CommitterName: f[5], CommitterEmail: f[6], Subject: f[8],
+	}
+	if f[1] != "" {
+		c.Parents = strings.Fields(f[1])
+	}
+	c.AuthoredAt, _ = time.Parse(time.RFC3339, f[4])
+	c.CommittedAt, _ = time.Parse(time.RFC3339, f[7])
+	return c
+}
+
+// Tag describes a tag. Lightweight tags have no tagger.
+type Tag struct {
+	Name        string    `json:"name"`
+	Annotated   bool      `json:"annotated"`
+	Commit      string    `json:"commit"`
+	TaggerName  string    `json:"tagger_name,omitempty"`
+	TaggerEmail string    `json:"tagger_email,omitempty"`
+	TaggedAt    time.Time `json:"tagged_at,omitempty"`
+}
+
+// ReadTag returns the tag and the commit it points to.
+func ReadTag(ctx context.Context, dir, name string) (*Tag, error) {
+	out, err := Run(ctx, dir, "for-each-ref", "--format=%(objecttype)%1f%(taggername)%1f%(taggeremail)%1f%(taggerdate:iso-strict)", "refs/tags/"+name)
+	if err != nil {
+		return nil, err
+	}
+	out = strings.TrimSpace(out)
+	if out == "" {
+		return nil, fmt.Errorf("tag %s not found", name)
+	}
+	f := strings.Split(out, "\x1f")
+	t := &Tag{Name: name, Annotated: f[0] == "tag"}
+	if t.Annotated && len(f) == 4 {
+		t.TaggerName = f[1]
+		t.TaggerEmail = strings.Trim(f[2], "<>")
+		t.TaggedAt, _ = time.Parse(time.RFC3339, f[3])
+	}
+	sha, err := Run(ctx, dir, "rev-parse", name+"^{commit}")
+	if err != nil {
+		return nil, err
+	}
+	t.Commit = strings.TrimSpace(sha)
+	return t, nil
+}
+
+// emptyTree is git's well-known empty tree object.
+const emptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// File is a blob in a commit's tree.
+type File struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	Binary bool   `json:"binary"`
+}
+
+// Files returns the files added or modified between base and head, or
+// every file in head when base is "". Binary detection is git's own (a NUL
+// byte in the first 8000 bytes, or a -diff/binary attribute).
+func Files(ctx context.Context, dir, base, head string) ([]File, error) {
+	if base == "" {
+		base = emptyTree
+	}
+	out, err := Run(ctx, dir, "diff", "--numstat", "-z", "--no-renames", "--diff-filter=AM", base, head)
+	if err != nil {
+		return nil, err
+	}
+	var files []File
+	for _, rec := range strings.Split(out, "\x00") {
+		f := strings.SplitN(rec, "\t", 3)
+		if len(f) != 3 {
+			continue
+		}
+		files = append(files, File{Path: f[2], Binary: f[0] == "-" && f[1] == "-"})
+	}
+	if len(files) == 0 {
+		return files, nil
+	}
+	sizes, err := Run(ctx, dir, "ls-tree", "-r", "-l", "-z", head)
+	if err != nil {
+		return nil, err
+	}
+	size := map[string]int64{}
+	for _, rec := range strings.Split(sizes, "\x00") {
+		meta, p, ok := strings.Cut(rec, "\t")
+		if f := strings.Fields(meta); ok && len(f) == 4 {
+			size[p], _ = strconv.ParseInt(f[3], 10, 64)
+		}
+	}
+	for i := range files {
+		files[i].Size = size[files[i].Path]
+	}
+	return files, nil
+}
+
+// Changed returns every path added, modified or deleted between base and
+// head; a rename is its old and its new path.
+func Changed(ctx context.Context, dir, base, head string) ([]string, error) {
+	out, err := Run(ctx, dir, "diff", "--name-only", "-z", "--no-renames", base, head)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, p := range strings.Split(out, "\x00") {
+		if p != "" {
+			paths = append(paths, p)
+		}
+	}
+	return paths, nil
+}
+
+// MergeBase returns the best common ancestor of a and b.
+func MergeBase(ctx context.Context, dir, a, b string) (string, error) {
+	out, err := Run(ctx, dir, "merge-base", a, b)
+	return strings.TrimSpace(out), err
+}
+
+// Show returns the content of path at rev; ok is false when rev has no
+// such file.
+func Show(ctx context.Context, dir, rev, path string) (content []byte, ok bool, err error) {
+	out, err := Run(ctx, dir, "ls-tree", "--name-only", rev, "--", path)
+	if err != nil || strings.TrimSpace(out) == "" {
+		return nil, false, err
+	}
+	out, err = Run(ctx, dir, "show", rev+":"+path)
+	if err != nil {
+		return nil, false, err
+	}
+	return []byte(out), true, nil
+}
@@ -0,0 +1,210 @@
+// Package hotfix implements the hotfix fast path: a PR to a hotfix branch
+// that links a SEV incident of a qualifying severity may merge with the reduced
+// approvals of hotfix.fast_path instead of the reviews requirement for the
+// branch. Every fast-path merge opens a post-merge review obligation that
+// must be cleared within hotfix.post_merge_review.due_hours; overdue
+// obligations escalate, and uncleared ones block the next release tag.
+//
+// Obligations and their clearances are .audit records (namespace "hotfix"),
+// so the trail is part of the audit bundle:
+//
+//	hotfix/<id>  kind=obligation         subject=pr-<n>
+//	hotfix/<id>  kind=post-merge-review  subject=pr-<n>
+package hotfix
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BrikByte-Studios/github-governance/internal/ghexport"
+	"github.com/BrikByte-Studios/github-governance/internal/incidents"
+	"github.com/BrikByte-Studios/github-governance/internal/issueform"
+	"github.com/BrikByte-Studios/github-governance/internal/policy"
+	"github.com/BrikByte-Studios/github-governance/internal/reviews"
+)
+
+// Modes reported in the decision.
+const (
+	ModeNotHotfix = "not-hotfix"
+	ModeStandard  = "standard"
+	ModeFastPath  = "fast-path"
+)
+
+// Config is the hotfix section of the effective policy.
+type Config struct {
+	Branches        []string        `json:"branches"`
+	FastPath        FastPath        `json:"fast_path"`
+	PostMergeReview PostMergeReview `json:"post_merge_review"`
+}
+
+// FastPath relaxes pre-merge approvals when a qualifying incident is linked.
+type FastPath struct {
+	Enabled            bool     `json:"enabled"`
+	RequiredApprovals  int      `json:"required_approvals"`
+	RequiredRoles      []string `json:"required_roles"`
+	IncidentSeverities []string `json:"incident_severities"`
+}
+
+// PostMergeReview is the obligation opened by a fast-path merge.
+type PostMergeReview struct {
+	DueHours          int      `json:"due_hours"`
+	RequiredApprovals int      `json:"required_approvals"`
+	EscalateTo        []string `json:"escalate_to"`
+	BlockReleaseTag   bool     `json:"block_release_tag"`
+}
+
+// LoadConfig reads the hotfix section of an effective policy. Without one
+// the fast path is disabled.
+func LoadConfig(path string) (*Config, error) {
+	c := &Config{Branches: []string{"hotfix/*"}}
+	if err := policy.LoadSection(path, "hotfix", c); err != nil {
+		return nil, err
+	}
+	if c.FastPath.Enabled && c.PostMergeReview.DueHours <= 0 {
+		return nil, fmt.Errorf("%s: hotfix.post_merge_review.due_hours must be > 0 when the fast path is enabled", path)
+	}
+	return c, nil
+}
+
+// Incident is the SEV incident linked to a hotfix.
+type Incident struct {
+	Number   int    `json:"number"`
+	Title    string `json:"title"`
+	URL      string `json:"url,omitempty"`
+	Severity string `json:"severity"`
+	State    string `json:"state"`
+}
+
+var linkRe = regexp.MustCompile(`(?im)\bincident\b[^#\n]*#(\d+)`)
+
+// LinkedIncident finds "Incident: #123" (or "Fixes incident #123") in a PR
+// body. It returns 0 when there is no link.
+func LinkedIncident(body string) int {
+	m := linkRe.FindStringSubmatch(body)
+	if m == nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(m[1])
+	return n
+}
+
+// FindIncident looks up an incident issue in a gh export and reads its
+// severity from the incident form.
+func FindIncident(issues []ghexport.Issue, form *issueform.Template, number int) (*Incident, error) {
+	for _, is := range issues {
+		if is.Number != number {
+			continue
+		}
+		if !is.HasLabel(incidents.IncidentLabel) {
+			return nil, fmt.Errorf("#%d is not labelled %s", number, incidents.IncidentLabel)
+		}
+		return &Incident{
+			Number:   is.Number,
+			Title:    is.Title,
+			URL:      is.URL,
+			Severity: incidents.SeverityCode(form.Parse(is.Body)["severity"]),
+			State:    strings.ToLower(is.State),
+		}, nil
+	}
+	return nil, fmt.Errorf("incident #%d not found in the issue export", number)
+}
+
+// Decision is written alongside decision.reviews.
+type Decision struct {
+	Mode     string    `json:"mode"`
+	Branch   string    `json:"branch"`
+	PR       int       `json:"pr_number"`
+	Incident *Incident `json:"incident,omitempty"`
+	// Standard is the branch requirement the fast path replaced.
+	Standard *reviews.Requirement `json:"standard_requirement,omitempty"`
+	// PostMergeReview is set when merging opens an obligation.
+	PostMergeReview *PostMergeReview `json:"post_merge_review,omitempty"`
+	Notes           []string         `json:"notes,omitempty"`
+}
+
+// Check picks the requirement for ev and evaluates it. incident may be nil
+// (no link) and incidentErr explains a link that could not be resolved.
+func Check(cfg *Config, rp *reviews.Policy, ev reviews.Evidence, incident *Incident, incidentErr error) (reviews.Result, Decision) {
+	std := rp.For(ev.Branch)
+	d := Decision{Mode: ModeNotHotfix, Branch: ev.Branch, PR: ev.PRNumber}
+	if !reviews.MatchBranch(cfg.Branches, ev.Branch) {
+		return reviews.Evaluate(std, ev), d
+	}
+	d.Mode = ModeStandard
+
+	switch {
+	case !cfg.FastPath.Enabled:
+		d.Notes = append(d.Notes, "fast path disabled by policy")
+	case incidentErr != nil:
+		d.Notes = append(d.Notes, "fast path unavailable: "+incidentErr.Error())
+	case incident == nil:
+		d.Notes = append(d.Notes, "fast path unavailable: no linked incident (add \"Incident: #<n>\" to the PR body)")
+	case !contains(cfg.FastPath.IncidentSeverities, incident.Severity):
+		d.Notes = append(d.Notes, fmt.Sprintf("fast path unavailable: incident #%d is %s, fast path requires %s",
+			incident.Number, orUnknown(incident.Severity), strings.Join(cfg.FastPath.IncidentSeverities, "/")))
+	default:
+		d.Mode = ModeFastPath
+	}
+	d.Incident = incident
+
+	if d.Mode != ModeFastPath {
+		return reviews.Evaluate(std, ev), d
+	}
+	fast := reviews.Requirement{
+		RequiredApprovals: cfg.FastPath.RequiredApprovals,
+		RequiredRoles:     append([]string{}, cfg.FastPath.RequiredRoles...),
+		Source:            "hotfix.fast_path",
+	}
+	if fast.RequiredRoles == nil {
+		fast.RequiredRoles = []string{}
+	}
+	d.Standard = &std
+	pmr := cfg.PostMergeReview
+	d.PostMergeReview = &pmr
+	d.Notes = append(d.Notes, fmt.Sprintf("merging opens a post-merge review due within %dh", pmr.DueHours))
+	return reviews.Evaluate(fast, ev), d
+}
+
+// NewObligation builds the obligation for a fast-path decision merged by
+// author at mergedAt.
+func NewObligation(d Decision, author string, mergedAt time.Time) (*Obligation, error) {
+	if d.Mode != ModeFastPath || d.PostMergeReview == nil {
+		return nil, fmt.Errorf("PR #%d did not merge through the fast path (mode %s)", d.PR, d.Mode)
+	}
+	o := &Obligation{
+		PR:                d.PR,
+		Branch:            d.Branch,
+		Author:            author,
+		MergedAt:          mergedAt.UTC(),
+		DueAt:             mergedAt.UTC().Add(time.Duration(d.PostMergeReview.DueHours) * time.Hour),
+		RequiredApprovals: d.PostMergeReview.RequiredApprovals,
+		EscalateTo:        d.PostMergeReview.EscalateTo,
+		BlockReleaseTag:   d.PostMergeReview.BlockReleaseTag,
+	}
+	if d.Incident != nil {
+		o.Incident = d.Incident.Number
+	}
+	if o.RequiredApprovals < 1 {
+		o.RequiredApprovals = 1
+	}
+	return o, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if strings.EqualFold(x, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return "of unknown severity"
+	}
+	return s
+}
@@ -0,0 +1,157 @@
+package hotfix
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BrikByte-Studios/github-governance/internal/audit"
+	"github.com/BrikByte-Studios/github-governance/internal/ghexport"
+	"github.com/BrikByte-Studios/github-governance/internal/issueform"
+	"github.com/BrikByte-Studios/github-governance/internal/reviews"
+)
+
+const effective = `{
+  "reviews": {
+    "required_approvals": 1,
+    "branches": {"hotfix/*": {"required_approvals": 2, "required_roles": ["platform-leads"]}}
+  },
+  "hotfix": {
+    "branches": ["hotfix/*"],
+    "fast_path": {"enabled": true, "required_approvals": 1, "required_roles": ["platform-leads"], "incident_severities": ["SEV-0", "SEV-1"]},
+    "post_merge_review": {"due_hours": 24, "required_approvals": 2, "escalate_to": ["@BrikByte-Studios/sre"], "block_release_tag": true}
+  }
+}`
+
+func setup(t *testing.T) (*Config, *reviews.Policy, *issueform.Template) {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), "effective-policy.json")
+	_ = os.WriteFile(p, []byte(effective), 0o644)
+	cfg, err := LoadConfig(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rp, err := reviews.LoadPolicy(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	form, err := issueform.LoadTemplate(filepath.Join("..", "..", ".github", "ISSUE_TEMPLATE", "incident.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg, rp, form
+}
+
+func incident(form *issueform.Template, n int, sev string) ghexport.Issue {
+	return ghexport.Issue{
+		Number: n, Title: "[INCIDENT] checkout down", State: "OPEN",
+		Labels: []ghexport.Label{{Name: "type:incident"}},
+		Body:   form.Render(map[string]string{"severity": sev}),
+	}
+}
+
+func TestCheck(t *testing.T) {
+	cfg, rp, form := setup(t)
+	issues := []ghexport.Issue{
+		incident(form, 500, "SEV-1 - High (major degradation; core flows severely impacted)"),
+		incident(form, 501, "SEV-3 - Low (minor impact / edge cases)"),
+	}
+	ev := reviews.Evidence{
+		Branch: "hotfix/checkout", PRNumber: 77, Author: "carol",
+		Body:      "Fixes the gateway timeout.\n\nIncident: #500\n",
+		Approvals: []reviews.Approval{{User: "alice", Teams: []string{"platform-leads"}}},
+	}
+
+	inc, err := FindIncident(issues, form, LinkedIncident(ev.Body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, d := Check(cfg, rp, ev, inc, nil)
+	if d.Mode != ModeFastPath || res.Result != reviews.ResultPass || d.Standard.RequiredApprovals != 2 {
+		t.Errorf("SEV-1: mode=%s result=%s %v", d.Mode, res.Result, res.Reasons)
+	}
+
+	low, _ := FindIncident(issues, form, 501)
+	res, d = Check(cfg, rp, ev, low, nil)
+	if d.Mode != ModeStandard || res.Result != reviews.ResultFail {
+		t.Errorf("SEV-3: mode=%s result=%s", d.Mode, res.Result)
+	}
+
+	res, d = Check(cfg, rp, ev, nil, nil)
+	if d.Mode != ModeStandard || res.Result != reviews.ResultFail || !strings.Contains(d.Notes[0], "no linked incident") {
+		t.Errorf("unlinked: mode=%s result=%s notes=%v", d.Mode, res.Result, d.Notes)
+	}
+
+	ev.Branch = "main"
+	if _, d = Check(cfg, rp, ev, inc, nil); d.Mode != ModeNotHotfix {
+		t.Errorf("main: mode=%s", d.Mode)
+	}
+}
+
+type nop struct{}
+
+func (nop) RedactBytes(b []byte) ([]byte, int) { return b, 0 }
+
+func TestObligationLifecycle(t *testing.T) {
+	cfg, rp, form := setup(t)
+	inc, _ := FindIncident([]ghexport.Issue{incident(form, 500, "SEV-0 - Critical")}, form, 500)
+	ev := reviews.Evidence{Branch: "hotfix/a", PRNumber: 77, Approvals: []reviews.Approval{{User: "alice", Teams: []string{"platform-leads"}}}}
+	_, d := Check(cfg, rp, ev, inc, nil)
+
+	merged := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	now := merged
+	store := &audit.Store{Dir: t.TempDir(), Sanitizer: nop{}, Now: func() time.Time { return now }}
+	o, err := NewObligation(d, "carol", merged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := Open(store, o); err != nil {
+		t.Fatal(err)
+	}
+
+	status := func(at time.Time) ObligationStatus {
+		t.Helper()
+		recs, _ := store.List(Namespace)
+		st, err := Evaluate(recs, at)
+		if err != nil || len(st) != 1 {
+			t.Fatalf("statuses = %v, %v", st, err)
+		}
+		return st[0]
+	}
+
+	if s := status(merged.Add(2 * time.Hour)); s.Status != StatusOpen || len(ReleaseBlockers([]ObligationStatus{s})) != 1 {
+		t.Errorf("before due: %+v", s)
+	}
+	late := merged.Add(30 * time.Hour)
+	s := status(late)
+	if s.Status != StatusOverdue || s.OverdueHours != 6 || !strings.Contains(Escalation([]ObligationStatus{s}), "@BrikByte-Studios/sre") {
+		t.Errorf("after due: %+v", s)
+	}
+
+	// The author's own review and a single reviewer are not enough.
+	now = late
+	_, _, _ = Clear(store, &Review{PR: 77, Reviewers: []string{"carol", "dave"}, CompletedAt: late})
+	if s := status(late); s.Status != StatusOverdue || len(s.Reviewers) != 1 {
+		t.Errorf("partial review: %+v", s)
+	}
+	now = late.Add(time.Hour)
+	_, _, _ = Clear(store, &Review{PR: 77, Reviewers: []string{"@erin"}, CompletedAt: now})
+	s = status(now)
+	if s.Status != StatusCleared || !s.Late || len(ReleaseBlockers([]ObligationStatus{s})) != 0 {
+		t.Errorf("cleared: %+v", s)
+	}
+}
+
+func TestEvaluateRejectsTamperedClearance(t *testing.T) {
+	store := &audit.Store{Dir: t.TempDir(), Sanitizer: nop{}}
+	rec, _, err := Clear(store, &Review{PR: 1, Reviewers: []string{"x"}, CompletedAt: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.Content = []byte(`{"pr_number":2,"reviewers":["x"]}`)
+	if _, err := Evaluate([]audit.Record{*rec}, time.Now()); err == nil {
+		t.Error("tampered clearance accepted")
+	}
+}
@@ -0,0 +1,205 @@
+package hotfix
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BrikByte-Studios/github-governance/internal/audit"
+)
+
+// Audit namespace and record kinds.
+const (
+	Namespace      = "hotfix"
+	KindObligation = "obligation"
+	KindReview     = "post-merge-review"
+)
+
+// Obligation statuses.
+const (
+	StatusOpen    = "open"
+	StatusOverdue = "overdue"
+	StatusCleared = "cleared"
+)
+
+// Obligation is the post-merge review owed by a fast-path hotfix.
+type Obligation struct {
+	PR                int       `json:"pr_number"`
+	Branch            string    `json:"branch"`
+	Incident          int       `json:"incident,omitempty"`
+	Author            string    `json:"author,omitempty"`
+	MergedAt          time.Time `json:"merged_at"`
+	DueAt             time.Time `json:"due_at"`
+	RequiredApprovals int       `json:"required_approvals"`
+	EscalateTo        []string  `json:"escalate_to,omitempty"`
+	BlockReleaseTag   bool      `json:"block_release_tag"`
+}
+
+// Review records a completed post-merge review of a hotfix PR.
+type Review struct {
+	PR          int       `json:"pr_number"`
+	Reviewers   []string  `json:"reviewers"`
+	CompletedAt time.Time `json:"completed_at"`
+	Notes       string    `json:"notes,omitempty"`
+}
+
+// Subject is the audit subject for a PR's records.
+func Subject(pr int) string { return fmt.Sprintf("pr-%d", pr) }
+
+// Open stores an obligation record.
+func Open(store *audit.Store, o *Obligation) (*audit.Record, string, error) {
+	raw, err := json.Marshal(o)
+	if err != nil {
+		return nil, "", err
+	}
+	return store.Put(Namespace, KindObligation, Subject(o.PR), raw)
+}
+
+// Clear stores a post-merge review record. Whether it clears the obligation
+// is decided by Evaluate (enough distinct reviewers other than the author).
+func Clear(store *audit.Store, r *Review) (*audit.Record, string, error) {
+	if len(r.Reviewers) == 0 {
+		return nil, "", fmt.Errorf("post-merge review of PR #%d lists no reviewers", r.PR)
+	}
+	raw, err := json.Marshal(r)
+	if err != nil {
+		return nil, "", err
+	}
+	return store.Put(Namespace, KindReview, Subject(r.PR), raw)
+}
+
+// ObligationStatus is an obligation folded with its reviews at a point in
+// time.
+type ObligationStatus struct {
+	Obligation
+	Status    string     `json:"status"`
+	Reviewers []string   `json:"reviewers"`
+	ClearedAt *time.Time `json:"cleared_at,omitempty"`
+	// Late is set when the review cleared after the due time.
+	Late bool `json:"late,omitempty"`
+	// OverdueHours is how long an uncleared obligation is past due.
+	OverdueHours int    `json:"overdue_hours,omitempty"`
+	Record       string `json:"record"`
+}
+
+// Evaluate folds the hotfix records into obligation statuses at now,
+// ordered by PR number. Records failing their integrity check are an error:
+// a tampered clearance must not unblock a release.
+func Evaluate(records []audit.Record, now time.Time) ([]ObligationStatus, error) {
+	byPR := map[int]*ObligationStatus{}
+	var reviews []Review
+	for _, rec := range records {
+		if rec.Namespace != Namespace {
+			continue
+		}
+		if err := audit.Verify(rec); err != nil {
+			return nil, err
+		}
+		switch rec.Kind {
+		case KindObligation:
+			var o Obligation
+			if err := json.Unmarshal(rec.Content, &o); err != nil {
+				return nil, fmt.Errorf("%s: %w", rec.ID, err)
+			}
+			// A re-opened obligation (e.g. re-run after a failed workflow)
+			// keeps the earliest due time.
+			if prev, ok := byPR[o.PR]; ok && !o.DueAt.Before(prev.DueAt) {
+				continue
+			}
+			byPR[o.PR] = &ObligationStatus{Obligation: o, Reviewers: []string{}, Record: rec.ID}
+		case KindReview:
+			var r Review
+			if err := json.Unmarshal(rec.Content, &r); err != nil {
+				return nil, fmt.Errorf("%s: %w", rec.ID, err)
+			}
+			reviews = append(reviews, r)
+		}
+	}
+	sort.SliceStable(reviews, func(i, j int) bool { return reviews[i].CompletedAt.Before(reviews[j].CompletedAt) })
+
+	for _, r := range reviews {
+		st, ok := byPR[r.PR]
+		if !ok || st.ClearedAt != nil || r.CompletedAt.After(now) {
+			continue
+		}
+		for _, u := range r.Reviewers {
+			u = strings.TrimPrefix(u, "@")
+			if strings.EqualFold(u, st.Author) || containsFold(st.Reviewers, u) {
+				continue
+			}
+			st.Reviewers = append(st.Reviewers, u)
+		}
+		if len(st.Reviewers) >= st.RequiredApprovals {
+			at := r.CompletedAt.UTC()
+			st.ClearedAt = &at
+			st.Late = at.After(st.DueAt)
+		}
+	}
+
+	out := make([]ObligationStatus, 0, len(byPR))
+	for _, st := range byPR {
+		switch {
+		case st.ClearedAt != nil:
+			st.Status = StatusCleared
+		case now.After(st.DueAt):
+			st.Status = StatusOverdue
+			st.OverdueHours = int(now.Sub(st.DueAt).Hours())
+		default:
+			st.Status = StatusOpen
+		}
+		out = append(out, *st)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].PR < out[j].PR })
+	return out, nil
+}
+
+// ReleaseBlockers returns the uncleared obligations that block tagging.
+func ReleaseBlockers(statuses []ObligationStatus) []ObligationStatus {
+	var out []ObligationStatus
+	for _, s := range statuses {
+		if s.Status != StatusCleared && s.BlockReleaseTag {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// Escalation renders a notification for overdue obligations, mentioning
+// each obligation's escalation contacts. It returns "" when nothing is
+// overdue.
+func Escalation(statuses []ObligationStatus) string {
+	var b strings.Builder
+	for _, s := range statuses {
+		if s.Status != StatusOverdue {
+			continue
+		}
+		if b.Len() == 0 {
+			b.WriteString("## ⏰ Overdue hotfix post-merge reviews\n\n")
+			b.WriteString("| PR | Incident | Merged | Due | Overdue | Reviews | Escalate to |\n")
+			b.WriteString("|----|----------|--------|-----|---------|---------|-------------|\n")
+		}
+		inc := "—"
+		if s.Incident > 0 {
+			inc = fmt.Sprintf("#%d", s.Incident)
+		}
+		fmt.Fprintf(&b, "| #%d | %s | %s | %s | %dh | %d/%d | %s |\n",
+			s.PR, inc, s.MergedAt.Format(time.RFC3339), s.DueAt.Format(time.RFC3339),
+			s.OverdueHours, len(s.Reviewers), s.RequiredApprovals, strings.Join(s.EscalateTo, " "))
+	}
+	if b.Len() > 0 {
+		b.WriteString("\nRecord the review with `brikgov hotfix clear --pr <n> --reviewers <a,b>`. ")
+		b.WriteString("Uncleared reviews block the next release tag.\n")
+	}
+	return b.String()
+}
+
+func containsFold(list []string, s string) bool {
+	for _, x := range list {
+		if strings.EqualFold(x, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,230 @@
+// Package hygiene checks repository content for files that should not be
+// committed: secrets and environment files, OS artifacts (Windows
+// `:Zone.Identifier` streams, .DS_Store), files over a size limit and
+// binaries outside the paths where they are expected.
+//
+// Patterns use .gitignore syntax, so a finding's pattern can be proposed as
+// a .gitignore line as-is.
+package hygiene
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/BrikByte-Studios/github-governance/internal/codeowners"
+	"github.com/BrikByte-Studios/github-governance/internal/gitrepo"
+)
+
+// Finding kinds, in the order they are checked. A file gets at most one
+// finding: the first kind that applies.
+const (
+	KindForbidden  = "forbidden"
+	KindOSArtifact = "os_artifact"
+	KindTooLarge   = "too_large"
+	KindBinary     = "binary"
+)
+
+// Defaults apply when the policy leaves a list unset.
+var (
+	DefaultForbidden = []string{
+		".env", ".env.*", "*.pem", "*.key", "*.p12", "*.pfx", "*.jks", "*.kdbx",
+		"id_rsa", "id_dsa", "id_ecdsa", "id_ed25519", ".npmrc", ".pypirc",
+	}
+	DefaultAllow       = []string{".env.example", ".env.sample", ".env.template"}
+	DefaultOSArtifacts = []string{
+		"*:Zone.Identifier", ".DS_Store", "._*", "Thumbs.db", "ehthumbs.db", "desktop.ini", "$RECYCLE.BIN/",
+	}
+)
+
+// Config is the repo.hygiene rule's policy options.
+type Config struct {
+	// Forbidden files may never be committed, unless they match Allow.
+	Forbidden []string `json:"forbidden"`
+	Allow     []string `json:"allow"`
+	// MaxFileMB is the size limit per file (0: no limit).
+	MaxFileMB float64 `json:"max_file_mb"`
+	// BinaryAllowedPaths are where binaries may live (images, fonts, ...).
+	BinaryAllowedPaths []string `json:"binary_allowed_paths"`
+	OSArtifacts        []string `json:"os_artifacts"`
+}
+
+// Finding is one file that violates the policy.
+type Finding struct {
+	Path    string `json:"path"`
+	Kind    string `json:"kind"`
+	Pattern string `json:"pattern,omitempty"`
+	Size    int64  `json:"size"`
+	Detail  string `json:"detail"`
+}
+
+// Report is the result of a scan.
+type Report struct {
+	Scope    string         `json:"scope"`
+	Files    int            `json:"files_scanned"`
+	Findings []Finding      `json:"findings"`
+	Counts   map[string]int `json:"counts"`
+	// Ignore lists the .gitignore lines that would have prevented the
+	// findings; GitignorePatch renders them as a diff.
+	Ignore []string `json:"gitignore_additions,omitempty"`
+	// Untrack lists the commands that remove the files from the index
+	// while keeping them on disk.
+	Untrack []string `json:"untrack,omitempty"`
+}
+
+type matcher struct {
+	pattern string
+	match   func(string) bool
+}
+
+func compile(pats []string) ([]matcher, error) {
+	out := make([]matcher, 0, len(pats))
+	for _, p := range pats {
+		re, err := codeowners.Compile(p)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, matcher{pattern: p, match: re.MatchString})
+	}
+	return out, nil
+}
+
+func first(ms []matcher, p string) string {
+	for _, m := range ms {
+		if m.match(p) {
+			return m.pattern
+		}
+	}
+	return ""
+}
+
+func orDefault(v, def []string) []string {
+	if v == nil {
+		return def
+	}
+	return v
+}
+
+// Scan checks files against cfg. scope is recorded in the report ("diff"
+// or "tree").
+func Scan(scope string, files []gitrepo.File, cfg Config) (*Report, error) {
+	var lists [4][]matcher
+	for i, pats := range [][]string{
+		orDefault(cfg.Forbidden, DefaultForbidden),
+		orDefault(cfg.Allow, DefaultAllow),
+		orDefault(cfg.OSArtifacts, DefaultOSArtifacts),
+		cfg.BinaryAllowedPaths,
+	} {
+		ms, err := compile(pats)
+		if err != nil {
+			return nil, err
+		}
+		lists[i] = ms
+	}
+	forbidden, allow, artifacts, binaries := lists[0], lists[1], lists[2], lists[3]
+	limit := int64(cfg.MaxFileMB * 1024 * 1024)
+
+	rep := &Report{Scope: scope, Files: len(files), Findings: []Finding{}, Counts: map[string]int{}}
+	for _, f := range files {
+		var fd *Finding
+		switch {
+		case first(forbidden, f.Path) != "" && first(allow, f.Path) == "":
+			pat := first(forbidden, f.Path)
+			fd = &Finding{Kind: KindForbidden, Pattern: pat, Detail: fmt.Sprintf("matches forbidden pattern %s", pat)}
+		case first(artifacts, f.Path) != "":
+			pat := first(artifacts, f.Path)
+			fd = &Finding{Kind: KindOSArtifact, Pattern: pat, Detail: fmt.Sprintf("OS artifact (%s)", pat)}
+		case limit > 0 && f.Size > limit:
+			fd = &Finding{Kind: KindTooLarge, Pattern: "/" + f.Path, Detail: fmt.Sprintf("%s exceeds the %g MB limit", mb(f.Size), cfg.MaxFileMB)}
+		case f.Binary && first(binaries, f.Path) == "":
+			fd = &Finding{Kind: KindBinary, Pattern: "/" + f.Path, Detail: "binary file outside binary_allowed_paths"}
+		}
+		if fd == nil {
+			continue
+		}
+		fd.Path, fd.Size = f.Path, f.Size
+		rep.Findings = append(rep.Findings, *fd)
+		rep.Counts[fd.Kind]++
+	}
+	sort.Slice(rep.Findings, func(i, j int) bool { return rep.Findings[i].Path < rep.Findings[j].Path })
+
+	seen := map[string]bool{}
+	for _, fd := range rep.Findings {
+		if !seen[fd.Pattern] {
+			seen[fd.Pattern] = true
+			rep.Ignore = append(rep.Ignore, fd.Pattern)
+		}
+		rep.Untrack = append(rep.Untrack, "git rm --cached -- "+shellQuote(fd.Path))
+	}
+	return rep, nil
+}
+
+// Summary counts the findings as "binary: 1, os_artifact: 3".
+func (r *Report) Summary() string {
+	var parts []string
+	for k, n := range r.Counts {
+		parts = append(parts, fmt.Sprintf("%s: %d", k, n))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ", ")
+}
+
+// GitignorePatch renders the lines of add that existing (the current
+// .gitignore, "" if there is none) lacks as a unified diff that `git apply`
+// accepts. It returns "" when nothing is missing.
+func GitignorePatch(existing string, add []string) string {
+	have := map[string]bool{}
+	lines := strings.Split(strings.TrimSuffix(existing, "\n"), "\n")
+	if existing == "" {
+		lines = nil
+	}
+	for _, l := range lines {
+		have[strings.TrimSpace(l)] = true
+	}
+	var missing []string
+	for _, a := range add {
+		if !have[a] {
+			have[a] = true
+			missing = append(missing, a)
+		}
+	}
+	if len(missing) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	if existing == "" {
+		b.WriteString("--- /dev/null\n+++ b/.gitignore\n")
+	} else {
+		b.WriteString("--- a/.gitignore\n+++ b/.gitignore\n")
+	}
+	n := len(missing) + 1
+	start := len(lines)
+	if start == 0 {
+		fmt.Fprintf(&b, "@@ -0,0 +1,%d @@\n", n)
+	} else {
+		// Anchor on the last line so git apply has context.
+		fmt.Fprintf(&b, "@@ -%d,1 +%d,%d @@\n %s\n", start, start, n+1, lines[start-1])
+	}
+	b.WriteString("+# repo.hygiene\n")
+	for _, m := range missing {
+		b.WriteString("+" + m + "\n")
+	}
+	if existing != "" && !strings.HasSuffix(existing, "\n") {
+		// The old last line had no newline; the context line above adds one.
+		return strings.Replace(b.String(), " "+lines[start-1]+"\n", "-"+lines[start-1]+"\n\\ No newline at end of file\n+"+lines[start-1]+"\n", 1)
+	}
+	return b.String()
+}
+
+func mb(n int64) string {
+	return fmt.Sprintf("%.1f MB", float64(n)/1024/1024)
+}
+
+func shellQuote(s string) string {
+	if strings.IndexFunc(s, func(r rune) bool {
+		return !(r == '/' || r == '.' || r == '-' || r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z')
+	}) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
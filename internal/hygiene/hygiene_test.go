@@ -0,0 +1,129 @@
+package hygiene
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BrikByte-Studios/github-governance/internal/gitrepo"
+)
+
+func repo(t *testing.T) (string, func(args ...string) string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_SYSTEM=/dev/null",
+			"GIT_AUTHOR_NAME=a", "GIT_AUTHOR_EMAIL=a@x", "GIT_COMMITTER_NAME=a", "GIT_COMMITTER_EMAIL=a@x")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "-q", "-b", "main")
+	return dir, git
+}
+
+func write(t *testing.T, dir, p string, data []byte) {
+	t.Helper()
+	full := filepath.Join(dir, p)
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(full, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScanRepo(t *testing.T) {
+	dir, git := repo(t)
+	png := append([]byte("\x89PNG\r\n\x1a\n\x00\x00"), make([]byte, 64)...)
+	write(t, dir, "README.md", []byte("# x\n"))
+	write(t, dir, ".gitignore", []byte("node_modules/\n*.log"))
+	write(t, dir, "profile/assets/banner.png", png)
+	write(t, dir, "profile/assets/banner.png:Zone.Identifier", []byte("[ZoneTransfer]\nZoneId=3\n"))
+	git("add", "-A")
+	git("commit", "-q", "-m", "base")
+	base := git("rev-parse", "HEAD")
+
+	write(t, dir, "services/api/.env", []byte("TOKEN=x\n"))
+	write(t, dir, "services/api/.env.example", []byte("TOKEN=\n"))
+	write(t, dir, "dist/app.bundle.js", []byte(strings.Repeat("x", 3*1024*1024)))
+	write(t, dir, "tools/helper.bin", png)
+	write(t, dir, "docs/a b.md", []byte("ok\n"))
+	git("add", "-A")
+	git("commit", "-q", "-m", "change")
+
+	cfg := Config{MaxFileMB: 2, BinaryAllowedPaths: []string{"profile/assets/**"}}
+
+	files, err := gitrepo.Files(context.Background(), dir, base, "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep, err := Scan("diff", files, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for _, f := range rep.Findings {
+		got[f.Path] = f.Kind
+	}
+	want := map[string]string{
+		"services/api/.env":  KindForbidden,
+		"dist/app.bundle.js": KindTooLarge,
+		"tools/helper.bin":   KindBinary,
+	}
+	if len(got) != len(want) || rep.Files != 5 {
+		t.Fatalf("diff findings %v of %d files, want %v", got, rep.Files, want)
+	}
+	for p, k := range want {
+		if got[p] != k {
+			t.Errorf("%s: %q, want %q", p, got[p], k)
+		}
+	}
+
+	files, err = gitrepo.Files(context.Background(), dir, "", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep, err = Scan("tree", files, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if rep.Counts[KindOSArtifact] != 1 || len(rep.Findings) != 4 {
+		t.Fatalf("tree: %s", rep.Summary())
+	}
+
+	// The suggested patch applies to the existing .gitignore (which has no
+	// trailing newline).
+	existing, _ := os.ReadFile(filepath.Join(dir, ".gitignore"))
+	patch := GitignorePatch(string(existing), rep.Ignore)
+	write(t, dir, "hygiene.patch", []byte(patch))
+	git("apply", "hygiene.patch")
+	after, _ := os.ReadFile(filepath.Join(dir, ".gitignore"))
+	for _, line := range []string{"*.log", "*:Zone.Identifier", ".env", "/dist/app.bundle.js", "/tools/helper.bin"} {
+		if !strings.Contains(string(after), line+"\n") {
+			t.Errorf(".gitignore after patch lacks %q:\n%s", line, after)
+		}
+	}
+	if GitignorePatch(string(after), rep.Ignore) != "" {
+		t.Error("patch not empty once applied")
+	}
+}
+
+func TestGitignorePatchNewFile(t *testing.T) {
+	p := GitignorePatch("", []string{".DS_Store", ".DS_Store"})
+	want := "--- /dev/null\n+++ b/.gitignore\n@@ -0,0 +1,2 @@\n+# repo.hygiene\n+.DS_Store\n"
+	if p != want {
+		t.Fatalf("patch:\n%s", p)
+	}
+	if shellQuote("docs/a b.md") != "'docs/a b.md'" || shellQuote("x/y.go") != "x/y.go" {
+		t.Error("shellQuote")
+	}
+}
@@ -0,0 +1,194 @@
+package impact
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// GoModule is the selection in one Go module.
+type GoModule struct {
+	// Dir is the module root, relative to the repository.
+	Dir  string `json:"dir"`
+	Path string `json:"path"`
+	// Affected are the packages whose code imports a change.
+	Affected []string `json:"affected"`
+	// Run are the packages to pass to `go test` in Dir.
+	Run []string `json:"run"`
+}
+
+// goPackage is the part of `go list -json` the graph needs.
+type goPackage struct {
+	ImportPath      string
+	Dir             string
+	GoFiles         []string
+	CgoFiles        []string
+	IgnoredGoFiles  []string
+	TestGoFiles     []string
+	XTestGoFiles    []string
+	EmbedFiles      []string
+	TestEmbedFiles  []string
+	XTestEmbedFiles []string
+	Imports         []string
+	TestImports     []string
+	XTestImports    []string
+	Module          *struct{ Path string }
+}
+
+func (p *goPackage) hasTests() bool { return len(p.TestGoFiles)+len(p.XTestGoFiles) > 0 }
+
+// goModules returns the directories of the go.mod files under root, as
+// the go command sees them (no vendor, testdata or dot directories).
+func goModules(root string) ([]string, error) {
+	var mods []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if p != root && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") ||
+				name == "testdata" || name == "vendor" || name == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() == "go.mod" {
+			rel, _ := filepath.Rel(root, filepath.Dir(p))
+			mods = append(mods, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	return mods, err
+}
+
+// goList runs `go list -e -json ./...` in a module.
+func goList(dir string) ([]*goPackage, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("go", "list", "-e", "-json", "./...")
+	cmd.Dir = dir
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("go list in %s: %v: %s", dir, err, strings.TrimSpace(stderr.String()))
+	}
+	var pkgs []*goPackage
+	dec := json.NewDecoder(&stdout)
+	for {
+		var p goPackage
+		if err := dec.Decode(&p); err == io.EOF {
+			return pkgs, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("go list in %s: %w", dir, err)
+		}
+		pkgs = append(pkgs, &p)
+	}
+}
+
+// selectGo maps the changed files onto a module's packages: a changed
+// non-test file affects its package and every importer, a changed test
+// file or testdata only its own package's tests.
+func selectGo(root, mod string, changed []string, mapped map[string]bool) (*GoModule, error) {
+	pkgs, err := goList(filepath.Join(root, filepath.FromSlash(mod)))
+	if err != nil {
+		return nil, err
+	}
+	gm := &GoModule{Dir: mod, Affected: []string{}, Run: []string{}}
+	byDir := map[string]*goPackage{}
+	importers := map[string][]string{}
+	for _, p := range pkgs {
+		if p.Module != nil && gm.Path == "" {
+			gm.Path = p.Module.Path
+		}
+		rel, err := filepath.Rel(root, p.Dir)
+		if err != nil {
+			return nil, err
+		}
+		byDir[filepath.ToSlash(rel)] = p
+		for _, imp := range p.Imports {
+			importers[imp] = append(importers[imp], p.ImportPath)
+		}
+	}
+
+	var prod []string
+	testOnly := map[string]bool{}
+	for _, f := range changed {
+		p, test := goOwner(byDir, f)
+		if p == nil {
+			continue
+		}
+		mapped[f] = true
+		if test {
+			testOnly[p.ImportPath] = true
+		} else {
+			prod = append(prod, p.ImportPath)
+		}
+	}
+	affected := closure(prod, importers)
+	gm.Affected = sortedSet(affected)
+
+	run := map[string]bool{}
+	for _, p := range pkgs {
+		if !p.hasTests() {
+			continue
+		}
+		hit := affected[p.ImportPath] || testOnly[p.ImportPath]
+		for _, imp := range append(append([]string{}, p.TestImports...), p.XTestImports...) {
+			hit = hit || affected[imp]
+		}
+		run[p.ImportPath] = hit
+	}
+	gm.Run = sortedSet(run)
+	return gm, nil
+}
+
+// goOwner finds the package a changed file belongs to and whether it only
+// affects the package's tests. Files that are neither Go sources nor
+// embedded in a package have no owner.
+func goOwner(byDir map[string]*goPackage, f string) (*goPackage, bool) {
+	if i := strings.Index("/"+f, "/testdata/"); i >= 0 {
+		dir := "."
+		if i > 0 {
+			dir = f[:i-1]
+		}
+		if p := byDir[dir]; p != nil {
+			return p, true
+		}
+	}
+	dir := path.Dir(f)
+	if p := byDir[dir]; p != nil && strings.HasSuffix(f, ".go") {
+		return p, strings.HasSuffix(f, "_test.go")
+	}
+	// Embedded files may sit below their package directory.
+	for d := dir; ; d = path.Dir(d) {
+		if p := byDir[d]; p != nil {
+			rel := strings.TrimPrefix(f, d+"/")
+			if d == "." {
+				rel = f
+			}
+			switch {
+			case contains(p.EmbedFiles, rel):
+				return p, false
+			case contains(p.TestEmbedFiles, rel), contains(p.XTestEmbedFiles, rel):
+				return p, true
+			}
+		}
+		if d == "." {
+			return nil, false
+		}
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,193 @@
+// Package impact selects the tests a change can affect: Go test packages
+// from the `go list` import graph and JS/TS test files from their relative
+// imports. A safety valve from policy (tests.impact) selects everything on
+// protected branches, when shared configuration changes, and whenever a
+// changed source file cannot be placed in a graph.
+package impact
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BrikByte-Studios/github-governance/internal/codeowners"
+	"github.com/BrikByte-Studios/github-governance/internal/policy"
+)
+
+// Config is the tests.impact policy section.
+type Config struct {
+	Enabled bool `json:"enabled"`
+	// FullRunBranches are branch globs ("release/*") that always run every
+	// test, as the pushed branch or as a pull request's target.
+	FullRunBranches []string `json:"full_run_branches"`
+	// SharedConfig are gitignore-style patterns of files every test depends
+	// on (lockfiles, toolchain and CI config).
+	SharedConfig []string `json:"shared_config"`
+}
+
+// DefaultConfig applies without a tests.impact section.
+func DefaultConfig() *Config {
+	return &Config{
+		Enabled:         true,
+		FullRunBranches: []string{"main", "release/*"},
+		SharedConfig: []string{
+			"go.mod", "go.sum", "go.work", "go.work.sum",
+			"package.json", "package-lock.json", "pnpm-lock.yaml", "yarn.lock",
+			"tsconfig*.json", "jest.config.*", "vitest.config.*", "babel.config.*", ".babelrc*",
+			"brikpipe.build.yml", "/docs/pipelines/runtime-matrix.yml", "/.github/workflows/",
+		},
+	}
+}
+
+// LoadConfig reads tests.impact from a policy file; a missing section
+// keeps the defaults, and listed fields replace them.
+func LoadConfig(path string) (*Config, error) {
+	var sec struct {
+		Impact *Config `json:"impact"`
+	}
+	sec.Impact = DefaultConfig()
+	if err := policy.LoadSection(path, "tests", &sec); err != nil {
+		return nil, err
+	}
+	return sec.Impact, nil
+}
+
+// Change is what Analyze selects from.
+type Change struct {
+	// Files are repository-relative paths, deleted ones included.
+	Files []string
+	// Branch is the pushed or pull request head branch; Target is the pull
+	// request's base branch, if any.
+	Branch, Target string
+}
+
+// Selection is the output for the build plan's test step.
+type Selection struct {
+	// Full means run every test; Reasons say why.
+	Full    bool        `json:"full"`
+	Reasons []string    `json:"reasons,omitempty"`
+	Changed []string    `json:"changed"`
+	Go      []GoModule  `json:"go"`
+	JS      JSSelection `json:"js"`
+	// Unmapped are changed files outside every graph that do not force a
+	// full run (docs, assets).
+	Unmapped []string `json:"unmapped,omitempty"`
+}
+
+// Analyze selects the tests affected by a change in the repository at
+// root.
+func Analyze(root string, c Change, cfg *Config) (*Selection, error) {
+	// go list reports absolute package directories.
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	sel := &Selection{Changed: append([]string{}, c.Files...)}
+	sort.Strings(sel.Changed)
+	full := func(format string, args ...any) {
+		sel.Full = true
+		sel.Reasons = append(sel.Reasons, fmt.Sprintf(format, args...))
+	}
+	if !cfg.Enabled {
+		full("test impact analysis is disabled by policy")
+	}
+	for _, b := range cfg.FullRunBranches {
+		if ok, _ := path.Match(b, c.Branch); ok && c.Branch != "" {
+			full("branch %s matches full_run_branches %q", c.Branch, b)
+		}
+		if ok, _ := path.Match(b, c.Target); ok && c.Target != "" {
+			full("target branch %s matches full_run_branches %q", c.Target, b)
+		}
+	}
+	for _, pat := range cfg.SharedConfig {
+		re, err := codeowners.Compile(pat)
+		if err != nil {
+			return nil, fmt.Errorf("tests.impact.shared_config: %w", err)
+		}
+		for _, f := range sel.Changed {
+			if re.MatchString(f) {
+				full("%s is shared config (%s)", f, pat)
+			}
+		}
+	}
+
+	mapped := map[string]bool{}
+	mods, err := goModules(root)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range mods {
+		gm, err := selectGo(root, m, sel.Changed, mapped)
+		if err != nil {
+			return nil, err
+		}
+		sel.Go = append(sel.Go, *gm)
+	}
+	js, err := selectJS(root, sel.Changed, mapped)
+	if err != nil {
+		return nil, err
+	}
+	sel.JS = *js
+
+	for _, f := range sel.Changed {
+		if mapped[f] {
+			continue
+		}
+		if isSource(f) {
+			full("%s is not in the Go or JS/TS import graph", f)
+			continue
+		}
+		sel.Unmapped = append(sel.Unmapped, f)
+	}
+	if sel.Full {
+		for i := range sel.Go {
+			sel.Go[i].Run = []string{"./..."}
+		}
+		sel.JS.Run = nil
+	}
+	return sel, nil
+}
+
+// isSource reports whether a file is Go or JS/TS code, which must map to
+// a graph for a partial run to be safe.
+func isSource(f string) bool {
+	if strings.HasSuffix(f, ".go") {
+		return true
+	}
+	for _, ext := range jsExts {
+		if strings.HasSuffix(f, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// closure returns the nodes that reach a seed through reverse edges
+// (importers of importers), seeds included.
+func closure(seeds []string, importers map[string][]string) map[string]bool {
+	seen := map[string]bool{}
+	queue := append([]string{}, seeds...)
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		if seen[n] {
+			continue
+		}
+		seen[n] = true
+		queue = append(queue, importers[n]...)
+	}
+	return seen
+}
+
+func sortedSet(m map[string]bool) []string {
+	out := make([]string, 0, len(m))
+	for k, ok := range m {
+		if ok {
+			out = append(out, k)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
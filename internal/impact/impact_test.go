@@ -0,0 +1,175 @@
+package impact
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func write(t *testing.T, root, rel, content string) {
+	t.Helper()
+	p := filepath.Join(root, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// fixture is a repository with a Go module in svc/ (a ← b ← cmd, c on its
+// own, b's external test importing c) and a TypeScript app in web/.
+func fixture(t *testing.T) string {
+	root := t.TempDir()
+	write(t, root, "svc/go.mod", "module example.com/svc\n\ngo 1.22\n")
+	write(t, root, "svc/a/a.go", "package a\n\nimport _ \"embed\"\n\n//go:embed data/names.txt\nvar Names string\n")
+	write(t, root, "svc/a/data/names.txt", "x\n")
+	write(t, root, "svc/a/a_test.go", "package a\n\nimport \"testing\"\n\nfunc TestA(t *testing.T) {}\n")
+	write(t, root, "svc/a/testdata/in.txt", "x\n")
+	write(t, root, "svc/b/b.go", "package b\n\nimport \"example.com/svc/a\"\n\nvar B = a.Names\n")
+	write(t, root, "svc/b/b_test.go", "package b_test\n\nimport (\n\t\"testing\"\n\n\t_ \"example.com/svc/c\"\n)\n\nfunc TestB(t *testing.T) {}\n")
+	write(t, root, "svc/c/c.go", "package c\n")
+	write(t, root, "svc/c/c_test.go", "package c\n\nimport \"testing\"\n\nfunc TestC(t *testing.T) {}\n")
+	write(t, root, "svc/cmd/main.go", "package main\n\nimport \"example.com/svc/b\"\n\nfunc main() { _ = b.B }\n")
+
+	write(t, root, "web/src/util.ts", "export const id = (x: string) => x;\n")
+	write(t, root, "web/src/api/index.ts", "import { id } from '../util';\nexport { id };\n")
+	write(t, root, "web/src/view.tsx", "import type { Props } from './props.js';\nconst api = await import(\"./api\");\n")
+	write(t, root, "web/src/props.ts", "export type Props = {};\n")
+	write(t, root, "web/src/view.test.tsx", "import './view';\n")
+	write(t, root, "web/test/api.spec.ts", "import {\n  id,\n} from '../src/api';\nimport { x } from 'vitest';\n")
+	write(t, root, "web/src/__tests__/legacy.js", "const gone = require('../gone');\n")
+	write(t, root, "web/src/alone.test.ts", "test('x', () => {});\n")
+	write(t, root, "web/node_modules/dep/index.test.js", "require('../../src/util');\n")
+	return root
+}
+
+func analyze(t *testing.T, root string, files ...string) *Selection {
+	t.Helper()
+	cfg := DefaultConfig()
+	sel, err := Analyze(root, Change{Files: files, Branch: "feature/x", Target: "develop"}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sel
+}
+
+func TestGoSelection(t *testing.T) {
+	root := fixture(t)
+	cases := []struct {
+		changed  []string
+		affected []string
+		run      []string
+	}{
+		// a change reaches every importer; c's change reaches b's external test.
+		{[]string{"svc/a/a.go"}, []string{"example.com/svc/a", "example.com/svc/b", "example.com/svc/cmd"},
+			[]string{"example.com/svc/a", "example.com/svc/b"}},
+		{[]string{"svc/c/c.go"}, []string{"example.com/svc/c"}, []string{"example.com/svc/b", "example.com/svc/c"}},
+		{[]string{"svc/a/data/names.txt"}, []string{"example.com/svc/a", "example.com/svc/b", "example.com/svc/cmd"},
+			[]string{"example.com/svc/a", "example.com/svc/b"}},
+		// test files and testdata stay in their package.
+		{[]string{"svc/a/a_test.go"}, []string{}, []string{"example.com/svc/a"}},
+		{[]string{"svc/a/testdata/in.txt"}, []string{}, []string{"example.com/svc/a"}},
+		{[]string{"README.md"}, []string{}, []string{}},
+	}
+	for _, c := range cases {
+		sel := analyze(t, root, c.changed...)
+		if sel.Full || len(sel.Go) != 1 {
+			t.Fatalf("%v: full=%t %v, %d modules", c.changed, sel.Full, sel.Reasons, len(sel.Go))
+		}
+		gm := sel.Go[0]
+		if gm.Dir != "svc" || gm.Path != "example.com/svc" {
+			t.Errorf("module = %s %s", gm.Dir, gm.Path)
+		}
+		if !reflect.DeepEqual(gm.Affected, c.affected) || !reflect.DeepEqual(gm.Run, c.run) {
+			t.Errorf("%v: affected %v run %v, want %v %v", c.changed, gm.Affected, gm.Run, c.affected, c.run)
+		}
+	}
+	if sel := analyze(t, root, "README.md"); !reflect.DeepEqual(sel.Unmapped, []string{"README.md"}) {
+		t.Errorf("unmapped = %v", sel.Unmapped)
+	}
+}
+
+func TestJSSelection(t *testing.T) {
+	root := fixture(t)
+	cases := []struct {
+		changed []string
+		run     []string
+	}{
+		{[]string{"web/src/util.ts"}, []string{"web/src/view.test.tsx", "web/test/api.spec.ts"}},
+		// .js specifiers resolve to TypeScript sources.
+		{[]string{"web/src/props.ts"}, []string{"web/src/view.test.tsx"}},
+		{[]string{"web/src/alone.test.ts"}, []string{"web/src/alone.test.ts"}},
+		// a deleted module still reaches the tests that imported it.
+		{[]string{"web/src/gone.js"}, []string{"web/src/__tests__/legacy.js"}},
+	}
+	for _, c := range cases {
+		sel := analyze(t, root, c.changed...)
+		if sel.Full {
+			t.Fatalf("%v: full run: %v", c.changed, sel.Reasons)
+		}
+		if !reflect.DeepEqual(sel.JS.Run, c.run) {
+			t.Errorf("%v: run %v, want %v", c.changed, sel.JS.Run, c.run)
+		}
+		if sel.JS.Tests != 4 {
+			t.Errorf("tests = %d, want 4 (node_modules skipped)", sel.JS.Tests)
+		}
+	}
+}
+
+func TestSafetyValve(t *testing.T) {
+	root := fixture(t)
+	cfg := DefaultConfig()
+	cases := []struct {
+		name   string
+		change Change
+		reason string
+	}{
+		{"main", Change{Files: []string{"svc/c/c.go"}, Branch: "main"}, "branch main"},
+		{"release target", Change{Files: []string{"svc/c/c.go"}, Branch: "fix/x", Target: "release/1.4"}, "target branch release/1.4"},
+		{"lockfile", Change{Files: []string{"web/package-lock.json"}, Branch: "fix/x"}, "web/package-lock.json is shared config"},
+		{"go.sum", Change{Files: []string{"svc/go.sum"}, Branch: "fix/x"}, "svc/go.sum is shared config"},
+		{"workflow", Change{Files: []string{".github/workflows/ci.yml"}, Branch: "fix/x"}, "is shared config"},
+		{"unmapped source", Change{Files: []string{"tools/gen.go"}, Branch: "fix/x"}, "tools/gen.go is not in the Go or JS/TS import graph"},
+	}
+	for _, c := range cases {
+		sel, err := Analyze(root, c.change, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sel.Full || !strings.Contains(strings.Join(sel.Reasons, "\n"), c.reason) {
+			t.Errorf("%s: full=%t %v", c.name, sel.Full, sel.Reasons)
+		}
+		if !reflect.DeepEqual(sel.Go[0].Run, []string{"./..."}) || sel.JS.Run != nil {
+			t.Errorf("%s: a full run selects everything: %v %v", c.name, sel.Go[0].Run, sel.JS.Run)
+		}
+	}
+
+	off := DefaultConfig()
+	off.Enabled = false
+	if sel, _ := Analyze(root, Change{Files: []string{"svc/c/c.go"}}, off); !sel.Full {
+		t.Error("disabled analysis must run everything")
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	write(t, dir, "none.yml", "version: 1\ntests:\n  coverage_min: 70\n")
+	cfg, err := LoadConfig(filepath.Join(dir, "none.yml"))
+	if err != nil || !reflect.DeepEqual(cfg, DefaultConfig()) {
+		t.Fatalf("missing section = %+v, %v", cfg, err)
+	}
+	write(t, dir, "set.yml", "version: 1\ntests:\n  impact:\n    full_run_branches: [main, hotfix/*]\n")
+	cfg, err = LoadConfig(filepath.Join(dir, "set.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.Enabled || !reflect.DeepEqual(cfg.FullRunBranches, []string{"main", "hotfix/*"}) || len(cfg.SharedConfig) == 0 {
+		t.Errorf("config = %+v", cfg)
+	}
+	if cfg, err := LoadConfig("../../.github/policy.yml"); err != nil || !reflect.DeepEqual(cfg, DefaultConfig()) {
+		t.Errorf("org policy tests.impact = %+v, %v; keep it in sync with DefaultConfig", cfg, err)
+	}
+}
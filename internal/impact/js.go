@@ -0,0 +1,159 @@
+package impact
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// JSSelection is the selection of JS/TS test files.
+type JSSelection struct {
+	// Tests is the number of test files found.
+	Tests int `json:"tests"`
+	// Run are the test files to pass to the test runner, relative to the
+	// repository.
+	Run []string `json:"run"`
+}
+
+// jsExts are the module extensions, in resolution order.
+var jsExts = []string{".ts", ".tsx", ".mts", ".cts", ".js", ".jsx", ".mjs", ".cjs"}
+
+var (
+	// importRe covers import/export ... from, side-effect imports, and
+	// require, dynamic import and jest/vi mocks.
+	importRe = regexp.MustCompile(`(?:\b(?:import|export)\s+(?:[^'"();]*?\s+from\s+)?|\b(?:require|import|jest\.mock|vi\.mock)\s*\(\s*)['"]([^'"\n]+)['"]`)
+	testRe   = regexp.MustCompile(`(?:^|/)(?:__tests__/.*|[^/]+\.(?:test|spec)\.[cm]?[jt]sx?)$`)
+)
+
+// skipDirs are build output and dependency directories.
+var skipDirs = map[string]bool{
+	".git": true, "node_modules": true, "dist": true, "build": true, "coverage": true,
+	"out": true, ".next": true, "vendor": true,
+}
+
+func isJS(f string) bool {
+	for _, ext := range jsExts {
+		if strings.HasSuffix(f, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+func isTest(f string) bool { return isJS(f) && testRe.MatchString(f) }
+
+// selectJS parses the relative imports of every JS/TS file under root and
+// selects the test files that reach a changed file. Package imports are
+// not followed; lockfile and package.json changes are shared config.
+func selectJS(root string, changed []string, mapped map[string]bool) (*JSSelection, error) {
+	files := map[string]bool{}
+	var sources []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != root && skipDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, _ := filepath.Rel(root, p)
+		rel = filepath.ToSlash(rel)
+		files[rel] = true
+		if isJS(rel) {
+			sources = append(sources, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	importers := map[string][]string{}
+	// dangling are relative imports that resolve to no file, by their
+	// joined path: a changed file may be a deleted module.
+	dangling := map[string][]string{}
+	sel := &JSSelection{Run: []string{}}
+	var tests []string
+	for _, src := range sources {
+		if isTest(src) {
+			tests = append(tests, src)
+		}
+		raw, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(src)))
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range importRe.FindAllStringSubmatch(string(raw), -1) {
+			spec := m[1]
+			if !strings.HasPrefix(spec, "./") && !strings.HasPrefix(spec, "../") && spec != "." && spec != ".." {
+				continue
+			}
+			base := path.Join(path.Dir(src), spec)
+			if target, ok := resolve(base, files); ok {
+				importers[target] = append(importers[target], src)
+			} else {
+				dangling[base] = append(dangling[base], src)
+			}
+		}
+	}
+	sel.Tests = len(tests)
+
+	var seeds []string
+	for _, f := range changed {
+		hit := files[f] && isJS(f) || isTest(f) || len(importers[f]) > 0
+		seeds = append(seeds, f)
+		for base, from := range dangling {
+			if candidate(base, f) {
+				seeds = append(seeds, from...)
+				hit = true
+			}
+		}
+		if hit {
+			mapped[f] = true
+		}
+	}
+	reach := closure(seeds, importers)
+	for _, t := range tests {
+		if reach[t] {
+			sel.Run = append(sel.Run, t)
+		}
+	}
+	return sel, nil
+}
+
+// resolve finds the file a relative import names, trying extensions,
+// index files and TypeScript sources behind .js specifiers.
+func resolve(base string, files map[string]bool) (string, bool) {
+	for _, c := range candidates(base) {
+		if files[c] {
+			return c, true
+		}
+	}
+	return "", false
+}
+
+func candidates(base string) []string {
+	out := []string{base}
+	for _, ext := range jsExts {
+		out = append(out, base+ext)
+	}
+	for _, ext := range jsExts {
+		out = append(out, base+"/index"+ext)
+	}
+	if ext := path.Ext(base); ext == ".js" || ext == ".jsx" || ext == ".mjs" || ext == ".cjs" {
+		stem := strings.TrimSuffix(base, ext)
+		for _, ts := range []string{".ts", ".tsx", ".mts", ".cts"} {
+			out = append(out, stem+ts)
+		}
+	}
+	out = append(out, base+".json")
+	return out
+}
+
+func candidate(base, f string) bool {
+	return contains(candidates(base), f)
+}
@@ -0,0 +1,154 @@
+// Package incidents turns the free-text follow-up sections of incident
+// issues and postmortems into tracked action items.
+//
+// Action items are Markdown checkboxes, optionally annotated with an owner,
+// a due date and a link to the task issue that implements them:
+//
+//	Prevention:
+//	- [ ] Add timeout budget to gateway client @BrikByte-Studios/sre due:2026-03-10 #412
+//	- [x] Alert on p99 > 2s owner: @alice (due 2026-03-01)
+//
+// The tracker reports open, overdue and closed actions per incident and per
+// team, drafts task issues for untracked actions and flags repeat incidents.
+package incidents
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Status of an action item. The three values are disjoint: an overdue action
+// is not counted as open.
+type Status string
+
+const (
+	StatusOpen    Status = "open"
+	StatusOverdue Status = "overdue"
+	StatusClosed  Status = "closed"
+)
+
+// Action is a single follow-up item extracted from an incident or postmortem.
+type Action struct {
+	Incident int    `json:"incident"`
+	Source   string `json:"source"`
+	Category string `json:"category,omitempty"`
+	Text     string `json:"text"`
+	Checked  bool   `json:"checked"`
+	Owner    string `json:"owner,omitempty"`
+	Team     string `json:"team"`
+	Due      string `json:"due,omitempty"`
+	Task     int    `json:"task,omitempty"`
+	// TaskMatched is set when Task was found by title (a task created from
+	// a draft) rather than linked on the action line.
+	TaskMatched bool   `json:"task_matched,omitempty"`
+	Status      Status `json:"status"`
+}
+
+var (
+	checkboxRe = regexp.MustCompile(`^\s*[-*+]\s+\[([ xX])\]\s*(.*)$`)
+	ownerRe    = regexp.MustCompile(`(?i)(?:owner:\s*)?@([A-Za-z0-9][A-Za-z0-9_.-]*(?:/[A-Za-z0-9][A-Za-z0-9_.-]*)?)`)
+	dueRe      = regexp.MustCompile(`(?i)\(?\b(?:due|by)[:\s]\s*(\d{4}-\d{2}-\d{2})\)?`)
+	taskRefRe  = regexp.MustCompile(`(?:^|[\s(])#(\d+)\b|/issues/(\d+)\b`)
+	headingRe  = regexp.MustCompile(`^\s*#{1,6}\s+(.+?)\s*:?\s*$`)
+	categoryRe = regexp.MustCompile(`^\s*([A-Za-z][A-Za-z /&-]{1,60}):\s*$`)
+)
+
+// ExtractActions returns every checkbox item in text. Plain lines and headings
+// preceding a checkbox list ("Prevention:", "## Detection") become the
+// category of the items below them.
+func ExtractActions(incident int, source, text string) []Action {
+	var (
+		out      []Action
+		category string
+	)
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if m := checkboxRe.FindStringSubmatch(line); m != nil {
+			a := parseAction(m[2])
+			// Template placeholders ("- [ ] ...", "- [ ] Create tasks/bugs:")
+			// carry no information.
+			if strings.Trim(a.Text, ". ") == "" || strings.HasSuffix(a.Text, ":") {
+				continue
+			}
+			a.Incident = incident
+			a.Source = source
+			a.Category = category
+			a.Checked = m[1] != " "
+			out = append(out, a)
+			continue
+		}
+		if m := headingRe.FindStringSubmatch(line); m != nil {
+			category = strings.ToLower(m[1])
+		} else if m := categoryRe.FindStringSubmatch(line); m != nil {
+			category = strings.ToLower(strings.TrimSpace(m[1]))
+		}
+	}
+	return out
+}
+
+// parseAction pulls owner, due date and task reference out of the item text
+// and returns what is left as the human-readable description.
+func parseAction(raw string) Action {
+	var a Action
+	rest := raw
+
+	if m := dueRe.FindStringSubmatchIndex(rest); m != nil {
+		a.Due = rest[m[2]:m[3]]
+		rest = rest[:m[0]] + rest[m[1]:]
+	}
+	if m := ownerRe.FindStringSubmatchIndex(rest); m != nil {
+		a.Owner = "@" + rest[m[2]:m[3]]
+		rest = rest[:m[0]] + rest[m[1]:]
+	}
+	if m := taskRefRe.FindStringSubmatchIndex(rest); m != nil {
+		num := ""
+		if m[2] >= 0 {
+			num = rest[m[2]:m[3]]
+		} else {
+			num = rest[m[4]:m[5]]
+		}
+		a.Task, _ = strconv.Atoi(num)
+		rest = rest[:m[0]] + rest[m[1]:]
+	}
+
+	rest = strings.ReplaceAll(rest, "()", "")
+	a.Text = strings.Trim(strings.Join(strings.Fields(rest), " "), " -—,;")
+	return a
+}
+
+// dueDate parses the action's due date; ok is false when none was given or
+// it is malformed.
+func (a Action) dueDate() (time.Time, bool) {
+	if a.Due == "" {
+		return time.Time{}, false
+	}
+	t, err := time.Parse("2006-01-02", a.Due)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// resolveStatus computes the action status at time now. A linked task issue
+// that is closed completes the action even if the checkbox was never ticked.
+func (a *Action) resolveStatus(now time.Time, taskClosed bool) {
+	switch {
+	case a.Checked || taskClosed:
+		a.Status = StatusClosed
+	case isPast(a, now):
+		a.Status = StatusOverdue
+	default:
+		a.Status = StatusOpen
+	}
+}
+
+func isPast(a *Action, now time.Time) bool {
+	due, ok := a.dueDate()
+	if !ok {
+		return false
+	}
+	// Due dates are whole days in UTC; the action is overdue from the
+	// following midnight.
+	return !now.UTC().Before(due.AddDate(0, 0, 1))
+}
@@ -0,0 +1,172 @@
+package incidents
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BrikByte-Studios/github-governance/internal/ghexport"
+	"github.com/BrikByte-Studios/github-governance/internal/issueform"
+)
+
+func form(t *testing.T, name string) *issueform.Template {
+	t.Helper()
+	tpl, err := issueform.LoadTemplate(filepath.Join("..", "..", ".github", "ISSUE_TEMPLATE", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tpl
+}
+
+func incidentIssue(n int, created string, failureMode, followUps string) ghexport.Issue {
+	ts, _ := time.Parse(time.RFC3339, created)
+	return ghexport.Issue{
+		Number:    n,
+		Title:     "[INCIDENT] gateway timeouts",
+		State:     "OPEN",
+		Labels:    []ghexport.Label{{Name: "type:incident"}},
+		CreatedAt: ts,
+		Body: "### Severity (SEV-*)\n\nSEV-1 - High (major degradation; core flows severely impacted)\n\n" +
+			"### Failure Mode (Inversion Thinking)\n\n" + failureMode + "\n\n" +
+			"### Follow-up Actions / Action Items\n\n" + followUps + "\n",
+	}
+}
+
+func TestExtractActions(t *testing.T) {
+	text := `Prevention:
+- [ ] Add timeout budget to gateway client @BrikByte-Studios/sre due:2026-03-10 #412
+- [ ] ...
+
+Detection:
+- [x] Alert on p99 > 2s owner: @alice (due 2026-03-01)
+
+Governance/quality:
+- [ ] Create tasks/bugs:`
+
+	got := ExtractActions(7, "issue#7", text)
+	if len(got) != 2 {
+		t.Fatalf("got %d actions, want 2: %+v", len(got), got)
+	}
+	a := got[0]
+	if a.Text != "Add timeout budget to gateway client" || a.Owner != "@BrikByte-Studios/sre" ||
+		a.Due != "2026-03-10" || a.Task != 412 || a.Category != "prevention" || a.Checked {
+		t.Errorf("first action = %+v", a)
+	}
+	b := got[1]
+	if b.Text != "Alert on p99 > 2s" || b.Owner != "@alice" || b.Due != "2026-03-01" || !b.Checked || b.Category != "detection" {
+		t.Errorf("second action = %+v", b)
+	}
+}
+
+func TestTrackCountsAndTeams(t *testing.T) {
+	now := time.Date(2026, 3, 5, 12, 0, 0, 0, time.UTC)
+	closedTask := ghexport.Issue{Number: 500, State: "CLOSED", Labels: []ghexport.Label{{Name: "type:task"}}}
+	issues := []ghexport.Issue{
+		incidentIssue(10, "2026-02-25T14:00:00Z", "Retry storm", `- [ ] Overdue fix @bob due:2026-03-01
+- [ ] Future fix @BrikByte-Studios/sre due:2026-04-01
+- [ ] Linked fix #500
+- [x] Done fix`),
+		closedTask,
+	}
+	rep := Track(Input{
+		Form:        form(t, "incident.yml"),
+		Issues:      issues,
+		Teams:       map[string]string{"bob": "payments"},
+		Now:         now,
+		Postmortems: []Postmortem{{Incident: 10, Path: "pm/10.md", Body: "## Actions\n- [ ] Write runbook @carol"}},
+	})
+
+	if len(rep.Incidents) != 1 {
+		t.Fatalf("incidents = %d", len(rep.Incidents))
+	}
+	ir := rep.Incidents[0]
+	if ir.Severity != "SEV-1" {
+		t.Errorf("severity = %q", ir.Severity)
+	}
+	want := Counts{Open: 2, Overdue: 1, Closed: 2}
+	if ir.Counts != want {
+		t.Errorf("counts = %+v, want %+v", ir.Counts, want)
+	}
+	if ir.Untracked != 3 {
+		t.Errorf("untracked = %d, want 3", ir.Untracked)
+	}
+
+	teams := map[string]Counts{}
+	for _, tr := range rep.Teams {
+		teams[tr.Team] = tr.Counts
+	}
+	if teams["payments"].Overdue != 1 || teams["sre"].Open != 1 || teams["unknown"].Open != 1 || teams[Unassigned].Closed != 2 {
+		t.Errorf("teams = %+v", teams)
+	}
+
+	drafts := DraftTasks(rep, form(t, "task.yml"))
+	if len(drafts) != 3 {
+		t.Fatalf("drafts = %d, want 3", len(drafts))
+	}
+	d := drafts[0]
+	if !strings.HasPrefix(d.Title, "[TASK] Overdue fix") || d.Assignees[0] != "bob" {
+		t.Errorf("draft = %+v", d)
+	}
+	if !strings.Contains(d.Body, "### Priority\n\nP1 - High") {
+		t.Errorf("draft body missing priority:\n%s", d.Body)
+	}
+}
+
+func TestTrackLinksCreatedTasks(t *testing.T) {
+	now := time.Date(2026, 3, 5, 12, 0, 0, 0, time.UTC)
+	in := Input{
+		Form:   form(t, "incident.yml"),
+		Issues: []ghexport.Issue{incidentIssue(10, "2026-02-25T14:00:00Z", "Retry storm", "- [ ] Cap retry fan-out @bob\n- [ ] Add timeout budget @bob")},
+		Now:    now,
+	}
+	drafts := DraftTasks(Track(in), form(t, "task.yml"))
+	if len(drafts) != 2 {
+		t.Fatalf("drafts = %d, want 2", len(drafts))
+	}
+
+	// The first draft became issue #600 and was closed; another incident's
+	// task with the same title does not count.
+	var labels []ghexport.Label
+	for _, l := range drafts[0].Labels {
+		labels = append(labels, ghexport.Label{Name: l})
+	}
+	created := ghexport.Issue{Number: 600, Title: drafts[0].Title, Body: drafts[0].Body, State: "CLOSED", Labels: labels}
+	other := ghexport.Issue{Number: 601, Title: drafts[1].Title, Body: "- Incident #11", State: "CLOSED", Labels: labels}
+	in.Issues = append(in.Issues, created, other)
+
+	rep := Track(in)
+	a, b := rep.Incidents[0].Actions[0], rep.Incidents[0].Actions[1]
+	if a.Task != 600 || !a.TaskMatched || a.Status != StatusClosed {
+		t.Errorf("created task not linked: %+v", a)
+	}
+	if b.Task != 0 || b.Status != StatusOpen {
+		t.Errorf("task of another incident linked: %+v", b)
+	}
+	if d := DraftTasks(rep, form(t, "task.yml")); len(d) != 1 || d[0].Action != b.Text {
+		t.Errorf("drafts after linking = %+v", d)
+	}
+}
+
+func TestRepeatIncidents(t *testing.T) {
+	mode := "What assumption failed? Gateway always answers within 2s"
+	issues := []ghexport.Issue{
+		incidentIssue(1, "2026-01-01T00:00:00Z", mode, "- [ ] Add timeout @alice"),
+		incidentIssue(2, "2026-02-01T00:00:00Z", mode, "- [x] Add timeout @alice"),
+		incidentIssue(3, "2026-03-01T00:00:00Z", "Disk full", "- [ ] Alert on disk"),
+	}
+	issues[1].Labels = append(issues[1].Labels, ghexport.Label{Name: "failure-mode:other"})
+	issues = append(issues, incidentIssue(4, "2026-03-02T00:00:00Z", mode, ""))
+
+	rep := Track(Input{Form: form(t, "incident.yml"), Issues: issues, Now: time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)})
+	if len(rep.Repeats) != 1 {
+		t.Fatalf("repeats = %+v", rep.Repeats)
+	}
+	r := rep.Repeats[0]
+	if r.Incident != 4 || len(r.Previous) != 1 || r.Previous[0] != 1 || r.OpenActions != 1 {
+		t.Errorf("repeat = %+v", r)
+	}
+	if r.FailureMode != "gateway always answers within 2s" {
+		t.Errorf("failure mode key = %q", r.FailureMode)
+	}
+}
@@ -0,0 +1,88 @@
+package incidents
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/BrikByte-Studios/github-governance/internal/ghexport"
+)
+
+// FailureModeLabelPrefix lets triagers pin a stable failure-mode key on an
+// incident (e.g. "failure-mode:gateway-timeout") instead of relying on the
+// free-text field.
+const FailureModeLabelPrefix = "failure-mode:"
+
+// Repeat links an incident to earlier incidents with the same failure mode
+// whose follow-up actions were not completed.
+type Repeat struct {
+	FailureMode  string `json:"failure_mode"`
+	Incident     int    `json:"incident"`
+	Previous     []int  `json:"previous"`
+	OpenActions  int    `json:"open_actions"`
+	NoActionsFor []int  `json:"no_actions_for,omitempty"`
+}
+
+// failureModeKey returns the grouping key for an incident: the failure-mode
+// label if present, otherwise the normalised failure_mode field with the
+// template's prompt lines removed.
+func failureModeKey(is ghexport.Issue, field string) string {
+	if l := is.LabelsWithPrefix(FailureModeLabelPrefix); len(l) > 0 {
+		return strings.ToLower(strings.TrimSpace(l[0]))
+	}
+	var parts []string
+	for _, line := range strings.Split(field, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasSuffix(line, "?") {
+			continue
+		}
+		// "What control failed? Retry budget" → keep only the answer.
+		if i := strings.LastIndex(line, "?"); i >= 0 {
+			line = strings.TrimSpace(line[i+1:])
+		}
+		parts = append(parts, strings.ToLower(strings.Join(strings.Fields(line), " ")))
+	}
+	return strings.Join(parts, " | ")
+}
+
+// findRepeats walks each failure-mode group in creation order and flags every
+// incident that followed one whose actions were left incomplete (or never
+// recorded).
+func findRepeats(incidents []IncidentReport) []Repeat {
+	groups := map[string][]IncidentReport{}
+	for _, ir := range incidents {
+		if ir.FailureMode == "" {
+			continue
+		}
+		groups[ir.FailureMode] = append(groups[ir.FailureMode], ir)
+	}
+
+	var out []Repeat
+	for mode, group := range groups {
+		sort.Slice(group, func(i, j int) bool {
+			if group[i].CreatedAt != group[j].CreatedAt {
+				return group[i].CreatedAt < group[j].CreatedAt
+			}
+			return group[i].Number < group[j].Number
+		})
+		for i := 1; i < len(group); i++ {
+			r := Repeat{FailureMode: mode, Incident: group[i].Number}
+			for _, prev := range group[:i] {
+				open := prev.Counts.Open + prev.Counts.Overdue
+				total := open + prev.Counts.Closed
+				switch {
+				case total == 0:
+					r.Previous = append(r.Previous, prev.Number)
+					r.NoActionsFor = append(r.NoActionsFor, prev.Number)
+				case open > 0:
+					r.Previous = append(r.Previous, prev.Number)
+					r.OpenActions += open
+				}
+			}
+			if len(r.Previous) > 0 {
+				out = append(out, r)
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Incident < out[j].Incident })
+	return out
+}
@@ -0,0 +1,103 @@
+package incidents
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/BrikByte-Studios/github-governance/internal/issueform"
+)
+
+// TaskDraft is a task issue ready for `gh issue create`, rendered through
+// .github/ISSUE_TEMPLATE/task.yml so it is indistinguishable from a task
+// opened by hand.
+type TaskDraft struct {
+	Incident  int      `json:"incident"`
+	Action    string   `json:"action"`
+	Title     string   `json:"title"`
+	Labels    []string `json:"labels"`
+	Assignees []string `json:"assignees,omitempty"`
+	Body      string   `json:"body"`
+	// Missing lists required task fields the tracker could not derive; the
+	// owner completes them after the issue is created.
+	Missing []string `json:"missing,omitempty"`
+}
+
+// severityPriority maps incident severity to the task form's priority option.
+var severityPriority = map[string]string{
+	"SEV-0": "P0 - Critical",
+	"SEV-1": "P1 - High",
+	"SEV-2": "P2 - Medium",
+	"SEV-3": "P3 - Low",
+	"SEV-4": "P3 - Low",
+}
+
+// DraftTasks returns a task draft for every open or overdue action that is
+// not yet linked to a task issue.
+func DraftTasks(rep *Report, task *issueform.Template) []TaskDraft {
+	var out []TaskDraft
+	for _, ir := range rep.Incidents {
+		for _, a := range ir.Actions {
+			if a.Status == StatusClosed || a.Task != 0 {
+				continue
+			}
+			out = append(out, draftTask(ir, a, task))
+		}
+	}
+	return out
+}
+
+func draftTask(ir IncidentReport, a Action, task *issueform.Template) TaskDraft {
+	priority := severityPriority[ir.Severity]
+	if priority == "" {
+		priority = "P2 - Medium"
+	}
+	ref := fmt.Sprintf("#%d", ir.Number)
+	category := a.Category
+	if category == "" {
+		category = "follow-up"
+	}
+
+	values := map[string]string{
+		"summary":             a.Text,
+		"intent":              fmt.Sprintf("Follow-up action (%s) from incident %s: %s.", category, ref, ir.Title),
+		"jtbd":                fmt.Sprintf("When an incident like %s recurs,\nI want this %s action in place,\nSo that the same failure mode does not reach customers again.", ref, category),
+		"problem_statement":   fmt.Sprintf("Current state:\nIncident %s (%s) recorded this action without a tracked task.\n\nSource: %s", ref, orDash(ir.Severity), a.Source),
+		"desired_outcome":     fmt.Sprintf("After this task is complete:\n- %s", a.Text),
+		"work_type":           "Reliability",
+		"priority":            priority,
+		"impact":              "High",
+		"in_scope":            "- " + a.Text,
+		"out_of_scope":        "- Changes unrelated to the failure mode of " + ref,
+		"acceptance_criteria": fmt.Sprintf("- [ ] %s\n- [ ] Incident %s follow-up checklist updated with this task link", a.Text, ref),
+		"references":          "- Incident " + ref,
+	}
+	if a.Due != "" {
+		values["phase_milestone"] = "Due " + a.Due
+	}
+
+	labels := append([]string{}, task.Labels...)
+	labels = append(labels, "sre:impact")
+	if p, _, ok := strings.Cut(priority, " "); ok {
+		labels = append(labels, "priority:"+p)
+	}
+
+	d := TaskDraft{
+		Incident: ir.Number,
+		Action:   a.Text,
+		Title:    strings.TrimSpace(task.Title) + " " + a.Text,
+		Labels:   labels,
+		Body:     task.Render(values),
+		Missing:  task.MissingRequired(values),
+	}
+	if a.Owner != "" && !strings.Contains(a.Owner, "/") {
+		d.Assignees = []string{strings.TrimPrefix(a.Owner, "@")}
+	}
+	return d
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "—"
+	}
+	return s
+}
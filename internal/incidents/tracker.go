@@ -0,0 +1,263 @@
+package incidents
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/BrikByte-Studios/github-governance/internal/ghexport"
+	"github.com/BrikByte-Studios/github-governance/internal/issueform"
+)
+
+// IncidentLabel marks incident issues (see .github/ISSUE_TEMPLATE/incident.yml).
+const IncidentLabel = "type:incident"
+
+// TaskLabel marks task issues (see .github/ISSUE_TEMPLATE/task.yml).
+const TaskLabel = "type:task"
+
+// Unassigned is the team bucket for actions without an owner.
+const Unassigned = "unassigned"
+
+// Postmortem is a Markdown postmortem document linked to an incident issue.
+type Postmortem struct {
+	Incident int
+	Path     string
+	Body     string
+}
+
+var postmortemIncidentRe = regexp.MustCompile(`(?im)^\s*(?:[-*]\s*)?\**incident\**\s*:\s*\**\s*#?(\d+)`)
+
+// LoadPostmortems reads every *.md file in dir. Files are linked to their
+// incident with an "Incident: #123" line (front-matter "incident: 123" works
+// too); files without one are returned in skipped.
+func LoadPostmortems(dir string) (pms []Postmortem, skipped []string, err error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.md"))
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		raw, err := os.ReadFile(p)
+		if err != nil {
+			return nil, nil, err
+		}
+		m := postmortemIncidentRe.FindSubmatch(raw)
+		if m == nil {
+			skipped = append(skipped, p)
+			continue
+		}
+		n, _ := strconv.Atoi(string(m[1]))
+		pms = append(pms, Postmortem{Incident: n, Path: filepath.ToSlash(p), Body: string(raw)})
+	}
+	return pms, skipped, nil
+}
+
+// LoadTeams reads a YAML mapping of team slug → member handles and returns
+// the reverse lookup (lower-cased handle without "@" → team).
+//
+//	sre: ["@alice", "bob"]
+//	payments: ["@carol"]
+func LoadTeams(path string) (map[string]string, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var teams map[string][]string
+	if err := yaml.Unmarshal(raw, &teams); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	out := map[string]string{}
+	for team, members := range teams {
+		for _, m := range members {
+			out[handleKey(m)] = team
+		}
+	}
+	return out, nil
+}
+
+// Input is everything the tracker needs. Issues may contain incidents and
+// task issues in a single export; tasks are only used to resolve links.
+type Input struct {
+	Form        *issueform.Template
+	Issues      []ghexport.Issue
+	Postmortems []Postmortem
+	Teams       map[string]string
+	Now         time.Time
+}
+
+// Counts holds disjoint action totals.
+type Counts struct {
+	Open    int `json:"open"`
+	Overdue int `json:"overdue"`
+	Closed  int `json:"closed"`
+}
+
+func (c *Counts) add(s Status) {
+	switch s {
+	case StatusOpen:
+		c.Open++
+	case StatusOverdue:
+		c.Overdue++
+	case StatusClosed:
+		c.Closed++
+	}
+}
+
+// IncidentReport summarises the follow-ups of one incident.
+type IncidentReport struct {
+	Number      int      `json:"number"`
+	Title       string   `json:"title"`
+	URL         string   `json:"url,omitempty"`
+	Severity    string   `json:"severity,omitempty"`
+	FailureMode string   `json:"failure_mode,omitempty"`
+	CreatedAt   string   `json:"created_at"`
+	Counts      Counts   `json:"counts"`
+	Untracked   int      `json:"untracked"`
+	Actions     []Action `json:"actions"`
+}
+
+// TeamReport aggregates actions by owning team.
+type TeamReport struct {
+	Team      string `json:"team"`
+	Counts    Counts `json:"counts"`
+	Incidents []int  `json:"incidents"`
+}
+
+// Report is the tracker output.
+type Report struct {
+	GeneratedAt string           `json:"generated_at"`
+	Totals      Counts           `json:"totals"`
+	Incidents   []IncidentReport `json:"incidents"`
+	Teams       []TeamReport     `json:"teams"`
+	Repeats     []Repeat         `json:"repeats"`
+}
+
+// Track extracts, resolves and aggregates the follow-up actions of every
+// incident issue in the input.
+func Track(in Input) *Report {
+	byNumber := map[int]ghexport.Issue{}
+	for _, is := range in.Issues {
+		byNumber[is.Number] = is
+	}
+	pmByIncident := map[int][]Postmortem{}
+	for _, pm := range in.Postmortems {
+		pmByIncident[pm.Incident] = append(pmByIncident[pm.Incident], pm)
+	}
+
+	rep := &Report{GeneratedAt: in.Now.UTC().Format(time.RFC3339)}
+	teams := map[string]*TeamReport{}
+
+	for _, is := range in.Issues {
+		if !is.HasLabel(IncidentLabel) {
+			continue
+		}
+		fields := in.Form.Parse(is.Body)
+		ir := IncidentReport{
+			Number:      is.Number,
+			Title:       is.Title,
+			URL:         is.URL,
+			Severity:    SeverityCode(fields["severity"]),
+			FailureMode: failureModeKey(is, fields["failure_mode"]),
+			CreatedAt:   is.CreatedAt.UTC().Format(time.RFC3339),
+		}
+
+		src := fmt.Sprintf("issue#%d", is.Number)
+		actions := ExtractActions(is.Number, src+":follow_up_actions", fields["follow_up_actions"])
+		actions = append(actions, ExtractActions(is.Number, src+":prevention_strategy", fields["prevention_strategy"])...)
+		for _, pm := range pmByIncident[is.Number] {
+			actions = append(actions, ExtractActions(is.Number, pm.Path, pm.Body)...)
+		}
+
+		for i := range actions {
+			a := &actions[i]
+			if a.Task == 0 {
+				a.Task = matchTask(in.Issues, is.Number, a.Text)
+				a.TaskMatched = a.Task != 0
+			}
+			task, linked := byNumber[a.Task]
+			a.resolveStatus(in.Now, linked && task.Closed())
+			a.Team = teamOf(a.Owner, in.Teams)
+			ir.Counts.add(a.Status)
+			rep.Totals.add(a.Status)
+			if a.Status != StatusClosed && a.Task == 0 {
+				ir.Untracked++
+			}
+
+			tr := teams[a.Team]
+			if tr == nil {
+				tr = &TeamReport{Team: a.Team}
+				teams[a.Team] = tr
+			}
+			tr.Counts.add(a.Status)
+			if n := len(tr.Incidents); n == 0 || tr.Incidents[n-1] != is.Number {
+				tr.Incidents = append(tr.Incidents, is.Number)
+			}
+		}
+		ir.Actions = actions
+		if ir.Actions == nil {
+			ir.Actions = []Action{}
+		}
+		rep.Incidents = append(rep.Incidents, ir)
+	}
+
+	sort.Slice(rep.Incidents, func(i, j int) bool { return rep.Incidents[i].Number < rep.Incidents[j].Number })
+	for _, tr := range teams {
+		sort.Ints(tr.Incidents)
+		rep.Teams = append(rep.Teams, *tr)
+	}
+	sort.Slice(rep.Teams, func(i, j int) bool { return rep.Teams[i].Team < rep.Teams[j].Team })
+	rep.Repeats = findRepeats(rep.Incidents)
+	return rep
+}
+
+// matchTask returns the task issue created for an unlinked action: a
+// type:task issue whose title ends with the action text and whose body
+// references the incident, as DraftTasks renders them. 0 when none.
+func matchTask(issues []ghexport.Issue, incident int, text string) int {
+	ref := regexp.MustCompile(fmt.Sprintf(`(?:^|[\s(])#%d\b`, incident))
+	want := strings.ToLower(strings.TrimSpace(text))
+	if want == "" {
+		return 0
+	}
+	for _, is := range issues {
+		if !is.HasLabel(TaskLabel) || is.HasLabel(IncidentLabel) {
+			continue
+		}
+		if strings.HasSuffix(strings.ToLower(strings.TrimSpace(is.Title)), want) && ref.MatchString(is.Body) {
+			return is.Number
+		}
+	}
+	return 0
+}
+
+// teamOf resolves an owner handle to a team. Team handles
+// (@BrikByte-Studios/sre) name the team directly; user handles go through the
+// teams file.
+func teamOf(owner string, teams map[string]string) string {
+	if owner == "" {
+		return Unassigned
+	}
+	if _, team, ok := strings.Cut(strings.TrimPrefix(owner, "@"), "/"); ok {
+		return strings.ToLower(team)
+	}
+	if t, ok := teams[handleKey(owner)]; ok {
+		return t
+	}
+	return "unknown"
+}
+
+func handleKey(h string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(h), "@"))
+}
+
+var severityRe = regexp.MustCompile(`SEV-\d`)
+
+// SeverityCode reduces the dropdown value ("SEV-1 - High (...)") to "SEV-1".
+func SeverityCode(v string) string { return severityRe.FindString(v) }
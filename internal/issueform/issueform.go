@@ -0,0 +1,155 @@
+// Package issueform reads the GitHub issue-form templates under
+// .github/ISSUE_TEMPLATE and converts between rendered issue bodies and
+// field values keyed by the template's field ids.
+//
+// GitHub renders a submitted form as a sequence of "### <label>" sections.
+// The template is the only place that knows which label belongs to which id
+// (e.g. "Follow-up Actions / Action Items" → follow_up_actions), so every
+// tool that reads or writes form-based issues goes through this package
+// instead of hard-coding labels.
+package issueform
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// NoResponse is the placeholder GitHub writes for optional fields left empty.
+const NoResponse = "_No response_"
+
+// Template is the subset of an issue-form definition the tooling relies on.
+type Template struct {
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description"`
+	Title       string   `yaml:"title"`
+	Labels      []string `yaml:"labels"`
+	Body        []Field  `yaml:"body"`
+}
+
+// Field is a single body element of an issue form.
+type Field struct {
+	Type       string `yaml:"type"`
+	ID         string `yaml:"id"`
+	Attributes struct {
+		Label   string `yaml:"label"`
+		Options []any  `yaml:"options"`
+	} `yaml:"attributes"`
+	Validations struct {
+		Required bool `yaml:"required"`
+	} `yaml:"validations"`
+}
+
+// Label returns the rendered heading of the field.
+func (f Field) Label() string { return strings.TrimSpace(f.Attributes.Label) }
+
+// LoadTemplate parses an issue-form YAML file.
+func LoadTemplate(path string) (*Template, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var t Template
+	if err := yaml.Unmarshal(raw, &t); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(t.Body) == 0 {
+		return nil, fmt.Errorf("%s: issue form has no body fields", path)
+	}
+	return &t, nil
+}
+
+// Fields returns the input fields (everything except markdown blocks) in
+// template order.
+func (t *Template) Fields() []Field {
+	out := make([]Field, 0, len(t.Body))
+	for _, f := range t.Body {
+		if f.Type == "markdown" || f.ID == "" {
+			continue
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+// Parse splits a rendered issue body into values keyed by field id. Only
+// "### <label>" lines naming a template field start a new section; any
+// other "### " line is part of the answer it appears in (users write
+// "### Detection" inside their follow-up actions). "_No response_" is
+// normalised to the empty string.
+func (t *Template) Parse(body string) map[string]string {
+	byLabel := map[string]string{}
+	for _, f := range t.Fields() {
+		byLabel[normalizeLabel(f.Label())] = f.ID
+	}
+
+	values := map[string]string{}
+	var (
+		current string
+		buf     []string
+	)
+	flush := func() {
+		if current == "" {
+			return
+		}
+		v := strings.TrimSpace(strings.Join(buf, "\n"))
+		if v == NoResponse {
+			v = ""
+		}
+		values[current] = v
+	}
+
+	sc := bufio.NewScanner(strings.NewReader(strings.ReplaceAll(body, "\r\n", "\n")))
+	sc.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		line := sc.Text()
+		if strings.HasPrefix(line, "### ") {
+			if id, ok := byLabel[normalizeLabel(strings.TrimPrefix(line, "### "))]; ok {
+				flush()
+				current = id
+				buf = buf[:0]
+				continue
+			}
+		}
+		if current != "" {
+			buf = append(buf, line)
+		}
+	}
+	flush()
+	return values
+}
+
+// Render produces an issue body in the same shape GitHub uses for submitted
+// forms. Fields without a value are rendered as "_No response_" so the
+// result round-trips through Parse.
+func (t *Template) Render(values map[string]string) string {
+	var b strings.Builder
+	for _, f := range t.Fields() {
+		v := strings.TrimSpace(values[f.ID])
+		if v == "" {
+			v = NoResponse
+		}
+		fmt.Fprintf(&b, "### %s\n\n%s\n\n", f.Label(), v)
+	}
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+// MissingRequired lists the ids of required fields that have no value.
+func (t *Template) MissingRequired(values map[string]string) []string {
+	var missing []string
+	for _, f := range t.Fields() {
+		if f.Validations.Required && f.Type != "checkboxes" && strings.TrimSpace(values[f.ID]) == "" {
+			missing = append(missing, f.ID)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+func normalizeLabel(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
@@ -0,0 +1,58 @@
+package issueform
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func loadRepoTemplate(t *testing.T, name string) *Template {
+	t.Helper()
+	tpl, err := LoadTemplate(filepath.Join("..", "..", ".github", "ISSUE_TEMPLATE", name))
+	if err != nil {
+		t.Fatalf("load %s: %v", name, err)
+	}
+	return tpl
+}
+
+func TestParseRenderedIncident(t *testing.T) {
+	tpl := loadRepoTemplate(t, "incident.yml")
+
+	body := "### Incident Summary\n\nCheckout failures\n\n" +
+		"### Severity (SEV-*)\n\nSEV-1 - High (major degradation; core flows severely impacted)\n\n" +
+		"### Follow-up Actions / Action Items\n\nPrevention:\n- [ ] Add retry budget @alice\n\n" +
+		"### Detection\n- [ ] Alert on p99 @bob\n\n" +
+		"### Prevention Strategy\n\n_No response_\n"
+
+	got := tpl.Parse(body)
+	if got["summary"] != "Checkout failures" {
+		t.Errorf("summary = %q", got["summary"])
+	}
+	// "### Detection" is not a field label, so it stays in the answer.
+	if got["follow_up_actions"] != "Prevention:\n- [ ] Add retry budget @alice\n\n### Detection\n- [ ] Alert on p99 @bob" {
+		t.Errorf("follow_up_actions = %q", got["follow_up_actions"])
+	}
+	if v, ok := got["prevention_strategy"]; !ok || v != "" {
+		t.Errorf("prevention_strategy = %q, %v; want empty", v, ok)
+	}
+	if len(got) != 4 {
+		t.Errorf("parsed %d fields, want 4: %v", len(got), got)
+	}
+}
+
+func TestRenderRoundTrip(t *testing.T) {
+	tpl := loadRepoTemplate(t, "task.yml")
+	in := map[string]string{
+		"summary":  "Add timeout budget",
+		"priority": "P1 - High",
+		"in_scope": "- gateway client\n- retries",
+	}
+	got := tpl.Parse(tpl.Render(in))
+	for k, v := range in {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k], v)
+		}
+	}
+	if missing := tpl.MissingRequired(in); len(missing) == 0 {
+		t.Error("expected required task fields to be reported missing")
+	}
+}
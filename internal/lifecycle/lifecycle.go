@@ -0,0 +1,393 @@
+// Package lifecycle classifies repositories as active, maintenance or
+// deprecated and plans the archival of dead ones.
+//
+// A repository's stage is the one it declares (.github/lifecycle.yml or a
+// lifecycle-<stage> topic); the stage its activity implies is reported
+// next to it, and a mismatch is a finding. Each stage has requirements: a
+// deprecated repository must say so in its README and have no open
+// security issues before it may be archived. The archival plan and the
+// owner notifications come from the evaluation, with owners taken from
+// each repository's CODEOWNERS.
+package lifecycle
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/BrikByte-Studios/github-governance/internal/codeowners"
+	"github.com/BrikByte-Studios/github-governance/internal/ghexport"
+	"github.com/BrikByte-Studios/github-governance/internal/policy"
+)
+
+// Stages, from most to least alive. Archived repositories are reported
+// but not evaluated.
+const (
+	StageActive      = "active"
+	StageMaintenance = "maintenance"
+	StageDeprecated  = "deprecated"
+	StageArchived    = "archived"
+)
+
+var stages = []string{StageActive, StageMaintenance, StageDeprecated}
+
+// Config is the lifecycle section of the effective policy.
+type Config struct {
+	// ActiveDays: a push or a release within this many days is active.
+	ActiveDays  int `json:"active_days"`
+	ReleaseDays int `json:"release_days"`
+	// MaintenanceDays: a push or an issue update within this many days is
+	// maintenance; anything quieter is deprecated.
+	MaintenanceDays int `json:"maintenance_days"`
+	// ArchiveAfterDays is how long a repository stays deprecated before it
+	// is archived.
+	ArchiveAfterDays int      `json:"archive_after_days"`
+	SecurityLabels   []string `json:"security_labels"`
+	// Banner must match one of the first BannerLines lines of a deprecated
+	// repository's README.
+	Banner      string `json:"banner"`
+	BannerLines int    `json:"banner_lines"`
+}
+
+// DefaultConfig applies without a lifecycle section.
+func DefaultConfig() *Config {
+	return &Config{
+		ActiveDays:       90,
+		ReleaseDays:      180,
+		MaintenanceDays:  365,
+		ArchiveAfterDays: 90,
+		SecurityLabels:   []string{"type:security", "area:security"},
+		Banner:           `(?i)\b(deprecated|no longer maintained|archived)\b`,
+		BannerLines:      15,
+	}
+}
+
+// LoadConfig reads the lifecycle section of a policy file.
+func LoadConfig(path string) (*Config, error) {
+	c := DefaultConfig()
+	if err := policy.LoadSection(path, "lifecycle", c); err != nil {
+		return nil, err
+	}
+	if c.ActiveDays <= 0 || c.MaintenanceDays < c.ActiveDays || c.ReleaseDays <= 0 || c.ArchiveAfterDays < 0 {
+		return nil, fmt.Errorf("%s: lifecycle needs 0 < active_days <= maintenance_days, release_days > 0 and archive_after_days >= 0", path)
+	}
+	if _, err := regexp.Compile(c.Banner); err != nil {
+		return nil, fmt.Errorf("%s: lifecycle.banner: %w", path, err)
+	}
+	return c, nil
+}
+
+// DeclarationFile is where a repository declares its stage.
+const DeclarationFile = ".github/lifecycle.yml"
+
+// Declaration is a repository's declared stage.
+type Declaration struct {
+	Stage string `yaml:"lifecycle" json:"lifecycle"`
+	// Since is the date (YYYY-MM-DD) the stage began; archival counts
+	// from it.
+	Since       string `yaml:"since,omitempty" json:"since,omitempty"`
+	Replacement string `yaml:"replacement,omitempty" json:"replacement,omitempty"`
+	Reason      string `yaml:"reason,omitempty" json:"reason,omitempty"`
+}
+
+// Repo is the snapshot of one repository.
+type Repo struct {
+	Meta ghexport.Repository
+	// Issues are the open issues.
+	Issues []ghexport.Issue
+	// README is the README content, "" when there is none.
+	README   string
+	Owners   *codeowners.File
+	Declared *Declaration
+}
+
+// LoadSnapshot reads <dir>/<owner>/<name>/ directories, each with
+//
+//	repo.json            gh repo view --json (required)
+//	issues.json          gh issue list --state open --json (optional)
+//	README.md            or any README* file
+//	CODEOWNERS           at any of GitHub's locations
+//	.github/lifecycle.yml
+func LoadSnapshot(dir string) ([]Repo, error) {
+	metas, err := filepath.Glob(filepath.Join(dir, "*", "*", "repo.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(metas)
+	var repos []Repo
+	for _, m := range metas {
+		r, err := loadRepo(filepath.Dir(m))
+		if err != nil {
+			return nil, err
+		}
+		repos = append(repos, *r)
+	}
+	if len(repos) == 0 {
+		return nil, fmt.Errorf("%s: no <owner>/<name>/repo.json", dir)
+	}
+	return repos, nil
+}
+
+func loadRepo(dir string) (*Repo, error) {
+	meta, err := ghexport.LoadRepository(filepath.Join(dir, "repo.json"))
+	if err != nil {
+		return nil, err
+	}
+	r := &Repo{Meta: *meta}
+	if _, err := os.Stat(filepath.Join(dir, "issues.json")); err == nil {
+		if r.Issues, err = ghexport.LoadIssues(filepath.Join(dir, "issues.json")); err != nil {
+			return nil, err
+		}
+	}
+	readmes, _ := filepath.Glob(filepath.Join(dir, "[Rr][Ee][Aa][Dd][Mm][Ee]*"))
+	if len(readmes) > 0 {
+		sort.Strings(readmes)
+		raw, err := os.ReadFile(readmes[0])
+		if err != nil {
+			return nil, err
+		}
+		r.README = string(raw)
+	}
+	for _, c := range codeowners.Candidates {
+		p := filepath.Join(dir, filepath.FromSlash(c))
+		if _, err := os.Stat(p); err == nil {
+			if r.Owners, err = codeowners.Load(p); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	raw, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(DeclarationFile)))
+	switch {
+	case err == nil:
+		var d Declaration
+		if err := yaml.Unmarshal(raw, &d); err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", meta.NameWithOwner, DeclarationFile, err)
+		}
+		r.Declared = &d
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, err
+	}
+	return r, nil
+}
+
+// declared returns the declared stage from the file or a lifecycle-<stage>
+// topic.
+func (r *Repo) declared() (*Declaration, error) {
+	if r.Declared != nil {
+		d := *r.Declared
+		d.Stage = strings.ToLower(strings.TrimSpace(d.Stage))
+		if !contains(stages, d.Stage) {
+			return nil, fmt.Errorf("%s declares lifecycle %q (one of %s)", DeclarationFile, r.Declared.Stage, strings.Join(stages, ", "))
+		}
+		if d.Since != "" {
+			if _, err := time.Parse("2006-01-02", d.Since); err != nil {
+				return nil, fmt.Errorf("%s since %q is not YYYY-MM-DD", DeclarationFile, d.Since)
+			}
+		}
+		return &d, nil
+	}
+	for _, t := range r.Meta.Topics {
+		if s, ok := strings.CutPrefix(strings.ToLower(t.Name), "lifecycle-"); ok && contains(stages, s) {
+			return &Declaration{Stage: s}, nil
+		}
+	}
+	return nil, nil
+}
+
+// Signals are the activity measures the inferred stage rests on.
+type Signals struct {
+	LastPush        time.Time  `json:"last_push"`
+	LastRelease     *time.Time `json:"last_release,omitempty"`
+	OpenIssues      int        `json:"open_issues"`
+	LastIssueUpdate *time.Time `json:"last_issue_update,omitempty"`
+	SecurityIssues  []int      `json:"security_issues,omitempty"`
+}
+
+// Finding is an unmet stage requirement (error) or an inconsistency
+// (warning).
+type Finding struct {
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// Finding severities.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Status is the evaluation of one repository.
+type Status struct {
+	Repo string `json:"repo"`
+	// Stage is the declared stage, or the inferred one when none is
+	// declared.
+	Stage    string       `json:"stage"`
+	Declared *Declaration `json:"declared,omitempty"`
+	Inferred string       `json:"inferred"`
+	Signals  Signals      `json:"signals"`
+	Owners   []string     `json:"owners"`
+	Findings []Finding    `json:"findings,omitempty"`
+}
+
+// Errors returns the messages of the error findings.
+func (s Status) Errors() []string {
+	var out []string
+	for _, f := range s.Findings {
+		if f.Severity == SeverityError {
+			out = append(out, f.Message)
+		}
+	}
+	return out
+}
+
+// Evaluate classifies each repository at now and checks its stage's
+// requirements. Statuses are ordered by repository.
+func Evaluate(repos []Repo, cfg *Config, now time.Time) ([]Status, error) {
+	banner, err := regexp.Compile(cfg.Banner)
+	if err != nil {
+		return nil, fmt.Errorf("lifecycle.banner: %w", err)
+	}
+	days := func(n int) time.Time { return now.AddDate(0, 0, -n) }
+	var out []Status
+	for i := range repos {
+		r := &repos[i]
+		st := Status{Repo: r.Meta.NameWithOwner, Owners: repoOwners(r.Owners)}
+		sig := &st.Signals
+		sig.LastPush = r.Meta.PushedAt
+		if rel := r.Meta.LatestRelease; rel != nil && !rel.PublishedAt.IsZero() {
+			t := rel.PublishedAt
+			sig.LastRelease = &t
+		}
+		for _, is := range r.Issues {
+			if is.Closed() {
+				continue
+			}
+			sig.OpenIssues++
+			updated := is.CreatedAt
+			if is.UpdatedAt != nil {
+				updated = *is.UpdatedAt
+			}
+			if sig.LastIssueUpdate == nil || updated.After(*sig.LastIssueUpdate) {
+				sig.LastIssueUpdate = &updated
+			}
+			for _, l := range cfg.SecurityLabels {
+				if is.HasLabel(l) {
+					sig.SecurityIssues = append(sig.SecurityIssues, is.Number)
+					break
+				}
+			}
+		}
+		sort.Ints(sig.SecurityIssues)
+
+		switch {
+		case sig.LastPush.After(days(cfg.ActiveDays)) || sig.LastRelease != nil && sig.LastRelease.After(days(cfg.ReleaseDays)):
+			st.Inferred = StageActive
+		case sig.LastPush.After(days(cfg.MaintenanceDays)) || sig.LastIssueUpdate != nil && sig.LastIssueUpdate.After(days(cfg.MaintenanceDays)):
+			st.Inferred = StageMaintenance
+		default:
+			st.Inferred = StageDeprecated
+		}
+		if r.Meta.IsArchived {
+			st.Stage = StageArchived
+			out = append(out, st)
+			continue
+		}
+
+		add := func(sev, format string, args ...any) {
+			st.Findings = append(st.Findings, Finding{Severity: sev, Message: fmt.Sprintf(format, args...)})
+		}
+		d, err := r.declared()
+		if err != nil {
+			add(SeverityError, "%v", err)
+		}
+		st.Declared = d
+		st.Stage = st.Inferred
+		if d != nil {
+			st.Stage = d.Stage
+		} else if err == nil {
+			add(SeverityWarning, "no declared lifecycle; inferred %s (declare it in %s)", st.Inferred, DeclarationFile)
+		}
+		if d != nil && rank(d.Stage) < rank(st.Inferred) {
+			add(SeverityWarning, "declared %s but activity suggests %s: last push %s", d.Stage, st.Inferred, sig.LastPush.Format("2006-01-02"))
+		}
+		if d != nil && d.Stage == StageDeprecated && st.Inferred == StageActive {
+			add(SeverityWarning, "declared deprecated but still active: last push %s", sig.LastPush.Format("2006-01-02"))
+		}
+		if len(st.Owners) == 0 {
+			add(SeverityWarning, "no CODEOWNERS owners to notify")
+		}
+		if st.Stage == StageDeprecated {
+			if !hasBanner(r.README, banner, cfg.BannerLines) {
+				add(SeverityError, "README has no deprecation banner in its first %d lines", cfg.BannerLines)
+			}
+			for _, n := range sig.SecurityIssues {
+				add(SeverityError, "open security issue #%d", n)
+			}
+		}
+		out = append(out, st)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Repo < out[j].Repo })
+	return out, nil
+}
+
+func rank(stage string) int {
+	for i, s := range stages {
+		if s == stage {
+			return i
+		}
+	}
+	return len(stages)
+}
+
+func hasBanner(readme string, re *regexp.Regexp, lines int) bool {
+	for i, l := range strings.Split(readme, "\n") {
+		if i >= lines {
+			break
+		}
+		if re.MatchString(l) {
+			return true
+		}
+	}
+	return false
+}
+
+// repoOwners are the owners of the catch-all rule, or every owner listed
+// when there is none.
+func repoOwners(f *codeowners.File) []string {
+	if f == nil {
+		return []string{}
+	}
+	for i := len(f.Rules) - 1; i >= 0; i-- {
+		if r := f.Rules[i]; r.Pattern == "*" && len(r.Owners) > 0 {
+			return append([]string{}, r.Owners...)
+		}
+	}
+	seen := map[string]bool{}
+	out := []string{}
+	for _, r := range f.Rules {
+		for _, o := range r.Owners {
+			if !seen[strings.ToLower(o)] {
+				seen[strings.ToLower(o)] = true
+				out = append(out, o)
+			}
+		}
+	}
+	return out
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
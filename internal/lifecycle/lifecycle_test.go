@@ -0,0 +1,192 @@
+package lifecycle
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const fixtures = "../../tests/fixtures/lifecycle"
+
+var now = time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+func evaluate(t *testing.T) []Status {
+	t.Helper()
+	repos, err := LoadSnapshot(fixtures)
+	if err != nil {
+		t.Fatal(err)
+	}
+	statuses, err := Evaluate(repos, DefaultConfig(), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return statuses
+}
+
+func byRepo(statuses []Status) map[string]Status {
+	m := map[string]Status{}
+	for _, st := range statuses {
+		m[strings.TrimPrefix(st.Repo, "BrikByte-Studios/")] = st
+	}
+	return m
+}
+
+func TestEvaluate(t *testing.T) {
+	got := byRepo(evaluate(t))
+	want := map[string][2]string{ // stage, inferred
+		"api":            {StageActive, StageActive},
+		"archived-thing": {StageArchived, StageDeprecated},
+		"bad-decl":       {StageActive, StageActive},
+		"ci-templates":   {StageMaintenance, StageMaintenance},
+		"legacy-sdk":     {StageDeprecated, StageMaintenance},
+		"old-portal":     {StageDeprecated, StageActive},
+		"tools":          {StageDeprecated, StageDeprecated},
+		"widgets":        {StageDeprecated, StageActive},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d repos, want %d", len(got), len(want))
+	}
+	for name, w := range want {
+		if st := got[name]; st.Stage != w[0] || st.Inferred != w[1] {
+			t.Errorf("%s: stage %s inferred %s, want %s inferred %s", name, st.Stage, st.Inferred, w[0], w[1])
+		}
+	}
+
+	for _, name := range []string{"api", "ci-templates", "legacy-sdk"} {
+		if f := got[name].Findings; len(f) != 0 {
+			t.Errorf("%s: unexpected findings %v", name, f)
+		}
+	}
+	if errs := got["old-portal"].Errors(); len(errs) != 2 ||
+		!strings.Contains(errs[0], "deprecation banner") || errs[1] != "open security issue #7" {
+		t.Errorf("old-portal errors = %q", errs)
+	}
+	if errs := got["bad-decl"].Errors(); len(errs) != 1 || !strings.Contains(errs[0], `"sunset"`) {
+		t.Errorf("bad-decl errors = %q", errs)
+	}
+	if len(got["widgets"].Errors()) != 0 || len(got["widgets"].Findings) != 1 {
+		t.Errorf("widgets findings = %v, want only the still-active warning", got["widgets"].Findings)
+	}
+	tools := got["tools"]
+	if tools.Declared != nil || len(tools.Findings) != 3 {
+		t.Errorf("tools findings = %v, want undeclared, unowned and banner", tools.Findings)
+	}
+
+	// Owners: the catch-all rule wins; without one every owner counts.
+	owners := map[string][]string{
+		"legacy-sdk":   {"@BrikByte-Studios/sdk", "@dana"},
+		"old-portal":   {"@BrikByte-Studios/web", "@eli"},
+		"ci-templates": {"@BrikByte-Studios/platform"},
+		"tools":        {},
+	}
+	for name, w := range owners {
+		if !reflect.DeepEqual(got[name].Owners, w) {
+			t.Errorf("%s owners = %v, want %v", name, got[name].Owners, w)
+		}
+	}
+	if sec := got["old-portal"].Signals.SecurityIssues; !reflect.DeepEqual(sec, []int{7}) {
+		t.Errorf("old-portal security issues = %v", sec)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	statuses := evaluate(t)
+	plan := BuildPlan(statuses, DefaultConfig(), now)
+	var got []string
+	for _, it := range plan {
+		got = append(got, it.Action+" "+strings.TrimPrefix(it.Repo, "BrikByte-Studios/")+" "+date(it.ArchiveOn))
+	}
+	want := []string{
+		"archive legacy-sdk 2026-07-30",
+		"blocked old-portal 2026-08-30",
+		"schedule-archive widgets 2026-12-14",
+		"propose-deprecation tools —",
+		"fix bad-decl —",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("plan =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if plan[0].Command != "gh repo archive BrikByte-Studios/legacy-sdk --yes" || plan[0].Replacement != "BrikByte-Studios/sdk" {
+		t.Errorf("archive item = %+v", plan[0])
+	}
+	if plan[1].Command != "" || len(plan[1].Reasons) != 2 {
+		t.Errorf("blocked item = %+v", plan[1])
+	}
+	if !strings.HasPrefix(plan[3].Reasons[0], "no push since 2025-01-10, no issue activity since 2025-03-01") {
+		t.Errorf("propose reasons = %q", plan[3].Reasons)
+	}
+
+	notes := Notifications(plan)
+	gotN := map[string][]string{}
+	var order []string
+	for _, n := range notes {
+		order = append(order, n.Owner)
+		for _, it := range n.Items {
+			gotN[n.Owner] = append(gotN[n.Owner], strings.TrimPrefix(it.Repo, "BrikByte-Studios/"))
+		}
+	}
+	wantOrder := []string{Unowned, "@BrikByte-Studios/platform", "@BrikByte-Studios/sdk", "@BrikByte-Studios/web", "@dana", "@eli"}
+	if !reflect.DeepEqual(order, wantOrder) {
+		t.Errorf("notification owners = %v, want %v", order, wantOrder)
+	}
+	if w := []string{"old-portal", "widgets"}; !reflect.DeepEqual(gotN["@BrikByte-Studios/web"], w) {
+		t.Errorf("web items = %v, want %v", gotN["@BrikByte-Studios/web"], w)
+	}
+	if w := []string{"tools"}; !reflect.DeepEqual(gotN[Unowned], w) {
+		t.Errorf("unowned items = %v, want %v", gotN[Unowned], w)
+	}
+
+	md := NotificationMarkdown(notes[2])
+	if !strings.Contains(md, "`gh repo archive BrikByte-Studios/legacy-sdk --yes`") || !strings.Contains(md, "replaced by BrikByte-Studios/sdk") {
+		t.Errorf("sdk notification:\n%s", md)
+	}
+	if md := PlanMarkdown(statuses, plan); !strings.Contains(md, "8 repositories — active: 2, maintenance: 1, deprecated: 4, archived: 1.") {
+		t.Errorf("plan markdown:\n%s", md)
+	}
+}
+
+func TestArchiveAfterFromLastPush(t *testing.T) {
+	repos, err := LoadSnapshot(fixtures)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range repos {
+		if repos[i].Meta.NameWithOwner == "BrikByte-Studios/widgets" {
+			repos[i].Declared.Since = ""
+		}
+	}
+	statuses, err := Evaluate(repos, DefaultConfig(), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, it := range BuildPlan(statuses, DefaultConfig(), now) {
+		if it.Repo == "BrikByte-Studios/widgets" && date(it.ArchiveOn) != "2026-12-09" {
+			t.Errorf("widgets archive on %s, want last push + 90 days", date(it.ArchiveOn))
+		}
+	}
+}
+
+func TestLoadSnapshotErrors(t *testing.T) {
+	if _, err := LoadSnapshot(t.TempDir()); err == nil {
+		t.Error("empty snapshot accepted")
+	}
+	dir := filepath.Join(t.TempDir(), "o", "r")
+	if err := os.MkdirAll(filepath.Join(dir, ".github"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dir, "repo.json"), []byte(`{"nameWithOwner":"o/r","pushedAt":"2026-01-01T00:00:00Z"}`), 0o644)
+	os.WriteFile(filepath.Join(dir, DeclarationFile), []byte("lifecycle: [\n"), 0o644)
+	if _, err := LoadSnapshot(filepath.Dir(filepath.Dir(dir))); err == nil || !strings.Contains(err.Error(), "o/r") {
+		t.Errorf("bad declaration: %v", err)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	cfg, err := LoadConfig("../../.github/policy.yml")
+	if err != nil || !reflect.DeepEqual(cfg, DefaultConfig()) {
+		t.Errorf("org policy lifecycle = %+v, %v; keep it in sync with DefaultConfig", cfg, err)
+	}
+}
@@ -0,0 +1,206 @@
+package lifecycle
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Plan actions.
+const (
+	// ActionArchive: deprecated long enough and every requirement met.
+	ActionArchive = "archive"
+	// ActionScheduleArchive: requirements met, archival date ahead.
+	ActionScheduleArchive = "schedule-archive"
+	// ActionBlocked: deprecated, but a requirement stops the archival.
+	ActionBlocked = "blocked"
+	// ActionProposeDeprecation: activity says deprecated, the repository
+	// does not.
+	ActionProposeDeprecation = "propose-deprecation"
+	// ActionFix: a requirement of another stage is unmet.
+	ActionFix = "fix"
+)
+
+// Unowned collects plan items of repositories without CODEOWNERS.
+const Unowned = "(unowned)"
+
+// PlanItem is one repository's next lifecycle step.
+type PlanItem struct {
+	Repo   string `json:"repo"`
+	Action string `json:"action"`
+	Stage  string `json:"stage"`
+	// ArchiveOn is when the repository is (or was) due for archival.
+	ArchiveOn   *time.Time `json:"archive_on,omitempty"`
+	Reasons     []string   `json:"reasons"`
+	Replacement string     `json:"replacement,omitempty"`
+	Owners      []string   `json:"owners"`
+	// Command archives the repository once the item is ActionArchive.
+	Command string `json:"command,omitempty"`
+}
+
+// BuildPlan turns statuses into the archival plan, ordered by action
+// urgency, then repository. Repositories with nothing to do are left out.
+func BuildPlan(statuses []Status, cfg *Config, now time.Time) []PlanItem {
+	var plan []PlanItem
+	for _, st := range statuses {
+		if st.Stage == StageArchived {
+			continue
+		}
+		it := PlanItem{Repo: st.Repo, Stage: st.Stage, Owners: st.Owners, Reasons: st.Errors()}
+		declaredDeprecated := st.Declared != nil && st.Declared.Stage == StageDeprecated
+		switch {
+		case declaredDeprecated:
+			start := st.Signals.LastPush
+			if st.Declared.Since != "" {
+				start, _ = time.Parse("2006-01-02", st.Declared.Since)
+			}
+			on := start.AddDate(0, 0, cfg.ArchiveAfterDays).UTC()
+			it.ArchiveOn = &on
+			it.Replacement = st.Declared.Replacement
+			switch {
+			case len(it.Reasons) > 0:
+				it.Action = ActionBlocked
+			case !on.After(now):
+				it.Action = ActionArchive
+				it.Reasons = []string{fmt.Sprintf("deprecated since %s", start.Format("2006-01-02"))}
+				it.Command = "gh repo archive " + st.Repo + " --yes"
+			default:
+				it.Action = ActionScheduleArchive
+				it.Reasons = []string{fmt.Sprintf("deprecated since %s; archival after %d days", start.Format("2006-01-02"), cfg.ArchiveAfterDays)}
+			}
+		case st.Inferred == StageDeprecated:
+			it.Action = ActionProposeDeprecation
+			it.Reasons = append([]string{quiet(st.Signals)}, it.Reasons...)
+		case len(it.Reasons) > 0:
+			it.Action = ActionFix
+		default:
+			continue
+		}
+		plan = append(plan, it)
+	}
+	order := map[string]int{ActionArchive: 0, ActionBlocked: 1, ActionScheduleArchive: 2, ActionProposeDeprecation: 3, ActionFix: 4}
+	sort.SliceStable(plan, func(i, j int) bool {
+		if order[plan[i].Action] != order[plan[j].Action] {
+			return order[plan[i].Action] < order[plan[j].Action]
+		}
+		return plan[i].Repo < plan[j].Repo
+	})
+	return plan
+}
+
+func quiet(s Signals) string {
+	msg := "no push since " + s.LastPush.Format("2006-01-02")
+	if s.LastRelease != nil {
+		msg += ", no release since " + s.LastRelease.Format("2006-01-02")
+	}
+	if s.LastIssueUpdate != nil {
+		msg += ", no issue activity since " + s.LastIssueUpdate.Format("2006-01-02")
+	}
+	return msg
+}
+
+// Notification is the message to one owner about their repositories.
+type Notification struct {
+	Owner string     `json:"owner"`
+	Items []PlanItem `json:"items"`
+}
+
+// Notifications groups the plan by owner; items of repositories without
+// owners go to Unowned.
+func Notifications(plan []PlanItem) []Notification {
+	by := map[string]*Notification{}
+	add := func(key, owner string, it PlanItem) {
+		n := by[key]
+		if n == nil {
+			n = &Notification{Owner: owner}
+			by[key] = n
+		}
+		n.Items = append(n.Items, it)
+	}
+	for _, it := range plan {
+		for _, o := range it.Owners {
+			add(strings.ToLower(o), o, it)
+		}
+		if len(it.Owners) == 0 {
+			add(Unowned, Unowned, it)
+		}
+	}
+	out := make([]Notification, 0, len(by))
+	for _, n := range by {
+		out = append(out, *n)
+	}
+	sort.Slice(out, func(i, j int) bool { return strings.ToLower(out[i].Owner) < strings.ToLower(out[j].Owner) })
+	return out
+}
+
+// PlanMarkdown renders the stage counts and the plan.
+func PlanMarkdown(statuses []Status, plan []PlanItem) string {
+	var b strings.Builder
+	b.WriteString("## Repository lifecycle\n\n")
+	counts := map[string]int{}
+	for _, st := range statuses {
+		counts[st.Stage]++
+	}
+	var parts []string
+	for _, s := range append(append([]string{}, stages...), StageArchived) {
+		parts = append(parts, fmt.Sprintf("%s: %d", s, counts[s]))
+	}
+	fmt.Fprintf(&b, "%d repositories — %s.\n\n", len(statuses), strings.Join(parts, ", "))
+	if len(plan) == 0 {
+		b.WriteString("Nothing to archive or fix.\n")
+		return b.String()
+	}
+	b.WriteString("| Repository | Action | Stage | Archive on | Reasons | Owners |\n")
+	b.WriteString("|---|---|---|---|---|---|\n")
+	for _, it := range plan {
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s |\n", it.Repo, it.Action, it.Stage, date(it.ArchiveOn),
+			strings.Join(it.Reasons, "; "), orDash(strings.Join(it.Owners, " ")))
+	}
+	return b.String()
+}
+
+// NotificationMarkdown renders one owner's notification.
+func NotificationMarkdown(n Notification) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## Repository lifecycle: action needed\n\n%s, these repositories need you:\n\n", n.Owner)
+	for _, it := range n.Items {
+		fmt.Fprintf(&b, "- **%s** — ", it.Repo)
+		switch it.Action {
+		case ActionArchive:
+			fmt.Fprintf(&b, "will be archived (`%s`)", it.Command)
+		case ActionScheduleArchive:
+			fmt.Fprintf(&b, "scheduled for archival on %s", date(it.ArchiveOn))
+		case ActionBlocked:
+			fmt.Fprintf(&b, "archival on %s is blocked until fixed", date(it.ArchiveOn))
+		case ActionProposeDeprecation:
+			fmt.Fprintf(&b, "looks unmaintained; declare `lifecycle: deprecated` in `%s` or push to keep it", DeclarationFile)
+		case ActionFix:
+			fmt.Fprintf(&b, "does not meet the requirements of stage %s", it.Stage)
+		}
+		if it.Replacement != "" {
+			fmt.Fprintf(&b, "; replaced by %s", it.Replacement)
+		}
+		b.WriteString("\n")
+		for _, r := range it.Reasons {
+			fmt.Fprintf(&b, "  - %s\n", r)
+		}
+	}
+	b.WriteString("\nAn archived repository no longer needs label sync, compliance scans or access reviews. ")
+	fmt.Fprintf(&b, "To keep a repository, set its stage in `%s`.\n", DeclarationFile)
+	return b.String()
+}
+
+func date(t *time.Time) string {
+	if t == nil {
+		return "—"
+	}
+	return t.Format("2006-01-02")
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "—"
+	}
+	return s
+}
@@ -0,0 +1,85 @@
+package people
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Absence is one out-of-office entry. Dates are inclusive; Until may be a
+// date (whole day) or an RFC 3339 time.
+type Absence struct {
+	Person string `yaml:"person" json:"person"`
+	From   string `yaml:"from" json:"from"`
+	Until  string `yaml:"until" json:"until"`
+	Note   string `yaml:"note,omitempty" json:"note,omitempty"`
+
+	from, until time.Time
+}
+
+// Absences is an out-of-office file:
+//
+//	out_of_office:
+//	  - person: carol
+//	    from: 2026-10-12
+//	    until: 2026-10-20
+//	    note: annual leave
+type Absences []Absence
+
+// LoadAbsences reads an out-of-office file.
+func LoadAbsences(path string) (Absences, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f struct {
+		OutOfOffice []Absence `yaml:"out_of_office"`
+	}
+	if err := yaml.Unmarshal(raw, &f); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for i := range f.OutOfOffice {
+		a := &f.OutOfOffice[i]
+		a.Person = Key(a.Person)
+		if a.from, err = parseDay(a.From, false); err != nil {
+			return nil, fmt.Errorf("%s: %s: from: %w", path, a.Person, err)
+		}
+		if a.until, err = parseDay(a.Until, true); err != nil {
+			return nil, fmt.Errorf("%s: %s: until: %w", path, a.Person, err)
+		}
+		if a.until.Before(a.from) {
+			return nil, fmt.Errorf("%s: %s: until is before from", path, a.Person)
+		}
+	}
+	return f.OutOfOffice, nil
+}
+
+// Away returns the absence covering person at t, or nil.
+func (as Absences) Away(person string, t time.Time) *Absence {
+	person = Key(person)
+	for i := range as {
+		a := &as[i]
+		if a.Person == person && !t.Before(a.from) && !t.After(a.until) {
+			return a
+		}
+	}
+	return nil
+}
+
+// parseDay parses a date or RFC 3339 time; a bare date as an end bound
+// covers the whole day.
+func parseDay(s string, end bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected YYYY-MM-DD or RFC 3339, got %q", s)
+	}
+	if end {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
+}
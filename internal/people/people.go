@@ -0,0 +1,177 @@
+// Package people maps GitHub accounts, commit emails and shared aliases to
+// the people behind them and the teams they belong to.
+//
+// The directory is a YAML file kept next to the workflows that need it:
+//
+//	teams:
+//	  payments: ["@carol", "@dave"]
+//	  platform-leads: ["@alice"]
+//	people:                      # optional: several identities, one person
+//	  carol:
+//	    accounts: ["carol", "carol-ops"]
+//	    emails: ["carol@brikbyte.io"]
+//	aliases:                     # shared or team accounts → people behind them
+//	  payments-bot: ["carol", "dave"]
+//
+// Accounts that are not listed resolve to themselves, so the directory only
+// has to describe the exceptions.
+package people
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Person lists the identities of one person.
+type Person struct {
+	Accounts []string `yaml:"accounts"`
+	Emails   []string `yaml:"emails"`
+}
+
+type file struct {
+	Teams   map[string][]string `yaml:"teams"`
+	People  map[string]Person   `yaml:"people"`
+	Aliases map[string][]string `yaml:"aliases"`
+}
+
+// Directory resolves identities. The zero value resolves every account to
+// itself and knows no teams.
+type Directory struct {
+	identity map[string]string   // account or email → person
+	aliases  map[string][]string // alias → people
+	teams    map[string][]string // team → people
+	memberOf map[string][]string // person → teams
+}
+
+// Load reads a directory file.
+func Load(path string) (*Directory, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f file
+	if err := yaml.Unmarshal(raw, &f); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	d := &Directory{
+		identity: map[string]string{},
+		aliases:  map[string][]string{},
+		teams:    map[string][]string{},
+		memberOf: map[string][]string{},
+	}
+	for id, p := range f.People {
+		pid := Key(id)
+		d.identity[pid] = pid
+		for _, a := range p.Accounts {
+			d.identity[Key(a)] = pid
+		}
+		for _, e := range p.Emails {
+			d.identity[strings.ToLower(e)] = pid
+		}
+	}
+	for alias, members := range f.Aliases {
+		for _, m := range members {
+			d.aliases[Key(alias)] = append(d.aliases[Key(alias)], d.person(m))
+		}
+	}
+	for team, members := range f.Teams {
+		t := TeamKey(team)
+		for _, m := range members {
+			p := d.person(m)
+			d.teams[t] = appendUnique(d.teams[t], p)
+			d.memberOf[p] = appendUnique(d.memberOf[p], t)
+		}
+	}
+	for _, ts := range d.memberOf {
+		sort.Strings(ts)
+	}
+	return d, nil
+}
+
+// Key normalises a handle: lower case, no leading "@".
+func Key(handle string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(handle), "@"))
+}
+
+// TeamKey normalises a team name so that "@BrikByte-Studios/payments",
+// "BrikByte-Studios/payments" and "payments" all name the same team.
+func TeamKey(team string) string {
+	k := Key(team)
+	if i := strings.LastIndex(k, "/"); i >= 0 {
+		return k[i+1:]
+	}
+	return k
+}
+
+var noreplyRe = regexp.MustCompile(`^(?:\d+\+)?([^@]+)@users\.noreply\.github\.com$`)
+
+func (d *Directory) person(account string) string {
+	k := Key(account)
+	if d != nil {
+		if p, ok := d.identity[k]; ok {
+			return p
+		}
+	}
+	return k
+}
+
+// Resolve returns the people behind an account, commit email or alias.
+// GitHub noreply emails resolve through the login they embed.
+func (d *Directory) Resolve(identity string) []string {
+	k := Key(identity)
+	if k == "" {
+		return nil
+	}
+	if d != nil {
+		if ps, ok := d.aliases[k]; ok {
+			return ps
+		}
+		if p, ok := d.identity[k]; ok {
+			return []string{p}
+		}
+	}
+	if m := noreplyRe.FindStringSubmatch(k); m != nil {
+		return d.Resolve(m[1])
+	}
+	return []string{k}
+}
+
+// Teams returns the teams of a person (team keys, sorted).
+func (d *Directory) Teams(person string) []string {
+	if d == nil {
+		return nil
+	}
+	return d.memberOf[Key(person)]
+}
+
+// Members returns the people in a team.
+func (d *Directory) Members(team string) []string {
+	if d == nil {
+		return nil
+	}
+	return d.teams[TeamKey(team)]
+}
+
+// InTeam reports whether person belongs to team.
+func (d *Directory) InTeam(person, team string) bool {
+	for _, t := range d.Teams(person) {
+		if t == TeamKey(team) {
+			return true
+		}
+	}
+	return false
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, x := range list {
+		if x == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
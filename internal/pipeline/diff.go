@@ -0,0 +1,114 @@
+package pipeline
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Diff returns a unified diff (3 lines of context) from a to b, or "" when
+// they are equal.
+func Diff(nameA, nameB, a, b string) string {
+	if a == b {
+		return ""
+	}
+	x, y := splitLines(a), splitLines(b)
+	ops := lineOps(x, y)
+
+	const ctx = 3
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", nameA, nameB)
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		// Grow the hunk until 2*ctx unchanged lines separate changes.
+		start := max(i-ctx, 0)
+		end := i
+		for j := i; j < len(ops); j++ {
+			if ops[j].kind != ' ' {
+				end = j + 1
+			} else if j-end >= 2*ctx {
+				break
+			}
+		}
+		end = min(end+ctx, len(ops))
+		hunk := ops[start:end]
+		ax, bx := ops[start].a, ops[start].b
+		an, bn := 0, 0
+		for _, o := range hunk {
+			if o.kind != '+' {
+				an++
+			}
+			if o.kind != '-' {
+				bn++
+			}
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(ax, an), hunkRange(bx, bn))
+		for _, o := range hunk {
+			out.WriteByte(o.kind)
+			out.WriteString(o.text)
+			out.WriteByte('\n')
+		}
+		i = end
+	}
+	return out.String()
+}
+
+type lineOp struct {
+	kind byte // ' ', '-', '+'
+	text string
+	// a and b are the 0-based positions in each file before this line.
+	a, b int
+}
+
+// lineOps computes a shortest edit script with a longest common
+// subsequence table; generated files are small.
+func lineOps(x, y []string) []lineOp {
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	var ops []lineOp
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			ops = append(ops, lineOp{' ', x[i], i, j})
+			i, j = i+1, j+1
+		case i < len(x) && (j == len(y) || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, lineOp{'-', x[i], i, j})
+			i++
+		default:
+			ops = append(ops, lineOp{'+', y[j], i, j})
+			j++
+		}
+	}
+	return ops
+}
+
+func hunkRange(start, n int) string {
+	if n == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if n == 1 {
+		return fmt.Sprint(start + 1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, n)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
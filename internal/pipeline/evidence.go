@@ -0,0 +1,319 @@
+package pipeline
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BrikByte-Studios/github-governance/internal/codeowners"
+)
+
+// TestSummary totals JUnit XML or .NET TRX reports.
+type TestSummary struct {
+	Total   int      `json:"total"`
+	Failed  int      `json:"failed"`
+	Skipped int      `json:"skipped"`
+	Files   []string `json:"files"`
+}
+
+// CoverageSummary totals coverage reports as covered/total counts, so
+// reports of several modules add up.
+type CoverageSummary struct {
+	LinesCovered    int      `json:"linesCovered"`
+	Lines           int      `json:"lines"`
+	BranchesCovered int      `json:"branchesCovered"`
+	Branches        int      `json:"branches"`
+	Files           []string `json:"files"`
+}
+
+// Line is the line coverage in percent, rounded to two decimals.
+func (c CoverageSummary) Line() float64 { return percent(c.LinesCovered, c.Lines) }
+
+// Branch is the branch coverage in percent.
+func (c CoverageSummary) Branch() float64 { return percent(c.BranchesCovered, c.Branches) }
+
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(n)/float64(total)*10000) / 100
+}
+
+// FindReports returns the files under dir matching a report glob (relative
+// to dir, "**" allowed), sorted. Dependency and VCS directories are not
+// searched.
+func FindReports(dir, glob string) ([]string, error) {
+	if glob == "" {
+		return nil, nil
+	}
+	re, err := codeowners.Compile("/" + strings.TrimPrefix(glob, "/"))
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			switch d.Name() {
+			case ".git", "node_modules", ".venv":
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, _ := filepath.Rel(dir, p)
+		if re.MatchString(filepath.ToSlash(rel)) {
+			out = append(out, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	sort.Strings(out)
+	return out, err
+}
+
+// ParseTests totals the test reports; the format is detected from the
+// root element (testsuites, testsuite or TRX TestRun).
+func ParseTests(dir string, files []string) (TestSummary, error) {
+	sum := TestSummary{Files: files}
+	for _, f := range files {
+		raw, err := os.ReadFile(filepath.Join(dir, f))
+		if err != nil {
+			return sum, err
+		}
+		var root struct {
+			XMLName xml.Name
+			junitSuite
+			Summary struct {
+				Counters trxCounters `xml:"Counters"`
+			} `xml:"ResultSummary"`
+		}
+		if err := xml.Unmarshal(raw, &root); err != nil {
+			return sum, fmt.Errorf("%s: %w", f, err)
+		}
+		switch root.XMLName.Local {
+		case "testsuites", "testsuite":
+			total, failed, skipped := root.junitSuite.counts()
+			sum.Total, sum.Failed, sum.Skipped = sum.Total+total, sum.Failed+failed, sum.Skipped+skipped
+		case "TestRun":
+			c := root.Summary.Counters
+			sum.Total += c.Total
+			sum.Failed += c.Failed + c.Error + c.Timeout + c.Aborted
+			sum.Skipped += c.Total - c.Executed
+		default:
+			return sum, fmt.Errorf("%s: <%s> is not a JUnit or TRX report", f, root.XMLName.Local)
+		}
+	}
+	return sum, nil
+}
+
+type junitSuite struct {
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Errors   int          `xml:"errors,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Disabled int          `xml:"disabled,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+// counts uses the element's own totals, or sums its suites when a
+// <testsuites> wrapper has none.
+func (s junitSuite) counts() (total, failed, skipped int) {
+	if s.Tests > 0 || len(s.Suites) == 0 {
+		return s.Tests, s.Failures + s.Errors, s.Skipped + s.Disabled
+	}
+	for _, c := range s.Suites {
+		t, f, sk := c.counts()
+		total, failed, skipped = total+t, failed+f, skipped+sk
+	}
+	return total, failed, skipped
+}
+
+type trxCounters struct {
+	Total    int `xml:"total,attr"`
+	Executed int `xml:"executed,attr"`
+	Failed   int `xml:"failed,attr"`
+	Error    int `xml:"error,attr"`
+	Timeout  int `xml:"timeout,attr"`
+	Aborted  int `xml:"aborted,attr"`
+}
+
+// ParseCoverage totals lcov, Cobertura, JaCoCo and Go coverprofile
+// reports.
+func ParseCoverage(dir string, files []string) (CoverageSummary, error) {
+	sum := CoverageSummary{Files: files}
+	for _, f := range files {
+		raw, err := os.ReadFile(filepath.Join(dir, f))
+		if err != nil {
+			return sum, err
+		}
+		var c CoverageSummary
+		switch trimmed := bytes.TrimSpace(raw); {
+		case bytes.HasPrefix(trimmed, []byte("mode:")):
+			c, err = goCoverage(raw)
+		case bytes.HasPrefix(trimmed, []byte("<")):
+			c, err = xmlCoverage(raw)
+		default:
+			c, err = lcovCoverage(raw)
+		}
+		if err != nil {
+			return sum, fmt.Errorf("%s: %w", f, err)
+		}
+		sum.LinesCovered += c.LinesCovered
+		sum.Lines += c.Lines
+		sum.BranchesCovered += c.BranchesCovered
+		sum.Branches += c.Branches
+	}
+	return sum, nil
+}
+
+// goCoverage counts statements; a block listed by several test binaries
+// is covered if any of them covered it.
+func goCoverage(raw []byte) (CoverageSummary, error) {
+	stmts := map[string]int{}
+	covered := map[string]bool{}
+	sc := bufio.NewScanner(bytes.NewReader(raw))
+	for sc.Scan() {
+		line := sc.Text()
+		if strings.HasPrefix(line, "mode:") || line == "" {
+			continue
+		}
+		f := strings.Fields(line)
+		if len(f) != 3 {
+			return CoverageSummary{}, fmt.Errorf("bad coverprofile line %q", line)
+		}
+		n, err1 := strconv.Atoi(f[1])
+		count, err2 := strconv.Atoi(f[2])
+		if err1 != nil || err2 != nil {
+			return CoverageSummary{}, fmt.Errorf("bad coverprofile line %q", line)
+		}
+		stmts[f[0]] = n
+		covered[f[0]] = covered[f[0]] || count > 0
+	}
+	var c CoverageSummary
+	for block, n := range stmts {
+		c.Lines += n
+		if covered[block] {
+			c.LinesCovered += n
+		}
+	}
+	return c, sc.Err()
+}
+
+func lcovCoverage(raw []byte) (CoverageSummary, error) {
+	var c CoverageSummary
+	seen := false
+	for _, line := range strings.Split(string(raw), "\n") {
+		key, val, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(val)
+		if err != nil {
+			continue
+		}
+		switch key {
+		case "LF":
+			c.Lines += n
+		case "LH":
+			c.LinesCovered += n
+		case "BRF":
+			c.Branches += n
+		case "BRH":
+			c.BranchesCovered += n
+		default:
+			continue
+		}
+		seen = true
+	}
+	if !seen {
+		return c, fmt.Errorf("not an lcov, Cobertura, JaCoCo or Go coverage report")
+	}
+	return c, nil
+}
+
+// xmlCoverage reads Cobertura <coverage> totals or the report-level
+// JaCoCo <counter> elements.
+func xmlCoverage(raw []byte) (CoverageSummary, error) {
+	var root struct {
+		XMLName         xml.Name
+		LinesCovered    int `xml:"lines-covered,attr"`
+		LinesValid      int `xml:"lines-valid,attr"`
+		BranchesCovered int `xml:"branches-covered,attr"`
+		BranchesValid   int `xml:"branches-valid,attr"`
+		Counters        []struct {
+			Type    string `xml:"type,attr"`
+			Missed  int    `xml:"missed,attr"`
+			Covered int    `xml:"covered,attr"`
+		} `xml:"counter"`
+	}
+	// JaCoCo reports reference a DTD the decoder must not fetch.
+	dec := xml.NewDecoder(bytes.NewReader(raw))
+	dec.Strict = false
+	if err := dec.Decode(&root); err != nil {
+		return CoverageSummary{}, err
+	}
+	var c CoverageSummary
+	switch root.XMLName.Local {
+	case "coverage":
+		c.LinesCovered, c.Lines = root.LinesCovered, root.LinesValid
+		c.BranchesCovered, c.Branches = root.BranchesCovered, root.BranchesValid
+	case "report":
+		for _, k := range root.Counters {
+			switch k.Type {
+			case "LINE":
+				c.LinesCovered, c.Lines = k.Covered, k.Covered+k.Missed
+			case "BRANCH":
+				c.BranchesCovered, c.Branches = k.Covered, k.Covered+k.Missed
+			}
+		}
+	default:
+		return c, fmt.Errorf("<%s> is not a Cobertura or JaCoCo report", root.XMLName.Local)
+	}
+	return c, nil
+}
+
+// Evidence reads the plan's reports under dir into gate inputs (tests and
+// coverage, as the CI adapters normalise them). Reports that were not
+// produced are left out, so their rules report missing evidence.
+func Evidence(dir string, r Reports) (map[string]any, error) {
+	in := map[string]any{}
+	if files, err := FindReports(dir, r.JUnit); err != nil {
+		return nil, err
+	} else if len(files) > 0 {
+		t, err := ParseTests(dir, files)
+		if err != nil {
+			return nil, err
+		}
+		status := "green"
+		if t.Failed > 0 {
+			status = "red"
+		}
+		in["tests"] = map[string]any{
+			"total": t.Total, "failed": t.Failed, "skipped": t.Skipped,
+			"status": status, "report_url": files[0],
+		}
+	}
+	if files, err := FindReports(dir, r.Coverage); err != nil {
+		return nil, err
+	} else if len(files) > 0 {
+		c, err := ParseCoverage(dir, files)
+		if err != nil {
+			return nil, err
+		}
+		cov := map[string]any{"line": c.Line(), "report_url": files[0]}
+		if c.Branches > 0 {
+			cov["branch"] = c.Branch()
+		}
+		in["coverage"] = cov
+	}
+	return in, nil
+}
@@ -0,0 +1,210 @@
+package pipeline
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GitHub renders the plan as a GitHub Actions caller workflow: a build job
+// on the pinned setup action, then the reusable gate, SBOM and publish
+// workflows. The output is deterministic, so it can be diffed against the
+// committed file.
+func GitHub(p *Plan) ([]byte, error) {
+	on := mapping(
+		"push", mapping("branches", flow(p.Branches...)),
+		"pull_request", mapping("branches", flow(p.Branches...)),
+	)
+	if p.Publish != nil {
+		push := on.Content[1]
+		push.Content = append(push.Content, scalar("tags"), flow("v*"))
+	}
+
+	build := mapping(
+		"name", scalar(fmt.Sprintf("Build (%s %s, %s)", p.Stack, p.RuntimeVersion, p.Toolchain)),
+		"runs-on", scalar("ubuntu-latest"),
+	)
+	if p.WorkingDirectory != "." {
+		build.Content = append(build.Content, scalar("defaults"),
+			mapping("run", mapping("working-directory", scalar(p.WorkingDirectory))))
+	}
+	steps := seq(step(Step{Name: "Checkout", Uses: p.Checkout}))
+	for _, s := range p.Setup {
+		steps.Content = append(steps.Content, step(s))
+	}
+	for _, s := range p.Steps {
+		steps.Content = append(steps.Content, step(s))
+	}
+	if reports := p.Reports.paths(); len(reports) > 0 {
+		steps.Content = append(steps.Content, step(Step{
+			Name: "Upload test reports", If: "always()", Uses: p.UploadArtifact,
+			With: map[string]string{"name": ReportsArtifact, "path": strings.Join(reports, "\n"), "if-no-files-found": "warn"},
+		}))
+	}
+	if len(p.Artifacts) > 0 {
+		var paths []string
+		for _, a := range p.Artifacts {
+			paths = append(paths, p.rel(a))
+		}
+		steps.Content = append(steps.Content, step(Step{
+			Name: "Upload build artifacts", Uses: p.UploadArtifact,
+			With: map[string]string{"name": DistArtifact, "path": strings.Join(paths, "\n"), "if-no-files-found": "error"},
+		}))
+	}
+	build.Content = append(build.Content, scalar("steps"), steps)
+
+	jobs := mapping("build", build)
+	needs := []string{"build"}
+	if p.Gate != nil {
+		jobs.Content = append(jobs.Content, scalar("gate"), mapping(
+			"name", scalar("Policy gate"),
+			"needs", scalar("build"),
+			"uses", scalar(p.Gate.Uses),
+			"with", mapping("policy", scalar(p.Gate.Policy)),
+		))
+		needs = append(needs, "gate")
+	}
+	if p.SBOM != nil {
+		jobs.Content = append(jobs.Content, scalar("sbom"), mapping(
+			"name", scalar("SBOM"),
+			"needs", scalar("build"),
+			"uses", scalar(p.SBOM.Uses),
+			"with", mapping("format", scalar(p.SBOM.Format), "working-directory", scalar(p.WorkingDirectory)),
+		))
+		needs = append(needs, "sbom")
+	}
+	if p.Publish != nil {
+		jobs.Content = append(jobs.Content, scalar("publish"), mapping(
+			"name", scalar("Publish release"),
+			"needs", flow(needs...),
+			"if", scalar("startsWith(github.ref, 'refs/tags/v')"),
+			"permissions", mapping("contents", scalar("write")),
+			"uses", scalar(p.Publish.Uses),
+			"with", mapping(
+				"tag", scalar("${{ github.ref_name }}"),
+				"repository", scalar("${{ github.repository }}"),
+				"artifacts_glob", scalar("dist/release/**"),
+				"dist_artifact_name", scalar(DistArtifact),
+				"require_attachments", boolean(false),
+			),
+			"secrets", mapping("release_token", scalar("${{ secrets.GITHUB_TOKEN }}")),
+		))
+	}
+
+	doc := mapping(
+		"name", scalar("BrikPipe build: "+p.Name),
+		"on", on,
+		"permissions", mapping("contents", scalar("read")),
+		"concurrency", mapping(
+			"group", scalar("brikpipe-${{ github.workflow }}-${{ github.ref }}"),
+			"cancel-in-progress", boolean(true),
+		),
+		"jobs", jobs,
+	)
+	return encode(header(p, "github", "#"), doc)
+}
+
+// header is the generated-file banner, with each line behind comment.
+func header(p *Plan, target, comment string) string {
+	flag := ""
+	if target != "github" {
+		flag = " --target " + target
+	}
+	lines := []string{
+		fmt.Sprintf("Generated by `brikgov pipeline generate%s` from %s and the", flag, p.Source),
+		"runtime matrix (" + DefaultMatrixPath + ", ADR-0001).",
+		"Do not edit by hand: change the build config and regenerate.",
+		fmt.Sprintf("`brikgov pipeline check%s` fails when this file is out of date.", flag),
+	}
+	for _, w := range p.Warnings {
+		lines = append(lines, "WARNING: "+w)
+	}
+	var b strings.Builder
+	for _, l := range lines {
+		b.WriteString(comment + " " + l + "\n")
+	}
+	return b.String()
+}
+
+func step(s Step) *yaml.Node {
+	n := mapping()
+	if s.ID != "" {
+		n.Content = append(n.Content, scalar("id"), scalar(s.ID))
+	}
+	n.Content = append(n.Content, scalar("name"), scalar(s.Name))
+	if s.If != "" {
+		n.Content = append(n.Content, scalar("if"), scalar(s.If))
+	}
+	if s.Uses != "" {
+		n.Content = append(n.Content, scalar("uses"), scalar(s.Uses))
+	}
+	if len(s.With) > 0 {
+		w := mapping()
+		keys := make([]string, 0, len(s.With))
+		for k := range s.With {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			w.Content = append(w.Content, scalar(k), scalar(s.With[k]))
+		}
+		n.Content = append(n.Content, scalar("with"), w)
+	}
+	if s.Run != "" {
+		n.Content = append(n.Content, scalar("run"), scalar(s.Run))
+	}
+	return n
+}
+
+// mapping builds an ordered mapping from alternating keys and values.
+func mapping(kv ...any) *yaml.Node {
+	n := &yaml.Node{Kind: yaml.MappingNode}
+	for i := 0; i+1 < len(kv); i += 2 {
+		n.Content = append(n.Content, scalar(kv[i].(string)), kv[i+1].(*yaml.Node))
+	}
+	return n
+}
+
+func scalar(s string) *yaml.Node {
+	n := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: s}
+	if strings.Contains(s, "\n") {
+		n.Style = yaml.LiteralStyle
+		if !strings.HasSuffix(s, "\n") {
+			n.Value += "\n"
+		}
+	}
+	return n
+}
+
+func boolean(b bool) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: fmt.Sprint(b)}
+}
+
+func seq(items ...*yaml.Node) *yaml.Node {
+	return &yaml.Node{Kind: yaml.SequenceNode, Content: items}
+}
+
+func flow(items ...string) *yaml.Node {
+	n := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+	for _, s := range items {
+		n.Content = append(n.Content, scalar(s))
+	}
+	return n
+}
+
+func encode(header string, doc *yaml.Node) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString(header)
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
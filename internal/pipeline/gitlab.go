@@ -0,0 +1,120 @@
+package pipeline
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GitLab renders the plan as .gitlab-ci.yml: a build job in the runtime
+// image with the toolchain cache, then a gate job that builds brikgov and
+// evaluates the policy against the merge request's diff base.
+func GitLab(p *Plan) ([]byte, error) {
+	image, err := containerImage(p, "gitlab")
+	if err != nil {
+		return nil, err
+	}
+	rules := seq(mapping("if", scalar(`$CI_PIPELINE_SOURCE == "merge_request_event"`)))
+	for _, b := range p.Branches {
+		rules.Content = append(rules.Content, mapping("if", scalar(branchRule(b))))
+	}
+
+	build := mapping("stage", scalar("build"), "image", scalar(image))
+	if env := containerEnv(p, "$CI_PROJECT_DIR"); len(env) > 0 {
+		vars := mapping()
+		for _, kv := range env {
+			vars.Content = append(vars.Content, scalar(kv[0]), scalar(kv[1]))
+		}
+		build.Content = append(build.Content, scalar("variables"), vars)
+	}
+	if c := p.Container; c.CacheDir != "" {
+		key := mapping("files", flow(c.CacheKey))
+		if c.CacheKey == "" || strings.ContainsAny(c.CacheKey, "*?[") {
+			key = scalar(fmt.Sprintf("%s-%s-%s", p.Stack, p.Toolchain, p.RuntimeVersion))
+		}
+		build.Content = append(build.Content, scalar("cache"), mapping("key", key, "paths", flow(c.CacheDir)))
+	}
+	var before []string
+	if p.WorkingDirectory != "." {
+		before = append(before, "cd "+p.WorkingDirectory)
+	}
+	if p.Container.Bootstrap != "" {
+		before = append(before, p.Container.Bootstrap)
+	}
+	if len(before) > 0 {
+		build.Content = append(build.Content, scalar("before_script"), lines(before...))
+	}
+	var script []string
+	for _, s := range p.Steps {
+		script = append(script, s.Run)
+	}
+	build.Content = append(build.Content, scalar("script"), lines(script...))
+	var paths []string
+	for _, a := range p.Artifacts {
+		paths = append(paths, p.rel(a))
+	}
+	if p.Reports.Coverage != "" {
+		paths = append(paths, p.Reports.Coverage)
+	}
+	if len(paths) > 0 {
+		artifacts := mapping()
+		if len(p.Artifacts) > 0 {
+			artifacts.Content = append(artifacts.Content, scalar("name"), scalar(DistArtifact))
+		}
+		artifacts.Content = append(artifacts.Content, scalar("paths"), flow(paths...))
+		if p.Reports.JUnit != "" && !strings.HasSuffix(p.Reports.JUnit, ".trx") {
+			artifacts.Content = append(artifacts.Content, scalar("reports"), mapping("junit", scalar(p.Reports.JUnit)))
+		}
+		if p.Reports.Coverage != "" || p.Reports.JUnit != "" {
+			// Test reports matter most when the tests fail.
+			artifacts.Content = append(artifacts.Content, scalar("when"), scalar("always"))
+		}
+		build.Content = append(build.Content, scalar("artifacts"), artifacts)
+	}
+
+	stages := []string{"build"}
+	jobs := []any{"build", build}
+	if p.Gate != nil {
+		stages = append(stages, "gate")
+		jobs = append(jobs, "gate", mapping(
+			"stage", scalar("gate"),
+			"image", scalar(p.Gate.Runner.Image),
+			// Diff-scoped rules need the history back to the merge base.
+			"variables", mapping("GIT_DEPTH", scalar("0")),
+			"script", lines(gateScript(p, "${CI_MERGE_REQUEST_DIFF_BASE_SHA:-}", "$CI_COMMIT_SHA")...),
+			"artifacts", mapping(
+				"when", scalar("always"),
+				"paths", flow(GateDir+"/decision.json"),
+			),
+		))
+	}
+
+	doc := mapping(
+		"workflow", mapping("rules", rules),
+		"stages", flow(stages...),
+	)
+	doc.Content = append(doc.Content, mapping(jobs...).Content...)
+	return encode(header(p, "gitlab", "#"), doc)
+}
+
+// branchRule matches a push to a branch, which may be a glob as in the
+// GitHub trigger.
+func branchRule(branch string) string {
+	if !strings.ContainsAny(branch, "*?") {
+		return fmt.Sprintf("$CI_COMMIT_BRANCH == %q", branch)
+	}
+	re := regexp.QuoteMeta(branch)
+	re = strings.NewReplacer(`\*\*`, ".*", `\*`, "[^/]*", `\?`, "[^/]").Replace(re)
+	return "$CI_COMMIT_BRANCH =~ /^" + strings.ReplaceAll(re, "/", `\/`) + "$/"
+}
+
+// lines is a block sequence of shell commands.
+func lines(cmds ...string) *yaml.Node {
+	n := seq()
+	for _, c := range cmds {
+		n.Content = append(n.Content, scalar(c))
+	}
+	return n
+}
@@ -0,0 +1,174 @@
+package pipeline
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Jenkins renders the plan as a declarative Jenkinsfile for a multibranch
+// pipeline: a build stage in the runtime image, then a gate stage that
+// builds brikgov and evaluates the policy against the change target.
+func Jenkins(p *Plan) ([]byte, error) {
+	image, err := containerImage(p, "jenkins")
+	if err != nil {
+		return nil, err
+	}
+	var b groovy
+	b.raw(header(p, "jenkins", "//"))
+	b.open("pipeline")
+	b.line("agent none")
+	b.open("options")
+	b.line("disableConcurrentBuilds(abortPrevious: true)")
+	b.close()
+	b.open("stages")
+
+	b.open("stage(%s)", quote("Build"))
+	b.when(p.Branches)
+	b.agent(image)
+	if env := containerEnv(p, "${WORKSPACE}"); len(env) > 0 {
+		b.open("environment")
+		for _, kv := range env {
+			b.line("%s = %s", kv[0], interpolated(kv[1]))
+		}
+		b.close()
+	}
+	b.open("steps")
+	if p.WorkingDirectory != "." {
+		b.open("dir(%s)", quote(p.WorkingDirectory))
+	}
+	if p.Container.Bootstrap != "" {
+		b.line("sh label: %s, script: %s", quote("Bootstrap "+p.Toolchain), quote(p.Container.Bootstrap))
+	}
+	for _, s := range p.Steps {
+		b.line("sh label: %s, script: %s", quote(s.Name), quote(s.Run))
+	}
+	if p.WorkingDirectory != "." {
+		b.close()
+	}
+	b.close()
+	if len(p.Artifacts) > 0 || len(p.Reports.paths()) > 0 {
+		b.open("post")
+		if len(p.Reports.paths()) > 0 {
+			b.open("always")
+			if j := p.Reports.JUnit; j != "" && !strings.HasSuffix(j, ".trx") {
+				b.line("junit allowEmptyResults: true, testResults: %s", quote(j))
+			} else if j != "" {
+				b.line("archiveArtifacts artifacts: %s, allowEmptyArchive: true", quote(j))
+			}
+			if c := p.Reports.Coverage; c != "" {
+				b.line("archiveArtifacts artifacts: %s, allowEmptyArchive: true", quote(c))
+			}
+			b.close()
+		}
+		if len(p.Artifacts) > 0 {
+			var paths []string
+			for _, a := range p.Artifacts {
+				paths = append(paths, p.rel(a))
+			}
+			b.open("success")
+			b.line("archiveArtifacts artifacts: %s, fingerprint: true", quote(strings.Join(paths, ",")))
+			b.close()
+		}
+		b.close()
+	}
+	b.close()
+
+	if p.Gate != nil {
+		// Multibranch pull requests set CHANGE_TARGET; the merge base with
+		// it is the diff base of diff-scoped rules.
+		script := append([]string{
+			"set -eu",
+			`base=""`,
+			`if [ -n "${CHANGE_TARGET:-}" ]; then`,
+			`  git fetch --quiet --no-tags origin "+refs/heads/$CHANGE_TARGET:refs/remotes/origin/$CHANGE_TARGET"`,
+			`  base=$(git merge-base "origin/$CHANGE_TARGET" HEAD)`,
+			"fi",
+		}, gateScript(p, "$base", "$(git rev-parse HEAD)")...)
+		b.open("stage(%s)", quote("Policy gate"))
+		b.when(p.Branches)
+		b.agent(p.Gate.Runner.Image)
+		b.open("steps")
+		b.script("Policy gate", script)
+		b.close()
+		b.open("post")
+		b.open("always")
+		b.line("archiveArtifacts artifacts: %s, allowEmptyArchive: true", quote(GateDir+"/decision.json"))
+		b.close()
+		b.close()
+		b.close()
+	}
+
+	b.close()
+	b.close()
+	return []byte(b.String()), nil
+}
+
+// groovy writes an indented Jenkinsfile.
+type groovy struct {
+	strings.Builder
+	depth int
+}
+
+func (g *groovy) raw(s string) { g.WriteString(s) }
+
+func (g *groovy) line(format string, args ...any) {
+	g.WriteString(strings.Repeat("    ", g.depth))
+	fmt.Fprintf(g, format, args...)
+	g.WriteByte('\n')
+}
+
+func (g *groovy) open(format string, args ...any) {
+	g.line(format+" {", args...)
+	g.depth++
+}
+
+func (g *groovy) close() {
+	g.depth--
+	g.line("}")
+}
+
+// when runs the stage for change requests and pushes to the plan's
+// branches (Ant-style globs, as in the GitHub trigger).
+func (g *groovy) when(branches []string) {
+	g.open("when")
+	g.open("anyOf")
+	g.line("changeRequest()")
+	for _, br := range branches {
+		g.line("branch pattern: %s, comparator: 'GLOB'", quote(br))
+	}
+	g.close()
+	g.close()
+}
+
+func (g *groovy) agent(image string) {
+	g.open("agent")
+	g.open("docker")
+	g.line("image %s", quote(image))
+	g.close()
+	g.close()
+}
+
+// script is a multi-line sh step.
+func (g *groovy) script(label string, cmds []string) {
+	g.line("sh label: %s, script: '''", quote(label))
+	for _, c := range cmds {
+		g.line("    %s", escapeGroovy(c, "'''"))
+	}
+	g.line("'''")
+}
+
+// quote is a single-quoted Groovy string, which does not interpolate.
+func quote(s string) string {
+	return "'" + escapeGroovy(s, "'") + "'"
+}
+
+// interpolated is a double-quoted Groovy string for values that reference
+// ${WORKSPACE}.
+func interpolated(s string) string {
+	return `"` + escapeGroovy(s, `"`) + `"`
+}
+
+// escapeGroovy escapes backslashes and the closing quote q.
+func escapeGroovy(s, q string) string {
+	return strings.NewReplacer(`\`, `\\`, q, strings.Repeat(`\`+q[:1], len(q))).Replace(s)
+}
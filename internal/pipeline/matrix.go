@@ -0,0 +1,322 @@
+// Package pipeline resolves a repository's build config (brikpipe.build.yml)
+// against the runtime matrix of ADR-0001 into a build plan, and renders the
+// plan as a GitHub Actions workflow that calls this repository's reusable
+// gate, SBOM and publish workflows, or as a GitLab CI or Jenkins pipeline
+// with the same build and gate.
+package pipeline
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Default locations, relative to the repository root.
+const (
+	DefaultMatrixPath   = "docs/pipelines/runtime-matrix.yml"
+	DefaultConfigPath   = "brikpipe.build.yml"
+	DefaultWorkflowPath = ".github/workflows/brikpipe-build.yml"
+)
+
+// Support tiers (ADR-0001 §2.3).
+const (
+	TierSupported    = "supported"
+	TierExperimental = "experimental"
+	TierPlanned      = "planned"
+)
+
+// Matrix is docs/pipelines/runtime-matrix.yml.
+type Matrix struct {
+	SchemaVersion int    `yaml:"schemaVersion" json:"schemaVersion"`
+	ReviewedOn    string `yaml:"reviewedOn" json:"reviewedOn"`
+	NextReview    string `yaml:"nextReview" json:"nextReview"`
+	Actions       struct {
+		Checkout       string `yaml:"checkout" json:"checkout"`
+		UploadArtifact string `yaml:"uploadArtifact" json:"uploadArtifact"`
+	} `yaml:"actions" json:"actions"`
+	ReusableWorkflows ReusableWorkflows `yaml:"reusableWorkflows" json:"reusableWorkflows"`
+	GateRunner        GateRunner        `yaml:"gateRunner" json:"gateRunner"`
+	Stacks            map[string]Stack  `yaml:"stacks" json:"stacks"`
+	Exceptions        []Exception       `yaml:"exceptions" json:"exceptions"`
+}
+
+// ReusableWorkflows locates the gate, SBOM and publish workflows generated
+// pipelines call.
+type ReusableWorkflows struct {
+	Repository string `yaml:"repository" json:"repository"`
+	Ref        string `yaml:"ref" json:"ref"`
+	Gate       string `yaml:"gate" json:"gate"`
+	SBOM       string `yaml:"sbom" json:"sbom"`
+	Publish    string `yaml:"publish" json:"publish"`
+}
+
+// Uses returns the "uses:" reference of one of the workflows.
+func (r ReusableWorkflows) Uses(path string) string {
+	return r.Repository + "/" + path + "@" + r.Ref
+}
+
+// GateRunner is how CI systems without reusable workflows (GitLab CI,
+// Jenkins) run the gate: build brikgov from Repository at Ref in Image.
+type GateRunner struct {
+	Image      string `yaml:"image" json:"image"`
+	Repository string `yaml:"repository" json:"repository"`
+	Ref        string `yaml:"ref" json:"ref"`
+}
+
+// Stack is one language stack of the matrix.
+type Stack struct {
+	Tier              string `yaml:"tier" json:"tier"`
+	Priority          string `yaml:"priority" json:"priority"`
+	SupportedVersions struct {
+		Policy   string   `yaml:"policy" json:"policy"`
+		Versions []string `yaml:"versions" json:"versions"`
+	} `yaml:"supportedVersions" json:"supportedVersions"`
+	DefaultVersion string `yaml:"defaultVersion" json:"defaultVersion"`
+	// VersionCommand prints the version of a locally installed runtime.
+	VersionCommand string `yaml:"versionCommand" json:"versionCommand"`
+	// Images are the container images per version, for CI systems that run
+	// jobs in containers.
+	Images map[string]string `yaml:"images" json:"images"`
+	Setup  struct {
+		Action       string            `yaml:"action" json:"action"`
+		VersionInput string            `yaml:"versionInput" json:"versionInput"`
+		With         map[string]string `yaml:"with" json:"with,omitempty"`
+	} `yaml:"setup" json:"setup"`
+	Toolchains struct {
+		Default string               `yaml:"default" json:"default"`
+		Allowed map[string]Toolchain `yaml:"allowed" json:"allowed"`
+	} `yaml:"toolchains" json:"toolchains"`
+	Deprecation map[string]Deprecation `yaml:"deprecation" json:"deprecation,omitempty"`
+}
+
+// Supports reports whether v is in the stack's version allowlist.
+func (s Stack) Supports(v string) bool {
+	for _, x := range s.SupportedVersions.Versions {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Toolchain is a package manager / build tool of a stack.
+type Toolchain struct {
+	// Cache is the setup action's cache input ("npm", "poetry", "true").
+	Cache string `yaml:"cache" json:"cache"`
+	// Lockfile is the cache key file, relative to the project directory.
+	Lockfile string `yaml:"lockfile" json:"lockfile"`
+	PreSetup []Step `yaml:"preSetup" json:"preSetup,omitempty"`
+	// Images override the stack's images, for toolchains that are not in
+	// the runtime image (Maven).
+	Images map[string]string `yaml:"images" json:"images,omitempty"`
+	// Bootstrap installs the toolchain in a container image, where the
+	// preSetup actions do not run.
+	Bootstrap string `yaml:"bootstrap" json:"bootstrap,omitempty"`
+	// CacheDir is the toolchain's cache, relative to the project directory,
+	// for CI systems that cache paths. CacheEnv points the toolchain at it;
+	// "{cache}" in a value stands for the absolute cache directory.
+	CacheDir string            `yaml:"cacheDir" json:"cacheDir,omitempty"`
+	CacheEnv map[string]string `yaml:"cacheEnv" json:"cacheEnv,omitempty"`
+	Reports  Reports           `yaml:"reports" json:"reports"`
+	Commands Commands          `yaml:"commands" json:"commands"`
+}
+
+// Reports are where the test command writes its results: JUnit XML (or
+// .NET TRX) and a coverage report (lcov, Cobertura, JaCoCo or a Go
+// coverprofile). They are globs relative to the project directory.
+type Reports struct {
+	JUnit    string `yaml:"junit" json:"junit,omitempty"`
+	Coverage string `yaml:"coverage" json:"coverage,omitempty"`
+}
+
+// Image returns the container image of version v, if the matrix has one.
+func (s Stack) Image(toolchain, v string) string {
+	if img := s.Toolchains.Allowed[toolchain].Images[v]; img != "" {
+		return img
+	}
+	return s.Images[v]
+}
+
+// Commands are the build conventions of a toolchain.
+type Commands struct {
+	Install string `yaml:"install" json:"install"`
+	Test    string `yaml:"test" json:"test"`
+	Build   string `yaml:"build" json:"build"`
+}
+
+// Deprecation announces the end of support for a version.
+type Deprecation struct {
+	EndOfSupport string `yaml:"endOfSupport" json:"endOfSupport"`
+	Note         string `yaml:"note" json:"note"`
+}
+
+// Exception lets one repository use a version outside the allowlist until
+// it expires (ADR-0001 §2.5).
+type Exception struct {
+	Repo      string `yaml:"repo" json:"repo"`
+	Stack     string `yaml:"stack" json:"stack"`
+	Version   string `yaml:"version" json:"version"`
+	ExpiresOn string `yaml:"expiresOn" json:"expiresOn"`
+	// Image is the container image of the excepted version.
+	Image    string `yaml:"image" json:"image,omitempty"`
+	Approval struct {
+		Owner     string `yaml:"owner" json:"owner"`
+		Reference string `yaml:"reference" json:"reference"`
+	} `yaml:"approval" json:"approval"`
+}
+
+// Expired reports whether the exception no longer applies at now; the
+// expiry date is inclusive.
+func (e Exception) Expired(now time.Time) bool {
+	t, err := time.Parse("2006-01-02", e.ExpiresOn)
+	return err != nil || now.After(t.AddDate(0, 0, 1))
+}
+
+// LoadMatrix reads and validates the runtime matrix.
+func LoadMatrix(path string) (*Matrix, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m Matrix
+	if err := yaml.Unmarshal(raw, &m); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if errs := m.Validate(); len(errs) > 0 {
+		return nil, fmt.Errorf("%s: %s", path, strings.Join(errs, "; "))
+	}
+	return &m, nil
+}
+
+// StackNames returns the stacks of the matrix, sorted.
+func (m *Matrix) StackNames() []string {
+	return sortedKeys(m.Stacks)
+}
+
+// Validate runs the sanity checks of ADR-0001 §2.6 and checks that every
+// action and image is pinned.
+func (m *Matrix) Validate() []string {
+	var errs []string
+	bad := func(format string, args ...any) { errs = append(errs, fmt.Sprintf(format, args...)) }
+	pinned := func(where, uses string) {
+		if err := checkPinned(uses); err != nil {
+			bad("%s: %v", where, err)
+		}
+	}
+	pinned("actions.checkout", m.Actions.Checkout)
+	pinned("actions.uploadArtifact", m.Actions.UploadArtifact)
+	rw := m.ReusableWorkflows
+	if rw.Repository == "" || rw.Ref == "" || rw.Gate == "" || rw.SBOM == "" || rw.Publish == "" {
+		bad("reusableWorkflows needs repository, ref, gate, sbom and publish")
+	}
+	gr := m.GateRunner
+	if gr.Image == "" || gr.Repository == "" || gr.Ref == "" {
+		bad("gateRunner needs image, repository and ref")
+	} else if err := checkImage(gr.Image); err != nil {
+		bad("gateRunner.image: %v", err)
+	}
+	for _, name := range m.StackNames() {
+		s := m.Stacks[name]
+		switch s.Tier {
+		case TierSupported, TierExperimental, TierPlanned:
+		default:
+			bad("stacks.%s.tier %q is not supported, experimental or planned", name, s.Tier)
+		}
+		if s.Tier == TierPlanned {
+			continue
+		}
+		if !s.Supports(s.DefaultVersion) {
+			bad("stacks.%s.defaultVersion %q is not in supportedVersions.versions", name, s.DefaultVersion)
+		}
+		if strings.HasPrefix(s.SupportedVersions.Policy, "N/N-1") && len(s.SupportedVersions.Versions) < 2 {
+			bad("stacks.%s: policy N/N-1 needs two versions", name)
+		}
+		pinned("stacks."+name+".setup.action", s.Setup.Action)
+		if s.VersionCommand == "" {
+			bad("stacks.%s.versionCommand is required", name)
+		}
+		if s.Setup.VersionInput == "" {
+			bad("stacks.%s.setup.versionInput is required", name)
+		}
+		if _, ok := s.Toolchains.Allowed[s.Toolchains.Default]; !ok {
+			bad("stacks.%s.toolchains.default %q is not an allowed toolchain", name, s.Toolchains.Default)
+		}
+		for _, tn := range sortedKeys(s.Toolchains.Allowed) {
+			tc := s.Toolchains.Allowed[tn]
+			for _, v := range s.SupportedVersions.Versions {
+				img := s.Image(tn, v)
+				if img == "" {
+					bad("stacks.%s: no image for %s %s", name, tn, v)
+				} else if err := checkImage(img); err != nil {
+					bad("stacks.%s.images: %v", name, err)
+				}
+			}
+			if len(tc.CacheEnv) > 0 && tc.CacheDir == "" {
+				bad("stacks.%s.toolchains.allowed.%s: cacheEnv needs cacheDir", name, tn)
+			}
+			for i, st := range tc.PreSetup {
+				if st.Uses != "" {
+					pinned(fmt.Sprintf("stacks.%s.toolchains.allowed.%s.preSetup[%d]", name, tn, i), st.Uses)
+				}
+			}
+			if tc.Commands.Install == "" || tc.Commands.Test == "" || tc.Commands.Build == "" {
+				bad("stacks.%s.toolchains.allowed.%s needs install, test and build commands", name, tn)
+			}
+		}
+	}
+	for i, e := range m.Exceptions {
+		if _, ok := m.Stacks[e.Stack]; !ok {
+			bad("exceptions[%d]: unknown stack %q", i, e.Stack)
+		}
+		if e.Repo == "" || e.Version == "" {
+			bad("exceptions[%d] needs repo and version", i)
+		}
+		if _, err := time.Parse("2006-01-02", e.ExpiresOn); err != nil {
+			bad("exceptions[%d].expiresOn %q is not YYYY-MM-DD", i, e.ExpiresOn)
+		}
+		if e.Approval.Owner == "" || e.Approval.Reference == "" {
+			bad("exceptions[%d] needs approval.owner and approval.reference", i)
+		}
+		if e.Image != "" {
+			if err := checkImage(e.Image); err != nil {
+				bad("exceptions[%d].image: %v", i, err)
+			}
+		}
+	}
+	return errs
+}
+
+// floatingRefs are refs that move under a pinned workflow.
+var floatingRefs = map[string]bool{"main": true, "master": true, "latest": true, "HEAD": true}
+
+// checkPinned requires owner/repo[/path]@ref with a ref that is not a
+// branch name.
+func checkPinned(uses string) error {
+	action, ref, ok := strings.Cut(uses, "@")
+	if !ok || !strings.Contains(action, "/") || ref == "" {
+		return fmt.Errorf("%q is not owner/repo@ref", uses)
+	}
+	if floatingRefs[ref] {
+		return fmt.Errorf("%q is not pinned (use a release tag or commit SHA)", uses)
+	}
+	return nil
+}
+
+// checkImage requires an explicit image tag or digest other than "latest".
+func checkImage(image string) error {
+	if strings.Contains(image, "@sha256:") {
+		return nil
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	_, tag, ok := strings.Cut(name, ":")
+	if !ok || tag == "" {
+		return fmt.Errorf("%q has no tag", image)
+	}
+	if floatingRefs[tag] {
+		return fmt.Errorf("%q is not pinned (use a version tag or digest)", image)
+	}
+	return nil
+}
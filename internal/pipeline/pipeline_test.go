@@ -0,0 +1,498 @@
+package pipeline
+
+import (
+	"flag"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var now = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+var update = flag.Bool("update", false, "rewrite the golden files under tests/fixtures/pipeline")
+
+func repoMatrix(t *testing.T) *Matrix {
+	t.Helper()
+	m, err := LoadMatrix(filepath.Join("..", "..", DefaultMatrixPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func config(t *testing.T, src string) *Config {
+	t.Helper()
+	var c Config
+	if err := yaml.Unmarshal([]byte(src), &c); err != nil {
+		t.Fatal(err)
+	}
+	return &c
+}
+
+func TestRepoMatrix(t *testing.T) {
+	m := repoMatrix(t)
+	want := map[string]string{"node": "20", "python": "3.12", "java": "21", "dotnet": "8.0.x", "go": "1.22.x"}
+	for stack, v := range want {
+		if got := m.Stacks[stack].DefaultVersion; got != v {
+			t.Errorf("%s default = %q, want %q", stack, got, v)
+		}
+	}
+	if m.Stacks["node"].Priority != "primary" || m.Stacks["go"].Priority != "secondary" {
+		t.Errorf("priorities: %+v", m.Stacks)
+	}
+}
+
+func TestMatrixValidate(t *testing.T) {
+	m := repoMatrix(t)
+	node := m.Stacks["node"]
+	node.Setup.Action = "actions/setup-node@main"
+	node.SupportedVersions.Versions = []string{"20"}
+	node.DefaultVersion = "22"
+	m.Stacks["node"] = node
+	java := m.Stacks["java"]
+	java.Images = map[string]string{"17": "eclipse-temurin:latest"}
+	m.Stacks["java"] = java
+	m.GateRunner.Image = "golang"
+	m.Exceptions = []Exception{{Repo: "o/r", Stack: "ruby", Version: "3.3", ExpiresOn: "soon"}}
+	errs := strings.Join(m.Validate(), "\n")
+	for _, want := range []string{
+		`stacks.node.setup.action: "actions/setup-node@main" is not pinned`,
+		"stacks.node: policy N/N-1 needs two versions",
+		`stacks.node.defaultVersion "22" is not in supportedVersions.versions`,
+		`exceptions[0]: unknown stack "ruby"`,
+		`exceptions[0].expiresOn "soon" is not YYYY-MM-DD`,
+		"exceptions[0] needs approval.owner and approval.reference",
+		`stacks.java.images: "eclipse-temurin:latest" is not pinned`,
+		"stacks.java: no image for gradle 21",
+		`gateRunner.image: "golang" has no tag`,
+	} {
+		if !strings.Contains(errs, want) {
+			t.Errorf("missing %q in:\n%s", want, errs)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	m := repoMatrix(t)
+	p, err := Resolve(config(t, "stack: python\ntoolchain: poetry\n"), m, Options{Now: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	setup := p.Setup[len(p.Setup)-1]
+	if p.RuntimeVersion != "3.12" || setup.Uses != "actions/setup-python@v5" || setup.With["cache"] != "poetry" ||
+		setup.With["python-version"] != "3.12" || p.Setup[0].Run != "pipx install poetry" {
+		t.Errorf("python plan: %+v", p)
+	}
+	if p.Steps[1].Run != "poetry run pytest --junitxml=reports/junit.xml" || p.Reports.Coverage != "coverage.xml" || p.Gate == nil || p.SBOM == nil || p.Publish != nil {
+		t.Errorf("python steps/jobs: %+v", p)
+	}
+
+	m.Exceptions = []Exception{{Repo: "o/legacy", Stack: "node", Version: "16", ExpiresOn: "2026-03-01"}}
+	m.Exceptions[0].Approval.Owner, m.Exceptions[0].Approval.Reference = "@o/leads", "o/legacy#12"
+	legacy := config(t, "stack: node\nruntimeVersion: \"16\"\n")
+	if p, err := Resolve(legacy, m, Options{Repo: "o/legacy", Now: now}); err != nil || p.Exception == nil || len(p.Warnings) != 1 {
+		t.Errorf("exception: %+v, %v", p, err)
+	} else if _, err := GitLab(p); err == nil || !strings.Contains(err.Error(), "add image to its matrix exception") {
+		t.Errorf("exception without image: %v", err)
+	}
+	for name, tc := range map[string]struct {
+		src  string
+		opt  Options
+		want string
+	}{
+		"expired exception": {"stack: node\nruntimeVersion: \"16\"\n", Options{Repo: "o/legacy", Now: now.AddDate(0, 0, 1)}, "node 16 is not supported (18, 20)"},
+		"other repo":        {"stack: node\nruntimeVersion: \"16\"\n", Options{Repo: "o/other", Now: now}, "no active exception covers o/other"},
+		"unknown stack":     {"stack: ruby\n", Options{}, `stack "ruby" is not in the runtime matrix`},
+		"toolchain":         {"stack: java\ntoolchain: ant\n", Options{}, `toolchain "ant" is not allowed for java (gradle, maven)`},
+		"outside repo":      {"stack: go\nworkingDirectory: ../x\n", Options{}, "must be inside the repository"},
+		"sbom format":       {"stack: go\nsbom: {format: syft-table}\n", Options{}, `sbom.format "syft-table"`},
+		"publish":           {"stack: go\npublish: {enabled: true}\n", Options{}, "publish needs artifacts"},
+	} {
+		if _, err := Resolve(config(t, tc.src), m, tc.opt); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: %v, want %q", name, err, tc.want)
+		}
+	}
+
+	exp := m.Stacks["go"]
+	exp.Tier = TierExperimental
+	m.Stacks["go"] = exp
+	if _, err := Resolve(config(t, "stack: go\n"), m, Options{}); err == nil || !strings.Contains(err.Error(), "traceability") {
+		t.Errorf("experimental without traceability: %v", err)
+	}
+	if _, err := Resolve(config(t, "stack: go\ntraceability: ADR-0012\n"), m, Options{}); err != nil {
+		t.Errorf("experimental with traceability: %v", err)
+	}
+}
+
+const nodeService = `
+name: payments-api
+stack: node
+toolchain: pnpm
+workingDirectory: svc
+commands:
+  test: pnpm run test:ci
+artifacts: ["dist/**"]
+gate:
+  policy: .github/policy.local.yml
+publish:
+  enabled: true
+`
+
+func TestGitHub(t *testing.T) {
+	p, err := Resolve(config(t, nodeService), repoMatrix(t), Options{Now: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := GitHub(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _ := GitHub(p)
+	if string(raw) != string(again) {
+		t.Fatal("rendering is not deterministic")
+	}
+	if !strings.HasPrefix(string(raw), "# Generated by `brikgov pipeline generate` from brikpipe.build.yml") {
+		t.Errorf("header:\n%s", raw)
+	}
+
+	var wf struct {
+		On   map[string]map[string][]string `yaml:"on"`
+		Jobs map[string]struct {
+			Needs    any               `yaml:"needs"`
+			If       string            `yaml:"if"`
+			Uses     string            `yaml:"uses"`
+			With     map[string]any    `yaml:"with"`
+			Defaults map[string]any    `yaml:"defaults"`
+			Steps    []Step            `yaml:"steps"`
+			Secrets  map[string]string `yaml:"secrets"`
+		} `yaml:"jobs"`
+	}
+	if err := yaml.Unmarshal(raw, &wf); err != nil {
+		t.Fatalf("%v\n%s", err, raw)
+	}
+	if tags := wf.On["push"]["tags"]; len(tags) != 1 || tags[0] != "v*" {
+		t.Errorf("push tags: %v", wf.On)
+	}
+	build := wf.Jobs["build"]
+	var names, runs []string
+	for _, s := range build.Steps {
+		names = append(names, s.Name)
+		runs = append(runs, s.Run)
+	}
+	if strings.Join(names, "|") != "Checkout|Setup pnpm|Setup node 20|Install|Test|Build|Upload test reports|Upload build artifacts" {
+		t.Errorf("steps: %v", names)
+	}
+	setup := build.Steps[2]
+	if setup.Uses != "actions/setup-node@v4" || setup.With["node-version"] != "20" ||
+		setup.With["cache"] != "pnpm" || setup.With["cache-dependency-path"] != "svc/pnpm-lock.yaml" {
+		t.Errorf("setup: %+v", setup)
+	}
+	if runs[4] != "pnpm run test:ci" || build.Steps[7].With["path"] != "svc/dist/**" {
+		t.Errorf("override/artifacts: %v %+v", runs, build.Steps[7])
+	}
+	if r := build.Steps[6]; r.If != "always()" || r.With["path"] != "svc/reports/junit.xml\nsvc/coverage/lcov.info\n" {
+		t.Errorf("reports: %+v", r)
+	}
+	if wf.Jobs["gate"].Uses != "BrikByte-Studios/.github/.github/workflows/reusable-policy-gate.yml@main" ||
+		wf.Jobs["gate"].With["policy"] != ".github/policy.local.yml" {
+		t.Errorf("gate: %+v", wf.Jobs["gate"])
+	}
+	if wf.Jobs["sbom"].With["working-directory"] != "svc" {
+		t.Errorf("sbom: %+v", wf.Jobs["sbom"])
+	}
+	pub := wf.Jobs["publish"]
+	if pub.If != "startsWith(github.ref, 'refs/tags/v')" || pub.With["dist_artifact_name"] != DistArtifact ||
+		pub.With["require_attachments"] != false || len(pub.Needs.([]any)) != 3 {
+		t.Errorf("publish: %+v", pub)
+	}
+}
+
+// TestGolden renders every stack of the runtime matrix for every target
+// and compares with tests/fixtures/pipeline/<stack>; go test -update
+// rewrites the golden files.
+func TestGolden(t *testing.T) {
+	m := repoMatrix(t)
+	dir := filepath.Join("..", "..", "tests", "fixtures", "pipeline")
+	for _, stack := range m.StackNames() {
+		c, err := LoadConfig(filepath.Join(dir, stack, "build.yml"))
+		if err != nil {
+			t.Errorf("%s: every matrix stack needs a golden build config: %v", stack, err)
+			continue
+		}
+		p, err := Resolve(c, m, Options{Now: now})
+		if err != nil {
+			t.Errorf("%s: %v", stack, err)
+			continue
+		}
+		for _, name := range TargetNames() {
+			target := Targets[name]
+			got, err := target.Render(p)
+			if err != nil {
+				t.Errorf("%s/%s: %v", stack, name, err)
+				continue
+			}
+			golden := filepath.Join(dir, stack, filepath.Base(target.Path))
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				continue
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Errorf("%s/%s: %v (run go test -update)", stack, name, err)
+				continue
+			}
+			if d := Diff(golden, "rendered", string(want), string(got)); d != "" {
+				t.Errorf("%s/%s differs from the golden file:\n%s", stack, name, d)
+			}
+		}
+	}
+}
+
+func TestGitLab(t *testing.T) {
+	p, err := Resolve(config(t, nodeService), repoMatrix(t), Options{Now: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := GitLab(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type job struct {
+		Image        string            `yaml:"image"`
+		Variables    map[string]string `yaml:"variables"`
+		BeforeScript []string          `yaml:"before_script"`
+		Script       []string          `yaml:"script"`
+		Cache        struct {
+			Key struct {
+				Files []string `yaml:"files"`
+			} `yaml:"key"`
+			Paths []string `yaml:"paths"`
+		} `yaml:"cache"`
+		Artifacts struct {
+			Paths []string `yaml:"paths"`
+		} `yaml:"artifacts"`
+	}
+	var ci struct {
+		Build job `yaml:"build"`
+		Gate  job `yaml:"gate"`
+	}
+	if err := yaml.Unmarshal(raw, &ci); err != nil {
+		t.Fatalf("%v\n%s", err, raw)
+	}
+	build := ci.Build
+	if build.Image != "node:20-bookworm" || build.Variables["npm_config_store_dir"] != "$CI_PROJECT_DIR/svc/.cache/pnpm" ||
+		build.Cache.Key.Files[0] != "svc/pnpm-lock.yaml" || build.Cache.Paths[0] != "svc/.cache/pnpm" {
+		t.Errorf("build: %+v", build)
+	}
+	if strings.Join(build.BeforeScript, "|") != "cd svc|corepack enable && corepack prepare pnpm@9 --activate" ||
+		build.Script[1] != "pnpm run test:ci" || build.Artifacts.Paths[0] != "svc/dist/**" {
+		t.Errorf("build scripts: %+v", build)
+	}
+	gate := ci.Gate
+	last := gate.Script[len(gate.Script)-1]
+	if gate.Image != "golang:1.22-bookworm" || !strings.Contains(last, "gate --policy .github/policy.local.yml") {
+		t.Errorf("gate: %+v", gate)
+	}
+	if got := branchRule("release/*"); got != `$CI_COMMIT_BRANCH =~ /^release\/[^\/]*$/` {
+		t.Errorf("branchRule: %s", got)
+	}
+}
+
+func TestJenkins(t *testing.T) {
+	p, err := Resolve(config(t, nodeService), repoMatrix(t), Options{Now: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Steps[2].Run = `echo 'it''s' "C:\\dist"`
+	raw, err := Jenkins(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"image 'node:20-bookworm'",
+		`npm_config_store_dir = "${WORKSPACE}/svc/.cache/pnpm"`,
+		"dir('svc') {",
+		`sh label: 'Build', script: 'echo \'it\'\'s\' "C:\\\\dist"'`,
+		"archiveArtifacts artifacts: 'svc/dist/**', fingerprint: true",
+		"--policy .github/policy.local.yml",
+	} {
+		if !strings.Contains(string(raw), want) {
+			t.Errorf("missing %q in:\n%s", want, raw)
+		}
+	}
+	if strings.Count(string(raw), "{") != strings.Count(string(raw), "}") {
+		t.Errorf("unbalanced braces:\n%s", raw)
+	}
+}
+
+func TestVersionMatches(t *testing.T) {
+	for _, tc := range []struct {
+		want, have string
+		ok         bool
+	}{
+		{"20", "20.11.1", true},
+		{"20", "18.19.0", false},
+		{"3.12", "3.12.1", true},
+		{"3.12", "3.1", false},
+		{"1.22.x", "1.22.5", true},
+		{"1.22.x", "1.23.0", false},
+		{"8.0.x", "8.0.401", true},
+	} {
+		if got := versionMatches(tc.want, tc.have); got != tc.ok {
+			t.Errorf("versionMatches(%q, %q) = %v", tc.want, tc.have, got)
+		}
+	}
+	if v := versionRe.FindString(`openjdk version "21.0.2" 2024-01-16`); v != "21.0.2" {
+		t.Errorf("java version: %q", v)
+	}
+}
+
+func write(t *testing.T, dir, name, content string) {
+	t.Helper()
+	p := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEvidence(t *testing.T) {
+	dir := t.TempDir()
+	write(t, dir, "svc/reports/junit.xml", `<testsuites>
+  <testsuite name="a" tests="3" failures="1" errors="0" skipped="1"/>
+  <testsuite name="b" tests="2" failures="0" errors="1"/>
+</testsuites>`)
+	write(t, dir, "svc/TestResults/run.trx", `<?xml version="1.0"?>
+<TestRun xmlns="http://microsoft.com/schemas/VisualStudio/TeamTest/2010">
+  <ResultSummary outcome="Completed"><Counters total="4" executed="3" passed="3" failed="0" error="0"/></ResultSummary>
+</TestRun>`)
+	write(t, dir, "svc/coverage/lcov.info", "SF:a.js\nLF:10\nLH:8\nBRF:4\nBRH:2\nend_of_record\nSF:b.js\nLF:10\nLH:5\nend_of_record\n")
+	write(t, dir, "cobertura.xml", `<coverage line-rate="0.5" lines-covered="5" lines-valid="10" branches-covered="1" branches-valid="2"></coverage>`)
+	write(t, dir, "mod/target/site/jacoco/jacoco.xml", `<?xml version="1.0"?><!DOCTYPE report PUBLIC "-//JACOCO//DTD Report 1.1//EN" "report.dtd">
+<report name="m"><package name="p"><counter type="LINE" missed="100" covered="1"/></package>
+<counter type="BRANCH" missed="1" covered="3"/><counter type="LINE" missed="3" covered="7"/></report>`)
+	write(t, dir, "coverage.out", "mode: set\na.go:1.1,2.2 3 1\na.go:3.1,4.2 1 0\na.go:3.1,4.2 1 1\nb.go:1.1,2.2 4 0\n")
+	write(t, dir, "node_modules/x/coverage.out", "ignored")
+
+	in, err := Evidence(dir, Reports{JUnit: "svc/out/*.xml", Coverage: "svc/coverage/lcov.info"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := in["tests"]; ok {
+		t.Errorf("reports that were not produced must stay missing, got %v", in["tests"])
+	}
+	tests, err := ParseTests(dir, []string{"svc/reports/junit.xml", "svc/TestResults/run.trx"})
+	if err != nil || tests.Total != 9 || tests.Failed != 2 || tests.Skipped != 2 {
+		t.Errorf("tests: %+v, %v", tests, err)
+	}
+	cov := in["coverage"].(map[string]any)
+	if cov["line"] != 65.0 || cov["branch"] != 50.0 || cov["report_url"] != "svc/coverage/lcov.info" {
+		t.Errorf("lcov: %v", cov)
+	}
+	for file, want := range map[string][2]float64{
+		"cobertura.xml":                     {50, 50},
+		"mod/target/site/jacoco/jacoco.xml": {70, 75},
+		"coverage.out":                      {50, 0},
+	} {
+		c, err := ParseCoverage(dir, []string{file})
+		if err != nil || c.Line() != want[0] || c.Branch() != want[1] {
+			t.Errorf("%s: %+v (line %g, branch %g), %v", file, c, c.Line(), c.Branch(), err)
+		}
+	}
+	in, err = Evidence(dir, Reports{JUnit: "svc/reports/*.xml", Coverage: "**/coverage.out"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tests := in["tests"].(map[string]any); tests["status"] != "red" || tests["failed"] != 2 || tests["total"] != 5 {
+		t.Errorf("junit: %v", tests)
+	}
+	if files, _ := FindReports(dir, "**/coverage.out"); len(files) != 1 {
+		t.Errorf("node_modules should not be searched: %v", files)
+	}
+	if _, err := ParseCoverage(dir, []string{"svc/reports/junit.xml"}); err == nil {
+		t.Error("a JUnit report is not coverage")
+	}
+}
+
+func TestLocalRun(t *testing.T) {
+	root := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", root}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_CONFIG_GLOBAL=/dev/null",
+			"GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	write(t, root, ".gitignore", "*.log\n")
+	write(t, root, "svc/main.sh", "echo ok\n")
+	write(t, root, "svc/debug.log", "ignored\n")
+	write(t, root, ".brik-gate/logs/test.log", "old run\n")
+	git("add", ".gitignore", "svc/main.sh")
+	git("commit", "-qm", "init")
+	write(t, root, "svc/new.txt", "untracked\n")
+
+	ws := t.TempDir()
+	if n, err := CopyWorkspace(root, ws); err != nil || n != 3 {
+		t.Fatalf("CopyWorkspace = %d, %v", n, err)
+	}
+	if _, err := os.Stat(filepath.Join(ws, "svc", "debug.log")); !os.IsNotExist(err) {
+		t.Error("ignored files must not be copied")
+	}
+
+	p := &Plan{WorkingDirectory: "svc", Reports: Reports{JUnit: "svc/reports/junit.xml"}}
+	step := Step{ID: "test", Run: `test "$CI" = true && mkdir -p reports && echo '<testsuite tests="1"/>' > reports/junit.xml && cat new.txt`}
+	var out strings.Builder
+	if err := RunStep(p, step, ws, io.MultiWriter(&out)); err != nil || out.String() != "untracked\n" {
+		t.Fatalf("RunStep: %v, %q", err, out.String())
+	}
+	if err := RunStep(p, Step{Run: "exit 3"}, ws, io.Discard); err == nil {
+		t.Error("a failing step must fail")
+	}
+	copied, err := CollectReports(p.Reports, ws, root)
+	if err != nil || len(copied) != 1 {
+		t.Fatalf("CollectReports = %v, %v", copied, err)
+	}
+	if raw, err := os.ReadFile(filepath.Join(root, "svc", "reports", "junit.xml")); err != nil || !strings.Contains(string(raw), `tests="1"`) {
+		t.Errorf("report not at the CI path: %q, %v", raw, err)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	if Diff("a", "b", "x\n", "x\n") != "" {
+		t.Fatal("equal inputs should not diff")
+	}
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	b := "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n"
+	want := `--- a
++++ b
+@@ -1,6 +1,6 @@
+ 1
+ 2
+-3
++three
+ 4
+ 5
+ 6
+@@ -10,3 +10,4 @@
+ 10
+ 11
+ 12
++13
+`
+	if got := Diff("a", "b", a, b); got != want {
+		t.Errorf("Diff:\n%s\nwant:\n%s", got, want)
+	}
+}
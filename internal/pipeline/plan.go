@@ -0,0 +1,318 @@
+package pipeline
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is a repository's brikpipe.build.yml.
+type Config struct {
+	Name  string `yaml:"name" json:"name"`
+	Stack string `yaml:"stack" json:"stack"`
+	// RuntimeVersion defaults to the matrix's defaultVersion.
+	RuntimeVersion string `yaml:"runtimeVersion" json:"runtimeVersion,omitempty"`
+	// Toolchain defaults to the stack's default toolchain.
+	Toolchain string `yaml:"toolchain" json:"toolchain,omitempty"`
+	// WorkingDirectory is the project directory, relative to the repo root.
+	WorkingDirectory string `yaml:"workingDirectory" json:"workingDirectory,omitempty"`
+	// Commands override the toolchain conventions one by one.
+	Commands Commands `yaml:"commands" json:"commands"`
+	// Artifacts are the build outputs to keep (globs, relative to the
+	// project directory).
+	Artifacts []string `yaml:"artifacts" json:"artifacts,omitempty"`
+	// Reports override the toolchain's test report paths one by one.
+	Reports Reports `yaml:"reports" json:"reports"`
+	// Branches trigger the workflow on push and pull_request; default main.
+	Branches []string `yaml:"branches" json:"branches,omitempty"`
+	// Traceability is the issue or ADR reference an experimental stack
+	// needs.
+	Traceability string `yaml:"traceability" json:"traceability,omitempty"`
+	Gate         struct {
+		Enabled *bool  `yaml:"enabled" json:"enabled"`
+		Policy  string `yaml:"policy" json:"policy,omitempty"`
+	} `yaml:"gate" json:"gate"`
+	SBOM struct {
+		Enabled *bool  `yaml:"enabled" json:"enabled"`
+		Format  string `yaml:"format" json:"format,omitempty"`
+	} `yaml:"sbom" json:"sbom"`
+	Publish struct {
+		Enabled bool `yaml:"enabled" json:"enabled"`
+	} `yaml:"publish" json:"publish"`
+}
+
+// LoadConfig reads brikpipe.build.yml.
+func LoadConfig(path string) (*Config, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Config
+	dec := yaml.NewDecoder(strings.NewReader(string(raw)))
+	dec.KnownFields(true)
+	if err := dec.Decode(&c); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// Step is one workflow step.
+type Step struct {
+	ID   string            `yaml:"id" json:"id,omitempty"`
+	Name string            `yaml:"name" json:"name"`
+	Uses string            `yaml:"uses" json:"uses,omitempty"`
+	With map[string]string `yaml:"with" json:"with,omitempty"`
+	Run  string            `yaml:"run" json:"run,omitempty"`
+	If   string            `yaml:"if" json:"if,omitempty"`
+}
+
+// Plan is a build config resolved against the runtime matrix: everything a
+// renderer needs, with no defaults left to apply.
+type Plan struct {
+	// Source is the build config the plan was resolved from.
+	Source           string      `json:"source"`
+	Name             string      `json:"name"`
+	Stack            string      `json:"stack"`
+	Tier             string      `json:"tier"`
+	RuntimeVersion   string      `json:"runtimeVersion"`
+	Toolchain        string      `json:"toolchain"`
+	WorkingDirectory string      `json:"workingDirectory"`
+	Branches         []string    `json:"branches"`
+	Checkout         string      `json:"checkout"`
+	UploadArtifact   string      `json:"uploadArtifact"`
+	Setup            []Step      `json:"setup"`
+	Steps            []Step      `json:"steps"`
+	Artifacts        []string    `json:"artifacts,omitempty"`
+	Gate             *GateJob    `json:"gate,omitempty"`
+	SBOM             *SBOMJob    `json:"sbom,omitempty"`
+	Publish          *PublishJob `json:"publish,omitempty"`
+	// Reports are relative to the repository root, like Artifacts in the
+	// generated files.
+	Reports Reports `json:"reports"`
+	// Container is the job environment of CI systems that run in images.
+	Container Container `json:"container"`
+	// Exception is the matrix exception that allows RuntimeVersion.
+	Exception *Exception `json:"exception,omitempty"`
+	// Warnings are deprecations and other non-blocking notes.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Container is the build job's image, toolchain bootstrap and cache for
+// GitLab CI and Jenkins.
+type Container struct {
+	// Image is empty for an excepted version without an image; only the
+	// GitHub workflow can be rendered then.
+	Image     string `json:"image,omitempty"`
+	Bootstrap string `json:"bootstrap,omitempty"`
+	// CacheDir is relative to the repository root; Env values keep the
+	// "{cache}" placeholder for each renderer to make absolute.
+	CacheDir string            `json:"cacheDir,omitempty"`
+	CacheKey string            `json:"cacheKey,omitempty"`
+	Env      map[string]string `json:"env,omitempty"`
+}
+
+// GateJob calls the reusable policy gate workflow, or builds brikgov in
+// Runner on other CI systems.
+type GateJob struct {
+	Uses   string     `json:"uses"`
+	Policy string     `json:"policy"`
+	Runner GateRunner `json:"runner"`
+}
+
+// SBOMJob calls the reusable SBOM workflow.
+type SBOMJob struct {
+	Uses   string `json:"uses"`
+	Format string `json:"format"`
+}
+
+// PublishJob calls the reusable publish workflow on version tags.
+type PublishJob struct {
+	Uses string `json:"uses"`
+}
+
+// Artifact names shared by the build job and the reusable workflows.
+const (
+	// DistArtifact is the build output the publish workflow downloads.
+	DistArtifact = "release-dist"
+	// ReportsArtifact holds the JUnit and coverage reports.
+	ReportsArtifact = "test-reports"
+)
+
+// SBOMFormats are the formats the reusable SBOM workflow produces.
+var SBOMFormats = []string{"cyclonedx-json", "spdx-json"}
+
+// Options are the caller context of Resolve.
+type Options struct {
+	// Source names the build config in the plan and generated files.
+	Source string
+	// Repo ("owner/name") selects matrix exceptions.
+	Repo string
+	Now  time.Time
+}
+
+// Resolve applies the matrix to a build config.
+func Resolve(c *Config, m *Matrix, opt Options) (*Plan, error) {
+	s, ok := m.Stacks[c.Stack]
+	if !ok {
+		return nil, fmt.Errorf("stack %q is not in the runtime matrix (%s)", c.Stack, strings.Join(m.StackNames(), ", "))
+	}
+	p := &Plan{
+		Source:           or(opt.Source, DefaultConfigPath),
+		Name:             or(c.Name, c.Stack),
+		Stack:            c.Stack,
+		Tier:             s.Tier,
+		RuntimeVersion:   or(c.RuntimeVersion, s.DefaultVersion),
+		Toolchain:        or(c.Toolchain, s.Toolchains.Default),
+		WorkingDirectory: path.Clean(or(c.WorkingDirectory, ".")),
+		Branches:         c.Branches,
+		Checkout:         m.Actions.Checkout,
+		UploadArtifact:   m.Actions.UploadArtifact,
+		Artifacts:        c.Artifacts,
+	}
+	if len(p.Branches) == 0 {
+		p.Branches = []string{"main"}
+	}
+	if strings.HasPrefix(p.WorkingDirectory, "..") || path.IsAbs(p.WorkingDirectory) {
+		return nil, fmt.Errorf("workingDirectory %q must be inside the repository", c.WorkingDirectory)
+	}
+	switch s.Tier {
+	case TierPlanned:
+		return nil, fmt.Errorf("stack %q is planned and not usable yet", c.Stack)
+	case TierExperimental:
+		if c.Traceability == "" {
+			return nil, fmt.Errorf("stack %q is experimental; set traceability to the tracking issue or ADR", c.Stack)
+		}
+	}
+	if !s.Supports(p.RuntimeVersion) {
+		e := findException(m, opt, c.Stack, p.RuntimeVersion)
+		if e == nil {
+			return nil, fmt.Errorf("%s %s is not supported (%s) and no active exception covers %s",
+				c.Stack, p.RuntimeVersion, strings.Join(s.SupportedVersions.Versions, ", "), or(opt.Repo, "this repository"))
+		}
+		p.Exception = e
+		p.Warnings = append(p.Warnings, fmt.Sprintf("%s %s is allowed by an exception until %s (%s)", c.Stack, p.RuntimeVersion, e.ExpiresOn, e.Approval.Reference))
+	}
+	if d, ok := s.Deprecation[p.RuntimeVersion]; ok {
+		p.Warnings = append(p.Warnings, fmt.Sprintf("%s %s is deprecated, support ends %s: %s", c.Stack, p.RuntimeVersion, d.EndOfSupport, d.Note))
+	}
+	tc, ok := s.Toolchains.Allowed[p.Toolchain]
+	if !ok {
+		return nil, fmt.Errorf("toolchain %q is not allowed for %s (%s)", p.Toolchain, c.Stack, strings.Join(sortedKeys(s.Toolchains.Allowed), ", "))
+	}
+
+	p.Setup = append(p.Setup, tc.PreSetup...)
+	with := map[string]string{s.Setup.VersionInput: p.RuntimeVersion}
+	for k, v := range s.Setup.With {
+		with[k] = v
+	}
+	if tc.Cache != "" {
+		with["cache"] = tc.Cache
+		if tc.Lockfile != "" {
+			with["cache-dependency-path"] = p.rel(tc.Lockfile)
+		}
+	}
+	p.Setup = append(p.Setup, Step{Name: fmt.Sprintf("Setup %s %s", c.Stack, p.RuntimeVersion), Uses: s.Setup.Action, With: with})
+	p.Container = Container{
+		Image:     s.Image(p.Toolchain, p.RuntimeVersion),
+		Bootstrap: tc.Bootstrap,
+		Env:       tc.CacheEnv,
+	}
+	if p.Exception != nil {
+		p.Container.Image = p.Exception.Image
+	}
+	if tc.CacheDir != "" {
+		p.Container.CacheDir = p.rel(tc.CacheDir)
+	}
+	if tc.Lockfile != "" {
+		p.Container.CacheKey = p.rel(tc.Lockfile)
+	}
+	if r := or(c.Reports.JUnit, tc.Reports.JUnit); r != "" {
+		p.Reports.JUnit = p.rel(r)
+	}
+	if r := or(c.Reports.Coverage, tc.Reports.Coverage); r != "" {
+		p.Reports.Coverage = p.rel(r)
+	}
+	p.Steps = []Step{
+		{ID: "install", Name: "Install", Run: or(c.Commands.Install, tc.Commands.Install)},
+		{ID: "test", Name: "Test", Run: or(c.Commands.Test, tc.Commands.Test)},
+		{ID: "build", Name: "Build", Run: or(c.Commands.Build, tc.Commands.Build)},
+	}
+
+	rw := m.ReusableWorkflows
+	if c.Gate.Enabled == nil || *c.Gate.Enabled {
+		p.Gate = &GateJob{Uses: rw.Uses(rw.Gate), Policy: or(c.Gate.Policy, ".github/policy.yml"), Runner: m.GateRunner}
+	}
+	if c.SBOM.Enabled == nil || *c.SBOM.Enabled {
+		f := or(c.SBOM.Format, SBOMFormats[0])
+		if !contains(SBOMFormats, f) {
+			return nil, fmt.Errorf("sbom.format %q is not one of %s", f, strings.Join(SBOMFormats, ", "))
+		}
+		p.SBOM = &SBOMJob{Uses: rw.Uses(rw.SBOM), Format: f}
+	}
+	if c.Publish.Enabled {
+		if len(c.Artifacts) == 0 {
+			return nil, fmt.Errorf("publish needs artifacts to publish")
+		}
+		p.Publish = &PublishJob{Uses: rw.Uses(rw.Publish)}
+	}
+	return p, nil
+}
+
+// paths returns the report globs that are set.
+func (r Reports) paths() []string {
+	var out []string
+	for _, p := range []string{r.JUnit, r.Coverage} {
+		if p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// rel joins a project-relative path onto the working directory.
+func (p *Plan) rel(file string) string {
+	if p.WorkingDirectory == "." {
+		return file
+	}
+	return p.WorkingDirectory + "/" + file
+}
+
+func findException(m *Matrix, opt Options, stack, version string) *Exception {
+	for _, e := range m.Exceptions {
+		if e.Stack == stack && e.Version == version && e.Repo == opt.Repo && !e.Expired(opt.Now) {
+			e := e
+			return &e
+		}
+	}
+	return nil
+}
+
+func or(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,182 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/BrikByte-Studios/github-governance/internal/gitrepo"
+)
+
+// versionRe finds the first dotted version in a runtime's version output
+// ("v20.11.1", "go1.22.5", `openjdk version "21.0.2"`).
+var versionRe = regexp.MustCompile(`\d+(?:\.\d+)*`)
+
+// CheckRuntime runs the stack's versionCommand and checks that the local
+// runtime is the plan's version. It returns the local version.
+func CheckRuntime(p *Plan, m *Matrix) (string, error) {
+	cmd := m.Stacks[p.Stack].VersionCommand
+	// java -version prints to stderr.
+	out, err := exec.Command("sh", "-c", cmd).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%s runtime not found (`%s`: %v)", p.Stack, cmd, err)
+	}
+	have := versionRe.FindString(string(out))
+	if have == "" {
+		return "", fmt.Errorf("no version in the output of `%s`: %q", cmd, strings.TrimSpace(string(out)))
+	}
+	if !versionMatches(p.RuntimeVersion, have) {
+		return have, fmt.Errorf("local %s is %s, the plan needs %s (runtime matrix)", p.Stack, have, p.RuntimeVersion)
+	}
+	return have, nil
+}
+
+// versionMatches reports whether have is within want, where want may be
+// shorter ("20" covers 20.11.1) and "x" matches any component.
+func versionMatches(want, have string) bool {
+	w, h := strings.Split(want, "."), strings.Split(have, ".")
+	if len(h) < len(w) {
+		return false
+	}
+	for i, c := range w {
+		if c != "x" && c != h[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// MissingTools returns the programs the steps start with that are not on
+// PATH; relative paths such as ./gradlew come from the workspace.
+func MissingTools(p *Plan) []string {
+	var missing []string
+	for _, s := range p.Steps {
+		f := strings.Fields(s.Run)
+		if len(f) == 0 || strings.Contains(f[0], "/") || contains(missing, f[0]) {
+			continue
+		}
+		if _, err := exec.LookPath(f[0]); err != nil {
+			missing = append(missing, f[0])
+		}
+	}
+	return missing
+}
+
+// CopyWorkspace copies the files of root a build would see into dst: the
+// tracked and untracked, not ignored files of a git checkout, or every
+// file but .git elsewhere, without GateDir. It returns the number of files
+// copied.
+func CopyWorkspace(root, dst string) (int, error) {
+	var files []string
+	if out, err := gitrepo.Run(context.Background(), root, "ls-files", "-z", "--cached", "--others", "--exclude-standard"); err == nil {
+		for _, f := range strings.Split(out, "\x00") {
+			if f != "" {
+				files = append(files, f)
+			}
+		}
+	} else {
+		err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() && d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			if !d.IsDir() {
+				rel, _ := filepath.Rel(root, p)
+				files = append(files, rel)
+			}
+			return nil
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	n := 0
+	for _, f := range files {
+		// Earlier local runs leave their logs and gate files behind.
+		if strings.HasPrefix(filepath.ToSlash(f), GateDir+"/") {
+			continue
+		}
+		ok, err := copyFile(filepath.Join(root, f), filepath.Join(dst, f))
+		if err != nil {
+			return n, err
+		}
+		if ok {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// copyFile copies a regular file or symlink; deleted files and submodule
+// directories are skipped.
+func copyFile(src, dst string) (bool, error) {
+	info, err := os.Lstat(src)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil || info.IsDir() {
+		return false, err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return false, err
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(src)
+		if err != nil {
+			return false, err
+		}
+		return true, os.Symlink(target, dst)
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return false, err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return false, err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return false, err
+	}
+	return true, out.Close()
+}
+
+// RunStep runs one step with sh in the plan's working directory under
+// workspace, streaming its output to out. CI=true is set as on CI.
+func RunStep(p *Plan, s Step, workspace string, out io.Writer) error {
+	cmd := exec.Command("sh", "-c", s.Run)
+	cmd.Dir = filepath.Join(workspace, filepath.FromSlash(p.WorkingDirectory))
+	cmd.Env = append(os.Environ(), "CI=true")
+	cmd.Stdout, cmd.Stderr = out, out
+	return cmd.Run()
+}
+
+// CollectReports copies the reports the steps produced in workspace to the
+// same repository-relative paths under root, where CI keeps them. It
+// returns the copied paths.
+func CollectReports(r Reports, workspace, root string) ([]string, error) {
+	var copied []string
+	for _, glob := range r.paths() {
+		files, err := FindReports(workspace, glob)
+		if err != nil {
+			return copied, err
+		}
+		for _, f := range files {
+			if _, err := copyFile(filepath.Join(workspace, f), filepath.Join(root, f)); err != nil {
+				return copied, err
+			}
+			copied = append(copied, f)
+		}
+	}
+	return copied, nil
+}
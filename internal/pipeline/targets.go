@@ -0,0 +1,63 @@
+package pipeline
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Target is a CI system a plan renders to.
+type Target struct {
+	// Path is where the generated file lives, relative to the repo root.
+	Path   string
+	Render func(*Plan) ([]byte, error)
+}
+
+// Targets are the supported CI systems by --target name. Only GitHub
+// Actions gets the SBOM and publish jobs: they call reusable workflows.
+var Targets = map[string]Target{
+	"github":  {Path: DefaultWorkflowPath, Render: GitHub},
+	"gitlab":  {Path: ".gitlab-ci.yml", Render: GitLab},
+	"jenkins": {Path: "Jenkinsfile", Render: Jenkins},
+}
+
+// TargetNames returns the target names, sorted.
+func TargetNames() []string {
+	return sortedKeys(Targets)
+}
+
+// GateDir holds the gate's brikgov binary, inputs and decision in every
+// target and in local runs; it matches the reusable gate workflow.
+const GateDir = ".brik-gate"
+
+// gateScript builds brikgov from the gate runner repository and evaluates
+// the policy with the change's base and head SHAs, which each CI system
+// exposes differently (an empty base skips diff-scoped rules).
+func gateScript(p *Plan, base, head string) []string {
+	r := p.Gate.Runner
+	return []string{
+		"mkdir -p " + GateDir,
+		fmt.Sprintf("git clone --quiet --depth 1 --branch %s %s .brik-meta", r.Ref, r.Repository),
+		"(cd .brik-meta && go build -o ../" + GateDir + "/brikgov ./cmd/brikgov)",
+		fmt.Sprintf(`printf '{"meta":{"base_sha":"%%s","head_sha":"%%s"}}\n' "%s" "%s" > %s/inputs.json`, base, head, GateDir),
+		fmt.Sprintf("%s/brikgov gate --policy %s --inputs %s/inputs.json --out %s/decision.json", GateDir, p.Gate.Policy, GateDir, GateDir),
+	}
+}
+
+// containerEnv returns the cache environment with "{cache}" made absolute
+// under root, sorted by name.
+func containerEnv(p *Plan, root string) [][2]string {
+	var env [][2]string
+	for _, k := range sortedKeys(p.Container.Env) {
+		v := strings.ReplaceAll(p.Container.Env[k], "{cache}", root+"/"+p.Container.CacheDir)
+		env = append(env, [2]string{k, v})
+	}
+	return env
+}
+
+// containerImage is the build image, which an excepted version may lack.
+func containerImage(p *Plan, target string) (string, error) {
+	if p.Container.Image == "" {
+		return "", fmt.Errorf("no container image for %s %s; add image to its matrix exception to render %s", p.Stack, p.RuntimeVersion, target)
+	}
+	return p.Container.Image, nil
+}
@@ -0,0 +1,121 @@
+package policy
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Change kinds and effects reported by Diff.
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+
+	EffectTightened = "tightened"
+	EffectRelaxed   = "relaxed"
+)
+
+// Change is one field that differs between two effective policies.
+type Change struct {
+	Path string `json:"path"`
+	Kind string `json:"kind"`
+	Old  any    `json:"old,omitempty"`
+	New  any    `json:"new,omitempty"`
+	// Effect is set for constrained fields (see Constraints).
+	Effect string `json:"effect,omitempty"`
+}
+
+func (c Change) String() string {
+	var s string
+	switch c.Kind {
+	case ChangeAdded:
+		s = fmt.Sprintf("+ %s: %v", c.Path, c.New)
+	case ChangeRemoved:
+		s = fmt.Sprintf("- %s: %v", c.Path, c.Old)
+	default:
+		s = fmt.Sprintf("~ %s: %v → %v", c.Path, c.Old, c.New)
+	}
+	if c.Effect != "" {
+		s += " (" + c.Effect + ")"
+	}
+	return s
+}
+
+// Diff compares two effective policies field by field, ignoring
+// resolved_chain. Lists are compared as whole values.
+func Diff(old, new map[string]any) []Change {
+	a, b := map[string]any{}, map[string]any{}
+	flatten("", old, a)
+	flatten("", new, b)
+	delete(a, "resolved_chain")
+	delete(b, "resolved_chain")
+
+	var out []Change
+	for p, ov := range a {
+		nv, ok := b[p]
+		switch {
+		case !ok:
+			out = append(out, Change{Path: p, Kind: ChangeRemoved, Old: ov, Effect: presenceEffect(p, false)})
+		case !reflect.DeepEqual(ov, nv):
+			out = append(out, Change{Path: p, Kind: ChangeChanged, Old: ov, New: nv, Effect: changeEffect(p, ov, nv)})
+		}
+	}
+	for p, nv := range b {
+		if _, ok := a[p]; !ok {
+			out = append(out, Change{Path: p, Kind: ChangeAdded, New: nv, Effect: presenceEffect(p, true)})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out
+}
+
+func flatten(prefix string, v any, out map[string]any) {
+	m, ok := v.(map[string]any)
+	if !ok || (len(m) == 0 && prefix != "") {
+		out[prefix] = v
+		return
+	}
+	for k, x := range m {
+		flatten(join(prefix, k), x, out)
+	}
+}
+
+// presenceEffect: a constrained requirement appearing tightens, one
+// disappearing relaxes.
+func presenceEffect(p string, added bool) string {
+	if _, ok := constraintFor(p); !ok {
+		return ""
+	}
+	if added {
+		return EffectTightened
+	}
+	return EffectRelaxed
+}
+
+func changeEffect(p string, ov, nv any) string {
+	c, ok := constraintFor(p)
+	if !ok || c.Direction == Fixed {
+		return ""
+	}
+	if c.Direction == Union {
+		ol, _ := toStrings(ov)
+		nl, _ := toStrings(nv)
+		have := map[string]bool{}
+		for _, s := range nl {
+			have[s] = true
+		}
+		for _, s := range ol {
+			if !have[s] {
+				return EffectRelaxed
+			}
+		}
+		return EffectTightened
+	}
+	var vs []Violation
+	mergeValue(p, ov, nv, "", &vs)
+	if len(vs) > 0 {
+		return EffectRelaxed
+	}
+	return EffectTightened
+}
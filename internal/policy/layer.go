@@ -0,0 +1,269 @@
+// Package policy resolves layered governance policies.
+//
+// A policy layer is a YAML document that may name its parent with
+// `extends`. The org policy (.github/policy.yml) is the root; named
+// intermediate layers live in this repo under .governance/policies and are
+// referenced as "<kind>/<name>", e.g.
+//
+//	# .governance/policies/team/payments.yml
+//	extends: org
+//	tests:
+//	  coverage_min: 85
+//
+//	# .github/policy.local.yml (repo overlay)
+//	extends: team/payments
+//
+// Resolution walks the chain to org, then merges root-first. Every layer may
+// only tighten what it inherits (see Constraints); cyclic and diamond
+// `extends` graphs are rejected. The effective policy records the resolved
+// chain under "resolved_chain".
+//
+// A reference may be pinned to a SemVer tag of this repo, e.g.
+// `extends: org@v1.4.0`. Pinned layers are read from a vendored cache
+// (<CacheDir>/<tag>/<path>) and checked against policy.lock, so resolution
+// never needs the network and an org policy change only reaches a repo when
+// it bumps the pin.
+package policy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Default locations, relative to the repository root.
+const (
+	DefaultOrgPath   = ".github/policy.yml"
+	DefaultRepoPath  = ".github/policy.local.yml"
+	DefaultLayersDir = ".governance/policies"
+	DefaultCacheDir  = ".github/policy-vendor"
+	DefaultLockPath  = ".github/policy.lock"
+)
+
+// OrgRef names the root layer.
+const OrgRef = "org"
+
+// TopLevelKeys are the sections a policy layer may declare. Anything else is
+// rejected so a typo cannot silently disable enforcement.
+var TopLevelKeys = map[string]bool{
+	"version":        true,
+	"policy_version": true,
+	"extends":        true,
+	"owner":          true,
+	"description":    true,
+	"mode":           true,
+	"release":        true,
+	"reviews":        true,
+	"tests":          true,
+	"security":       true,
+	"docs":           true,
+	"supply_chain":   true,
+	"artifacts":      true,
+	"rules":          true,
+	"hotfix":         true,
+	"bypass":         true,
+	"lifecycle":      true,
+}
+
+// layerOnlyKeys describe a layer rather than policy and are not inherited.
+var layerOnlyKeys = map[string]bool{"extends": true, "owner": true, "description": true}
+
+var (
+	refRe = regexp.MustCompile(`^[a-z][a-z0-9-]*/[a-z0-9][a-z0-9._-]*$`)
+	tagRe = regexp.MustCompile(`^v\d+\.\d+\.\d+$`)
+)
+
+// SplitRef splits "org@v1.4.0" into ("org", "v1.4.0"). Unpinned references
+// return an empty tag.
+func SplitRef(ref string) (name, tag string) {
+	if i := strings.LastIndexByte(ref, '@'); i >= 0 {
+		return ref[:i], ref[i+1:]
+	}
+	return ref, ""
+}
+
+// Layer is one parsed policy document.
+type Layer struct {
+	Ref     string
+	Source  string
+	SHA256  string
+	Extends []string
+	Doc     map[string]any
+	// raw is the file content, kept so update can vendor fetched layers.
+	raw []byte
+}
+
+// Source loads layers by reference.
+type Source interface {
+	Load(ref string) (*Layer, error)
+}
+
+// FileSource loads "org" from OrgPath and "<kind>/<name>" from
+// LayersDir/<kind>/<name>.yml, both relative to Root. Pinned references
+// ("org@v1.4.0") are read from CacheDir/<tag>/ using the upstream layout
+// (DefaultOrgPath, DefaultLayersDir), or through Fetch when it is set.
+type FileSource struct {
+	Root      string
+	OrgPath   string
+	LayersDir string
+	CacheDir  string
+	// Fetch reads a file at a tag of the upstream repo; update uses it to
+	// resolve a new pin before anything is vendored.
+	Fetch func(tag, path string) ([]byte, error)
+}
+
+// Load implements Source.
+func (s FileSource) Load(ref string) (*Layer, error) {
+	name, tag := SplitRef(ref)
+	if tag != "" {
+		return s.loadPinned(ref, name, tag)
+	}
+	var rel string
+	switch {
+	case name == OrgRef:
+		rel = or(s.OrgPath, DefaultOrgPath)
+	case refRe.MatchString(name):
+		rel = filepath.Join(or(s.LayersDir, DefaultLayersDir), filepath.FromSlash(name)+".yml")
+	default:
+		return nil, fmt.Errorf("invalid extends %q: want %q or <kind>/<name>, optionally @vX.Y.Z", ref, OrgRef)
+	}
+	p := s.abs(rel)
+	if _, err := os.Stat(p); err != nil {
+		return nil, fmt.Errorf("layer %s: %w", ref, err)
+	}
+	l, err := LoadLayer(ref, p)
+	if err != nil {
+		return nil, err
+	}
+	l.Source = filepath.ToSlash(rel)
+	return l, nil
+}
+
+// UpstreamPath is where a layer lives inside this repo at any tag.
+func UpstreamPath(name string) string {
+	if name == OrgRef {
+		return DefaultOrgPath
+	}
+	return DefaultLayersDir + "/" + name + ".yml"
+}
+
+func (s FileSource) loadPinned(ref, name, tag string) (*Layer, error) {
+	if !tagRe.MatchString(tag) {
+		return nil, fmt.Errorf("invalid extends %q: pins must be SemVer tags (vX.Y.Z)", ref)
+	}
+	if name != OrgRef && !refRe.MatchString(name) {
+		return nil, fmt.Errorf("invalid extends %q: want %q or <kind>/<name>, optionally @vX.Y.Z", ref, OrgRef)
+	}
+	up := UpstreamPath(name)
+	rel := filepath.Join(or(s.CacheDir, DefaultCacheDir), tag, filepath.FromSlash(up))
+	var (
+		raw []byte
+		err error
+	)
+	if s.Fetch != nil {
+		raw, err = s.Fetch(tag, up)
+	} else {
+		raw, err = os.ReadFile(s.abs(rel))
+		if os.IsNotExist(err) {
+			err = fmt.Errorf("not vendored at %s (run `brikgov policy update`)", filepath.ToSlash(rel))
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("layer %s: %w", ref, err)
+	}
+	l, err := ParseLayer(ref, raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ref, err)
+	}
+	l.Source = filepath.ToSlash(rel)
+	// A pinned layer's own parents come from the same tag.
+	for i, e := range l.Extends {
+		if _, t := SplitRef(e); t == "" {
+			l.Extends[i] = e + "@" + tag
+		}
+	}
+	return l, nil
+}
+
+func (s FileSource) abs(rel string) string {
+	if s.Root != "" && !filepath.IsAbs(rel) {
+		return filepath.Join(s.Root, rel)
+	}
+	return rel
+}
+
+func or(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
+
+// LoadLayer reads and validates one layer file.
+func LoadLayer(ref, path string) (*Layer, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	l, err := ParseLayer(ref, raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	l.Source = filepath.ToSlash(path)
+	return l, nil
+}
+
+// ParseLayer parses a layer document. An empty document is a valid layer
+// that inherits everything.
+func ParseLayer(ref string, raw []byte) (*Layer, error) {
+	doc := map[string]any{}
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		doc = map[string]any{}
+	}
+	sum := sha256.Sum256(raw)
+	l := &Layer{Ref: ref, SHA256: hex.EncodeToString(sum[:]), Doc: doc, raw: raw}
+
+	var unknown []string
+	for k := range doc {
+		if !TopLevelKeys[k] {
+			unknown = append(unknown, k)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown top-level field(s): %s", strings.Join(unknown, ", "))
+	}
+
+	switch ext := doc["extends"].(type) {
+	case nil:
+	case string:
+		l.Extends = []string{ext}
+	case []any:
+		for _, e := range ext {
+			s, ok := e.(string)
+			if !ok {
+				return nil, fmt.Errorf("extends: entries must be strings")
+			}
+			l.Extends = append(l.Extends, s)
+		}
+	default:
+		return nil, fmt.Errorf("extends: must be a string or a list of strings")
+	}
+	if name, _ := SplitRef(ref); name == OrgRef && len(l.Extends) > 0 {
+		return nil, fmt.Errorf("the org policy cannot extend another layer")
+	}
+	if m, ok := doc["mode"]; ok && rank(modeScale, m) < 0 {
+		return nil, fmt.Errorf("mode: must be one of %v", modeScale)
+	}
+	return l, nil
+}
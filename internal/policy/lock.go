@@ -0,0 +1,139 @@
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BrikByte-Studios/github-governance/internal/canon"
+)
+
+// LockVersion is the policy.lock format version.
+const LockVersion = 1
+
+// Lock pins the content of every tagged layer in a resolved chain.
+type Lock struct {
+	LockVersion int           `json:"lock_version"`
+	Layers      []LockedLayer `json:"layers"`
+}
+
+// LockedLayer records one pinned layer: its reference, the vendored copy and
+// the SHA-256 of that copy.
+type LockedLayer struct {
+	Ref    string `json:"ref"`
+	Source string `json:"source"`
+	SHA256 string `json:"sha256"`
+}
+
+// BuildLock records the pinned layers of eff in chain order.
+func BuildLock(eff *Effective) *Lock {
+	l := &Lock{LockVersion: LockVersion, Layers: []LockedLayer{}}
+	for _, c := range eff.Chain {
+		if _, tag := SplitRef(c.Layer); tag != "" {
+			l.Layers = append(l.Layers, LockedLayer{Ref: c.Layer, Source: c.Source, SHA256: c.SHA256})
+		}
+	}
+	return l
+}
+
+// LoadLock reads a policy.lock file.
+func LoadLock(path string) (*Lock, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var l Lock
+	if err := json.Unmarshal(raw, &l); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if l.LockVersion != LockVersion {
+		return nil, fmt.Errorf("%s: unsupported lock_version %d", path, l.LockVersion)
+	}
+	return &l, nil
+}
+
+// Marshal renders the lock as pretty canonical JSON with a trailing
+// newline.
+func (l *Lock) Marshal() ([]byte, error) {
+	raw, err := canon.MarshalPretty(l)
+	if err != nil {
+		return nil, err
+	}
+	return append(raw, '\n'), nil
+}
+
+// Verify checks that the pinned layers of eff match the lock exactly: every
+// pinned layer is locked with the same hash and the lock has no stale
+// entries. A nil lock fails if anything in the chain is pinned.
+func (l *Lock) Verify(eff *Effective) error {
+	want := BuildLock(eff)
+	if len(want.Layers) == 0 && (l == nil || len(l.Layers) == 0) {
+		return nil
+	}
+	if l == nil {
+		return fmt.Errorf("chain has pinned layers but there is no policy.lock (run `brikgov policy lock`)")
+	}
+	locked := map[string]LockedLayer{}
+	for _, e := range l.Layers {
+		locked[e.Ref] = e
+	}
+	var problems []string
+	for _, w := range want.Layers {
+		e, ok := locked[w.Ref]
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("%s is not in policy.lock", w.Ref))
+		case e.SHA256 != w.SHA256:
+			problems = append(problems, fmt.Sprintf("%s: %s has sha256 %s, policy.lock expects %s", w.Ref, w.Source, w.SHA256, e.SHA256))
+		}
+		delete(locked, w.Ref)
+	}
+	for ref := range locked {
+		problems = append(problems, fmt.Sprintf("%s is locked but no longer in the chain", ref))
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("policy.lock mismatch:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// Vendor writes the pinned layers of eff into the cache under root so later
+// resolution works offline. It returns the paths written.
+func Vendor(eff *Effective, root string) ([]string, error) {
+	var written []string
+	for i, c := range eff.Chain {
+		if _, tag := SplitRef(c.Layer); tag == "" {
+			continue
+		}
+		p := filepath.Join(root, filepath.FromSlash(c.Source))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(p, eff.layers[i].raw, 0o644); err != nil {
+			return nil, err
+		}
+		written = append(written, c.Source)
+	}
+	return written, nil
+}
+
+// GitFetch returns a FileSource.Fetch that reads files from tags of a local
+// clone of this repo. The tag must exist locally; nothing is fetched.
+func GitFetch(upstream string) func(tag, path string) ([]byte, error) {
+	return func(tag, path string) ([]byte, error) {
+		var stderr bytes.Buffer
+		cmd := exec.Command("git", "-C", upstream, "show", "refs/tags/"+tag+":"+path)
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("git show %s:%s: %s", tag, path, strings.TrimSpace(stderr.String()))
+		}
+		return out, nil
+	}
+}
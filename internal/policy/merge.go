@@ -0,0 +1,420 @@
+package policy
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Direction says which way a field may move when a child layer overrides it.
+type Direction int
+
+const (
+	// Higher numbers are stricter (coverage_min, required_approvals).
+	Higher Direction = iota + 1
+	// Lower numbers are stricter (deadlines such as due_hours).
+	Lower
+	// true is stricter (require_* flags).
+	TrueStricter
+	// false is stricter (critical_paths_only narrows what is measured).
+	FalseStricter
+	// Ordered enums: later entries in Scale are stricter.
+	Ordered
+	// Union lists: a child may add entries but never drop inherited ones.
+	Union
+	// Subset lists: a child may only narrow the inherited list
+	// (allowed_branches).
+	Subset
+	// Fixed values are set by the org layer only; a lower layer may repeat
+	// the inherited value but not change or introduce it (allowed_signers).
+	Fixed
+)
+
+// Constraint binds a field path pattern to its tightening direction. Path
+// segments are matched with path.Match, so "reviews.branches.*.required_roles"
+// covers every branch pattern.
+type Constraint struct {
+	Path      string
+	Direction Direction
+	Scale     []string
+	// Positive marks Lower limits where 0 or less means "no limit"
+	// (max_file_mb, SLA hours), so it may not replace a positive inherited
+	// value.
+	Positive bool
+}
+
+var (
+	// severityScale orders max_severity thresholds from loosest to strictest.
+	severityScale = []string{"critical", "high", "medium", "low", "none"}
+	// levelScale orders "no-X" thresholds from loosest to strictest.
+	levelScale   = []string{"no-critical", "no-high", "no-medium", "no-low"}
+	modeScale    = []string{"advisory", "enforce"}
+	blockScale   = []string{"warn", "block"}
+	scopeScale   = []string{"diff", "tree"}
+	reviewScopes = []string{"reviews", "reviews.default", "reviews.branches.*"}
+)
+
+// Constraints is the tighten-only contract between policy layers. Fields not
+// listed here may be overridden freely (tool names, report paths).
+var Constraints = buildConstraints()
+
+func buildConstraints() []Constraint {
+	c := []Constraint{
+		{Path: "mode", Direction: Ordered, Scale: modeScale},
+		{Path: "reviews.additional_reviewer_teams", Direction: Union},
+		{Path: "reviews.sla.first_review_hours", Direction: Lower, Positive: true},
+		{Path: "reviews.sla.approval_hours", Direction: Lower, Positive: true},
+
+		{Path: "tests.coverage_min", Direction: Higher},
+		{Path: "tests.coverage_delta_min", Direction: Higher},
+		{Path: "tests.require_tests_green", Direction: TrueStricter},
+		{Path: "tests.critical_paths_only", Direction: FalseStricter},
+		{Path: "tests.impact.enabled", Direction: FalseStricter},
+		{Path: "tests.impact.full_run_branches", Direction: Union},
+		{Path: "tests.impact.shared_config", Direction: Union},
+
+		{Path: "security.*.max_severity", Direction: Ordered, Scale: severityScale},
+		{Path: "security.sast_threshold", Direction: Ordered, Scale: levelScale},
+		{Path: "security.sca_threshold", Direction: Ordered, Scale: levelScale},
+		{Path: "security.dast_threshold", Direction: Ordered, Scale: levelScale},
+
+		{Path: "docs.require_docs_on_feature_change", Direction: TrueStricter},
+		{Path: "docs.paths", Direction: Union},
+
+		{Path: "supply_chain.require_signed_artifacts", Direction: TrueStricter},
+		{Path: "supply_chain.require_sbom", Direction: TrueStricter},
+		{Path: "artifacts.require_sbom", Direction: TrueStricter},
+		{Path: "artifacts.require_hashes", Direction: TrueStricter},
+		{Path: "artifacts.require_signatures", Direction: TrueStricter},
+
+		{Path: "rules.*.severity", Direction: Ordered, Scale: blockScale},
+		{Path: "rules.*.requires_evidence", Direction: TrueStricter},
+		{Path: "rules.*.threshold", Direction: Higher},
+		{Path: "rules.*.max_level", Direction: Ordered, Scale: levelScale},
+		{Path: "rules.*.exempt_web_flow", Direction: FalseStricter},
+		{Path: "rules.*.allowed_signers", Direction: Fixed},
+		{Path: "rules.*.forbidden", Direction: Union},
+		{Path: "rules.*.os_artifacts", Direction: Union},
+		{Path: "rules.*.max_file_mb", Direction: Lower, Positive: true},
+		{Path: "rules.*.allow", Direction: Subset},
+		{Path: "rules.*.scope", Direction: Ordered, Scale: scopeScale},
+		{Path: "rules.*.binary_allowed_paths", Direction: Subset},
+		{Path: "rules.*.registries", Direction: Subset},
+		{Path: "rules.*.lockfiles", Direction: Union},
+		{Path: "rules.*.allow_duplicates", Direction: FalseStricter},
+		{Path: "rules.*.max_new_direct", Direction: Lower},
+		{Path: "rules.*.banned", Direction: Union},
+		{Path: "rules.*.min_age_days", Direction: Higher},
+		{Path: "rules.*.require_metadata", Direction: TrueStricter},
+		{Path: "rules.*.typosquat_distance", Direction: Higher},
+		{Path: "rules.*.popular", Direction: Fixed},
+		{Path: "rules.*.metadata_cache", Direction: Fixed},
+		{Path: "rules.*.sensitive_paths", Direction: Union},
+		{Path: "rules.*.labels", Direction: Union},
+		{Path: "rules.*.keywords", Direction: Union},
+		{Path: "rules.*.team", Direction: Fixed},
+		{Path: "rules.*.template", Direction: Fixed},
+		{Path: "rules.*.section", Direction: Fixed},
+		{Path: "rules.*.checklist", Direction: Fixed},
+
+		{Path: "release.semver.enforcement_mode", Direction: Ordered, Scale: blockScale},
+		{Path: "release.semver.allowed_branches", Direction: Subset},
+		{Path: "release.semver.guardrails.*", Direction: TrueStricter},
+
+		{Path: "hotfix.branches", Direction: Subset},
+		{Path: "hotfix.fast_path.enabled", Direction: FalseStricter},
+		{Path: "hotfix.fast_path.required_approvals", Direction: Higher},
+		{Path: "hotfix.fast_path.required_roles", Direction: Union},
+		{Path: "hotfix.fast_path.incident_severities", Direction: Subset},
+		{Path: "hotfix.post_merge_review.due_hours", Direction: Lower},
+		{Path: "hotfix.post_merge_review.required_approvals", Direction: Higher},
+		{Path: "hotfix.post_merge_review.escalate_to", Direction: Union},
+		{Path: "hotfix.post_merge_review.block_release_tag", Direction: TrueStricter},
+
+		{Path: "bypass.protected_branches", Direction: Union},
+		{Path: "bypass.protected_tags", Direction: Union},
+		{Path: "bypass.decision_window_hours", Direction: Lower},
+		{Path: "bypass.max_grant_hours", Direction: Lower},
+		{Path: "lifecycle.security_labels", Direction: Union},
+		{Path: "lifecycle.archive_after_days", Direction: Higher},
+	}
+	for _, scope := range reviewScopes {
+		c = append(c,
+			Constraint{Path: scope + ".required_approvals", Direction: Higher},
+			Constraint{Path: scope + ".require_code_owner_review", Direction: TrueStricter},
+			Constraint{Path: scope + ".required_roles", Direction: Union},
+		)
+	}
+	return c
+}
+
+// Violation is an attempt by a layer to relax an inherited setting
+// (code POL-030).
+type Violation struct {
+	Code   string `json:"code"`
+	Layer  string `json:"layer"`
+	Path   string `json:"path"`
+	Parent any    `json:"parent"`
+	Child  any    `json:"child"`
+	Reason string `json:"reason"`
+}
+
+func (v Violation) Error() string {
+	return fmt.Sprintf("%s: %s relaxes %v → %v (%s)", v.Layer, v.Path, v.Parent, v.Child, v.Reason)
+}
+
+// Merge overlays child on parent under tighten-only semantics and returns
+// the merged document plus every relaxation attempt. Neither input is
+// modified. Layer metadata (extends, owner, description) is not merged.
+func Merge(parent, child map[string]any, layer string) (map[string]any, []Violation) {
+	var vs []Violation
+	out := mergeMaps(parent, child, "", layer, &vs)
+	sort.Slice(vs, func(i, j int) bool { return vs[i].Path < vs[j].Path })
+	return out, vs
+}
+
+func mergeMaps(parent, child map[string]any, prefix, layer string, vs *[]Violation) map[string]any {
+	out := make(map[string]any, len(parent)+len(child))
+	for k, v := range parent {
+		out[k] = deepCopy(v)
+	}
+	for k, cv := range child {
+		if prefix == "" && layerOnlyKeys[k] {
+			continue
+		}
+		p := join(prefix, k)
+		pv, inherited := parent[k]
+		if !inherited {
+			if cm, ok := cv.(map[string]any); ok {
+				out[k] = mergeMaps(map[string]any{}, cm, p, layer, vs)
+				continue
+			}
+			if c, ok := constraintFor(p); ok && c.Direction == Fixed {
+				*vs = append(*vs, Violation{Code: "POL-030", Layer: layer, Path: p, Child: cv,
+					Reason: "may only be set by the org layer"})
+				continue
+			}
+			out[k] = deepCopy(cv)
+			continue
+		}
+		pm, pIsMap := pv.(map[string]any)
+		cm, cIsMap := cv.(map[string]any)
+		if pIsMap && cIsMap {
+			out[k] = mergeMaps(pm, cm, p, layer, vs)
+			continue
+		}
+		if pIsMap {
+			// "rules: null" would drop every inherited rule at once.
+			*vs = append(*vs, Violation{Code: "POL-030", Layer: layer, Path: p, Parent: pv, Child: cv,
+				Reason: "cannot replace an inherited section with null or a non-map"})
+			out[k] = deepCopy(pv)
+			continue
+		}
+		out[k] = mergeValue(p, pv, cv, layer, vs)
+	}
+	return out
+}
+
+// mergeValue applies the constraint for path p (if any) to a scalar or list
+// override.
+func mergeValue(p string, pv, cv any, layer string, vs *[]Violation) any {
+	c, ok := constraintFor(p)
+	if !ok {
+		return deepCopy(cv)
+	}
+	relax := func(reason string) any {
+		*vs = append(*vs, Violation{Code: "POL-030", Layer: layer, Path: p, Parent: pv, Child: cv, Reason: reason})
+		return deepCopy(pv)
+	}
+
+	switch c.Direction {
+	case Higher:
+		pf, ok1 := toFloat(pv)
+		cf, ok2 := toFloat(cv)
+		if !ok1 || !ok2 {
+			return relax("expected a number")
+		}
+		if cf < pf {
+			return relax("must not be lower than the inherited value")
+		}
+	case Lower:
+		pf, ok1 := toFloat(pv)
+		cf, ok2 := toFloat(cv)
+		if !ok1 || !ok2 {
+			return relax("expected a number")
+		}
+		if c.Positive && cf <= 0 && pf > 0 {
+			return relax("must be positive; 0 or less removes the limit")
+		}
+		if cf > pf {
+			return relax("must not be higher than the inherited value")
+		}
+	case TrueStricter:
+		pb, ok1 := pv.(bool)
+		cb, ok2 := cv.(bool)
+		if !ok1 || !ok2 {
+			return relax("expected a boolean")
+		}
+		if pb && !cb {
+			return relax("cannot be disabled once required")
+		}
+	case FalseStricter:
+		pb, ok1 := pv.(bool)
+		cb, ok2 := cv.(bool)
+		if !ok1 || !ok2 {
+			return relax("expected a boolean")
+		}
+		if !pb && cb {
+			return relax("cannot be enabled once an inherited layer disabled it")
+		}
+	case Ordered:
+		pi, ci := rank(c.Scale, pv), rank(c.Scale, cv)
+		if ci < 0 {
+			return relax(fmt.Sprintf("must be one of %v", c.Scale))
+		}
+		if pi >= 0 && ci < pi {
+			return relax(fmt.Sprintf("must be %q or stricter", pv))
+		}
+	case Union:
+		pl, ok1 := toStrings(pv)
+		cl, ok2 := toStrings(cv)
+		if !ok1 || !ok2 {
+			return relax("expected a list of strings")
+		}
+		return union(pl, cl)
+	case Subset:
+		pl, ok1 := toStrings(pv)
+		cl, ok2 := toStrings(cv)
+		if !ok1 || !ok2 {
+			return relax("expected a list of strings")
+		}
+		allowed := map[string]bool{}
+		for _, s := range pl {
+			allowed[s] = true
+		}
+		for _, s := range cl {
+			if !allowed[s] {
+				return relax(fmt.Sprintf("%q is not in the inherited list", s))
+			}
+		}
+		if len(cl) == 0 {
+			return relax("cannot be emptied")
+		}
+	case Fixed:
+		if !reflect.DeepEqual(pv, cv) {
+			return relax("may only be set by the org layer")
+		}
+	}
+	return deepCopy(cv)
+}
+
+func constraintFor(p string) (Constraint, bool) {
+	segs := strings.Split(p, ".")
+	// Gate rule ids contain dots ("rules.coverage.min.threshold"); the id is
+	// one segment for matching "rules.*.threshold".
+	if len(segs) > 3 && segs[0] == "rules" {
+		segs = []string{"rules", strings.Join(segs[1:len(segs)-1], "."), segs[len(segs)-1]}
+	}
+	for _, c := range Constraints {
+		pat := strings.Split(c.Path, ".")
+		if len(pat) != len(segs) {
+			continue
+		}
+		match := true
+		for i := range pat {
+			if ok, _ := path.Match(pat[i], segs[i]); !ok {
+				match = false
+				break
+			}
+		}
+		if match {
+			return c, true
+		}
+	}
+	return Constraint{}, false
+}
+
+func join(prefix, k string) string {
+	if prefix == "" {
+		return k
+	}
+	return prefix + "." + k
+}
+
+func rank(scale []string, v any) int {
+	s, ok := v.(string)
+	if !ok {
+		return -1
+	}
+	for i, x := range scale {
+		if strings.EqualFold(x, s) {
+			return i
+		}
+	}
+	return -1
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func toStrings(v any) ([]string, bool) {
+	list, ok := v.([]any)
+	if !ok {
+		return nil, false
+	}
+	out := make([]string, 0, len(list))
+	for _, x := range list {
+		s, ok := x.(string)
+		if !ok {
+			return nil, false
+		}
+		out = append(out, s)
+	}
+	return out, true
+}
+
+// union keeps parent order and appends new child entries.
+func union(parent, child []string) []any {
+	seen := map[string]bool{}
+	out := make([]any, 0, len(parent)+len(child))
+	for _, l := range [][]string{parent, child} {
+		for _, s := range l {
+			if !seen[s] {
+				seen[s] = true
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
+
+func deepCopy(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(t))
+		for k, x := range t {
+			m[k] = deepCopy(x)
+		}
+		return m
+	case []any:
+		l := make([]any, len(t))
+		for i, x := range t {
+			l[i] = deepCopy(x)
+		}
+		return l
+	}
+	return v
+}
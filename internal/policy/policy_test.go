@@ -0,0 +1,545 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func parse(t *testing.T, ref, doc string) *Layer {
+	t.Helper()
+	l, err := ParseLayer(ref, []byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+const base = `
+version: 1
+tests:
+  coverage_min: 80
+  require_tests_green: true
+security:
+  sast:
+    max_severity: medium
+    tool: semgrep
+  sca_threshold: no-high
+reviews:
+  required_approvals: 1
+  additional_reviewer_teams: [devops]
+rules:
+  coverage.min: {severity: block, threshold: 80}
+`
+
+func TestMergeInheritOnly(t *testing.T) {
+	b := parse(t, OrgRef, base)
+	got, vs := Merge(b.Doc, map[string]any{}, "repo")
+	if len(vs) > 0 || !reflect.DeepEqual(got, b.Doc) {
+		t.Errorf("inherit-only merge changed policy: %v %v", got, vs)
+	}
+}
+
+func TestMergeTighten(t *testing.T) {
+	b := parse(t, OrgRef, base)
+	r := parse(t, "repo", `
+tests: {coverage_min: 90}
+security:
+  sast: {max_severity: low, tool: codeql}
+  sca_threshold: no-medium
+reviews:
+  additional_reviewer_teams: [security]
+`)
+	got, vs := Merge(b.Doc, r.Doc, "repo")
+	if len(vs) > 0 {
+		t.Fatalf("tightening rejected: %v", vs)
+	}
+	tests := got["tests"].(map[string]any)
+	sast := got["security"].(map[string]any)["sast"].(map[string]any)
+	teams := got["reviews"].(map[string]any)["additional_reviewer_teams"]
+	if tests["coverage_min"] != 90 || tests["require_tests_green"] != true {
+		t.Errorf("tests = %v", tests)
+	}
+	if sast["max_severity"] != "low" || sast["tool"] != "codeql" {
+		t.Errorf("sast = %v", sast)
+	}
+	if !reflect.DeepEqual(teams, []any{"devops", "security"}) {
+		t.Errorf("teams = %v", teams)
+	}
+}
+
+func TestMergeRejectsRelaxation(t *testing.T) {
+	b := parse(t, OrgRef, base)
+	r := parse(t, "repo", `
+tests: {coverage_min: 60, require_tests_green: false}
+security:
+  sast: {max_severity: critical}
+  sca_threshold: no-critical
+reviews:
+  required_approvals: 0
+rules:
+  coverage.min: {severity: warn, threshold: 70}
+`)
+	_, vs := Merge(b.Doc, r.Doc, "repo")
+	var paths []string
+	for _, v := range vs {
+		paths = append(paths, v.Path)
+	}
+	want := []string{
+		"reviews.required_approvals",
+		"rules.coverage.min.severity",
+		"rules.coverage.min.threshold",
+		"security.sast.max_severity",
+		"security.sca_threshold",
+		"tests.coverage_min",
+		"tests.require_tests_green",
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("violations = %v, want %v", paths, want)
+	}
+}
+
+func TestMergeRejectsDroppedSections(t *testing.T) {
+	b := parse(t, OrgRef, base)
+	r := parse(t, "repo", `
+tests: null
+security:
+  sast: off
+rules: []
+`)
+	got, vs := Merge(b.Doc, r.Doc, "repo")
+	var paths []string
+	for _, v := range vs {
+		paths = append(paths, v.Path)
+	}
+	if want := []string{"rules", "security.sast", "tests"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("violations = %v, want %v", paths, want)
+	}
+	if !reflect.DeepEqual(got, b.Doc) {
+		t.Errorf("dropped sections were not restored: %v", got)
+	}
+}
+
+func TestMergeFixedBelowOrg(t *testing.T) {
+	org := parse(t, OrgRef, `
+rules:
+  commits.signed: {severity: warn, allowed_signers: .governance/signing/allowed-signers.yml}
+`)
+	same := parse(t, "repo", `
+rules:
+  commits.signed: {severity: block, allowed_signers: .governance/signing/allowed-signers.yml}
+`)
+	if _, vs := Merge(org.Doc, same.Doc, "repo"); len(vs) > 0 {
+		t.Errorf("repeating the org value rejected: %v", vs)
+	}
+	r := parse(t, "repo", `
+rules:
+  commits.signed: {allowed_signers: my-signers.yml}
+  commits.signed.release: {allowed_signers: my-signers.yml}
+`)
+	got, vs := Merge(org.Doc, r.Doc, "repo")
+	var paths []string
+	for _, v := range vs {
+		paths = append(paths, v.Path)
+	}
+	want := []string{"rules.commits.signed.allowed_signers", "rules.commits.signed.release.allowed_signers"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("violations = %v, want %v", paths, want)
+	}
+	rules := got["rules"].(map[string]any)
+	if rules["commits.signed"].(map[string]any)["allowed_signers"] != ".governance/signing/allowed-signers.yml" {
+		t.Errorf("commits.signed = %v", rules["commits.signed"])
+	}
+	if _, ok := rules["commits.signed.release"].(map[string]any)["allowed_signers"]; ok {
+		t.Errorf("repo-introduced allowed_signers kept: %v", rules["commits.signed.release"])
+	}
+}
+
+func TestMergeSecurityReviewTeamFixed(t *testing.T) {
+	org := parse(t, OrgRef, `
+rules:
+  security.review: {severity: block, team: "@BrikByte-Studios/security"}
+`)
+	r := parse(t, "repo", `
+rules:
+  security.review:
+    team: "@BrikByte-Studios/payments"
+    template: docs/easy_template.md
+    section: Notes
+    checklist: Anything
+`)
+	got, vs := Merge(org.Doc, r.Doc, "repo")
+	var paths []string
+	for _, v := range vs {
+		paths = append(paths, v.Path)
+	}
+	want := []string{
+		"rules.security.review.checklist",
+		"rules.security.review.section",
+		"rules.security.review.team",
+		"rules.security.review.template",
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("violations = %v, want %v", paths, want)
+	}
+	if rule := got["rules"].(map[string]any)["security.review"]; !reflect.DeepEqual(rule, org.Doc["rules"].(map[string]any)["security.review"]) {
+		t.Errorf("security.review = %v", rule)
+	}
+}
+
+func TestMergeHygieneLimits(t *testing.T) {
+	org := parse(t, OrgRef, `
+rules:
+  repo.hygiene: {scope: diff, max_file_mb: 5, allow: [.env.example, .env.sample]}
+`)
+	tight := parse(t, "repo", `
+rules:
+  repo.hygiene: {scope: tree, max_file_mb: 2, allow: [.env.example]}
+`)
+	if _, vs := Merge(org.Doc, tight.Doc, "repo"); len(vs) > 0 {
+		t.Errorf("tightening rejected: %v", vs)
+	}
+	loose := parse(t, "repo", `
+rules:
+  repo.hygiene: {scope: everything, max_file_mb: 0, allow: [.env.example, "*.pem"]}
+`)
+	got, vs := Merge(org.Doc, loose.Doc, "repo")
+	var paths []string
+	for _, v := range vs {
+		paths = append(paths, v.Path)
+	}
+	want := []string{"rules.repo.hygiene.allow", "rules.repo.hygiene.max_file_mb", "rules.repo.hygiene.scope"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("violations = %v, want %v", paths, want)
+	}
+	if rule := got["rules"].(map[string]any)["repo.hygiene"]; !reflect.DeepEqual(rule, org.Doc["rules"].(map[string]any)["repo.hygiene"]) {
+		t.Errorf("repo.hygiene = %v", rule)
+	}
+	for _, mb := range []string{"-1", "7"} {
+		r := parse(t, "repo", "rules:\n  repo.hygiene: {max_file_mb: "+mb+"}\n")
+		if _, vs := Merge(org.Doc, r.Doc, "repo"); len(vs) != 1 {
+			t.Errorf("max_file_mb %s: violations = %v", mb, vs)
+		}
+	}
+}
+
+func TestMergeDepsReviewFilesFixed(t *testing.T) {
+	org := parse(t, OrgRef, `
+rules:
+  deps.review: {metadata_cache: .governance/deps/metadata.yml, popular: .governance/deps/popular.yml}
+`)
+	for _, key := range []string{"popular", "metadata_cache"} {
+		r := parse(t, "repo", "rules:\n  deps.review: {"+key+": empty.yml}\n")
+		got, vs := Merge(org.Doc, r.Doc, "repo")
+		if len(vs) != 1 || vs[0].Path != "rules.deps.review."+key {
+			t.Errorf("%s: violations = %v", key, vs)
+		}
+		if rule := got["rules"].(map[string]any)["deps.review"]; !reflect.DeepEqual(rule, org.Doc["rules"].(map[string]any)["deps.review"]) {
+			t.Errorf("%s: deps.review = %v", key, rule)
+		}
+	}
+}
+
+func TestMergeLockfilesUnion(t *testing.T) {
+	team := parse(t, "team/api", `
+rules:
+  deps.lockfile: {lockfiles: [package-lock.json, services/api/poetry.lock]}
+`)
+	r := parse(t, "repo", `
+rules:
+  deps.lockfile: {lockfiles: [package-lock.json]}
+`)
+	got, vs := Merge(team.Doc, r.Doc, "repo")
+	if len(vs) > 0 {
+		t.Fatalf("violations = %v", vs)
+	}
+	want := []any{"package-lock.json", "services/api/poetry.lock"}
+	if l := got["rules"].(map[string]any)["deps.lockfile"].(map[string]any)["lockfiles"]; !reflect.DeepEqual(l, want) {
+		t.Errorf("lockfiles = %v, want %v", l, want)
+	}
+}
+
+func TestMergeReviewSLA(t *testing.T) {
+	org := parse(t, OrgRef, "reviews:\n  sla: {first_review_hours: 24, approval_hours: 72}\n")
+	if _, vs := Merge(org.Doc, parse(t, "repo", "reviews:\n  sla: {first_review_hours: 8}\n").Doc, "repo"); len(vs) > 0 {
+		t.Errorf("tightening rejected: %v", vs)
+	}
+	// 0 turns breach detection off, so it is not "lower is stricter".
+	got, vs := Merge(org.Doc, parse(t, "repo", "reviews:\n  sla: {first_review_hours: 0, approval_hours: -1}\n").Doc, "repo")
+	if len(vs) != 2 || vs[0].Path != "reviews.sla.approval_hours" || vs[1].Path != "reviews.sla.first_review_hours" {
+		t.Errorf("violations = %v", vs)
+	}
+	if sla := got["reviews"].(map[string]any)["sla"]; !reflect.DeepEqual(sla, org.Doc["reviews"].(map[string]any)["sla"]) {
+		t.Errorf("sla = %v", sla)
+	}
+}
+
+func TestUnknownTopLevelField(t *testing.T) {
+	_, err := ParseLayer("repo", []byte("weird_magic_flag: true\n"))
+	if err == nil || !strings.Contains(err.Error(), "weird_magic_flag") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+// layers writes an org policy plus named layers into a temp repo.
+func layers(t *testing.T, files map[string]string) FileSource {
+	t.Helper()
+	root := t.TempDir()
+	for rel, doc := range files {
+		p := filepath.Join(root, filepath.FromSlash(rel))
+		_ = os.MkdirAll(filepath.Dir(p), 0o755)
+		if err := os.WriteFile(p, []byte(doc), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return FileSource{Root: root}
+}
+
+func TestResolveThreeLevels(t *testing.T) {
+	src := layers(t, map[string]string{
+		DefaultOrgPath:                           base,
+		".governance/policies/team/payments.yml": "extends: org\ntests: {coverage_min: 85}\n",
+	})
+	repo := parse(t, "repo", "extends: team/payments\ntests: {coverage_min: 90}\n")
+	eff, err := Resolve(src, repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var refs []string
+	for _, c := range eff.Chain {
+		refs = append(refs, c.Layer)
+	}
+	if !reflect.DeepEqual(refs, []string{"org", "team/payments", "repo"}) {
+		t.Errorf("chain = %v", refs)
+	}
+	if got := eff.Policy["tests"].(map[string]any)["coverage_min"]; got != 90 {
+		t.Errorf("coverage_min = %v", got)
+	}
+	if _, ok := eff.Policy["extends"]; ok {
+		t.Error("extends leaked into the effective policy")
+	}
+	if chain, _ := eff.Policy["resolved_chain"].([]any); len(chain) != 3 {
+		t.Errorf("resolved_chain = %v", eff.Policy["resolved_chain"])
+	}
+}
+
+func TestResolveEnforcesEachLevel(t *testing.T) {
+	src := layers(t, map[string]string{
+		DefaultOrgPath:                           base,
+		".governance/policies/team/payments.yml": "extends: org\ntests: {coverage_min: 85}\n",
+	})
+	// Above org but below the team baseline.
+	repo := parse(t, "repo", "extends: team/payments\ntests: {coverage_min: 82}\n")
+	_, err := Resolve(src, repo)
+	var relax *RelaxError
+	if !errors.As(err, &relax) || relax.Violations[0].Layer != "repo" {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestResolveCycle(t *testing.T) {
+	src := layers(t, map[string]string{
+		DefaultOrgPath:                      base,
+		".governance/policies/team/a.yml":   "extends: team/b\n",
+		".governance/policies/team/b.yml":   "extends: team/a\n",
+		".governance/policies/team/nil.yml": "",
+	})
+	_, err := Resolve(src, parse(t, "repo", "extends: team/a\n"))
+	if err == nil || !strings.Contains(err.Error(), "cyclic extends: repo → team/a → team/b → team/a") {
+		t.Errorf("err = %v", err)
+	}
+	// A team layer without extends never reaches org.
+	_, err = Resolve(src, parse(t, "repo", "extends: team/nil\n"))
+	if err == nil || !strings.Contains(err.Error(), "does not reach") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestResolveDiamond(t *testing.T) {
+	src := layers(t, map[string]string{
+		DefaultOrgPath:                           base,
+		".governance/policies/team/payments.yml": "extends: org\n",
+		".governance/policies/team/identity.yml": "extends: org\n",
+	})
+	_, err := Resolve(src, parse(t, "repo", "extends: [team/payments, team/identity]\n"))
+	if err == nil || !strings.Contains(err.Error(), "diamond extends: repo reaches org via both team/payments and team/identity") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestRepoTeamLayersTightenOrg(t *testing.T) {
+	src := FileSource{Root: filepath.Join("..", "..")}
+	for _, team := range []string{"team/payments", "team/identity"} {
+		eff, err := Resolve(src, parse(t, "repo", "extends: "+team+"\n"))
+		if err != nil {
+			t.Fatalf("%s: %v", team, err)
+		}
+		if eff.Chain[1].Layer != team {
+			t.Errorf("%s: chain = %v", team, eff.Chain)
+		}
+	}
+}
+
+func TestResolveKeepsOrgFixedValues(t *testing.T) {
+	src := FileSource{Root: filepath.Join("..", "..")}
+	org, err := src.Load(OrgRef)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eff, err := Resolve(src, parse(t, "repo", "extends: team/payments\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	orgRules := org.Doc["rules"].(map[string]any)
+	effRules := eff.Policy["rules"].(map[string]any)
+	var fixed int
+	for id, r := range orgRules {
+		for k, v := range r.(map[string]any) {
+			if c, ok := constraintFor("rules." + id + "." + k); !ok || c.Direction != Fixed {
+				continue
+			}
+			fixed++
+			if got := effRules[id].(map[string]any)[k]; !reflect.DeepEqual(got, v) {
+				t.Errorf("rules.%s.%s = %v, want the org value %v", id, k, got, v)
+			}
+		}
+	}
+	if fixed == 0 {
+		t.Fatal("org policy sets no Fixed rule options")
+	}
+}
+
+func TestPinnedChainAndLock(t *testing.T) {
+	src := layers(t, map[string]string{
+		".github/policy-vendor/v1.4.0/.github/policy.yml":                     base,
+		".github/policy-vendor/v1.4.0/.governance/policies/team/payments.yml": "extends: org\ntests: {coverage_min: 85}\n",
+	})
+	eff, err := Resolve(src, parse(t, "repo", "extends: team/payments@v1.4.0\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The team layer's unpinned `extends: org` resolves at the same tag.
+	if eff.Chain[0].Layer != "org@v1.4.0" || eff.Chain[0].Source != ".github/policy-vendor/v1.4.0/.github/policy.yml" {
+		t.Errorf("chain = %+v", eff.Chain)
+	}
+
+	lock := BuildLock(eff)
+	if len(lock.Layers) != 2 {
+		t.Fatalf("lock = %+v", lock)
+	}
+	if err := lock.Verify(eff); err != nil {
+		t.Errorf("fresh lock: %v", err)
+	}
+	if err := (*Lock)(nil).Verify(eff); err == nil {
+		t.Error("pinned chain verified without a lock")
+	}
+	lock.Layers[0].SHA256 = strings.Repeat("0", 64)
+	if err := lock.Verify(eff); err == nil || !strings.Contains(err.Error(), "org@v1.4.0") {
+		t.Errorf("hash mismatch: %v", err)
+	}
+
+	if _, err := Resolve(src, parse(t, "repo", "extends: org@v2.0.0\n")); err == nil || !strings.Contains(err.Error(), "not vendored") {
+		t.Errorf("missing cache: %v", err)
+	}
+	if _, err := Resolve(src, parse(t, "repo", "extends: org@main\n")); err == nil || !strings.Contains(err.Error(), "SemVer") {
+		t.Errorf("branch pin: %v", err)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a := parse(t, OrgRef, base).Doc
+	b := parse(t, OrgRef, `
+version: 1
+tests:
+  coverage_min: 75
+  require_tests_green: true
+  coverage_delta_min: 0
+security:
+  sast:
+    max_severity: low
+    tool: codeql
+  sca_threshold: no-high
+reviews:
+  required_approvals: 1
+rules:
+  coverage.min: {severity: warn, threshold: 80}
+`).Doc
+	got := map[string]string{}
+	for _, c := range Diff(a, b) {
+		got[c.Path] = c.Kind + "/" + c.Effect
+	}
+	want := map[string]string{
+		"tests.coverage_min":                "changed/relaxed",
+		"tests.coverage_delta_min":          "added/tightened",
+		"security.sast.max_severity":        "changed/tightened",
+		"security.sast.tool":                "changed/",
+		"reviews.additional_reviewer_teams": "removed/relaxed",
+		"rules.coverage.min.severity":       "changed/relaxed",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diff = %v, want %v", got, want)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	doc := `version: "1"
+release:
+  semver:
+    enabled: "true"
+    source_of_truth: version-file
+    tag_prefix: v
+    tag_pattern: "^v\\d+$"
+    allowed_branches: []
+    enforcement_mode: strict
+    idempotency: noop
+    initial_version: "1.0"
+    prerelease: {allowed_channels: [rc]}
+    guardrails: {require_full_history: true, prevent_tag_move: yes, signed: true}
+    channels: [rc]
+tets:
+  coverage_min: 80
+`
+	fs, err := Validate("policy.yml", []byte(doc), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range fs {
+		got = append(got, fmt.Sprintf("%s %d %s", f.Code, f.Line, f.Message))
+	}
+	want := []string{
+		`POL-001 1 version must be an integer ≥ 1, got "1"`,
+		"POL-011 3 release.semver.tag_type is required",
+		`POL-017 4 release.semver.enabled must be a boolean, got "true" (str)`,
+		`POL-013 5 release.semver.source_of_truth must be "git-tags" in v1, got "version-file"`,
+		`POL-012 7 release.semver.tag_pattern must be "^v\\d+\\.\\d+\\.\\d+$" (strict vX.Y.Z) in v1, got "^v\\d+$"`,
+		"POL-014 8 release.semver.allowed_branches must list at least one branch",
+		`POL-016 9 release.semver.enforcement_mode must be one of warn, block, got "strict"`,
+		`POL-015 11 release.semver.initial_version must be vX.Y.Z, got "1.0"`,
+		"POL-011 12 release.semver.prerelease.enabled is required",
+		"POL-010 13 unknown field release.semver.guardrails.signed",
+		"POL-011 13 release.semver.guardrails.require_checks_passed is required",
+		`POL-017 13 release.semver.guardrails.prevent_tag_move must be a boolean, got "yes" (str)`,
+		"POL-010 14 unknown field release.semver.channels",
+		`POL-002 15 unknown top-level field "tets"`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findings:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// Layers other than org may set any subset.
+	if fs, _ := Validate("team.yml", []byte("release:\n  semver:\n    tag_type: lightweight\n"), false); len(fs) != 0 {
+		t.Errorf("team layer: %+v", fs)
+	}
+	raw, err := os.ReadFile("../../" + DefaultOrgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fs, err := Validate(DefaultOrgPath, raw, true); err != nil || len(fs) != 0 {
+		t.Errorf("%s: %v %+v", DefaultOrgPath, err, fs)
+	}
+}
@@ -0,0 +1,135 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ChainLink records one layer of the resolved chain, root first.
+type ChainLink struct {
+	Layer  string `json:"layer"`
+	Source string `json:"source"`
+	SHA256 string `json:"sha256"`
+}
+
+// Effective is the result of resolving a layer chain.
+type Effective struct {
+	// Policy is the merged document, including "resolved_chain".
+	Policy map[string]any
+	Chain  []ChainLink
+	layers []*Layer
+}
+
+// RelaxError lists every attempt in the chain to loosen an inherited value.
+type RelaxError struct {
+	Violations []Violation
+}
+
+func (e *RelaxError) Error() string {
+	lines := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		lines[i] = v.Error()
+	}
+	return fmt.Sprintf("policy layers may only tighten inherited settings:\n  %s", strings.Join(lines, "\n  "))
+}
+
+// Resolve loads the ancestors of leaf from src and merges the chain
+// root-first. A leaf without `extends` inherits from org (the historical
+// org + repo overlay behaviour); the org layer itself resolves to a chain of
+// one.
+func Resolve(src Source, leaf *Layer) (*Effective, error) {
+	if name, _ := SplitRef(leaf.Ref); name != OrgRef && len(leaf.Extends) == 0 {
+		leaf.Extends = []string{OrgRef}
+	}
+	chain, err := ancestry(src, leaf, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	eff := &Effective{layers: chain}
+	merged := map[string]any{}
+	var vs []Violation
+	for i, l := range chain {
+		eff.Chain = append(eff.Chain, ChainLink{Layer: l.Ref, Source: l.Source, SHA256: l.SHA256})
+		if i == 0 {
+			merged = rootDoc(l.Doc)
+			continue
+		}
+		var lv []Violation
+		merged, lv = Merge(merged, l.Doc, l.Ref)
+		vs = append(vs, lv...)
+	}
+	if len(vs) > 0 {
+		return nil, &RelaxError{Violations: vs}
+	}
+
+	links := make([]any, len(eff.Chain))
+	for i, c := range eff.Chain {
+		links[i] = map[string]any{"layer": c.Layer, "source": c.Source, "sha256": c.SHA256}
+	}
+	merged["resolved_chain"] = links
+	eff.Policy = merged
+	return eff, nil
+}
+
+// rootDoc copies the root layer as the starting point of the chain. It is
+// not merged onto an empty parent: nothing above it can be relaxed, and
+// Fixed values must survive from the layer allowed to set them.
+func rootDoc(doc map[string]any) map[string]any {
+	out := make(map[string]any, len(doc))
+	for k, v := range doc {
+		if !layerOnlyKeys[k] {
+			out[k] = deepCopy(v)
+		}
+	}
+	return out
+}
+
+// ancestry returns the chain from org down to l. stack holds the refs being
+// resolved, for cycle reporting.
+func ancestry(src Source, l *Layer, stack []string) ([]*Layer, error) {
+	for _, s := range stack {
+		if s == l.Ref {
+			return nil, fmt.Errorf("cyclic extends: %s → %s", strings.Join(stack, " → "), l.Ref)
+		}
+	}
+	stack = append(stack, l.Ref)
+
+	if len(l.Extends) == 0 {
+		if name, _ := SplitRef(l.Ref); name != OrgRef {
+			return nil, fmt.Errorf("extends chain %s does not reach %q", strings.Join(stack, " → "), OrgRef)
+		}
+		return []*Layer{l}, nil
+	}
+
+	var parents [][]*Layer
+	for _, ref := range l.Extends {
+		p, err := src.Load(ref)
+		if err != nil {
+			return nil, fmt.Errorf("%s extends %s: %w", l.Ref, ref, err)
+		}
+		chain, err := ancestry(src, p, stack)
+		if err != nil {
+			return nil, err
+		}
+		parents = append(parents, chain)
+	}
+
+	// Every chain ends at org, so two parents always share an ancestor
+	// (possibly at different pins). Reject instead of guessing which path's
+	// values should win.
+	if len(parents) > 1 {
+		seen := map[string]string{}
+		for i, chain := range parents {
+			for _, a := range chain {
+				name, _ := SplitRef(a.Ref)
+				if via, ok := seen[name]; ok {
+					return nil, fmt.Errorf("diamond extends: %s reaches %s via both %s and %s",
+						l.Ref, name, via, l.Extends[i])
+				}
+				seen[name] = l.Extends[i]
+			}
+		}
+	}
+	return append(parents[0], l), nil
+}
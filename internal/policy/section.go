@@ -0,0 +1,36 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadSection decodes one top-level section of a policy file into dst using
+// its JSON field names. The file may be an effective policy written by
+// `brikgov policy merge` or a YAML layer. A missing section leaves dst
+// untouched so callers can pre-fill defaults.
+func LoadSection(path, name string, dst any) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	doc := map[string]any{}
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	sec, ok := doc[name]
+	if !ok {
+		return nil
+	}
+	buf, err := json.Marshal(sec)
+	if err != nil {
+		return fmt.Errorf("%s: %s: %w", path, name, err)
+	}
+	if err := json.Unmarshal(buf, dst); err != nil {
+		return fmt.Errorf("%s: %s: %w", path, name, err)
+	}
+	return nil
+}
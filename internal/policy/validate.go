@@ -0,0 +1,232 @@
+package policy
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/BrikByte-Studios/github-governance/internal/codes"
+)
+
+// StrictTagPattern is the only release tag pattern allowed in v1.
+const StrictTagPattern = `^v\d+\.\d+\.\d+$`
+
+// semverFields are the release.semver fields of schemas/policy.schema.json,
+// in document order; all are required in the org layer.
+var semverFields = []string{
+	"enabled", "source_of_truth", "tag_prefix", "tag_pattern", "allowed_branches",
+	"enforcement_mode", "idempotency", "tag_type", "initial_version", "prerelease", "guardrails",
+}
+
+var (
+	semverEnums = map[string][]string{
+		"enforcement_mode": {"warn", "block"},
+		"idempotency":      {"fail", "noop"},
+		"tag_type":         {"annotated", "lightweight"},
+	}
+	prereleaseFields = []string{"enabled", "allowed_channels"}
+	guardrailFields  = []string{"require_full_history", "require_checks_passed", "prevent_tag_move"}
+	versionRe        = regexp.MustCompile(StrictTagPattern)
+)
+
+// Validate checks one layer file against the v1 policy schema
+// (schemas/policy.schema.json) and returns coded findings with line
+// numbers. The org layer must declare `version` and a complete
+// release.semver block; other layers may set any subset of it. The error
+// is for documents that are not YAML mappings at all.
+func Validate(file string, raw []byte, org bool) ([]codes.Finding, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	v := validator{file: file}
+	if len(doc.Content) == 0 {
+		if org {
+			v.add("POL-001", 0, "version is required")
+		}
+		return v.fs, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s: policy must be a mapping", file)
+	}
+
+	if _, val := lookup(root, "version"); val == nil {
+		if org {
+			v.add("POL-001", root.Line, "version is required")
+		}
+	} else if n, err := strconv.Atoi(val.Value); val.Tag != "!!int" || err != nil || n < 1 {
+		v.add("POL-001", val.Line, "version must be an integer ≥ 1, got %q", val.Value)
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		k := root.Content[i]
+		if !TopLevelKeys[k.Value] {
+			v.add("POL-002", k.Line, "unknown top-level field %q", k.Value)
+		}
+	}
+	if _, rel := lookup(root, "release"); rel != nil && rel.Kind == yaml.MappingNode {
+		if key, sv := lookup(rel, "semver"); sv != nil {
+			v.semver(key, sv, org)
+		}
+	}
+	codes.Sort(v.fs)
+	return v.fs, nil
+}
+
+type validator struct {
+	file string
+	fs   []codes.Finding
+}
+
+func (v *validator) add(code string, line int, format string, args ...any) {
+	v.fs = append(v.fs, codes.New(code, v.file, line, format, args...))
+}
+
+func (v *validator) semver(key, n *yaml.Node, org bool) {
+	const p = "release.semver"
+	if n.Kind != yaml.MappingNode {
+		v.add("POL-017", n.Line, "%s must be a mapping", p)
+		return
+	}
+	v.fields(p, key, n, semverFields, org)
+
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		name, val := n.Content[i].Value, n.Content[i+1]
+		f := p + "." + name
+		switch name {
+		case "enabled":
+			v.scalar(f, val, "!!bool", "a boolean")
+		case "source_of_truth":
+			if v.scalar(f, val, "!!str", "a string") && val.Value != "git-tags" {
+				v.add("POL-013", val.Line, "%s must be \"git-tags\" in v1, got %q", f, val.Value)
+			}
+		case "tag_prefix":
+			if v.scalar(f, val, "!!str", "a string") && val.Value == "" {
+				v.add("POL-017", val.Line, "%s must not be empty", f)
+			}
+		case "tag_pattern":
+			if v.scalar(f, val, "!!str", "a string") && val.Value != StrictTagPattern {
+				v.add("POL-012", val.Line, "%s must be %q (strict vX.Y.Z) in v1, got %q", f, StrictTagPattern, val.Value)
+			}
+		case "allowed_branches":
+			if v.strings(f, val) && len(val.Content) == 0 {
+				v.add("POL-014", val.Line, "%s must list at least one branch", f)
+			}
+		case "enforcement_mode", "idempotency", "tag_type":
+			allowed := semverEnums[name]
+			if v.scalar(f, val, "!!str", "a string") && !contains(allowed, val.Value) {
+				v.add("POL-016", val.Line, "%s must be one of %s, got %q", f, strings.Join(allowed, ", "), val.Value)
+			}
+		case "initial_version":
+			if v.scalar(f, val, "!!str", "a string") && !versionRe.MatchString(val.Value) {
+				v.add("POL-015", val.Line, "%s must be vX.Y.Z, got %q", f, val.Value)
+			}
+		case "prerelease":
+			if v.mapping(f, val) {
+				v.fields(f, n.Content[i], val, prereleaseFields, false)
+				if _, e := lookup(val, "enabled"); e != nil {
+					v.scalar(f+".enabled", e, "!!bool", "a boolean")
+				} else if org {
+					v.add("POL-011", n.Content[i].Line, "%s.enabled is required", f)
+				}
+				if _, c := lookup(val, "allowed_channels"); c != nil {
+					v.strings(f+".allowed_channels", c)
+				}
+			}
+		case "guardrails":
+			if v.mapping(f, val) {
+				v.fields(f, n.Content[i], val, guardrailFields, org)
+				for j := 0; j+1 < len(val.Content); j += 2 {
+					if contains(guardrailFields, val.Content[j].Value) {
+						v.scalar(f+"."+val.Content[j].Value, val.Content[j+1], "!!bool", "a boolean")
+					}
+				}
+			}
+		}
+	}
+}
+
+// fields reports unknown keys of n (POL-010) and, when required is set,
+// the missing ones (POL-011) at the line of key.
+func (v *validator) fields(p string, key, n *yaml.Node, known []string, required bool) {
+	seen := map[string]bool{}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k := n.Content[i]
+		seen[k.Value] = true
+		if !contains(known, k.Value) {
+			v.add("POL-010", k.Line, "unknown field %s.%s", p, k.Value)
+		}
+	}
+	if !required {
+		return
+	}
+	for _, f := range known {
+		if !seen[f] {
+			v.add("POL-011", key.Line, "%s.%s is required", p, f)
+		}
+	}
+}
+
+func (v *validator) scalar(f string, n *yaml.Node, tag, want string) bool {
+	if n.Kind != yaml.ScalarNode || n.Tag != tag {
+		v.add("POL-017", n.Line, "%s must be %s, got %s", f, want, describe(n))
+		return false
+	}
+	return true
+}
+
+func (v *validator) mapping(f string, n *yaml.Node) bool {
+	if n.Kind != yaml.MappingNode {
+		v.add("POL-017", n.Line, "%s must be a mapping, got %s", f, describe(n))
+		return false
+	}
+	return true
+}
+
+func (v *validator) strings(f string, n *yaml.Node) bool {
+	if n.Kind != yaml.SequenceNode {
+		v.add("POL-017", n.Line, "%s must be a list of strings, got %s", f, describe(n))
+		return false
+	}
+	for _, e := range n.Content {
+		if e.Kind != yaml.ScalarNode || e.Tag != "!!str" || e.Value == "" {
+			v.add("POL-017", e.Line, "%s entries must be non-empty strings, got %s", f, describe(e))
+		}
+	}
+	return true
+}
+
+// lookup returns the key and value nodes of name in mapping n.
+func lookup(n *yaml.Node, name string) (key, val *yaml.Node) {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == name {
+			return n.Content[i], n.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+func describe(n *yaml.Node) string {
+	switch n.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	}
+	if n.Tag == "!!null" {
+		return "null"
+	}
+	return fmt.Sprintf("%q (%s)", n.Value, strings.TrimPrefix(n.Tag, "!!"))
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
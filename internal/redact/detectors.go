@@ -0,0 +1,137 @@
+package redact
+
+import (
+	"net"
+	"regexp"
+	"strings"
+)
+
+// Detector finds one class of sensitive value.
+type Detector struct {
+	// Name is used in placeholders ([EMAIL:…]) and findings.
+	Name string
+	// Pattern matches candidates. When Group > 0 only that capture group is
+	// replaced (e.g. the value of "api_key=…" but not the key name).
+	Pattern *regexp.Regexp
+	Group   int
+	// Valid filters false positives the regexp cannot express (checksums,
+	// address parsing). Nil accepts every match.
+	Valid func(string) bool
+}
+
+// Built-in detector names.
+const (
+	Email      = "email"
+	Phone      = "phone"
+	NationalID = "national_id"
+	JWT        = "jwt"
+	APIKey     = "api_key"
+	IP         = "ip"
+)
+
+// builtins returns the default detectors in priority order: when two matches
+// overlap, the detector listed first wins. Tokens come before emails and
+// phone numbers so a JWT is never half-redacted as digits.
+func builtins() []Detector {
+	return []Detector{
+		{
+			Name:    JWT,
+			Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{5,}\.eyJ[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]{8,}`),
+		},
+		{
+			Name: APIKey,
+			Pattern: regexp.MustCompile(`\b(?:` +
+				`AKIA[0-9A-Z]{16}` + // AWS access key id
+				`|gh[pousr]_[A-Za-z0-9]{36,}` + // GitHub tokens
+				`|github_pat_[A-Za-z0-9_]{40,}` +
+				`|xox[abprs]-[A-Za-z0-9-]{10,}` + // Slack
+				`|(?:sk|rk)_(?:live|test)_[A-Za-z0-9]{16,}` + // Stripe
+				`|AIza[0-9A-Za-z_-]{35}` + // Google API key
+				`)\b`),
+		},
+		{
+			// key=value / "key": "value" assignments of secrets in logs. The
+			// separator is required, so prose ("password authentication") is
+			// left alone.
+			Name:    APIKey,
+			Pattern: regexp.MustCompile(`(?i)\b(?:api[_-]?key|access[_-]?token|auth[_-]?token|client[_-]?secret|secret|password|passwd)["']?\s*[:=]\s*["']?([A-Za-z0-9_\-./+=]{12,})`),
+			Group:   1,
+		},
+		{
+			// "Bearer <token>" credentials; a token has at least one digit,
+			// unlike "Bearer authentication".
+			Name:    APIKey,
+			Pattern: regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9_\-./+=~]{12,})`),
+			Group:   1,
+			Valid:   hasDigit,
+		},
+		{
+			Name:    Email,
+			Pattern: regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`),
+		},
+		{
+			// South African ID (YYMMDD SSSS C A Z, Luhn checked) — POPIA scope.
+			Name:    NationalID,
+			Pattern: regexp.MustCompile(`\b\d{2}(?:0[1-9]|1[0-2])(?:0[1-9]|[12]\d|3[01])\d{4}[01]\d{2}\b`),
+			Valid:   luhn,
+		},
+		{
+			// US SSN.
+			Name:    NationalID,
+			Pattern: regexp.MustCompile(`\b(?:00[1-9]|0[1-9]\d|[1-578]\d{2}|6[0-57-9]\d|66[0-57-9])-(?:0[1-9]|[1-9]\d)-(?:000[1-9]|00[1-9]\d|0[1-9]\d{2}|[1-9]\d{3})\b`),
+		},
+		{
+			Name:    IP,
+			Pattern: regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b|(?i:\b(?:[0-9a-f]{1,4}:){2,7}[0-9a-f]{1,4}\b|\b(?:[0-9a-f]{1,4}:){1,6}:(?:[0-9a-f]{1,4})?\b)`),
+			Valid:   validIP,
+		},
+		{
+			// International (+27 82 123 4567, +1-202-555-0143) and ZA local
+			// (082 123 4567) formats. Requires separators or a leading + so
+			// timestamps and plain counters are not matched.
+			Name:    Phone,
+			Pattern: regexp.MustCompile(`(?:\+\d{1,3}[\s.-]?\(?\d{1,4}\)?(?:[\s.-]?\d{2,4}){2,3}|\b0\d{2}[\s.-]\d{3}[\s.-]\d{4})\b`),
+			Valid:   validPhone,
+		},
+	}
+}
+
+func luhn(s string) bool {
+	sum := 0
+	double := false
+	for i := len(s) - 1; i >= 0; i-- {
+		d := int(s[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+func validIP(s string) bool {
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return false
+	}
+	// Loopback and unspecified addresses carry no information worth hiding.
+	return !ip.IsLoopback() && !ip.IsUnspecified()
+}
+
+func validPhone(s string) bool {
+	digits := 0
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			digits++
+		}
+	}
+	return digits >= 9 && digits <= 15 && !strings.Contains(s, "..")
+}
+
+func hasDigit(s string) bool {
+	return strings.ContainsAny(s, "0123456789")
+}
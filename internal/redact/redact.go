@@ -0,0 +1,261 @@
+// Package redact detects and removes personal data and secrets from issue
+// bodies, logs and evidence files before they are stored or shared.
+//
+// Two replacement modes are supported:
+//
+//   - redact:       every match becomes "[REDACTED:<DETECTOR>]"
+//   - pseudonymize: every match becomes "[<DETECTOR>:<token>]" where token is
+//     an HMAC-SHA256 of the value under a secret key, so the same email or
+//     customer ID maps to the same token across files and runs without the
+//     value being recoverable.
+//
+// Report-only use (Scan) returns findings without touching the input.
+// Findings never contain the matched value, only its position and token.
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Mode selects how matches are replaced.
+type Mode string
+
+const (
+	ModeRedact       Mode = "redact"
+	ModePseudonymize Mode = "pseudonymize"
+)
+
+// DefaultKeyEnv is the environment variable holding the pseudonymization key
+// when the config does not name another one.
+const DefaultKeyEnv = "BRIK_REDACT_KEY"
+
+// Config is the YAML configuration (see examples/redaction/redaction.yml).
+type Config struct {
+	Mode Mode `yaml:"mode"`
+	// KeyEnv names the environment variable with the pseudonymization key.
+	KeyEnv string `yaml:"key_env"`
+	// Disable turns off built-in detectors by name (e.g. ["ip"]).
+	Disable []string `yaml:"disable"`
+	// Custom detectors are evaluated before the built-ins.
+	Custom []CustomPattern `yaml:"custom"`
+	// Allow lists literal values that are never redacted (e.g. the public
+	// security@ address).
+	Allow []string `yaml:"allow"`
+}
+
+// CustomPattern is a user-defined detector.
+type CustomPattern struct {
+	Name    string `yaml:"name"`
+	Pattern string `yaml:"pattern"`
+	Group   int    `yaml:"group"`
+}
+
+// LoadConfig reads a YAML config file.
+func LoadConfig(path string) (Config, error) {
+	var c Config
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return c, err
+	}
+	if err := yaml.Unmarshal(raw, &c); err != nil {
+		return c, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Finding is one detected value. Value is never included.
+type Finding struct {
+	Detector string `json:"detector"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Length   int    `json:"length"`
+	Token    string `json:"token,omitempty"`
+}
+
+// Redactor applies a fixed set of detectors.
+type Redactor struct {
+	mode      Mode
+	key       []byte
+	detectors []Detector
+	allow     map[string]bool
+}
+
+// New builds a Redactor from cfg. Pseudonymization requires a non-empty key,
+// taken from the environment variable named by cfg.KeyEnv.
+func New(cfg Config) (*Redactor, error) {
+	r := &Redactor{mode: cfg.Mode, allow: map[string]bool{}}
+	if r.mode == "" {
+		r.mode = ModeRedact
+	}
+	if r.mode != ModeRedact && r.mode != ModePseudonymize {
+		return nil, fmt.Errorf("redact: unknown mode %q (want redact|pseudonymize)", cfg.Mode)
+	}
+	if r.mode == ModePseudonymize {
+		env := cfg.KeyEnv
+		if env == "" {
+			env = DefaultKeyEnv
+		}
+		r.key = []byte(os.Getenv(env))
+		if len(r.key) == 0 {
+			return nil, fmt.Errorf("redact: pseudonymize mode needs a key in $%s", env)
+		}
+	}
+
+	for _, c := range cfg.Custom {
+		if c.Name == "" || c.Pattern == "" {
+			return nil, fmt.Errorf("redact: custom pattern needs name and pattern")
+		}
+		re, err := regexp.Compile(c.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("redact: custom pattern %q: %w", c.Name, err)
+		}
+		if c.Group < 0 || c.Group > re.NumSubexp() {
+			return nil, fmt.Errorf("redact: custom pattern %q: group %d out of range", c.Name, c.Group)
+		}
+		r.detectors = append(r.detectors, Detector{Name: c.Name, Pattern: re, Group: c.Group})
+	}
+
+	disabled := map[string]bool{}
+	for _, d := range cfg.Disable {
+		disabled[d] = true
+	}
+	for _, d := range builtins() {
+		if !disabled[d.Name] {
+			r.detectors = append(r.detectors, d)
+		}
+	}
+	for _, a := range cfg.Allow {
+		r.allow[a] = true
+	}
+	return r, nil
+}
+
+type match struct {
+	start, end int
+	prio       int
+	detector   string
+}
+
+// matches returns non-overlapping matches ordered by position. Overlaps are
+// resolved in favour of the earlier detector, then the longer match.
+func (r *Redactor) matches(text string) []match {
+	var all []match
+	for prio, d := range r.detectors {
+		for _, loc := range d.Pattern.FindAllStringSubmatchIndex(text, -1) {
+			s, e := loc[2*d.Group], loc[2*d.Group+1]
+			if s < 0 {
+				continue
+			}
+			v := text[s:e]
+			if r.allow[v] || (d.Valid != nil && !d.Valid(v)) {
+				continue
+			}
+			all = append(all, match{start: s, end: e, prio: prio, detector: d.Name})
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		if all[i].prio != all[j].prio {
+			return all[i].prio < all[j].prio
+		}
+		return all[i].end-all[i].start > all[j].end-all[j].start
+	})
+
+	var kept []match
+	for _, m := range all {
+		overlaps := false
+		for _, k := range kept {
+			if m.start < k.end && k.start < m.end {
+				overlaps = true
+				break
+			}
+		}
+		if !overlaps {
+			kept = append(kept, m)
+		}
+	}
+	sort.Slice(kept, func(i, j int) bool { return kept[i].start < kept[j].start })
+	return kept
+}
+
+// Token returns the deterministic pseudonym for value under detector. It is
+// empty in redact mode.
+func (r *Redactor) Token(detector, value string) string {
+	if r.mode != ModePseudonymize {
+		return ""
+	}
+	mac := hmac.New(sha256.New, r.key)
+	mac.Write([]byte(detector))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))[:12]
+}
+
+func (r *Redactor) placeholder(detector, token string) string {
+	name := strings.ToUpper(detector)
+	if token == "" {
+		return "[REDACTED:" + name + "]"
+	}
+	return "[" + name + ":" + token + "]"
+}
+
+// Redact returns text with every finding replaced, plus the findings.
+func (r *Redactor) Redact(text string) (string, []Finding) {
+	ms := r.matches(text)
+	if len(ms) == 0 {
+		return text, nil
+	}
+	var (
+		b    strings.Builder
+		last int
+	)
+	findings := make([]Finding, 0, len(ms))
+	for _, m := range ms {
+		tok := r.Token(m.detector, text[m.start:m.end])
+		b.WriteString(text[last:m.start])
+		b.WriteString(r.placeholder(m.detector, tok))
+		last = m.end
+		findings = append(findings, r.finding(text, m, tok))
+	}
+	b.WriteString(text[last:])
+	return b.String(), findings
+}
+
+// Scan reports findings without modifying anything (report-only mode).
+func (r *Redactor) Scan(text string) []Finding {
+	ms := r.matches(text)
+	findings := make([]Finding, 0, len(ms))
+	for _, m := range ms {
+		findings = append(findings, r.finding(text, m, r.Token(m.detector, text[m.start:m.end])))
+	}
+	return findings
+}
+
+// RedactBytes implements the audit store's sanitizer hook.
+func (r *Redactor) RedactBytes(b []byte) ([]byte, int) {
+	out, f := r.Redact(string(b))
+	return []byte(out), len(f)
+}
+
+func (r *Redactor) finding(text string, m match, token string) Finding {
+	line := 1 + strings.Count(text[:m.start], "\n")
+	col := m.start - strings.LastIndex(text[:m.start], "\n")
+	return Finding{Detector: m.detector, Line: line, Column: col, Length: m.end - m.start, Token: token}
+}
+
+// Summary counts findings per detector.
+func Summary(findings []Finding) map[string]int {
+	out := map[string]int{}
+	for _, f := range findings {
+		out[f.Detector]++
+	}
+	return out
+}
@@ -0,0 +1,352 @@
+// Package reviewmetrics measures how long pull requests wait for review.
+//
+// For each PR it computes the time from ready-for-review to the first
+// review and to the required approvals, and the number of review rounds
+// (one, plus one per "changes requested"). Results are broken down per
+// repository, per owning team and per CODEOWNERS path, and PRs that exceed
+// reviews.sla are reported as breaches so the owning teams can be
+// notified.
+package reviewmetrics
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BrikByte-Studios/github-governance/internal/codeowners"
+	"github.com/BrikByte-Studios/github-governance/internal/ghexport"
+	"github.com/BrikByte-Studios/github-governance/internal/reviews"
+)
+
+// Breach kinds.
+const (
+	BreachFirstReview = "first_review"
+	BreachApproval    = "approval"
+)
+
+// Input is everything the analyzer needs.
+type Input struct {
+	PRs       []ghexport.PullRequest
+	Timelines ghexport.Timelines
+	// Owners maps "owner/repo" to its CODEOWNERS; the "" entry is used for
+	// PRs whose repo has no entry of its own.
+	Owners  map[string]*codeowners.File
+	Reviews *reviews.Policy
+	Now     time.Time
+}
+
+// PRMetrics is the review latency of one PR. Durations are hours.
+type PRMetrics struct {
+	Repo              string     `json:"repo"`
+	Number            int        `json:"number"`
+	Title             string     `json:"title"`
+	URL               string     `json:"url,omitempty"`
+	Author            string     `json:"author"`
+	Open              bool       `json:"open"`
+	ReadyAt           time.Time  `json:"ready_at"`
+	FirstReviewAt     *time.Time `json:"first_review_at,omitempty"`
+	ApprovedAt        *time.Time `json:"approved_at,omitempty"`
+	FirstReviewHours  *float64   `json:"first_review_hours,omitempty"`
+	ApprovalHours     *float64   `json:"approval_hours,omitempty"`
+	RequiredApprovals int        `json:"required_approvals"`
+	Rounds            int        `json:"rounds"`
+	Teams             []string   `json:"teams"`
+	Owners            []string   `json:"owners"`
+	Paths             []string   `json:"paths"`
+	Requested         []string   `json:"requested_reviewers,omitempty"`
+	Breaches          []Breach   `json:"breaches,omitempty"`
+}
+
+// Breach is a PR that missed an SLA target. Waiting PRs are still open and
+// past the target; the others met it late.
+type Breach struct {
+	Kind        string  `json:"kind"`
+	TargetHours float64 `json:"target_hours"`
+	Hours       float64 `json:"hours"`
+	Waiting     bool    `json:"waiting"`
+}
+
+// Stats summarises a set of durations (hours).
+type Stats struct {
+	Count int     `json:"count"`
+	P50   float64 `json:"p50"`
+	P90   float64 `json:"p90"`
+	Mean  float64 `json:"mean"`
+	Max   float64 `json:"max"`
+}
+
+// Group aggregates the PRs sharing a repo, team or path.
+type Group struct {
+	Key         string  `json:"key"`
+	PRs         int     `json:"prs"`
+	FirstReview Stats   `json:"first_review"`
+	Approval    Stats   `json:"approval"`
+	RoundsMean  float64 `json:"rounds_mean"`
+	Breaches    int     `json:"breaches"`
+	Waiting     int     `json:"waiting"`
+	Numbers     []int   `json:"pr_numbers"`
+}
+
+// Report is the analyzer output.
+type Report struct {
+	GeneratedAt string       `json:"generated_at"`
+	SLA         *reviews.SLA `json:"sla,omitempty"`
+	Totals      Group        `json:"totals"`
+	Repos       []Group      `json:"repos"`
+	Teams       []Group      `json:"teams"`
+	Paths       []Group      `json:"paths"`
+	PRs         []PRMetrics  `json:"prs"`
+}
+
+// Unowned is the team and path key for files no CODEOWNERS line owns.
+const Unowned = "(unowned)"
+
+// Analyze computes the report. Draft PRs that never became ready are
+// skipped.
+func Analyze(in Input) *Report {
+	rep := &Report{GeneratedAt: in.Now.UTC().Format(time.RFC3339), PRs: []PRMetrics{}}
+	if in.Reviews != nil {
+		rep.SLA = in.Reviews.SLA
+	}
+	for _, pr := range in.PRs {
+		m, ok := measure(pr, in)
+		if !ok {
+			continue
+		}
+		rep.PRs = append(rep.PRs, m)
+	}
+	sort.Slice(rep.PRs, func(i, j int) bool {
+		a, b := rep.PRs[i], rep.PRs[j]
+		if a.Repo != b.Repo {
+			return a.Repo < b.Repo
+		}
+		return a.Number < b.Number
+	})
+
+	rep.Totals = aggregate("all", rep.PRs)
+	rep.Repos = groupBy(rep.PRs, func(m PRMetrics) []string { return []string{m.Repo} })
+	rep.Teams = groupBy(rep.PRs, func(m PRMetrics) []string { return m.Teams })
+	rep.Paths = groupBy(rep.PRs, func(m PRMetrics) []string { return m.Paths })
+	return rep
+}
+
+func measure(pr ghexport.PullRequest, in Input) (PRMetrics, bool) {
+	repo := pr.Repo()
+	events := in.Timelines.For(repo, pr.Number)
+	ready, ok := readyAt(pr, events)
+	if !ok {
+		return PRMetrics{}, false
+	}
+	m := PRMetrics{
+		Repo: repo, Number: pr.Number, Title: pr.Title, URL: pr.URL, Author: pr.Author.Login,
+		Open: pr.Open(), ReadyAt: ready, RequiredApprovals: 1,
+		Teams: []string{}, Paths: []string{},
+	}
+	if m.Repo == "" {
+		m.Repo = "(local)"
+	}
+	if in.Reviews != nil {
+		if n := in.Reviews.For(pr.BaseRefName).RequiredApprovals; n > 0 {
+			m.RequiredApprovals = n
+		}
+	}
+
+	revs := append([]ghexport.Review{}, pr.Reviews...)
+	sort.SliceStable(revs, func(i, j int) bool { return revs[i].SubmittedAt.Before(revs[j].SubmittedAt) })
+	approvers := map[string]bool{}
+	for _, r := range revs {
+		login := strings.ToLower(r.Author.Login)
+		if login == "" || strings.EqualFold(login, pr.Author.Login) || r.SubmittedAt.Before(ready) {
+			continue
+		}
+		state := strings.ToUpper(r.State)
+		if state == "PENDING" {
+			continue
+		}
+		if m.FirstReviewAt == nil {
+			at := r.SubmittedAt.UTC()
+			m.FirstReviewAt = &at
+			m.Rounds = 1
+		}
+		switch state {
+		case "CHANGES_REQUESTED":
+			m.Rounds++
+			delete(approvers, login)
+		case "APPROVED":
+			approvers[login] = true
+			if m.ApprovedAt == nil && len(approvers) >= m.RequiredApprovals {
+				at := r.SubmittedAt.UTC()
+				m.ApprovedAt = &at
+			}
+		}
+	}
+	if m.FirstReviewAt != nil {
+		m.FirstReviewHours = hours(m.FirstReviewAt.Sub(ready))
+	}
+	if m.ApprovedAt != nil {
+		m.ApprovalHours = hours(m.ApprovedAt.Sub(ready))
+	}
+	for _, r := range pr.ReviewRequests {
+		if r.Login != "" {
+			m.Requested = append(m.Requested, r.Login)
+		}
+	}
+
+	owners := in.Owners[repo]
+	if owners == nil {
+		owners = in.Owners[""]
+	}
+	teams, handles, paths := map[string]bool{}, map[string]bool{}, map[string]bool{}
+	for _, f := range pr.Files {
+		var rule *codeowners.Rule
+		if owners != nil {
+			rule = owners.Match(f.Path)
+		}
+		if rule == nil || len(rule.Owners) == 0 {
+			paths[Unowned] = true
+			teams[Unowned] = true
+			continue
+		}
+		paths[rule.Pattern] = true
+		for _, o := range rule.Owners {
+			teams[codeowners.TeamSlug(o)] = true
+			handles[o] = true
+		}
+	}
+	m.Teams, m.Owners, m.Paths = keys(teams), keys(handles), keys(paths)
+
+	if in.Reviews != nil && in.Reviews.SLA != nil {
+		sla := in.Reviews.SLA
+		m.check(BreachFirstReview, sla.FirstReviewHours, m.FirstReviewAt, in.Now)
+		m.check(BreachApproval, sla.ApprovalHours, m.ApprovedAt, in.Now)
+	}
+	return m, true
+}
+
+// check records a breach of target for the milestone reached at done (nil:
+// not reached). Closed PRs that never reached it are not waiting on anyone.
+func (m *PRMetrics) check(kind string, target float64, done *time.Time, now time.Time) {
+	if target <= 0 {
+		return
+	}
+	switch {
+	case done != nil:
+		if h := *hours(done.Sub(m.ReadyAt)); h > target {
+			m.Breaches = append(m.Breaches, Breach{Kind: kind, TargetHours: target, Hours: h})
+		}
+	case m.Open:
+		if h := *hours(now.Sub(m.ReadyAt)); h > target {
+			m.Breaches = append(m.Breaches, Breach{Kind: kind, TargetHours: target, Hours: h, Waiting: true})
+		}
+	}
+}
+
+// readyAt is when the PR became ready for review: the last
+// ready_for_review event before the first review (drafts can be reviewed
+// early; those reviews do not count), else creation. Drafts are never
+// ready.
+func readyAt(pr ghexport.PullRequest, events []ghexport.Event) (time.Time, bool) {
+	if pr.IsDraft {
+		return time.Time{}, false
+	}
+	var firstReview time.Time
+	for _, r := range pr.Reviews {
+		if !strings.EqualFold(r.Author.Login, pr.Author.Login) && (firstReview.IsZero() || r.SubmittedAt.Before(firstReview)) {
+			firstReview = r.SubmittedAt
+		}
+	}
+	ready := pr.CreatedAt
+	for _, e := range events {
+		at := e.At()
+		if e.Event != "ready_for_review" || at.Before(ready) {
+			continue
+		}
+		if firstReview.IsZero() || at.Before(firstReview) {
+			ready = at
+		}
+	}
+	return ready.UTC(), !ready.IsZero()
+}
+
+func groupBy(prs []PRMetrics, keysOf func(PRMetrics) []string) []Group {
+	by := map[string][]PRMetrics{}
+	for _, m := range prs {
+		for _, k := range keysOf(m) {
+			by[k] = append(by[k], m)
+		}
+	}
+	out := make([]Group, 0, len(by))
+	for k, ms := range by {
+		out = append(out, aggregate(k, ms))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+func aggregate(key string, prs []PRMetrics) Group {
+	g := Group{Key: key, PRs: len(prs), Numbers: []int{}}
+	var first, approval []float64
+	rounds, reviewed := 0, 0
+	for _, m := range prs {
+		g.Numbers = append(g.Numbers, m.Number)
+		if m.FirstReviewHours != nil {
+			first = append(first, *m.FirstReviewHours)
+			rounds += m.Rounds
+			reviewed++
+		}
+		if m.ApprovalHours != nil {
+			approval = append(approval, *m.ApprovalHours)
+		}
+		if len(m.Breaches) > 0 {
+			g.Breaches++
+		}
+		for _, b := range m.Breaches {
+			if b.Waiting {
+				g.Waiting++
+				break
+			}
+		}
+	}
+	g.FirstReview, g.Approval = stats(first), stats(approval)
+	if reviewed > 0 {
+		g.RoundsMean = round1(float64(rounds) / float64(reviewed))
+	}
+	return g
+}
+
+// stats uses nearest-rank percentiles.
+func stats(xs []float64) Stats {
+	if len(xs) == 0 {
+		return Stats{}
+	}
+	sort.Float64s(xs)
+	sum := 0.0
+	for _, x := range xs {
+		sum += x
+	}
+	rank := func(p float64) float64 {
+		i := int(math.Ceil(p*float64(len(xs)))) - 1
+		if i < 0 {
+			i = 0
+		}
+		return xs[i]
+	}
+	return Stats{Count: len(xs), P50: rank(0.5), P90: rank(0.9), Mean: round1(sum / float64(len(xs))), Max: xs[len(xs)-1]}
+}
+
+func hours(d time.Duration) *float64 {
+	h := round1(d.Hours())
+	return &h
+}
+
+func round1(f float64) float64 { return math.Round(f*10) / 10 }
+
+func keys(m map[string]bool) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,129 @@
+package reviewmetrics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TeamReport renders the per-team Markdown summary.
+func TeamReport(rep *Report) string {
+	var b strings.Builder
+	b.WriteString("## Review latency by team\n\n")
+	if rep.SLA != nil {
+		fmt.Fprintf(&b, "SLA: first review within %gh, approval within %gh of ready-for-review.\n\n",
+			rep.SLA.FirstReviewHours, rep.SLA.ApprovalHours)
+	}
+	table := func(title string, groups []Group) {
+		fmt.Fprintf(&b, "| %s | PRs | First review p50 / p90 | Approval p50 / p90 | Rounds | Breaches | Waiting |\n", title)
+		b.WriteString("|---|---|---|---|---|---|---|\n")
+		for _, g := range groups {
+			fmt.Fprintf(&b, "| %s | %d | %s | %s | %.1f | %d | %d |\n",
+				g.Key, g.PRs, pair(g.FirstReview), pair(g.Approval), g.RoundsMean, g.Breaches, g.Waiting)
+		}
+		b.WriteString("\n")
+	}
+	table("Team", rep.Teams)
+	if len(rep.Repos) > 1 {
+		b.WriteString("### By repository\n\n")
+		table("Repository", rep.Repos)
+	}
+	b.WriteString("### By CODEOWNERS path\n\n")
+	table("Path", rep.Paths)
+	fmt.Fprintf(&b, "_%d PRs; generated %s._\n", rep.Totals.PRs, rep.GeneratedAt)
+	return b.String()
+}
+
+func pair(s Stats) string {
+	if s.Count == 0 {
+		return "—"
+	}
+	return fmt.Sprintf("%gh / %gh", s.P50, s.P90)
+}
+
+// Notification is the alert for one team's PRs waiting past the SLA.
+type Notification struct {
+	Team    string      `json:"team"`
+	Mention []string    `json:"mention"`
+	PRs     []PRMetrics `json:"prs"`
+}
+
+// Notifications groups open PRs waiting past the SLA by owning team.
+func Notifications(rep *Report) []Notification {
+	by := map[string]*Notification{}
+	for _, m := range rep.PRs {
+		if !waiting(m) {
+			continue
+		}
+		for _, owner := range m.Owners {
+			team := strings.ToLower(owner)
+			n := by[team]
+			if n == nil {
+				n = &Notification{Team: team, Mention: []string{owner}}
+				by[team] = n
+			}
+			n.PRs = append(n.PRs, m)
+		}
+		if len(m.Owners) == 0 {
+			n := by[Unowned]
+			if n == nil {
+				n = &Notification{Team: Unowned}
+				by[Unowned] = n
+			}
+			n.PRs = append(n.PRs, m)
+		}
+	}
+	out := make([]Notification, 0, len(by))
+	for _, n := range by {
+		out = append(out, *n)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Team < out[j].Team })
+	return out
+}
+
+// NotificationMarkdown renders the notifications, or "" when nothing is
+// waiting past the SLA.
+func NotificationMarkdown(ns []Notification) string {
+	if len(ns) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("## ⏰ Pull requests waiting past the review SLA\n\n")
+	for _, n := range ns {
+		fmt.Fprintf(&b, "### %s\n\n", strings.Join(orTeam(n), " "))
+		b.WriteString("| PR | Waiting for | Ready for | Target | Requested |\n")
+		b.WriteString("|----|-------------|-----------|--------|-----------|\n")
+		for _, m := range n.PRs {
+			for _, br := range m.Breaches {
+				if !br.Waiting {
+					continue
+				}
+				req := "—"
+				if len(m.Requested) > 0 {
+					req = "@" + strings.Join(m.Requested, " @")
+				}
+				fmt.Fprintf(&b, "| %s#%d %s | %s | %gh | %gh | %s |\n",
+					m.Repo, m.Number, m.Title, strings.ReplaceAll(br.Kind, "_", " "), br.Hours, br.TargetHours, req)
+			}
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("Pick a reviewer with `brikgov reviewers --pr <n>` or re-request review.\n")
+	return b.String()
+}
+
+func orTeam(n Notification) []string {
+	if len(n.Mention) == 0 {
+		return []string{n.Team}
+	}
+	return n.Mention
+}
+
+func waiting(m PRMetrics) bool {
+	for _, b := range m.Breaches {
+		if b.Waiting {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package reviewmetrics
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BrikByte-Studios/github-governance/internal/codeowners"
+	"github.com/BrikByte-Studios/github-governance/internal/ghexport"
+	"github.com/BrikByte-Studios/github-governance/internal/reviews"
+)
+
+var t0 = time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC) // Monday
+
+func at(h float64) time.Time { return t0.Add(time.Duration(h * float64(time.Hour))) }
+
+func review(login, state string, h float64) ghexport.Review {
+	return ghexport.Review{Author: ghexport.Actor{Login: login}, State: state, SubmittedAt: at(h)}
+}
+
+func pr(repo string, n int, files []string, revs ...ghexport.Review) ghexport.PullRequest {
+	p := ghexport.PullRequest{
+		Number: n, Title: "change", URL: "https://github.com/" + repo + "/pull/" + strconv.Itoa(n),
+		State: "MERGED", Author: ghexport.Actor{Login: "carol"}, BaseRefName: "main",
+		CreatedAt: t0, Reviews: revs,
+	}
+	for _, f := range files {
+		p.Files = append(p.Files, ghexport.File{Path: f})
+	}
+	return p
+}
+
+func input(t *testing.T) Input {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), "effective.json")
+	_ = os.WriteFile(p, []byte(`{"reviews": {"required_approvals": 2, "sla": {"first_review_hours": 24, "approval_hours": 72}}}`), 0o644)
+	rp, err := reviews.LoadPolicy(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	owners, _ := codeowners.Parse("*  @Org/platform\n/services/payments/**  @Org/payments\n/docs/**\n")
+	waitingPR := pr("org/api", 4, []string{"services/payments/refund.go"})
+	waitingPR.State, waitingPR.ReviewRequests = "OPEN", []ghexport.Actor{{Login: "dave"}}
+	draft := pr("org/api", 5, []string{"README.md"})
+	draft.State, draft.IsDraft = "OPEN", true
+	return Input{
+		PRs: []ghexport.PullRequest{
+			// Ready at +2h (was a draft); reviewed 4h later; approved by two at +10h.
+			pr("org/api", 1, []string{"services/payments/refund.go", "README.md"},
+				review("carol", "COMMENTED", 3), review("dave", "COMMENTED", 6),
+				review("erin", "APPROVED", 8), review("dave", "APPROVED", 12)),
+			// Two rounds of changes requested, approvals at 80h (late).
+			pr("org/api", 2, []string{"services/payments/ledger.go"},
+				review("dave", "CHANGES_REQUESTED", 30), review("dave", "CHANGES_REQUESTED", 50),
+				review("dave", "APPROVED", 70), review("erin", "APPROVED", 80)),
+			pr("org/web", 3, []string{"docs/a.md"}, review("erin", "APPROVED", 1), review("dave", "APPROVED", 1)),
+			waitingPR, draft,
+		},
+		Timelines: ghexport.Timelines{
+			"org/api#1": {{Event: "convert_to_draft", CreatedAt: at(0.5)}, {Event: "ready_for_review", CreatedAt: at(2)}},
+		},
+		Owners:  map[string]*codeowners.File{"": owners},
+		Reviews: rp,
+		Now:     at(100),
+	}
+}
+
+func find(rep *Report, repo string, n int) PRMetrics {
+	for _, m := range rep.PRs {
+		if m.Repo == repo && m.Number == n {
+			return m
+		}
+	}
+	return PRMetrics{}
+}
+
+func TestAnalyze(t *testing.T) {
+	rep := Analyze(input(t))
+	if len(rep.PRs) != 4 {
+		t.Fatalf("PRs = %d, drafts must be skipped", len(rep.PRs))
+	}
+	m := find(rep, "org/api", 1)
+	if *m.FirstReviewHours != 4 || *m.ApprovalHours != 10 || m.Rounds != 1 || len(m.Breaches) != 0 {
+		t.Fatalf("#1 = %+v", m)
+	}
+	if strings.Join(m.Teams, ",") != "payments,platform" || len(m.Paths) != 2 {
+		t.Fatalf("#1 ownership = %v %v", m.Teams, m.Paths)
+	}
+	m = find(rep, "org/api", 2)
+	if m.Rounds != 3 || *m.ApprovalHours != 80 || len(m.Breaches) != 2 || m.Breaches[1].Kind != BreachApproval || m.Breaches[1].Waiting {
+		t.Fatalf("#2 = %+v", m)
+	}
+	m = find(rep, "org/web", 3)
+	if strings.Join(m.Teams, ",") != Unowned {
+		t.Fatalf("#3 teams = %v", m.Teams)
+	}
+	m = find(rep, "org/api", 4)
+	if m.FirstReviewAt != nil || len(m.Breaches) != 2 || !m.Breaches[0].Waiting || m.Breaches[0].Hours != 100 {
+		t.Fatalf("#4 = %+v", m)
+	}
+
+	if len(rep.Repos) != 2 || rep.Repos[0].Key != "org/api" || rep.Repos[0].PRs != 3 {
+		t.Fatalf("repos = %+v", rep.Repos)
+	}
+	var payments Group
+	for _, g := range rep.Teams {
+		if g.Key == "payments" {
+			payments = g
+		}
+	}
+	if payments.PRs != 3 || payments.FirstReview.Count != 2 || payments.FirstReview.P50 != 4 || payments.FirstReview.P90 != 30 ||
+		payments.RoundsMean != 2 || payments.Breaches != 2 || payments.Waiting != 1 {
+		t.Fatalf("payments = %+v", payments)
+	}
+}
+
+func TestReportsAndNotifications(t *testing.T) {
+	rep := Analyze(input(t))
+	md := TeamReport(rep)
+	for _, want := range []string{"| payments | 3 | 4h / 30h |", "### By repository", "| /services/payments/** |"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("team report missing %q:\n%s", want, md)
+		}
+	}
+	ns := Notifications(rep)
+	if len(ns) != 1 || ns[0].Mention[0] != "@Org/payments" || ns[0].PRs[0].Number != 4 {
+		t.Fatalf("notifications = %+v", ns)
+	}
+	out := NotificationMarkdown(ns)
+	if !strings.Contains(out, "| org/api#4 change | first review | 100h | 24h | @dave |") {
+		t.Fatalf("notification:\n%s", out)
+	}
+	if NotificationMarkdown(nil) != "" {
+		t.Fatal("no notification expected")
+	}
+}
@@ -0,0 +1,349 @@
+package reviews
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BrikByte-Studios/github-governance/internal/codeowners"
+	"github.com/BrikByte-Studios/github-governance/internal/ghexport"
+	"github.com/BrikByte-Studios/github-governance/internal/gitrepo"
+	"github.com/BrikByte-Studios/github-governance/internal/people"
+)
+
+// Signals are the inputs the recommender weighs.
+type Signals struct {
+	Owners   *codeowners.File
+	People   *people.Directory
+	Absences people.Absences
+	// History is recent commits with their files (gitrepo.LogFiles).
+	History []gitrepo.Commit
+	// Open is the open PRs, for each person's pending review requests.
+	Open []ghexport.PullRequest
+	Now  time.Time
+	// MaxLoad caps pending review requests per person; people at the cap
+	// are only suggested when nobody else can fill a slot. 0 disables it.
+	MaxLoad int
+}
+
+// Ownership groups a PR's changed files by the CODEOWNERS line owning them.
+type Ownership struct {
+	Pattern string   `json:"pattern"`
+	Owners  []string `json:"owners"`
+	Files   []string `json:"files"`
+}
+
+// Suggestion is one recommended reviewer.
+type Suggestion struct {
+	Person    string   `json:"person"`
+	Teams     []string `json:"teams"`
+	Expertise int      `json:"expertise"`
+	Load      int      `json:"load"`
+	Score     float64  `json:"score"`
+	Reasons   []string `json:"reasons"`
+}
+
+// Skipped is a candidate that was not suggested.
+type Skipped struct {
+	Person string `json:"person"`
+	Reason string `json:"reason"`
+}
+
+// Recommendation is the reviewer suggestion for one PR.
+type Recommendation struct {
+	PR          int          `json:"pr_number"`
+	Base        string       `json:"base"`
+	Requirement Requirement  `json:"requirement"`
+	Ownership   []Ownership  `json:"ownership"`
+	Approved    []string     `json:"approved"`
+	Reviewers   []Suggestion `json:"reviewers"`
+	Unavailable []Skipped    `json:"unavailable"`
+	// Unmet lists slots no available candidate could fill.
+	Unmet []string `json:"unmet,omitempty"`
+}
+
+// candidate is a person under consideration.
+type candidate struct {
+	Suggestion
+	capped bool
+}
+
+// Recommend picks individual reviewers for pr. It first fills each
+// required role not yet approved, then makes sure every CODEOWNERS line
+// touched by the PR has an owner among the reviewers, then tops up to
+// required_approvals. Within each slot candidates are ranked by expertise
+// (recent commits to the changed paths) divided by pending review load;
+// authors, existing approvers and people out of office are never picked.
+func Recommend(pr ghexport.PullRequest, req Requirement, s Signals) *Recommendation {
+	rec := &Recommendation{
+		PR: pr.Number, Base: pr.BaseRefName, Requirement: req,
+		Ownership: []Ownership{}, Approved: []string{}, Reviewers: []Suggestion{}, Unavailable: []Skipped{},
+	}
+	dir := s.People
+
+	authors := map[string]bool{}
+	for _, p := range dir.Resolve(pr.Author.Login) {
+		authors[p] = true
+	}
+	for _, c := range pr.Commits {
+		for _, a := range c.Authors {
+			for _, p := range dir.Resolve(firstNonEmpty(a.Login, a.Email)) {
+				authors[p] = true
+			}
+		}
+	}
+	approved := map[string]bool{}
+	for _, login := range pr.Approvers() {
+		for _, p := range dir.Resolve(login) {
+			if !authors[p] && !approved[p] {
+				approved[p] = true
+				rec.Approved = append(rec.Approved, p)
+			}
+		}
+	}
+	sort.Strings(rec.Approved)
+
+	// Ownership and the candidate pool.
+	var files []string
+	for _, f := range pr.Files {
+		files = append(files, f.Path)
+	}
+	sort.Strings(files)
+	if s.Owners != nil {
+		byLine := map[int]*Ownership{}
+		var lines []int
+		for _, f := range files {
+			r := s.Owners.Match(f)
+			if r == nil || len(r.Owners) == 0 {
+				continue
+			}
+			o, ok := byLine[r.Line]
+			if !ok {
+				o = &Ownership{Pattern: r.Pattern, Owners: r.Owners}
+				byLine[r.Line] = o
+				lines = append(lines, r.Line)
+			}
+			o.Files = append(o.Files, f)
+		}
+		sort.Ints(lines)
+		for _, l := range lines {
+			rec.Ownership = append(rec.Ownership, *byLine[l])
+		}
+	}
+	pool := map[string]bool{}
+	for _, o := range rec.Ownership {
+		for _, owner := range o.Owners {
+			for _, p := range members(dir, owner) {
+				pool[p] = true
+			}
+		}
+	}
+	for _, role := range req.RequiredRoles {
+		for _, p := range dir.Members(role) {
+			pool[p] = true
+		}
+	}
+
+	expertise := expertiseFor(files, s.History, dir)
+	load := loadFor(pr.Number, s.Open, dir)
+	cands := map[string]*candidate{}
+	for _, p := range sortedKeys(pool) {
+		if authors[p] || approved[p] {
+			continue
+		}
+		if a := s.Absences.Away(p, s.Now); a != nil {
+			reason := "out of office until " + a.Until
+			if a.Note != "" {
+				reason += " (" + a.Note + ")"
+			}
+			rec.Unavailable = append(rec.Unavailable, Skipped{Person: p, Reason: reason})
+			continue
+		}
+		c := &candidate{Suggestion: Suggestion{
+			Person: p, Teams: append([]string{}, dir.Teams(p)...), Expertise: expertise[p], Load: load[p],
+		}}
+		c.Score = float64(c.Expertise+1) / float64(c.Load+1)
+		c.capped = s.MaxLoad > 0 && c.Load >= s.MaxLoad
+		cands[p] = c
+	}
+
+	picked := map[string]*Suggestion{}
+	var order []string
+	pick := func(eligible func(string) bool, reason string) bool {
+		var best *candidate
+		for _, capped := range []bool{false, true} {
+			for _, c := range cands {
+				if picked[c.Person] != nil || c.capped != capped || !eligible(c.Person) {
+					continue
+				}
+				if best == nil || better(c, best) {
+					best = c
+				}
+			}
+			if best != nil {
+				break
+			}
+		}
+		if best == nil {
+			return false
+		}
+		sg := best.Suggestion
+		sg.Reasons = []string{reason}
+		if best.capped {
+			sg.Reasons = append(sg.Reasons, fmt.Sprintf("at the review load cap (%d) but nobody else is available", s.MaxLoad))
+		}
+		picked[sg.Person] = &sg
+		order = append(order, sg.Person)
+		return true
+	}
+	covered := func(eligible func(string) bool) bool {
+		for p := range approved {
+			if eligible(p) {
+				return true
+			}
+		}
+		for p := range picked {
+			if eligible(p) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, role := range req.RequiredRoles {
+		inRole := func(p string) bool { return dir.InTeam(p, role) }
+		if covered(inRole) {
+			continue
+		}
+		if !pick(inRole, "required role "+role) {
+			rec.Unmet = append(rec.Unmet, "no available member of required role "+role)
+		}
+	}
+	for _, o := range rec.Ownership {
+		owners := o.Owners
+		owns := func(p string) bool {
+			for _, owner := range owners {
+				if contains(members(dir, owner), p) {
+					return true
+				}
+			}
+			return false
+		}
+		if covered(owns) {
+			continue
+		}
+		if !pick(owns, fmt.Sprintf("code owner of %s (%s)", o.Pattern, strings.Join(o.Owners, " "))) {
+			rec.Unmet = append(rec.Unmet, "no available code owner for "+o.Pattern)
+		}
+	}
+	anyone := func(string) bool { return true }
+	for len(rec.Approved)+len(picked) < req.RequiredApprovals {
+		if !pick(anyone, "additional approval") {
+			rec.Unmet = append(rec.Unmet, fmt.Sprintf("%d of %d approvals can be covered", len(rec.Approved)+len(picked), req.RequiredApprovals))
+			break
+		}
+	}
+
+	for _, p := range order {
+		sg := picked[p]
+		if sg.Expertise > 0 {
+			sg.Reasons = append(sg.Reasons, fmt.Sprintf("expertise %d (recent commits to the changed paths)", sg.Expertise))
+		}
+		sg.Reasons = append(sg.Reasons, fmt.Sprintf("%d pending review request(s)", sg.Load))
+		rec.Reviewers = append(rec.Reviewers, *sg)
+	}
+	return rec
+}
+
+// better orders candidates: higher score, then lower load, then name.
+func better(a, b *candidate) bool {
+	if a.Score != b.Score {
+		return a.Score > b.Score
+	}
+	if a.Load != b.Load {
+		return a.Load < b.Load
+	}
+	return a.Person < b.Person
+}
+
+// expertiseFor weighs each person's recent commits: 2 per commit touching a
+// changed file, 1 per commit touching only a sibling in the same directory.
+func expertiseFor(files []string, history []gitrepo.Commit, dir *people.Directory) map[string]int {
+	changed, dirs := map[string]bool{}, map[string]bool{}
+	for _, f := range files {
+		changed[f] = true
+		dirs[path.Dir(f)] = true
+	}
+	out := map[string]int{}
+	for _, c := range history {
+		w := 0
+		for _, f := range c.Files {
+			if changed[f] {
+				w = 2
+				break
+			}
+			if dirs[path.Dir(f)] {
+				w = 1
+			}
+		}
+		if w == 0 {
+			continue
+		}
+		for _, p := range dir.Resolve(c.AuthorEmail) {
+			out[p] += w
+		}
+	}
+	return out
+}
+
+// loadFor counts pending review requests per person across open PRs other
+// than self.
+func loadFor(self int, open []ghexport.PullRequest, dir *people.Directory) map[string]int {
+	out := map[string]int{}
+	for _, pr := range open {
+		if pr.Number == self || !pr.Open() {
+			continue
+		}
+		for _, r := range pr.ReviewRequests {
+			for _, p := range dir.Resolve(r.Login) {
+				out[p]++
+			}
+		}
+	}
+	return out
+}
+
+// members resolves a CODEOWNERS owner (@org/team, @user or email) to people.
+func members(dir *people.Directory, owner string) []string {
+	if codeowners.IsTeam(owner) {
+		return dir.Members(owner)
+	}
+	return dir.Resolve(owner)
+}
+
+func firstNonEmpty(a, b string) string {
+	if a != "" {
+		return a
+	}
+	return b
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]bool) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,231 @@
+// Package reviews evaluates pull request approvals against the reviews
+// section of the effective policy.
+//
+// Requirements are layered: the top-level reviews values, then
+// reviews.default, then the most specific reviews.branches entry matching
+// the target branch ("main", "hotfix/*"). Approvals by the PR author never
+// count, and each user counts once.
+//
+// Evidence uses the reviews.json shape consumed by the gate:
+//
+//	{"branch": "hotfix/urgent-123", "pr_number": 108, "author": "carol",
+//	 "approvals": [{"user": "alice", "teams": ["platform-leads"]}],
+//	 "code_owner_approved": true}
+package reviews
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/BrikByte-Studios/github-governance/internal/policy"
+)
+
+// Results, matching decision.reviews.result.
+const (
+	ResultPass = "pass"
+	ResultFail = "fail"
+)
+
+// Scope is one level of review requirements. Nil fields inherit.
+type Scope struct {
+	RequiredApprovals      *int     `json:"required_approvals,omitempty"`
+	RequireCodeOwnerReview *bool    `json:"require_code_owner_review,omitempty"`
+	RequiredRoles          []string `json:"required_roles,omitempty"`
+}
+
+// Policy is the reviews section of the effective policy.
+type Policy struct {
+	Scope
+	AdditionalReviewerTeams []string         `json:"additional_reviewer_teams,omitempty"`
+	Default                 *Scope           `json:"default,omitempty"`
+	Branches                map[string]Scope `json:"branches,omitempty"`
+	SLA                     *SLA             `json:"sla,omitempty"`
+}
+
+// SLA is reviews.sla: the review latency targets in wall-clock hours from
+// ready-for-review. Zero disables a target.
+type SLA struct {
+	FirstReviewHours float64 `json:"first_review_hours"`
+	ApprovalHours    float64 `json:"approval_hours"`
+}
+
+// LoadPolicy reads the reviews section from an effective policy file.
+func LoadPolicy(path string) (*Policy, error) {
+	p := &Policy{}
+	if err := policy.LoadSection(path, "reviews", p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Requirement is the resolved requirement for one branch.
+type Requirement struct {
+	RequiredApprovals      int      `json:"required_approvals"`
+	RequireCodeOwnerReview bool     `json:"require_code_owner_review"`
+	RequiredRoles          []string `json:"required_roles"`
+	// Source names the most specific scope applied ("reviews",
+	// "reviews.default", "reviews.branches.hotfix/*").
+	Source string `json:"source"`
+}
+
+// For resolves the requirement for branch.
+func (p *Policy) For(branch string) Requirement {
+	r := Requirement{RequiredRoles: []string{}, Source: "reviews"}
+	r.apply(p.Scope, "reviews")
+	if p.Default != nil {
+		r.apply(*p.Default, "reviews.default")
+	}
+	if pat, ok := p.matchBranch(branch); ok {
+		r.apply(p.Branches[pat], "reviews.branches."+pat)
+	}
+	return r
+}
+
+func (r *Requirement) apply(s Scope, source string) {
+	if s.RequiredApprovals != nil {
+		r.RequiredApprovals = *s.RequiredApprovals
+		r.Source = source
+	}
+	if s.RequireCodeOwnerReview != nil {
+		r.RequireCodeOwnerReview = *s.RequireCodeOwnerReview
+		r.Source = source
+	}
+	if len(s.RequiredRoles) > 0 {
+		r.RequiredRoles = unionStrings(r.RequiredRoles, s.RequiredRoles)
+		r.Source = source
+	}
+}
+
+// matchBranch returns the branches key for branch: an exact key wins,
+// otherwise the longest matching glob.
+func (p *Policy) matchBranch(branch string) (string, bool) {
+	if _, ok := p.Branches[branch]; ok {
+		return branch, true
+	}
+	best := ""
+	for pat := range p.Branches {
+		if ok, _ := path.Match(pat, branch); ok && len(pat) > len(best) {
+			best = pat
+		}
+	}
+	return best, best != ""
+}
+
+// MatchBranch reports whether branch matches any of the glob patterns.
+func MatchBranch(patterns []string, branch string) bool {
+	for _, pat := range patterns {
+		if ok, _ := path.Match(pat, branch); ok || pat == branch {
+			return true
+		}
+	}
+	return false
+}
+
+// Approval is one approving review.
+type Approval struct {
+	User              string   `json:"user"`
+	Teams             []string `json:"teams,omitempty"`
+	AuthorAssociation string   `json:"author_association,omitempty"`
+}
+
+// Evidence describes the reviews on one pull request.
+type Evidence struct {
+	Branch            string     `json:"branch"`
+	PRNumber          int        `json:"pr_number"`
+	Author            string     `json:"author,omitempty"`
+	Body              string     `json:"body,omitempty"`
+	Approvals         []Approval `json:"approvals"`
+	CodeOwnerApproved bool       `json:"code_owner_approved"`
+}
+
+// LoadEvidence reads a reviews.json file.
+func LoadEvidence(path string) (*Evidence, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var ev Evidence
+	if err := json.Unmarshal(raw, &ev); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &ev, nil
+}
+
+// Result is decision.reviews.
+type Result struct {
+	Result       string      `json:"result"`
+	Requirement  Requirement `json:"requirement"`
+	Approvals    int         `json:"approvals"`
+	Approvers    []string    `json:"approvers"`
+	MissingRoles []string    `json:"missing_roles,omitempty"`
+	Reasons      []string    `json:"reasons,omitempty"`
+}
+
+// Evaluate checks ev against req.
+func Evaluate(req Requirement, ev Evidence) Result {
+	res := Result{Requirement: req, Approvers: []string{}}
+	teams := map[string]bool{}
+	seen := map[string]bool{}
+	for _, a := range ev.Approvals {
+		u := strings.ToLower(a.User)
+		if u == "" || seen[u] {
+			continue
+		}
+		if ev.Author != "" && strings.EqualFold(a.User, ev.Author) {
+			res.Reasons = append(res.Reasons, fmt.Sprintf("approval by PR author %s ignored", a.User))
+			continue
+		}
+		seen[u] = true
+		res.Approvers = append(res.Approvers, a.User)
+		for _, t := range a.Teams {
+			teams[strings.ToLower(t)] = true
+		}
+	}
+	sort.Strings(res.Approvers)
+	res.Approvals = len(res.Approvers)
+
+	fail := false
+	if res.Approvals < req.RequiredApprovals {
+		fail = true
+		res.Reasons = append(res.Reasons, fmt.Sprintf("%d approval(s), %d required", res.Approvals, req.RequiredApprovals))
+	}
+	if req.RequireCodeOwnerReview && !ev.CodeOwnerApproved {
+		fail = true
+		res.Reasons = append(res.Reasons, "code owner approval required")
+	}
+	for _, role := range req.RequiredRoles {
+		if !teams[strings.ToLower(role)] {
+			res.MissingRoles = append(res.MissingRoles, role)
+		}
+	}
+	if len(res.MissingRoles) > 0 {
+		fail = true
+		res.Reasons = append(res.Reasons, "missing approval from: "+strings.Join(res.MissingRoles, ", "))
+	}
+	res.Result = ResultPass
+	if fail {
+		res.Result = ResultFail
+	}
+	return res
+}
+
+func unionStrings(a, b []string) []string {
+	out := append([]string{}, a...)
+	for _, s := range b {
+		found := false
+		for _, x := range out {
+			if x == s {
+				found = true
+				break
+			}
+		}
+		if !found {
+			out = append(out, s)
+		}
+	}
+	return out
+}
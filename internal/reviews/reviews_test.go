@@ -0,0 +1,205 @@
+package reviews
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BrikByte-Studios/github-governance/internal/codeowners"
+	"github.com/BrikByte-Studios/github-governance/internal/ghexport"
+	"github.com/BrikByte-Studios/github-governance/internal/gitrepo"
+	"github.com/BrikByte-Studios/github-governance/internal/people"
+)
+
+// policyJSON mirrors the fixtures of tests/policy/reviews-*.js.
+const policyJSON = `{
+  "policy_version": "1.0.0",
+  "reviews": {
+    "required_approvals": 2,
+    "require_code_owner_review": true,
+    "default": {"required_approvals": 2, "require_code_owner_review": true, "required_roles": []},
+    "branches": {
+      "main": {"required_approvals": 2, "require_code_owner_review": true},
+      "hotfix/*": {"required_approvals": 2, "required_roles": ["platform-leads"]}
+    }
+  }
+}`
+
+func load(t *testing.T) *Policy {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), "effective-policy.json")
+	if err := os.WriteFile(p, []byte(policyJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	pol, err := LoadPolicy(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pol
+}
+
+func TestEvaluate(t *testing.T) {
+	pol := load(t)
+	cases := []struct {
+		name    string
+		ev      Evidence
+		want    string
+		missing []string
+	}{
+		{"happy path", Evidence{Branch: "main", Approvals: []Approval{
+			{User: "alice", Teams: []string{"platform-leads"}}, {User: "bob", Teams: []string{"backend-team"}},
+		}, CodeOwnerApproved: true}, ResultPass, nil},
+		{"main insufficient", Evidence{Branch: "main", Approvals: []Approval{
+			{User: "alice", Teams: []string{"platform-leads"}},
+		}, CodeOwnerApproved: true}, ResultFail, nil},
+		{"hotfix missing platform-leads", Evidence{Branch: "hotfix/urgent-123", Approvals: []Approval{
+			{User: "alice", Teams: []string{"backend-team"}}, {User: "bob", Teams: []string{"frontend-team"}},
+		}, CodeOwnerApproved: true}, ResultFail, []string{"platform-leads"}},
+		{"self and duplicate approvals ignored", Evidence{Branch: "main", Author: "alice", Approvals: []Approval{
+			{User: "alice"}, {User: "bob"}, {User: "Bob"},
+		}, CodeOwnerApproved: true}, ResultFail, nil},
+	}
+	for _, c := range cases {
+		res := Evaluate(pol.For(c.ev.Branch), c.ev)
+		if res.Result != c.want || !reflect.DeepEqual(res.MissingRoles, c.missing) {
+			t.Errorf("%s: result = %s missing = %v (%v)", c.name, res.Result, res.MissingRoles, res.Reasons)
+		}
+	}
+}
+
+func TestRequirementPrecedence(t *testing.T) {
+	pol := load(t)
+	req := pol.For("hotfix/x")
+	if req.Source != "reviews.branches.hotfix/*" || !req.RequireCodeOwnerReview || req.RequiredRoles[0] != "platform-leads" {
+		t.Errorf("hotfix requirement = %+v", req)
+	}
+	if req := pol.For("feature/x"); req.Source != "reviews.default" || req.RequiredApprovals != 2 {
+		t.Errorf("default requirement = %+v", req)
+	}
+}
+
+const recommendFixtures = `
+teams:
+  payments: ["@carol", "@dave", "@frank"]
+  platform-leads: ["@alice", "@bob"]
+`
+
+const ooo = `
+out_of_office:
+  - person: frank
+    from: 2026-10-12
+    until: 2026-10-20
+    note: parental leave
+`
+
+func recommendSignals(t *testing.T) Signals {
+	t.Helper()
+	owners, err := codeowners.Parse(`
+*                     @Org/platform-leads
+/services/payments/** @Org/payments
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := t.TempDir()
+	_ = os.WriteFile(filepath.Join(d, "people.yml"), []byte(recommendFixtures), 0o644)
+	_ = os.WriteFile(filepath.Join(d, "ooo.yml"), []byte(ooo), 0o644)
+	dir, err := people.Load(filepath.Join(d, "people.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	abs, err := people.LoadAbsences(filepath.Join(d, "ooo.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit := func(email string, files ...string) gitrepo.Commit {
+		return gitrepo.Commit{AuthorEmail: email, Files: files}
+	}
+	requested := func(n int, logins ...string) ghexport.PullRequest {
+		pr := ghexport.PullRequest{Number: n, State: "OPEN"}
+		for _, l := range logins {
+			pr.ReviewRequests = append(pr.ReviewRequests, ghexport.Actor{Login: l})
+		}
+		return pr
+	}
+	return Signals{
+		Owners: owners, People: dir, Absences: abs,
+		History: []gitrepo.Commit{
+			commit("dave@x.io", "services/payments/refund.go"),
+			commit("dave@x.io", "services/payments/refund.go"),
+			commit("frank@x.io", "services/payments/refund.go"),
+			commit("frank@x.io", "services/payments/refund.go"),
+			commit("frank@x.io", "services/payments/refund.go"),
+			commit("bob@x.io", "services/payments/ledger.go"),
+			commit("alice@x.io", "docs/x.md"),
+		},
+		Open: []ghexport.PullRequest{
+			requested(1, "dave", "alice"), requested(2, "dave"), requested(3, "alice"),
+		},
+		Now: time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestRecommend(t *testing.T) {
+	s := recommendSignals(t)
+	pr := ghexport.PullRequest{
+		Number: 42, State: "OPEN", BaseRefName: "hotfix/refunds", Author: ghexport.Actor{Login: "carol"},
+		Files: []ghexport.File{{Path: "services/payments/refund.go"}, {Path: "README.md"}},
+	}
+	req := load(t).For(pr.BaseRefName)
+	rec := Recommend(pr, req, s)
+
+	var got []string
+	for _, r := range rec.Reviewers {
+		got = append(got, r.Person+":"+r.Reasons[0])
+	}
+	// bob: platform lead with ledger.go expertise and no load beats alice;
+	// frank is away, dave (expertise 4, load 2) is the payments owner; bob
+	// already covers the catch-all line.
+	want := []string{
+		"bob:required role platform-leads",
+		"dave:code owner of /services/payments/** (@Org/payments)",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("reviewers = %v, want %v", got, want)
+	}
+	if len(rec.Unavailable) != 1 || rec.Unavailable[0].Person != "frank" || !strings.Contains(rec.Unavailable[0].Reason, "parental leave") {
+		t.Fatalf("unavailable = %+v", rec.Unavailable)
+	}
+	if len(rec.Ownership) != 2 || rec.Ownership[1].Pattern != "/services/payments/**" || len(rec.Unmet) != 0 {
+		t.Fatalf("recommendation = %+v", rec)
+	}
+}
+
+func TestRecommendLoadCapAndExistingApprovals(t *testing.T) {
+	s := recommendSignals(t)
+	s.MaxLoad = 1
+	s.Now = time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC) // frank is back
+	pr := ghexport.PullRequest{
+		Number: 43, State: "OPEN", BaseRefName: "main", Author: ghexport.Actor{Login: "carol"},
+		Files:   []ghexport.File{{Path: "services/payments/refund.go"}},
+		Reviews: []ghexport.Review{{Author: ghexport.Actor{Login: "carol"}, State: "APPROVED"}},
+	}
+	rec := Recommend(pr, Requirement{RequiredApprovals: 2}, s)
+	var got []string
+	for _, r := range rec.Reviewers {
+		got = append(got, r.Person)
+	}
+	// frank has the most expertise; dave is over the cap so bob (platform
+	// lead, not an owner here) is not considered and the author's own
+	// approval does not count.
+	if !reflect.DeepEqual(got, []string{"frank", "dave"}) || len(rec.Approved) != 0 {
+		t.Fatalf("reviewers = %v approved = %v", got, rec.Approved)
+	}
+	if !strings.Contains(strings.Join(rec.Reviewers[1].Reasons, "; "), "load cap") {
+		t.Fatalf("reasons = %v", rec.Reviewers[1].Reasons)
+	}
+
+	rec = Recommend(pr, Requirement{RequiredApprovals: 4}, s)
+	if len(rec.Unmet) != 1 {
+		t.Fatalf("unmet = %v", rec.Unmet)
+	}
+}
@@ -0,0 +1,165 @@
+// Package signing verifies commit signatures in a local clone against the
+// org's allowed-signers file.
+//
+// The file maps org members to the keys they sign with:
+//
+//	gpg_keyring: keys/              # armored public keys (*.asc), relative to this file
+//	members:
+//	  alice:
+//	    emails: ["alice@brikbyte.io"]
+//	    ssh_keys: ["ssh-ed25519 AAAAC3Nza... alice@laptop"]
+//	    gpg_fingerprints: ["3AA5C34371567BD2..."]
+//	web_flow_fingerprints: []       # extra GitHub web-flow keys (GHES)
+//
+// SSH keys are written to a temporary git allowed_signers file and GPG keys
+// are imported into a temporary keyring, so the verification never depends
+// on (or changes) the runner's own trust stores.
+package signing
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultPath is where the org keeps its allowed signers.
+const DefaultPath = ".governance/signing/allowed-signers.yml"
+
+// Member lists the identities and keys of one org member.
+type Member struct {
+	Emails          []string `yaml:"emails"`
+	SSHKeys         []string `yaml:"ssh_keys"`
+	GPGFingerprints []string `yaml:"gpg_fingerprints"`
+}
+
+// Signers is a loaded allowed-signers file.
+type Signers struct {
+	Path string `yaml:"-"`
+	// GPGKeyring is a directory of armored public keys, relative to Path.
+	GPGKeyring string            `yaml:"gpg_keyring"`
+	Members    map[string]Member `yaml:"members"`
+	// WebFlowFingerprints are web-flow signing keys trusted in addition to
+	// the pinned WebFlowFingerprints, e.g. a GitHub Enterprise Server's.
+	WebFlowFingerprints []string `yaml:"web_flow_fingerprints"`
+}
+
+// Load reads an allowed-signers file.
+func Load(path string) (*Signers, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &Signers{Path: path}
+	if err := yaml.Unmarshal(raw, s); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for id, m := range s.Members {
+		for _, k := range m.SSHKeys {
+			if len(strings.Fields(k)) < 2 {
+				return nil, fmt.Errorf("%s: members.%s.ssh_keys: %q is not \"<type> <base64> [comment]\"", path, id, k)
+			}
+		}
+	}
+	return s, nil
+}
+
+// KeyringFiles returns the armored keys in the GPG keyring directory.
+func (s *Signers) KeyringFiles() ([]string, error) {
+	if s.GPGKeyring == "" {
+		return nil, nil
+	}
+	dir := s.GPGKeyring
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(filepath.Dir(s.Path), dir)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.asc"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// AllowedSigners renders the SSH keys in git's gpg.ssh.allowedSignersFile
+// format. The principal is the member id, so a verified signature names
+// the member directly.
+func (s *Signers) AllowedSigners() string {
+	var b strings.Builder
+	for _, id := range s.ids() {
+		for _, k := range s.Members[id].SSHKeys {
+			f := strings.Fields(k)
+			fmt.Fprintf(&b, "%s namespaces=\"git\" %s %s\n", id, f[0], f[1])
+		}
+	}
+	return b.String()
+}
+
+// ByFingerprint returns the member owning a GPG key fingerprint, or "".
+func (s *Signers) ByFingerprint(fprs ...string) string {
+	for _, id := range s.ids() {
+		for _, want := range s.Members[id].GPGFingerprints {
+			for _, f := range fprs {
+				if f != "" && normFingerprint(f) == normFingerprint(want) {
+					return id
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// IsWebFlowKey reports whether one of fprs is a GitHub web-flow key,
+// pinned or listed.
+func (s *Signers) IsWebFlowKey(fprs ...string) bool {
+	for _, want := range append(append([]string{}, WebFlowFingerprints...), s.WebFlowFingerprints...) {
+		for _, f := range fprs {
+			if f != "" && normFingerprint(f) == normFingerprint(want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isWebFlowKeyID reports whether the 16-digit key id git prints for a
+// signature it could not check (%GK) ends one of the web-flow fingerprints.
+// It only names the missing key; it never exempts a commit.
+func (s *Signers) isWebFlowKeyID(id string) bool {
+	if len(id) != 16 {
+		return false
+	}
+	for _, want := range append(append([]string{}, WebFlowFingerprints...), s.WebFlowFingerprints...) {
+		if strings.HasSuffix(normFingerprint(want), normFingerprint(id)) {
+			return true
+		}
+	}
+	return false
+}
+
+// OwnsEmail reports whether the member lists email. Members without
+// emails own none.
+func (s *Signers) OwnsEmail(member, email string) bool {
+	for _, e := range s.Members[member].Emails {
+		if strings.EqualFold(e, email) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Signers) ids() []string {
+	ids := make([]string, 0, len(s.Members))
+	for id := range s.Members {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func normFingerprint(f string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimPrefix(f, "0x"), " ", ""))
+}
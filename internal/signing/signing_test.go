@@ -0,0 +1,217 @@
+package signing
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type fixture struct {
+	t    *testing.T
+	dir  string
+	home string
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	for _, tool := range []string{"git", "ssh-keygen"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skip(tool + " not available")
+		}
+	}
+	f := &fixture{t: t, dir: t.TempDir(), home: t.TempDir()}
+	f.git(nil, "init", "-q", "-b", "main")
+	return f
+}
+
+func (f *fixture) run(env []string, name string, args ...string) string {
+	f.t.Helper()
+	cmd := exec.Command(name, args...)
+	cmd.Env = append(os.Environ(), "GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_SYSTEM=/dev/null", "GNUPGHOME="+filepath.Join(f.home, "gnupg"))
+	cmd.Env = append(cmd.Env, env...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		f.t.Fatalf("%s %v: %v\n%s", name, args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func (f *fixture) git(env []string, args ...string) string {
+	return f.run(env, "git", append([]string{"-C", f.dir}, args...)...)
+}
+
+// sshKey generates a key pair and returns the private key path and the
+// public key line.
+func (f *fixture) sshKey(name string) (string, string) {
+	p := filepath.Join(f.home, name)
+	f.run(nil, "ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", name, "-f", p)
+	pub, err := os.ReadFile(p + ".pub")
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	return p, strings.TrimSpace(string(pub))
+}
+
+// commit creates a commit as name <email>, signed with sign ("" unsigned).
+func (f *fixture) commit(name, email, msg string, sign ...string) string {
+	env := []string{
+		"GIT_AUTHOR_NAME=" + name, "GIT_AUTHOR_EMAIL=" + email,
+		"GIT_COMMITTER_NAME=" + name, "GIT_COMMITTER_EMAIL=" + email,
+	}
+	_ = os.WriteFile(filepath.Join(f.dir, "f.txt"), []byte(msg), 0o644)
+	f.git(env, "add", "f.txt")
+	args := append(sign, "commit", "-q", "-m", msg)
+	f.git(env, args...)
+	return f.git(nil, "rev-parse", "HEAD")
+}
+
+func ssh(key string) []string {
+	return []string{"-c", "gpg.format=ssh", "-c", "user.signingkey=" + key, "-c", "commit.gpgsign=true"}
+}
+
+func TestVerifySSH(t *testing.T) {
+	f := newFixture(t)
+	aliceKey, alicePub := f.sshKey("alice")
+	strangerKey, _ := f.sshKey("stranger")
+
+	base := f.commit("Alice", "alice@brikbyte.io", "initial")
+	want := map[string]string{
+		f.commit("Alice", "alice@brikbyte.io", "signed", ssh(aliceKey)...):                StatusVerified,
+		f.commit("Alice", "alice@brikbyte.io", "unsigned"):                                StatusUnsigned,
+		f.commit("Mallory", "mallory@example.com", "stranger", ssh(strangerKey)...):       StatusUnknownKey,
+		f.commit("Bob", "bob@brikbyte.io", "borrowed key", ssh(aliceKey)...):              StatusSignerMismatch,
+		f.commit(WebFlowName, WebFlowEmail, "Merge pull request #7", ssh(strangerKey)...): StatusUnknownKey,
+		f.commit(WebFlowName, WebFlowEmail, "forged web-flow"):                            StatusUnsigned,
+	}
+
+	signersFile := filepath.Join(f.home, "allowed-signers.yml")
+	doc := "members:\n  alice:\n    emails: [alice@brikbyte.io]\n    ssh_keys: [\"" + alicePub + "\"]\n"
+	if err := os.WriteFile(signersFile, []byte(doc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Load(signersFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep, err := Verify(context.Background(), f.dir, base+"..HEAD", s, Options{ExemptWebFlow: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.Commits) != len(want) {
+		t.Fatalf("verified %d commits, want %d", len(rep.Commits), len(want))
+	}
+	for _, c := range rep.Commits {
+		if c.Status != want[c.SHA] {
+			t.Errorf("%s: status %s (%s), want %s", c.Subject, c.Status, c.Detail, want[c.SHA])
+		}
+		if c.Status == StatusVerified && c.Signer != "alice" {
+			t.Errorf("%s: signer %q", c.Subject, c.Signer)
+		}
+	}
+	if got := len(rep.Problems()); got != 5 {
+		t.Errorf("Problems = %d, want 5", got)
+	}
+	if rep.Problems()[0].Subject != "unsigned" {
+		t.Errorf("Problems not oldest first: %+v", rep.Problems()[0])
+	}
+
+	rep, err = Verify(context.Background(), f.dir, base+"..HEAD", s, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Counts[StatusExempt] != 0 || rep.Counts[StatusUnknownKey] != 2 {
+		t.Errorf("without exemption: %s", rep.Summary())
+	}
+}
+
+func TestVerifyGPG(t *testing.T) {
+	f := newFixture(t)
+	if _, err := exec.LookPath("gpg"); err != nil {
+		t.Skip("gpg not available")
+	}
+	if err := os.Mkdir(filepath.Join(f.home, "gnupg"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	f.run(nil, "gpg", "--batch", "--passphrase", "", "--quick-gen-key", "Bob <bob@brikbyte.io>", "ed25519", "sign", "never")
+	fpr := ""
+	for _, line := range strings.Split(f.run(nil, "gpg", "--with-colons", "--fingerprint", "bob@brikbyte.io"), "\n") {
+		if strings.HasPrefix(line, "fpr:") && fpr == "" {
+			fpr = strings.Split(line, ":")[9]
+		}
+	}
+	keyring := filepath.Join(f.home, "keys")
+	if err := os.Mkdir(keyring, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	armored := f.run(nil, "gpg", "--armor", "--export", fpr)
+	if err := os.WriteFile(filepath.Join(keyring, "bob.asc"), []byte(armored+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	base := f.commit("Bob", "bob@brikbyte.io", "initial")
+	gpgSign := []string{"-c", "user.signingkey=" + fpr, "-c", "commit.gpgsign=true"}
+	listed := f.commit("Bob", "bob@brikbyte.io", "gpg signed", gpgSign...)
+	merge := f.commit(WebFlowName, WebFlowEmail, "Merge pull request #8", gpgSign...)
+
+	signersFile := filepath.Join(f.home, "allowed-signers.yml")
+	doc := "gpg_keyring: keys\nmembers:\n  bob:\n    emails: [bob@brikbyte.io]\n    gpg_fingerprints: [\"" + fpr + "\"]\n"
+	if err := os.WriteFile(signersFile, []byte(doc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Load(signersFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep, err := Verify(context.Background(), f.dir, base+"..HEAD", s, Options{ExemptWebFlow: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.Commits) != 2 || rep.Commits[1].SHA != listed || rep.Commits[1].Status != StatusVerified || rep.Commits[1].Signer != "bob" {
+		t.Fatalf("GPG: %+v", rep.Commits)
+	}
+	// Bob's key is not a web-flow key, so the web-flow identity does not help.
+	if rep.Commits[0].SHA != merge || rep.Commits[0].Status != StatusSignerMismatch {
+		t.Fatalf("web-flow identity with a member key: %+v", rep.Commits[0])
+	}
+
+	// Listed as a web-flow key, the same signature is exempt.
+	s.WebFlowFingerprints = []string{fpr}
+	rep, err = Verify(context.Background(), f.dir, base+"..HEAD", s, Options{ExemptWebFlow: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Commits[0].Status != StatusExempt {
+		t.Fatalf("listed web-flow key: %+v", rep.Commits[0])
+	}
+	s.WebFlowFingerprints = nil
+
+	// Without the keyring the key cannot be checked.
+	s.GPGKeyring = ""
+	rep, err = Verify(context.Background(), f.dir, base+"..HEAD", s, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Commits[1].Status != StatusUnknownKey || rep.Commits[1].Detail != "signing key not in keyring" {
+		t.Fatalf("GPG without keyring: %+v", rep.Commits[1])
+	}
+	// A web-flow key missing from the keyring is named, and still not exempt.
+	s.WebFlowFingerprints = []string{fpr}
+	rep, err = Verify(context.Background(), f.dir, base+"..HEAD", s, Options{ExemptWebFlow: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := rep.Commits[0]; c.Status != StatusUnknownKey || !strings.Contains(c.Detail, "web-flow signing key not in keyring") {
+		t.Fatalf("web-flow key without keyring: %+v", c)
+	}
+}
+
+func TestLoadRejectsMalformedKey(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "s.yml")
+	_ = os.WriteFile(p, []byte("members:\n  alice:\n    ssh_keys: [\"AAAAC3Nza\"]\n"), 0o644)
+	if _, err := Load(p); err == nil {
+		t.Fatal("want error for a key without type")
+	}
+}
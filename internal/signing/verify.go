@@ -0,0 +1,226 @@
+package signing
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BrikByte-Studios/github-governance/internal/gitrepo"
+)
+
+// Commit statuses.
+const (
+	StatusVerified       = "verified"
+	StatusUnsigned       = "unsigned"
+	StatusUnknownKey     = "unknown_key"
+	StatusBadSignature   = "bad_signature"
+	StatusSignerMismatch = "signer_mismatch"
+	StatusExempt         = "exempt"
+)
+
+// WebFlowName and WebFlowEmail identify commits GitHub creates and signs
+// itself (web UI merges, squashes and edits).
+const (
+	WebFlowName  = "GitHub"
+	WebFlowEmail = "noreply@github.com"
+)
+
+// WebFlowFingerprints are the GPG keys GitHub signs web-flow commits with
+// (https://github.com/web-flow.gpg): the current key and the one it
+// replaced in January 2024, which still signs older history. The committer
+// identity alone is never trusted; the signature must be by one of these
+// keys or one listed under web_flow_fingerprints in the allowed signers.
+var WebFlowFingerprints = []string{
+	"968479A1AFF927E37D1A566BB5690EEEBB952194",
+	"5DE3E0509C47EA3CF04A42D34AEE18F83AFDEB23",
+}
+
+// Options tune Verify.
+type Options struct {
+	// ExemptWebFlow accepts commits committed by GitHub's web-flow identity
+	// and signed by a web-flow key. Unsigned commits, or commits signed by
+	// any other key, claiming that identity are never exempt.
+	ExemptWebFlow bool
+}
+
+// Commit is the verdict for one commit.
+type Commit struct {
+	SHA            string `json:"sha"`
+	Subject        string `json:"subject"`
+	CommitterName  string `json:"committer_name"`
+	CommitterEmail string `json:"committer_email"`
+	Status         string `json:"status"`
+	// Signer is the member the key belongs to.
+	Signer string `json:"signer,omitempty"`
+	Key    string `json:"key,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// OK reports whether the commit passes.
+func (c Commit) OK() bool { return c.Status == StatusVerified || c.Status == StatusExempt }
+
+// Report is the verification of a range.
+type Report struct {
+	Range   string         `json:"range"`
+	Commits []Commit       `json:"commits"`
+	Counts  map[string]int `json:"counts"`
+}
+
+// Problems returns the commits that did not pass, oldest first.
+func (r *Report) Problems() []Commit {
+	var out []Commit
+	for i := len(r.Commits) - 1; i >= 0; i-- {
+		if !r.Commits[i].OK() {
+			out = append(out, r.Commits[i])
+		}
+	}
+	return out
+}
+
+const logFormat = "%H%x1f%G?%x1f%GK%x1f%GF%x1f%GP%x1f%GS%x1f%cn%x1f%ce%x1f%s%x1e"
+
+// Verify checks every commit in rng (e.g. "base..head") of the clone at
+// dir. git itself checks the signatures, against a temporary SSH
+// allowed_signers file and a temporary GPG home holding only the keyring.
+// git and gpg are killed when ctx is done.
+func Verify(ctx context.Context, dir, rng string, s *Signers, opt Options) (*Report, error) {
+	tmp, err := os.MkdirTemp("", "brikgov-signing-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	allowed := filepath.Join(tmp, "allowed_signers")
+	if err := os.WriteFile(allowed, []byte(s.AllowedSigners()), 0o600); err != nil {
+		return nil, err
+	}
+	gnupg := filepath.Join(tmp, "gnupg")
+	if err := os.Mkdir(gnupg, 0o700); err != nil {
+		return nil, err
+	}
+	keys, err := s.KeyringFiles()
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) > 0 {
+		if err := importKeys(ctx, gnupg, keys); err != nil {
+			return nil, err
+		}
+	}
+
+	out, err := gitrepo.RunEnv(ctx, dir, []string{"GNUPGHOME=" + gnupg},
+		"-c", "gpg.ssh.allowedSignersFile="+allowed, "log", "--format="+logFormat, rng)
+	if err != nil {
+		return nil, err
+	}
+	rep := &Report{Range: rng, Commits: []Commit{}, Counts: map[string]int{}}
+	for _, rec := range strings.Split(out, "\x1e") {
+		rec = strings.TrimLeft(rec, "\n")
+		if rec == "" {
+			continue
+		}
+		f := strings.Split(rec, "\x1f")
+		if len(f) != 9 {
+			return nil, fmt.Errorf("git log: unexpected record %q", rec)
+		}
+		c := classify(f, s, opt)
+		rep.Commits = append(rep.Commits, c)
+		rep.Counts[c.Status]++
+	}
+	return rep, nil
+}
+
+// classify maps git's %G? codes to a status:
+//
+//	G  good, trusted (SSH keys are always "trusted" once allowed)
+//	U  good signature, unknown validity (our temporary GPG keyring sets no
+//	   trust, so listed GPG keys land here; SSH keys not in allowed_signers
+//	   also report U with an empty signer)
+//	E  cannot check (key not in the keyring)
+//	N  no signature
+//	B, X, Y, R  bad, expired signature/key or revoked key
+func classify(f []string, s *Signers, opt Options) Commit {
+	code, key, fpr, primary, signer := f[1], f[2], f[3], f[4], f[5]
+	c := Commit{SHA: f[0], Subject: f[8], CommitterName: f[6], CommitterEmail: f[7], Key: or(fpr, key)}
+	webFlow := opt.ExemptWebFlow && c.CommitterName == WebFlowName && strings.EqualFold(c.CommitterEmail, WebFlowEmail)
+
+	switch code {
+	case "N":
+		c.Status = StatusUnsigned
+		return c
+	case "B", "X", "Y", "R":
+		c.Status, c.Detail = StatusBadSignature, badDetail[code]
+		return c
+	case "E":
+		c.Status, c.Detail = StatusUnknownKey, "signing key not in keyring"
+		if webFlow && s.isWebFlowKeyID(key) {
+			c.Detail = "GitHub web-flow signing key not in keyring; add https://github.com/web-flow.gpg to it"
+		}
+		return c
+	}
+	if webFlow && (code == "G" || code == "U") && s.IsWebFlowKey(fpr, primary) {
+		c.Status, c.Detail = StatusExempt, "signed by GitHub web-flow"
+		return c
+	}
+	member := ""
+	switch {
+	case strings.HasPrefix(fpr, "SHA256:"):
+		if _, ok := s.Members[signer]; ok && (code == "G" || code == "U") {
+			member = signer
+		}
+	case code == "G" || code == "U":
+		member = s.ByFingerprint(fpr, primary)
+	}
+	if member == "" {
+		c.Status, c.Detail = StatusUnknownKey, "key is not in the allowed signers"
+		return c
+	}
+	c.Signer = member
+	if len(s.Members[member].Emails) > 0 && !s.OwnsEmail(member, c.CommitterEmail) {
+		c.Status = StatusSignerMismatch
+		c.Detail = fmt.Sprintf("signed by %s's key but committed as %s", member, c.CommitterEmail)
+		return c
+	}
+	c.Status = StatusVerified
+	return c
+}
+
+var badDetail = map[string]string{
+	"B": "bad signature",
+	"X": "signature has expired",
+	"Y": "signing key has expired",
+	"R": "signing key is revoked",
+}
+
+func importKeys(ctx context.Context, home string, files []string) error {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "gpg", append([]string{"--batch", "--quiet", "--import"}, files...)...)
+	cmd.Env = append(os.Environ(), "GNUPGHOME="+home)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("gpg --import: %s", strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// Summary counts the statuses as "unsigned: 2, unknown_key: 1".
+func (r *Report) Summary() string {
+	var parts []string
+	for k, n := range r.Counts {
+		parts = append(parts, fmt.Sprintf("%s: %d", k, n))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ", ")
+}
+
+func or(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,291 @@
+// Package sod checks separation of duties over the commits of a release.
+//
+// For every commit between the previous tag and the release tag it finds the
+// pull request that brought it in and checks:
+//
+//   - sod.self-approval: no author of a PR (PR author or commit author,
+//     resolved through second accounts and shared aliases) approved it;
+//   - sod.releaser-unreviewed: the tagger and the publisher did not author
+//     any commit in the range that lacks an independent approval;
+//   - sod.role-independence: approvals that satisfy reviews required_roles
+//     come from people outside the authoring team(s).
+//
+// Identities are resolved with a people directory, so "carol" approving as
+// "carol-ops" or via the "payments-bot" alias still counts as carol.
+package sod
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/BrikByte-Studios/github-governance/internal/ghexport"
+	"github.com/BrikByte-Studios/github-governance/internal/gitrepo"
+	"github.com/BrikByte-Studios/github-governance/internal/people"
+	"github.com/BrikByte-Studios/github-governance/internal/reviews"
+)
+
+// Rule ids.
+const (
+	RuleSelfApproval       = "sod.self-approval"
+	RuleReleaserUnreviewed = "sod.releaser-unreviewed"
+	RuleRoleIndependence   = "sod.role-independence"
+)
+
+// Results.
+const (
+	ResultPass = "pass"
+	ResultFail = "fail"
+)
+
+// Input is a release range and the evidence around it.
+type Input struct {
+	Tag       *gitrepo.Tag
+	From      string
+	Commits   []gitrepo.Commit
+	PRs       []ghexport.PullRequest
+	Publisher string
+	People    *people.Directory
+	// Reviews supplies required_roles per base branch; nil skips
+	// sod.role-independence.
+	Reviews *reviews.Policy
+}
+
+// Violation is one separation-of-duties breach.
+type Violation struct {
+	Rule string `json:"rule"`
+	// Code is the rule's stable code (SOD-001…SOD-003).
+	Code    string `json:"code"`
+	PR      int    `json:"pr,omitempty"`
+	Commit  string `json:"commit,omitempty"`
+	Person  string `json:"person"`
+	Message string `json:"message"`
+}
+
+// PRSummary records who authored and who independently approved a PR.
+type PRSummary struct {
+	Number        int      `json:"number"`
+	Authors       []string `json:"authors"`
+	AuthoringTeam []string `json:"authoring_teams"`
+	Approvers     []string `json:"approvers"`
+	Independent   []string `json:"independent_approvers"`
+	Commits       int      `json:"commits"`
+}
+
+// Unreviewed is a commit without an independent approval.
+type Unreviewed struct {
+	SHA     string   `json:"sha"`
+	Subject string   `json:"subject"`
+	Authors []string `json:"authors"`
+	PR      int      `json:"pr,omitempty"`
+	Reason  string   `json:"reason"`
+}
+
+// Report is decision.separation_of_duties.
+type Report struct {
+	Result       string       `json:"result"`
+	Tag          string       `json:"tag"`
+	Range        string       `json:"range"`
+	Tagger       []string     `json:"tagger"`
+	Publisher    []string     `json:"publisher"`
+	Commits      int          `json:"commits"`
+	PullRequests []PRSummary  `json:"pull_requests"`
+	Unreviewed   []Unreviewed `json:"unreviewed"`
+	Violations   []Violation  `json:"violations"`
+}
+
+// Evaluate runs the checks.
+func Evaluate(in Input) *Report {
+	rep := &Report{
+		Tag:          in.Tag.Name,
+		Range:        in.From + ".." + in.Tag.Name,
+		Commits:      len(in.Commits),
+		PullRequests: []PRSummary{},
+		Unreviewed:   []Unreviewed{},
+		Violations:   []Violation{},
+	}
+	rep.Tagger = in.People.Resolve(in.Tag.TaggerEmail)
+	if len(rep.Tagger) == 0 {
+		rep.Tagger = in.People.Resolve(in.Tag.TaggerName)
+	}
+	rep.Publisher = in.People.Resolve(in.Publisher)
+
+	byCommit := map[string]*ghexport.PullRequest{}
+	for i := range in.PRs {
+		pr := &in.PRs[i]
+		for _, c := range pr.Commits {
+			byCommit[c.OID] = pr
+		}
+		if pr.MergeCommit != nil && pr.MergeCommit.OID != "" {
+			byCommit[pr.MergeCommit.OID] = pr
+		}
+	}
+
+	summaries := map[int]*PRSummary{}
+	var order []int
+	for _, c := range in.Commits {
+		authors := in.People.Resolve(c.AuthorEmail)
+		pr := byCommit[c.SHA]
+		if pr == nil {
+			rep.Unreviewed = append(rep.Unreviewed, Unreviewed{SHA: c.SHA, Subject: c.Subject, Authors: authors, Reason: "no pull request"})
+			continue
+		}
+		s, seen := summaries[pr.Number]
+		if !seen {
+			s = summarise(pr, in, rep)
+			summaries[pr.Number] = s
+			order = append(order, pr.Number)
+		}
+		s.Commits++
+		if len(s.Independent) == 0 {
+			rep.Unreviewed = append(rep.Unreviewed, Unreviewed{SHA: c.SHA, Subject: c.Subject, Authors: authors, PR: pr.Number, Reason: "no independent approval"})
+		}
+	}
+	sort.Ints(order)
+	for _, n := range order {
+		rep.PullRequests = append(rep.PullRequests, *summaries[n])
+	}
+
+	releasers := map[string]string{}
+	for _, p := range rep.Tagger {
+		releasers[p] = "tagger"
+	}
+	for _, p := range rep.Publisher {
+		if role, ok := releasers[p]; ok {
+			releasers[p] = role + " and publisher"
+		} else {
+			releasers[p] = "publisher"
+		}
+	}
+	for _, u := range rep.Unreviewed {
+		for _, a := range u.Authors {
+			if role, ok := releasers[a]; ok {
+				rep.Violations = append(rep.Violations, Violation{
+					Rule: RuleReleaserUnreviewed, Code: "SOD-002", PR: u.PR, Commit: u.SHA, Person: a,
+					Message: fmt.Sprintf("%s of %s authored %s (%s): %s", role, in.Tag.Name, short(u.SHA), u.Reason, u.Subject),
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(rep.Violations, func(i, j int) bool {
+		a, b := rep.Violations[i], rep.Violations[j]
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+		return a.PR < b.PR
+	})
+	rep.Result = ResultPass
+	if len(rep.Violations) > 0 {
+		rep.Result = ResultFail
+	}
+	return rep
+}
+
+// summarise resolves the authors and approvers of pr and records
+// self-approval and role-independence violations.
+func summarise(pr *ghexport.PullRequest, in Input, rep *Report) *PRSummary {
+	dir := in.People
+	authors := map[string]bool{}
+	for _, p := range dir.Resolve(pr.Author.Login) {
+		authors[p] = true
+	}
+	for _, c := range pr.Commits {
+		for _, a := range c.Authors {
+			id := a.Login
+			if id == "" {
+				id = a.Email
+			}
+			for _, p := range dir.Resolve(id) {
+				authors[p] = true
+			}
+		}
+	}
+	s := &PRSummary{Number: pr.Number, Authors: keys(authors), Approvers: []string{}, Independent: []string{}}
+	teams := map[string]bool{}
+	for a := range authors {
+		for _, t := range dir.Teams(a) {
+			teams[t] = true
+		}
+	}
+	s.AuthoringTeam = keys(teams)
+
+	independent := map[string]bool{}
+	for _, login := range pr.Approvers() {
+		s.Approvers = append(s.Approvers, login)
+		self := ""
+		persons := dir.Resolve(login)
+		for _, p := range persons {
+			if authors[p] {
+				self = p
+			}
+		}
+		if self != "" {
+			via := ""
+			if people.Key(login) != self {
+				via = fmt.Sprintf(" via %s", login)
+			}
+			rep.Violations = append(rep.Violations, Violation{
+				Rule: RuleSelfApproval, Code: "SOD-001", PR: pr.Number, Person: self,
+				Message: fmt.Sprintf("#%d approved by its author %s%s", pr.Number, self, via),
+			})
+			continue
+		}
+		for _, p := range persons {
+			independent[p] = true
+		}
+	}
+	s.Independent = keys(independent)
+
+	if in.Reviews == nil {
+		return s
+	}
+	for _, role := range in.Reviews.For(pr.BaseRefName).RequiredRoles {
+		var inRole, outside []string
+		for _, p := range s.Independent {
+			if !dir.InTeam(p, role) {
+				continue
+			}
+			inRole = append(inRole, p)
+			if !overlaps(dir.Teams(p), teams, role) {
+				outside = append(outside, p)
+			}
+		}
+		// No role approval at all is the reviews gate's failure, not ours.
+		if len(inRole) > 0 && len(outside) == 0 {
+			rep.Violations = append(rep.Violations, Violation{
+				Rule: RuleRoleIndependence, Code: "SOD-003", PR: pr.Number, Person: strings.Join(inRole, ","),
+				Message: fmt.Sprintf("#%d: required role %s approved only by members of the authoring team (%s)",
+					pr.Number, role, strings.Join(s.AuthoringTeam, ", ")),
+			})
+		}
+	}
+	return s
+}
+
+// overlaps reports whether list shares a team with set other than the
+// required role itself: a platform lead may approve another lead's PR.
+func overlaps(list []string, set map[string]bool, role string) bool {
+	for _, x := range list {
+		if set[x] && x != people.TeamKey(role) {
+			return true
+		}
+	}
+	return false
+}
+
+func keys(m map[string]bool) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func short(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -0,0 +1,278 @@
+package sod
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BrikByte-Studios/github-governance/internal/ghexport"
+	"github.com/BrikByte-Studios/github-governance/internal/gitrepo"
+	"github.com/BrikByte-Studios/github-governance/internal/people"
+	"github.com/BrikByte-Studios/github-governance/internal/reviews"
+)
+
+const directory = `
+teams:
+  payments: ["@carol", "@dave"]
+  platform-leads: ["@alice", "@dave"]
+  security: ["@erin"]
+people:
+  carol:
+    accounts: ["carol", "carol-ops"]
+    emails: ["carol@brikbyte.io"]
+  alice:
+    emails: ["alice@brikbyte.io"]
+aliases:
+  payments-bot: ["carol", "dave"]
+`
+
+const effective = `{"reviews": {"required_approvals": 1, "required_roles": ["platform-leads"]}}`
+
+// repo builds a clone with v1.0.0, three commits and an annotated v1.1.0
+// tagged by alice.
+func repo(t *testing.T) (string, []string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	git := func(env []string, args ...string) string {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_SYSTEM=/dev/null")
+		cmd.Env = append(cmd.Env, env...)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	as := func(name, email string) []string {
+		return []string{
+			"GIT_AUTHOR_NAME=" + name, "GIT_AUTHOR_EMAIL=" + email,
+			"GIT_COMMITTER_NAME=" + name, "GIT_COMMITTER_EMAIL=" + email,
+		}
+	}
+	git(nil, "init", "-q", "-b", "main")
+	commit := func(who []string, msg string) string {
+		_ = os.WriteFile(filepath.Join(dir, "f.txt"), []byte(msg), 0o644)
+		git(who, "add", "f.txt")
+		git(who, "commit", "-q", "-m", msg)
+		return git(nil, "rev-parse", "HEAD")
+	}
+	commit(as("Alice", "alice@brikbyte.io"), "initial")
+	git(as("Alice", "alice@brikbyte.io"), "tag", "v1.0.0")
+	shas := []string{
+		commit(as("Carol", "carol@brikbyte.io"), "feat: refunds"),
+		commit(as("Bob", "12345+bob@users.noreply.github.com"), "fix: rounding"),
+		commit(as("Alice", "alice@brikbyte.io"), "chore: bump version"),
+	}
+	git(as("Alice", "alice@brikbyte.io"), "tag", "-a", "v1.1.0", "-m", "v1.1.0")
+	return dir, shas
+}
+
+func approve(login string, at int) ghexport.Review {
+	return ghexport.Review{
+		Author: ghexport.Actor{Login: login}, State: "APPROVED",
+		SubmittedAt: time.Date(2026, 3, 1, at, 0, 0, 0, time.UTC),
+	}
+}
+
+func pr(n int, author, sha string, approvals ...ghexport.Review) ghexport.PullRequest {
+	return ghexport.PullRequest{
+		Number: n, Author: ghexport.Actor{Login: author}, BaseRefName: "main",
+		Commits: []ghexport.PRCommit{{OID: sha, Authors: []ghexport.CommitAuthor{{Login: author}}}},
+		Reviews: approvals,
+	}
+}
+
+func load(t *testing.T) (*people.Directory, *reviews.Policy) {
+	t.Helper()
+	d := t.TempDir()
+	pp := filepath.Join(d, "people.yml")
+	ep := filepath.Join(d, "effective.json")
+	_ = os.WriteFile(pp, []byte(directory), 0o644)
+	_ = os.WriteFile(ep, []byte(effective), 0o644)
+	dir, err := people.Load(pp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rp, err := reviews.LoadPolicy(ep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, rp
+}
+
+func input(t *testing.T) (Input, []string) {
+	t.Helper()
+	dir, shas := repo(t)
+	tag, err := gitrepo.ReadTag(context.Background(), dir, "v1.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	commits, err := gitrepo.Log(context.Background(), dir, "v1.0.0..v1.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pd, rp := load(t)
+	return Input{Tag: tag, From: "v1.0.0", Commits: commits, People: pd, Reviews: rp}, shas
+}
+
+func rules(rep *Report) []string {
+	var out []string
+	for _, v := range rep.Violations {
+		out = append(out, v.Rule+":"+v.Person)
+	}
+	return out
+}
+
+func TestGitRange(t *testing.T) {
+	dir, shas := repo(t)
+	tag, err := gitrepo.ReadTag(context.Background(), dir, "v1.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !tag.Annotated || tag.TaggerEmail != "alice@brikbyte.io" || tag.Commit != shas[2] {
+		t.Fatalf("tag = %+v", tag)
+	}
+	light, err := gitrepo.ReadTag(context.Background(), dir, "v1.0.0")
+	if err != nil || light.Annotated || light.TaggerEmail != "" {
+		t.Fatalf("lightweight tag = %+v, %v", light, err)
+	}
+	commits, err := gitrepo.Log(context.Background(), dir, "v1.0.0..v1.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != 3 || commits[0].SHA != shas[2] || commits[2].Subject != "feat: refunds" {
+		t.Fatalf("commits = %+v", commits)
+	}
+	withFiles, err := gitrepo.LogFiles(context.Background(), dir, "v1.0.0..v1.1.0", "--", "f.txt")
+	if err != nil || len(withFiles) != 3 || len(withFiles[1].Files) != 1 || withFiles[1].Files[0] != "f.txt" || withFiles[1].SHA != shas[1] {
+		t.Fatalf("LogFiles = %+v, %v", withFiles, err)
+	}
+	if _, err := gitrepo.ReadTag(context.Background(), dir, "v9.9.9"); err == nil {
+		t.Fatal("missing tag should fail")
+	}
+}
+
+func TestPeopleResolve(t *testing.T) {
+	d, _ := load(t)
+	cases := map[string]string{
+		"@carol-ops":                         "carol",
+		"carol@brikbyte.io":                  "carol",
+		"12345+bob@users.noreply.github.com": "bob",
+		"payments-bot":                       "carol,dave",
+		"frank":                              "frank",
+	}
+	for in, want := range cases {
+		if got := strings.Join(d.Resolve(in), ","); got != want {
+			t.Errorf("Resolve(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if !d.InTeam("dave", "Platform-Leads") || d.InTeam("carol", "security") {
+		t.Error("team membership")
+	}
+	var none *people.Directory
+	if got := none.Resolve("@Zed"); len(got) != 1 || got[0] != "zed" {
+		t.Errorf("nil directory Resolve = %v", got)
+	}
+}
+
+func TestCleanRelease(t *testing.T) {
+	in, shas := input(t)
+	in.PRs = []ghexport.PullRequest{
+		pr(10, "carol", shas[0], approve("alice", 9)),
+		pr(11, "bob", shas[1], approve("alice", 9)),
+		pr(12, "alice", shas[2], approve("erin", 9), approve("dave", 10)),
+	}
+	rep := Evaluate(in)
+	if rep.Result != ResultPass || len(rep.Unreviewed) != 0 {
+		t.Fatalf("report = %+v", rep)
+	}
+	if strings.Join(rep.Tagger, ",") != "alice" || len(rep.PullRequests) != 3 || rep.Range != "v1.0.0..v1.1.0" {
+		t.Fatalf("report = %+v", rep)
+	}
+}
+
+func TestSelfApprovalViaSecondAccountAndAlias(t *testing.T) {
+	in, shas := input(t)
+	in.PRs = []ghexport.PullRequest{
+		pr(10, "carol", shas[0], approve("carol-ops", 9)),
+		pr(11, "bob", shas[1], approve("alice", 9)),
+		pr(12, "payments-bot", shas[2], approve("dave", 9)),
+	}
+	// alice's commit in #12 is co-authored under the shared alias.
+	in.PRs[2].Commits[0].Authors = append(in.PRs[2].Commits[0].Authors, ghexport.CommitAuthor{Email: "alice@brikbyte.io"})
+	rep := Evaluate(in)
+	got := strings.Join(rules(rep), " ")
+	for _, want := range []string{
+		RuleSelfApproval + ":carol",
+		RuleSelfApproval + ":dave",
+		RuleReleaserUnreviewed + ":alice",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("violations %q missing %q", got, want)
+		}
+	}
+	if rep.Result != ResultFail {
+		t.Fatal("expected fail")
+	}
+	for _, v := range rep.Violations {
+		if v.PR == 10 && !strings.Contains(v.Message, "via carol-ops") {
+			t.Errorf("message = %q, want the second account named", v.Message)
+		}
+	}
+}
+
+func TestReleaserUnreviewedCommit(t *testing.T) {
+	in, shas := input(t)
+	in.PRs = []ghexport.PullRequest{
+		pr(10, "carol", shas[0], approve("alice", 9)),
+		pr(11, "bob", shas[1], approve("alice", 9)),
+	}
+	in.Publisher = "bob"
+	rep := Evaluate(in)
+	if got := strings.Join(rules(rep), " "); got != RuleReleaserUnreviewed+":alice" {
+		t.Fatalf("violations = %q", got)
+	}
+	if len(rep.Unreviewed) != 1 || rep.Unreviewed[0].Reason != "no pull request" {
+		t.Fatalf("unreviewed = %+v", rep.Unreviewed)
+	}
+
+	// Bob publishing after his own PR lost its approval is a violation too.
+	in.PRs[1].Reviews = append(in.PRs[1].Reviews, ghexport.Review{
+		Author: ghexport.Actor{Login: "alice"}, State: "DISMISSED",
+		SubmittedAt: time.Date(2026, 3, 1, 11, 0, 0, 0, time.UTC),
+	})
+	rep = Evaluate(in)
+	if got := strings.Join(rules(rep), " "); !strings.Contains(got, RuleReleaserUnreviewed+":bob") {
+		t.Fatalf("violations = %q", got)
+	}
+}
+
+func TestRoleIndependence(t *testing.T) {
+	in, shas := input(t)
+	in.PRs = []ghexport.PullRequest{
+		// dave is a platform lead but also in payments with carol.
+		pr(10, "carol", shas[0], approve("dave", 9)),
+		pr(11, "bob", shas[1], approve("alice", 9)),
+		pr(12, "alice", shas[2], approve("erin", 9)),
+	}
+	rep := Evaluate(in)
+	if got := strings.Join(rules(rep), " "); got != RuleRoleIndependence+":dave" {
+		t.Fatalf("violations = %q", got)
+	}
+	if !strings.Contains(rep.Violations[0].Message, "payments") {
+		t.Fatalf("message = %q", rep.Violations[0].Message)
+	}
+
+	// A second, independent platform lead satisfies the role.
+	in.PRs[0].Reviews = append(in.PRs[0].Reviews, approve("alice", 10))
+	if rep := Evaluate(in); rep.Result != ResultPass {
+		t.Fatalf("violations = %v", rules(rep))
+	}
+}